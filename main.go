@@ -16,6 +16,7 @@ import (
 	_ "time/tzdata"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/api"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/boost"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
@@ -64,9 +65,15 @@ const slashSetServerBanner string = "set-server-banner"
 const slashActiveContracts string = "active-contracts"
 const slashStatusMessage string = "status-message"
 const slashAdminExit string = "admin-exit"
+const slashAdminAPIKey string = "admin-api-key"
+const slashAdminAMQPOutbox string = "admin-amqp-outbox"
+const slashAdminEIAPI string = "admin-ei-api"
 
 // Slash Command Constants
 const slashContract string = "contract"
+const slashContractTemplate string = "contract-template"
+const slashContractSchedule string = "contract-schedule"
+const slashCoopGroup string = "coop-group"
 const slashSkip string = "skip"
 const slashBoost string = "boost"
 const slashBoostOrder string = "boost-order"
@@ -89,6 +96,7 @@ const slashSpeedrun string = "speedrun"
 const slashCoopETA string = "coopeta"
 const slashLaunchHelper string = "launch-helper"
 const slashEventHelper string = "events"
+const slashMissionPlanner string = "mission-planner"
 
 // const slashTokenRemove string = "token-remove"
 const slashTokenEdit string = "token-edit"
@@ -113,10 +121,12 @@ const slashRemoveDMMessage string = "remove-dm-message"
 const slashPrivacy string = "privacy"
 const slashRerunEval string = "rerun-eval"
 const slashContractReport string = "contract-report"
+const slashContractHistory string = "contract-history"
 const slashVirtue string = "virtue"
 const slashRegister string = "register"
 const slashRegisterAlt string = "register-alt"
 const slashHunt string = "hunt"
+const slashCraft string = "craft"
 const slashPredictions string = "predictions"
 const slashPred string = "pred"
 const slashMint string = "mint"
@@ -291,6 +301,7 @@ var (
 		"active-contracts":        boost.HandleActiveContractsPage,
 		"admin-contract-list":     boost.HandleAdminContractListComponent,
 		"admin_exit":              boost.HandleAdminExitButton,
+		"coop_plan":               boost.HandleCoopGroupPlanButtons,
 		"fd_signupStart":          boost.HandleSignupStart,
 		"fd_signupFarmer":         boost.HandleSignupFarmer,
 		"fd_signupBell":           boost.HandleSignupBell,
@@ -303,6 +314,7 @@ var (
 		"leaderboard_perm":        boost.HandleLeaderboardPermissionButton,
 		"timer_btn":               dashboard.HandleTimerInteraction,
 		"dashboard_btn":           dashboard.HandleDashboardInteraction,
+		"mission_plan":            events.HandleMissionPlannerButtons,
 		"mint_preview":            mint.HandleMintPreviewComponent,
 		"chart":                   boost.HandleChartReactions,
 		"lb_list":                 leaderboard.HandleLBListComponent,
@@ -430,6 +442,21 @@ func setupCommands() {
 			Handler:      guildstate.GetGuildFlag,
 			Autocomplete: guildstate.HandleGuildFlagAutoComplete,
 		},
+		{
+			AppCmd:   guildstate.SlashAPIKeyCommand(slashAdminAPIKey),
+			Category: CmdCategoryAdmin,
+			Handler:  guildstate.HandleAPIKeyCommand,
+		},
+		{
+			AppCmd:   boost.SlashAdminAMQPOutboxCommand(slashAdminAMQPOutbox),
+			Category: CmdCategoryAdmin,
+			Handler:  boost.HandleAdminAMQPOutboxCommand,
+		},
+		{
+			AppCmd:   boost.SlashAdminEIAPICommand(slashAdminEIAPI),
+			Category: CmdCategoryAdmin,
+			Handler:  boost.HandleAdminEIAPICommand,
+		},
 		{
 			AppCmd:   guildstate.SlashAdminSetServerBannerCommand(slashAdminSetServerBanner),
 			Category: CmdCategoryAdmin,
@@ -452,6 +479,11 @@ func setupCommands() {
 			Category: CmdCategoryGlobal,
 			Handler:  events.HandleEventHelper,
 		},
+		{
+			AppCmd:   events.SlashMissionPlannerCommand(slashMissionPlanner),
+			Category: CmdCategoryGlobal,
+			Handler:  events.HandleMissionPlanner,
+		},
 		{
 			AppCmd:       boost.GetSlashRerunEvalCommand(slashRerunEval),
 			Category:     CmdCategoryGlobal,
@@ -480,6 +512,12 @@ func setupCommands() {
 			Handler:      menno.HandleHuntCommand,
 			Autocomplete: menno.HandleHuntAutoComplete,
 		},
+		{
+			AppCmd:       menno.SlashCraftCommand(slashCraft),
+			Category:     CmdCategoryGlobal,
+			Handler:      menno.HandleCraftCommand,
+			Autocomplete: menno.HandleHuntAutoComplete,
+		},
 
 		// Standard Commands
 		{
@@ -488,6 +526,23 @@ func setupCommands() {
 			Handler:      boost.HandleContractCommand,
 			Autocomplete: boost.HandleContractAutoComplete,
 		},
+		{
+			AppCmd:       boost.GetSlashContractTemplateCommand(slashContractTemplate),
+			Category:     CmdCategoryStandard,
+			Handler:      boost.HandleContractTemplateCommand,
+			Autocomplete: boost.HandleContractTemplateAutoComplete,
+		},
+		{
+			AppCmd:       boost.GetSlashContractScheduleCommand(slashContractSchedule),
+			Category:     CmdCategoryStandard,
+			Handler:      boost.HandleContractScheduleCommand,
+			Autocomplete: boost.HandleContractScheduleAutoComplete,
+		},
+		{
+			AppCmd:   boost.GetSlashCoopGroupCommand(slashCoopGroup),
+			Category: CmdCategoryStandard,
+			Handler:  boost.HandleCoopGroupCommand,
+		},
 		{
 			AppCmd:   boost.GetSlashSpeedrunCommand(slashSpeedrun),
 			Category: CmdCategoryStandard,
@@ -605,6 +660,11 @@ func setupCommands() {
 			Handler:      boost.HandleContractReport,
 			Autocomplete: boost.HandleAllContractsAutoComplete,
 		},
+		{
+			AppCmd:   boost.GetSlashContractHistoryCommand(slashContractHistory),
+			Category: CmdCategoryStandard,
+			Handler:  boost.HandleContractHistoryCommand,
+		},
 		{
 			AppCmd:   boost.GetPredictionsCommand(slashPredictions),
 			Category: CmdCategoryStandard,
@@ -990,6 +1050,15 @@ func main() {
 			log.Println(http.ListenAndServe("localhost:6060", nil))
 		}()
 	*/
+	if config.APIListenAddr != "" {
+		safeGoMeta("contract-api", withSessionHints(map[string]string{
+			"listen_addr": config.APIListenAddr,
+		}, s), func() {
+			if err := api.ListenAndServe(config.APIListenAddr); err != nil {
+				log.Printf("Contract API stopped: %v", err)
+			}
+		})
+	}
 	// Init Mongodb
 	//db.Open()
 	//defer db.Close()
@@ -1016,6 +1085,12 @@ func main() {
 
 	bottools.LoadEmotes(s, false)
 	dashboard.LaunchIndependentTimers(s)
+	safeGoMeta("amqp-outbox-replay", withSessionHints(map[string]string{
+		"job": "boost.ReplayAMQPOutboxes",
+	}, s), boost.ReplayAMQPOutboxes)
+	safeGoMeta("contract-scheduler", withSessionHints(map[string]string{
+		"job": "boost.StartContractScheduler",
+	}, s), func() { boost.StartContractScheduler(s) })
 	safeGoMeta("menno-startup", withSessionHints(map[string]string{
 		"job": "menno.Startup",
 	}, s), menno.Startup)
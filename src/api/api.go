@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/boost"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// guildForAPIKey resolves an API key to its guild; replaced in tests
+var guildForAPIKey = guildstate.GetGuildIDForAPIKey
+
+type guildHandlerFunc func(w http.ResponseWriter, r *http.Request, guildID string)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the read-only contract API.
+//
+//	GET /api/v1/contracts                       running contracts for the key's guild
+//	GET /api/v1/contracts/{hash}                one contract by ContractHash
+//	GET /api/v1/channels/{channelID}/contract   the contract running in a thread
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/contracts", withGuildAuth(handleListContracts))
+	mux.HandleFunc("GET /api/v1/contracts/{hash}", withGuildAuth(handleGetContract))
+	mux.HandleFunc("GET /api/v1/channels/{channelID}/contract", withGuildAuth(handleGetChannelContract))
+	return mux
+}
+
+// ListenAndServe starts the API server on addr and blocks until it fails.
+func ListenAndServe(addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           NewHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+	log.Printf("Contract API listening on %s", addr)
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// apiKeyFromRequest accepts either "Authorization: Bearer <key>" or "X-API-Key: <key>"
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if key, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(key)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+func withGuildAuth(next guildHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := apiKeyFromRequest(r)
+		if key == "" {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "missing API key"})
+			return
+		}
+		guildID, ok := guildForAPIKey(key)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid API key"})
+			return
+		}
+		next(w, r, guildID)
+	}
+}
+
+func handleListContracts(w http.ResponseWriter, _ *http.Request, guildID string) {
+	writeJSON(w, http.StatusOK, boost.GetGuildContractAPIViews(guildID))
+}
+
+func handleGetContract(w http.ResponseWriter, r *http.Request, guildID string) {
+	view := boost.GetContractAPIView(boost.FindContractByHash(r.PathValue("hash")), guildID)
+	writeContractView(w, view)
+}
+
+func handleGetChannelContract(w http.ResponseWriter, r *http.Request, guildID string) {
+	view := boost.GetContractAPIView(boost.FindContract(r.PathValue("channelID")), guildID)
+	writeContractView(w, view)
+}
+
+// writeContractView reports contracts of other guilds as missing so a key can't
+// be used to probe for them.
+func writeContractView(w http.ResponseWriter, view *boost.ContractAPIView) {
+	if view == nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "contract not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Contract API: error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/boost"
+)
+
+func TestContractAPIGuildScoping(t *testing.T) {
+	origResolver := guildForAPIKey
+	defer func() { guildForAPIKey = origResolver }()
+	guildForAPIKey = func(key string) (string, bool) {
+		switch key {
+		case "key-a":
+			return "guild-a", true
+		case "key-b":
+			return "guild-b", true
+		}
+		return "", false
+	}
+
+	contract := &boost.Contract{
+		ContractHash: "api-test-hash",
+		ContractID:   "api-test",
+		CoopID:       "coop",
+		CoopSize:     2,
+		State:        boost.ContractStateFastrun,
+		Location:     []*boost.LocationData{{GuildID: "guild-a", ChannelID: "chan-a"}},
+		Order:        []string{"u1", "u2"},
+		Boosters: map[string]*boost.Booster{
+			"u1": {UserID: "u1", Nick: "One", BoostState: boost.BoostStateBoosted, TokensReceived: 6, TokensWanted: 6},
+			"u2": {UserID: "u2", Nick: "Two", BoostState: boost.BoostStateTokenTime, TokensReceived: 2, TokensWanted: 6},
+		},
+		CurrentBoosterUserID: "u2",
+	}
+	boost.ContractsMutex.Lock()
+	boost.Contracts[contract.ContractHash] = contract
+	boost.ContractsMutex.Unlock()
+	defer func() {
+		boost.ContractsMutex.Lock()
+		delete(boost.Contracts, contract.ContractHash)
+		boost.ContractsMutex.Unlock()
+	}()
+
+	srv := httptest.NewServer(NewHandler())
+	defer srv.Close()
+
+	get := func(path, key string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		key    string
+		status int
+	}{
+		{"no key", "/api/v1/contracts", "", http.StatusUnauthorized},
+		{"bad key", "/api/v1/contracts", "nope", http.StatusUnauthorized},
+		{"own contract", "/api/v1/contracts/api-test-hash", "key-a", http.StatusOK},
+		{"own channel", "/api/v1/channels/chan-a/contract", "key-a", http.StatusOK},
+		{"other guild", "/api/v1/contracts/api-test-hash", "key-b", http.StatusNotFound},
+		{"missing", "/api/v1/contracts/unknown", "key-a", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := get(tt.path, tt.key)
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+
+	resp := get("/api/v1/contracts", "key-a")
+	defer func() { _ = resp.Body.Close() }()
+	var views []boost.ContractAPIView
+	if err := json.NewDecoder(resp.Body).Decode(&views); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(views) != 1 {
+		t.Fatalf("expected 1 contract, got %d", len(views))
+	}
+	v := views[0]
+	if v.State != "fastrun" || v.CurrentBooster != "u2" || len(v.Order) != 2 {
+		t.Errorf("unexpected view: %+v", v)
+	}
+	if v.Order[0].BoostState != "boosted" || v.Order[1].TokensReceived != 2 {
+		t.Errorf("unexpected booster views: %+v", v.Order)
+	}
+}
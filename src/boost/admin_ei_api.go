@@ -0,0 +1,73 @@
+package boost
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// SlashAdminEIAPICommand creates the command showing Egg Inc API traffic counters.
+func SlashAdminEIAPICommand(cmd string) *discordgo.ApplicationCommand {
+	var adminPermission = int64(0)
+	guildID := guildstate.GetGuildSettingString("DEFAULT", "home_guild")
+	if guildID == "" {
+		guildID = "DISABLED"
+	}
+	return &discordgo.ApplicationCommand{
+		Name:                     cmd,
+		Description:              "Show Egg Inc API request, throttle and retry counters",
+		GuildID:                  guildID,
+		DefaultMemberPermissions: &adminPermission,
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+		},
+	}
+}
+
+// HandleAdminEIAPICommand reports the shared Egg Inc API client counters.
+func HandleAdminEIAPICommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	flags := discordgo.MessageFlagsEphemeral
+	if !isAdminCommandCaller(s, i) {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You are not authorized to use this command.",
+				Flags:   flags,
+			},
+		})
+		return
+	}
+	bottools.AcknowledgeResponse(s, i, flags)
+
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: formatEIAPIStats(ei.DefaultClient().Stats()),
+		Flags:   flags,
+	}); err != nil {
+		log.Println("Error sending admin-ei-api follow-up message:", err)
+	}
+}
+
+func formatEIAPIStats(stats ei.ClientStats) string {
+	if len(stats.Endpoints) == 0 {
+		return "No Egg Inc API requests since the bot started."
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "**Egg Inc API**: %d requests, %d coalesced, %d throttled (%s waiting), %d retries, %d failures\n",
+		stats.Requests, stats.Coalesced, stats.Throttled, bottools.FmtDuration(stats.ThrottleWait), stats.Retries, stats.Failures)
+	builder.WriteString("```\n")
+	fmt.Fprintf(&builder, "%-32s %6s %5s %5s %5s %5s\n", "Endpoint", "Req", "Coal", "Thr", "Rtry", "Fail")
+	for _, name := range stats.EndpointNames() {
+		e := stats.Endpoints[name]
+		fmt.Fprintf(&builder, "%-32s %6d %5d %5d %5d %5d\n", name, e.Requests, e.Coalesced, e.Throttled, e.Retries, e.Failures)
+	}
+	builder.WriteString("```")
+	return builder.String()
+}
@@ -0,0 +1,31 @@
+package boost
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+)
+
+func TestFormatEIAPIStats(t *testing.T) {
+	if got := formatEIAPIStats(ei.ClientStats{}); !strings.Contains(got, "No Egg Inc API requests") {
+		t.Fatalf("unexpected empty stats %q", got)
+	}
+
+	stats := ei.ClientStats{
+		EndpointStats: ei.EndpointStats{Requests: 12, Coalesced: 3, Throttled: 2, Retries: 1},
+		ThrottleWait:  1500 * time.Millisecond,
+		Endpoints: map[string]ei.EndpointStats{
+			"/ei/coop_status_bot":           {Requests: 10, Coalesced: 3, Throttled: 2, Retries: 1},
+			"/ei_ctx/get_contracts_archive": {Requests: 2},
+		},
+	}
+	got := formatEIAPIStats(stats)
+	if !strings.Contains(got, "12 requests, 3 coalesced, 2 throttled") {
+		t.Fatalf("missing totals in %q", got)
+	}
+	if strings.Index(got, "/ei/coop_status_bot") > strings.Index(got, "/ei_ctx/get_contracts_archive") {
+		t.Fatalf("expected the busiest endpoint first in %q", got)
+	}
+}
@@ -2,8 +2,6 @@ package boost
 
 import (
 	"context"
-	"encoding/json"
-	"log"
 	"sync"
 	"time"
 
@@ -16,12 +14,16 @@ var (
 )
 
 // AMQPClient manages the AMQP connection and channel for a single guild.
+// Messages pass through the guild's outbox so none are lost while the broker is down.
 type AMQPClient struct {
-	guildID string
-	url     string
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	mu      sync.Mutex
+	guildID    string
+	url        string
+	conn       *amqp.Connection
+	channel    *amqp.Channel
+	send       func(body []byte) error // sends one message; replaced in tests
+	retryTimer *time.Timer
+	closed     bool
+	mu         sync.Mutex
 }
 
 // getAMQPClient retrieves or creates an AMQP client for a guild.
@@ -34,15 +36,21 @@ func getAMQPClient(guildID string, url string) *AMQPClient {
 		if exists {
 			client.Close()
 		}
-		client = &AMQPClient{
-			guildID: guildID,
-			url:     url,
-		}
+		client = newAMQPClient(guildID, url)
 		amqpClients[guildID] = client
 	}
 	return client
 }
 
+func newAMQPClient(guildID string, url string) *AMQPClient {
+	c := &AMQPClient{
+		guildID: guildID,
+		url:     url,
+	}
+	c.send = c.publishToBroker
+	return c
+}
+
 // connect establishes the connection and channel, declaring the "boost-bot" queue.
 func (c *AMQPClient) connect() error {
 	conn, err := amqp.Dial(c.url)
@@ -80,6 +88,16 @@ func (c *AMQPClient) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.closed = true
+	if c.retryTimer != nil {
+		c.retryTimer.Stop()
+		c.retryTimer = nil
+	}
+	c.disconnect()
+}
+
+// disconnect drops the connection so the next send reconnects. Caller holds c.mu.
+func (c *AMQPClient) disconnect() {
 	if c.channel != nil {
 		_ = c.channel.Close()
 		c.channel = nil
@@ -90,11 +108,8 @@ func (c *AMQPClient) Close() {
 	}
 }
 
-// publish sends a message to the "boost-bot" queue.
-func (c *AMQPClient) publish(body []byte) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
+// publishToBroker sends a message to the "boost-bot" queue. Caller holds c.mu.
+func (c *AMQPClient) publishToBroker(body []byte) error {
 	if c.conn == nil || c.conn.IsClosed() || c.channel == nil {
 		if err := c.connect(); err != nil {
 			return err
@@ -104,7 +119,7 @@ func (c *AMQPClient) publish(body []byte) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	return c.channel.PublishWithContext(
+	err := c.channel.PublishWithContext(
 		ctx,
 		"",          // exchange
 		"boost-bot", // routing key
@@ -116,6 +131,10 @@ func (c *AMQPClient) publish(body []byte) error {
 			Expiration:  "86400000", // 24 hours in milliseconds
 		},
 	)
+	if err != nil {
+		c.disconnect()
+	}
+	return err
 }
 
 // AMQPTokenMessage represents the structure of the token unit log sent over AMQP.
@@ -158,38 +177,6 @@ func getBoostStateString(state int) string {
 	}
 }
 
-// PublishAMQPTokenLog publishes a token log to the guild's AMQP queue.
-func PublishAMQPTokenLog(guildID string, url string, contractID string, coopID string, logEntry AMQPTokenMessage) {
-	body, err := json.Marshal(logEntry)
-	if err != nil {
-		log.Printf("AMQP: error marshaling token log: %v", err)
-		return
-	}
-
-	client := getAMQPClient(guildID, url)
-	go func() {
-		if err := client.publish(body); err != nil {
-			log.Printf("AMQP: error publishing token log: %v", err)
-		}
-	}()
-}
-
-// PublishAMQPBoostStatus publishes a boost status update to the guild's AMQP queue.
-func PublishAMQPBoostStatus(guildID string, url string, contractID string, coopID string, status AMQPBoostStatusMessage) {
-	body, err := json.Marshal(status)
-	if err != nil {
-		log.Printf("AMQP: error marshaling boost status: %v", err)
-		return
-	}
-
-	client := getAMQPClient(guildID, url)
-	go func() {
-		if err := client.publish(body); err != nil {
-			log.Printf("AMQP: error publishing boost status: %v", err)
-		}
-	}()
-}
-
 // AMQPContractStartMessage represents details of a contract when started.
 type AMQPContractStartMessage struct {
 	Event           string    `json:"event"`
@@ -204,22 +191,6 @@ type AMQPContractStartMessage struct {
 	MinutesPerToken int       `json:"minutes_per_token"`
 }
 
-// PublishAMQPContractStart publishes a contract start event to the guild's AMQP queue.
-func PublishAMQPContractStart(guildID string, url string, status AMQPContractStartMessage) {
-	body, err := json.Marshal(status)
-	if err != nil {
-		log.Printf("AMQP: error marshaling contract start: %v", err)
-		return
-	}
-
-	client := getAMQPClient(guildID, url)
-	go func() {
-		if err := client.publish(body); err != nil {
-			log.Printf("AMQP: error publishing contract start: %v", err)
-		}
-	}()
-}
-
 // AMQPNonTokenMessage represents a non-token delivery report.
 type AMQPNonTokenMessage struct {
 	Event      string    `json:"event"`
@@ -229,19 +200,3 @@ type AMQPNonTokenMessage struct {
 	Nick       string    `json:"nick"`
 	Time       time.Time `json:"time"`
 }
-
-// PublishAMQPNonToken publishes a non-token event to the guild's AMQP queue.
-func PublishAMQPNonToken(guildID string, url string, contractID string, coopID string, status AMQPNonTokenMessage) {
-	body, err := json.Marshal(status)
-	if err != nil {
-		log.Printf("AMQP: error marshaling non-token: %v", err)
-		return
-	}
-
-	client := getAMQPClient(guildID, url)
-	go func() {
-		if err := client.publish(body); err != nil {
-			log.Printf("AMQP: error publishing non-token: %v", err)
-		}
-	}()
-}
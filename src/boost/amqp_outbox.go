@@ -0,0 +1,245 @@
+package boost
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// amqpOutboxBatchSize is how many queued messages are read per pass when draining
+const amqpOutboxBatchSize = 100
+
+// amqpOutboxMaxDepth and amqpOutboxMaxAge bound a guild's outbox while its broker is down.
+// Past either limit the oldest messages are dropped.
+var (
+	amqpOutboxMaxDepth int64 = 10000
+	amqpOutboxMaxAge         = 7 * 24 * time.Hour
+)
+
+// amqpOutboxRetryDelay is how long to wait before retrying after the broker refused a message
+var amqpOutboxRetryDelay = 30 * time.Second
+
+// outboxPublisher is implemented by publishers that persist events before
+// delivering them, so the enqueue can happen in the caller's order.
+type outboxPublisher interface {
+	EventPublisher
+	enqueue(body []byte) error
+	flushOutbox() error
+}
+
+// enqueue appends a message to the guild's outbox.
+func (c *AMQPClient) enqueue(body []byte) error {
+	if queries == nil {
+		sqliteInit()
+	}
+	now := time.Now()
+	if err := queries.InsertAMQPOutbox(ctx, InsertAMQPOutboxParams{
+		Guildid:   c.guildID,
+		Body:      string(body),
+		CreatedAt: now.UnixMilli(),
+	}); err != nil {
+		return err
+	}
+	c.trimOutbox(now)
+	return nil
+}
+
+// trimOutbox drops the guild's messages that are too old or beyond the depth limit.
+func (c *AMQPClient) trimOutbox(now time.Time) {
+	expired, err := queries.DeleteAMQPOutboxBefore(ctx, DeleteAMQPOutboxBeforeParams{
+		Guildid:   c.guildID,
+		CreatedAt: now.Add(-amqpOutboxMaxAge).UnixMilli(),
+	})
+	if err != nil {
+		log.Printf("AMQP: error expiring outbox for guild %s: %v", c.guildID, err)
+	} else if expired > 0 {
+		log.Printf("AMQP: dropped %d outbox messages older than %s for guild %s", expired, amqpOutboxMaxAge, c.guildID)
+	}
+
+	overflow, err := queries.DeleteAMQPOutboxOverflow(ctx, DeleteAMQPOutboxOverflowParams{
+		Guildid: c.guildID,
+		Offset:  amqpOutboxMaxDepth,
+	})
+	if err != nil {
+		log.Printf("AMQP: error trimming outbox for guild %s: %v", c.guildID, err)
+	} else if overflow > 0 {
+		log.Printf("AMQP: outbox for guild %s is full, dropped the %d oldest messages", c.guildID, overflow)
+	}
+}
+
+// Publish queues a message in the outbox and sends everything pending for the guild.
+func (c *AMQPClient) Publish(body []byte) error {
+	if err := c.enqueue(body); err != nil {
+		log.Printf("AMQP: outbox unavailable for guild %s, publishing directly: %v", c.guildID, err)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.send(body)
+	}
+	return c.flushOutbox()
+}
+
+// flushOutbox sends queued messages oldest first. A message is only removed once the
+// broker accepted it, so delivery is at-least-once. On failure a retry is scheduled.
+func (c *AMQPClient) flushOutbox() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	if queries == nil {
+		sqliteInit()
+	}
+
+	for {
+		pending, err := queries.GetAMQPOutbox(ctx, GetAMQPOutboxParams{
+			Guildid: c.guildID,
+			Limit:   amqpOutboxBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		for _, msg := range pending {
+			if err := c.send([]byte(msg.Body)); err != nil {
+				c.scheduleRetry()
+				return err
+			}
+			if err := queries.DeleteAMQPOutbox(ctx, msg.ID); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// scheduleRetry arranges another drain of the outbox. Caller holds c.mu.
+func (c *AMQPClient) scheduleRetry() {
+	if c.retryTimer != nil || c.closed {
+		return
+	}
+	c.retryTimer = time.AfterFunc(amqpOutboxRetryDelay, func() {
+		c.mu.Lock()
+		c.retryTimer = nil
+		c.mu.Unlock()
+		if err := c.flushOutbox(); err != nil {
+			log.Printf("AMQP: outbox for guild %s still pending: %v", c.guildID, err)
+		}
+	})
+}
+
+// ReplayAMQPOutboxes drains every guild outbox left over from a previous run or outage.
+func ReplayAMQPOutboxes() {
+	if queries == nil {
+		sqliteInit()
+	}
+	stats, err := queries.GetAMQPOutboxStats(ctx)
+	if err != nil {
+		log.Printf("AMQP: error reading outbox: %v", err)
+		return
+	}
+	for _, st := range stats {
+		client, ok := getGuildEventPublisher(st.Guildid).(outboxPublisher)
+		if !ok {
+			log.Printf("AMQP: guild %s has %d queued messages but no AMQP sink", st.Guildid, st.Depth)
+			continue
+		}
+		if err := client.flushOutbox(); err != nil {
+			log.Printf("AMQP: replay for guild %s stopped: %v", st.Guildid, err)
+		}
+	}
+}
+
+// SlashAdminAMQPOutboxCommand creates the command showing queued AMQP messages.
+func SlashAdminAMQPOutboxCommand(cmd string) *discordgo.ApplicationCommand {
+	var adminPermission = int64(0)
+	return &discordgo.ApplicationCommand{
+		Name:                     cmd,
+		Description:              "Show AMQP messages waiting for the broker",
+		DefaultMemberPermissions: &adminPermission,
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+		},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "replay",
+				Description: "Try to deliver the queued messages now",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// HandleAdminAMQPOutboxCommand reports outbox depth and age. The bot admin sees every guild.
+func HandleAdminAMQPOutboxCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	flags := discordgo.MessageFlagsEphemeral
+	if !isAdminCommandCaller(s, i) {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "You are not authorized to use this command.",
+				Flags:   flags,
+			},
+		})
+		return
+	}
+	bottools.AcknowledgeResponse(s, i, flags)
+
+	allGuilds := getInteractionUserID(i) == config.AdminUserID
+	opts := bottools.GetCommandOptionsMap(i)
+	if opt, ok := opts["replay"]; ok && opt.BoolValue() {
+		if allGuilds {
+			ReplayAMQPOutboxes()
+		} else if client, ok := getGuildEventPublisher(i.GuildID).(outboxPublisher); ok {
+			if err := client.flushOutbox(); err != nil {
+				log.Printf("AMQP: replay for guild %s stopped: %v", i.GuildID, err)
+			}
+		}
+	}
+
+	content := getAMQPOutboxStatus(i.GuildID, allGuilds, time.Now())
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   flags,
+	}); err != nil {
+		log.Println("Error sending admin-amqp-outbox follow-up message:", err)
+	}
+}
+
+func getAMQPOutboxStatus(guildID string, allGuilds bool, now time.Time) string {
+	if queries == nil {
+		sqliteInit()
+	}
+	stats, err := queries.GetAMQPOutboxStats(ctx)
+	if err != nil {
+		return fmt.Sprintf("Unable to read the AMQP outbox: %v", err)
+	}
+
+	var builder strings.Builder
+	for _, st := range stats {
+		if !allGuilds && st.Guildid != guildID {
+			continue
+		}
+		age := now.Sub(time.UnixMilli(st.Oldest)).Round(time.Second)
+		name := st.Guildid
+		if url := guildstate.GetGuildSettingString(st.Guildid, amqpURLSetting); url == "" {
+			name += " (no amqp_url)"
+		}
+		fmt.Fprintf(&builder, "**%s**: %d queued, oldest %s ago\n", name, st.Depth, bottools.FmtDuration(age))
+	}
+	if builder.Len() == 0 {
+		return "The AMQP outbox is empty."
+	}
+	return builder.String()
+}
@@ -2,6 +2,8 @@ package boost
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -152,3 +154,94 @@ func TestSaveSqliteDataAMQPTrigger(t *testing.T) {
 		t.Errorf("Expected LastPublishedTokenLogIndex to be 1")
 	}
 }
+
+func TestAMQPOutboxReplaysInOrder(t *testing.T) {
+	useInMemoryContractDB(t)
+
+	origDelay := amqpOutboxRetryDelay
+	amqpOutboxRetryDelay = time.Hour
+	defer func() { amqpOutboxRetryDelay = origDelay }()
+
+	brokerUp := false
+	var sent []string
+	client := newAMQPClient("guild-1", "amqp://unused")
+	defer client.Close()
+	client.send = func(body []byte) error {
+		if !brokerUp {
+			return errors.New("connection refused")
+		}
+		sent = append(sent, string(body))
+		return nil
+	}
+
+	for _, body := range []string{"1", "2", "3"} {
+		if err := client.Publish([]byte(body)); err == nil {
+			t.Fatalf("expected publish of %s to fail while the broker is down", body)
+		}
+	}
+
+	stats, err := queries.GetAMQPOutboxStats(ctx)
+	if err != nil {
+		t.Fatalf("GetAMQPOutboxStats failed: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Guildid != "guild-1" || stats[0].Depth != 3 {
+		t.Fatalf("unexpected outbox stats %+v", stats)
+	}
+	status := getAMQPOutboxStatus("guild-1", false, time.UnixMilli(stats[0].Oldest).Add(90*time.Second))
+	if !strings.Contains(status, "3 queued") || !strings.Contains(status, "1m30s") {
+		t.Errorf("unexpected status %q", status)
+	}
+	if status := getAMQPOutboxStatus("guild-2", false, time.Now()); status != "The AMQP outbox is empty." {
+		t.Errorf("other guilds should not see the queue, got %q", status)
+	}
+
+	brokerUp = true
+	if err := client.Publish([]byte("4")); err != nil {
+		t.Fatalf("Publish failed after reconnect: %v", err)
+	}
+	if strings.Join(sent, ",") != "1,2,3,4" {
+		t.Errorf("messages replayed out of order: %v", sent)
+	}
+
+	stats, err = queries.GetAMQPOutboxStats(ctx)
+	if err != nil || len(stats) != 0 {
+		t.Errorf("expected an empty outbox, got %+v (err=%v)", stats, err)
+	}
+}
+
+func TestAMQPOutboxDropsOldestMessages(t *testing.T) {
+	useInMemoryContractDB(t)
+
+	origDelay, origDepth := amqpOutboxRetryDelay, amqpOutboxMaxDepth
+	amqpOutboxRetryDelay = time.Hour
+	amqpOutboxMaxDepth = 2
+	defer func() { amqpOutboxRetryDelay, amqpOutboxMaxDepth = origDelay, origDepth }()
+
+	client := newAMQPClient("guild-1", "amqp://unused")
+	defer client.Close()
+	var sent []string
+	client.send = func(body []byte) error { return errors.New("connection refused") }
+
+	// A message queued before the age limit is expired on the next enqueue
+	if err := queries.InsertAMQPOutbox(ctx, InsertAMQPOutboxParams{
+		Guildid:   "guild-1",
+		Body:      "stale",
+		CreatedAt: time.Now().Add(-amqpOutboxMaxAge - time.Minute).UnixMilli(),
+	}); err != nil {
+		t.Fatalf("InsertAMQPOutbox failed: %v", err)
+	}
+	for _, body := range []string{"1", "2", "3"} {
+		_ = client.Publish([]byte(body))
+	}
+
+	client.send = func(body []byte) error {
+		sent = append(sent, string(body))
+		return nil
+	}
+	if err := client.flushOutbox(); err != nil {
+		t.Fatalf("flushOutbox failed: %v", err)
+	}
+	if strings.Join(sent, ",") != "2,3" {
+		t.Errorf("expected only the newest messages to survive, sent %v", sent)
+	}
+}
@@ -25,6 +25,23 @@ func GetSlashAvailabilityCommand(cmd string) *discordgo.ApplicationCommand {
 	}
 }
 
+// availabilitySlotLabels are the /availability hour offsets from the contract drop
+var availabilitySlotLabels = map[string]string{
+	"00-01": "+0", "01-02": "+1", "02-03": "+2", "03-04": "+3",
+	"04-05": "+4", "05-06": "+5", "06-07": "+6", "07-08": "+7", "08-09": "+8",
+	"20-21": "-4", "21-22": "-3", "22-23": "-2", "23-24": "-1",
+}
+
+// availabilityAnyTime reports whether a farmer picked every offset, which means any time
+func availabilityAnyTime(slots []string) bool {
+	for slot := range availabilitySlotLabels {
+		if !slices.Contains(slots, slot) {
+			return false
+		}
+	}
+	return true
+}
+
 // GetAvailabilityComponents returns the components for the availability command
 func GetAvailabilityComponents(s *discordgo.Session, contract *Contract, userID string) []discordgo.MessageComponent {
 	isCoord := creatorOfContract(s, contract, userID)
@@ -32,17 +49,11 @@ func GetAvailabilityComponents(s *discordgo.Session, contract *Contract, userID
 
 	var out []discordgo.MessageComponent
 
-	timeLabels := map[string]string{
-		"00-01": "+0", "01-02": "+1", "02-03": "+2", "03-04": "+3",
-		"04-05": "+4", "05-06": "+5", "06-07": "+6", "07-08": "+7", "08-09": "+8",
-		"20-21": "-4", "21-22": "-3", "22-23": "-2", "23-24": "-1",
-	}
-
 	formatTimes := func(slots []string) string {
 		if len(slots) == 0 {
 			return "Not set"
 		}
-		if len(slots) == len(timeLabels) {
+		if availabilityAnyTime(slots) {
 			return "Any"
 		}
 		sorted := make([]string, len(slots))
@@ -50,7 +61,7 @@ func GetAvailabilityComponents(s *discordgo.Session, contract *Contract, userID
 		sort.Strings(sorted)
 		var short []string
 		for _, s := range sorted {
-			if l, ok := timeLabels[s]; ok {
+			if l, ok := availabilitySlotLabels[s]; ok {
 				short = append(short, l)
 			} else {
 				short = append(short, s)
@@ -180,6 +191,10 @@ func GetAvailabilityComponents(s *discordgo.Session, contract *Contract, userID
 			}
 		}
 
+		if contract.BoostOrder == ContractOrderAvailability && contract.State == ContractStateSignup {
+			report.WriteString(availabilityOrderReport(contract))
+		}
+
 		out = append([]discordgo.MessageComponent{
 			&discordgo.TextDisplay{Content: report.String()},
 		}, out...)
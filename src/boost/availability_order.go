@@ -0,0 +1,379 @@
+package boost
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+)
+
+// availabilityDefaultBoostDuration is used when a farmer's boost time can't be estimated
+const availabilityDefaultBoostDuration = 15 * time.Minute
+
+// availabilityWindow is a span of time a farmer is available to boost
+type availabilityWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+var availabilityOffsetRe = regexp.MustCompile(`^(\d{1,2})-(\d{1,2})$`)
+var availabilityDayRe = regexp.MustCompile(`(?i)^(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?\s+(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\s*-\s*(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\s+(\S+)$`)
+
+var availabilityWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseAvailabilitySlot turns a timeslot into a time range. Slots are either the
+// hour offsets from /availability ("00-01", "23-24" is the hour before the
+// reference) or a weekday range with a time zone ("Monday 9-11am PT"), which
+// resolves to the first occurrence ending after the reference time.
+func parseAvailabilitySlot(slot string, reference time.Time) (availabilityWindow, error) {
+	slot = strings.TrimSpace(slot)
+
+	if m := availabilityOffsetRe.FindStringSubmatch(slot); m != nil {
+		from, _ := strconv.Atoi(m[1])
+		to, _ := strconv.Atoi(m[2])
+		if from > 24 || to > 24 || to <= from {
+			return availabilityWindow{}, fmt.Errorf("invalid hour range %q", slot)
+		}
+		// The late evening offsets are the hours before the contract drop
+		if from >= 12 {
+			from -= 24
+			to -= 24
+		}
+		return availabilityWindow{
+			Start: reference.Add(time.Duration(from) * time.Hour),
+			End:   reference.Add(time.Duration(to) * time.Hour),
+		}, nil
+	}
+
+	m := availabilityDayRe.FindStringSubmatch(slot)
+	if m == nil {
+		return availabilityWindow{}, fmt.Errorf("unrecognized timeslot %q", slot)
+	}
+	weekday := availabilityWeekdays[strings.ToLower(m[1])]
+
+	loc, err := bottools.ParseTimeZone(m[8])
+	if err != nil {
+		return availabilityWindow{}, err
+	}
+
+	startHour, _ := strconv.Atoi(m[2])
+	endHour, _ := strconv.Atoi(m[5])
+	startMin, endMin := 0, 0
+	if m[3] != "" {
+		startMin, _ = strconv.Atoi(m[3])
+	}
+	if m[6] != "" {
+		endMin, _ = strconv.Atoi(m[6])
+	}
+	startMeridiem := strings.ToLower(m[4])
+	endMeridiem := strings.ToLower(m[7])
+
+	end, ok := availabilityClock(endHour, endMin, endMeridiem)
+	if !ok {
+		return availabilityWindow{}, fmt.Errorf("invalid end time in %q", slot)
+	}
+	var start time.Duration
+	if startMeridiem == "" && endMeridiem != "" {
+		// "9-11am" shares the meridiem, "11-1pm" crosses noon
+		start, ok = availabilityClock(startHour, startMin, endMeridiem)
+		if ok && start >= end {
+			other := map[string]string{"am": "pm", "pm": "am"}[endMeridiem]
+			start, ok = availabilityClock(startHour, startMin, other)
+		}
+	} else {
+		start, ok = availabilityClock(startHour, startMin, startMeridiem)
+	}
+	if !ok {
+		return availabilityWindow{}, fmt.Errorf("invalid start time in %q", slot)
+	}
+	length := end - start
+	if length <= 0 {
+		// Ranges like "10pm-1am" run past midnight
+		length += 24 * time.Hour
+	}
+
+	local := reference.In(loc)
+	for offset := -7; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		if day.Weekday() != weekday {
+			continue
+		}
+		window := availabilityWindow{Start: day.Add(start), End: day.Add(start + length)}
+		if window.End.After(reference) {
+			return window, nil
+		}
+	}
+	return availabilityWindow{}, fmt.Errorf("no upcoming window for %q", slot)
+}
+
+// availabilityClock converts an hour and minute into time since midnight
+func availabilityClock(hour int, minute int, meridiem string) (time.Duration, bool) {
+	if minute > 59 {
+		return 0, false
+	}
+	switch meridiem {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		hour %= 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 24 {
+			return 0, false
+		}
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+// availabilityWindows parses and merges a farmer's timeslots, returning any slots that couldn't be parsed
+func availabilityWindows(slots []string, reference time.Time) ([]availabilityWindow, []string) {
+	var windows []availabilityWindow
+	var invalid []string
+	for _, slot := range slots {
+		window, err := parseAvailabilitySlot(slot, reference)
+		if err != nil {
+			invalid = append(invalid, slot)
+			continue
+		}
+		windows = append(windows, window)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+
+	// Adjacent hour slots become one window
+	var merged []availabilityWindow
+	for _, window := range windows {
+		if n := len(merged); n > 0 && !window.Start.After(merged[n-1].End) {
+			if window.End.After(merged[n-1].End) {
+				merged[n-1].End = window.End
+			}
+			continue
+		}
+		merged = append(merged, window)
+	}
+	return merged, invalid
+}
+
+// availabilityTurn is a farmer's planned boost turn
+type availabilityTurn struct {
+	UserID   string
+	Start    time.Time
+	Duration time.Duration
+	Conflict bool // True when the turn falls outside the farmer's availability
+}
+
+// availabilitySchedule is the planned boost order for the availability ordering
+type availabilitySchedule struct {
+	Turns     []availabilityTurn
+	Conflicts []string
+}
+
+// availabilityReference is the time the /availability hour offsets are measured from,
+// the contract drop when it is known
+func availabilityReference(contract *Contract) time.Time {
+	if !contract.ValidFrom.IsZero() {
+		return contract.ValidFrom
+	}
+	if !contract.PlannedStartTime.IsZero() {
+		return contract.PlannedStartTime
+	}
+	if !contract.StartTime.IsZero() {
+		return contract.StartTime
+	}
+	return time.Now()
+}
+
+// availabilityBoostDuration is how long a farmer's boost turn is expected to take
+func availabilityBoostDuration(b *Booster) time.Duration {
+	if b.EstDurationOfBoost > 0 {
+		return b.EstDurationOfBoost
+	}
+	if d, _, ok := estimateBoostDuration(b); ok && d > 0 {
+		return d
+	}
+	return availabilityDefaultBoostDuration
+}
+
+// scheduleAvailabilityOrder plans boost turns starting at start so each turn
+// sits inside the farmer's availability. Farmers ready to boost go earliest
+// deadline first, farmers without availability fill the gaps, and those that
+// can't be fit go last and are reported as conflicts.
+func scheduleAvailabilityOrder(contract *Contract, reference time.Time, start time.Time) availabilitySchedule {
+	type candidate struct {
+		userID   string
+		duration time.Duration
+		windows  []availabilityWindow
+	}
+
+	var remaining []candidate
+	for _, userID := range contract.Order {
+		b := contract.Boosters[userID]
+		if b == nil {
+			continue
+		}
+		var windows []availabilityWindow
+		if !availabilityAnyTime(b.Availability.Timeslots) {
+			windows, _ = availabilityWindows(b.Availability.Timeslots, reference)
+		}
+		remaining = append(remaining, candidate{
+			userID:   userID,
+			duration: availabilityBoostDuration(b),
+			windows:  windows,
+		})
+	}
+
+	// nextStart is the earliest start at or after t that fits a window, and the latest start in that window
+	nextStart := func(c candidate, t time.Time) (time.Time, time.Time, bool) {
+		for _, w := range c.windows {
+			begin := w.Start
+			if t.After(begin) {
+				begin = t
+			}
+			if !begin.Add(c.duration).After(w.End) {
+				return begin, w.End.Add(-c.duration), true
+			}
+		}
+		return time.Time{}, time.Time{}, false
+	}
+
+	var schedule availabilitySchedule
+	t := start
+	for len(remaining) > 0 {
+		best := -1
+		var bestDeadline time.Time
+		var nextTime time.Time
+		for i, c := range remaining {
+			if len(c.windows) == 0 {
+				// Anytime farmers only fill gaps
+				if best == -1 {
+					best = i
+				}
+				continue
+			}
+			begin, deadline, ok := nextStart(c, t)
+			if !ok {
+				continue
+			}
+			if begin.Equal(t) {
+				if best == -1 || len(remaining[best].windows) == 0 || deadline.Before(bestDeadline) {
+					best = i
+					bestDeadline = deadline
+				}
+			} else if nextTime.IsZero() || begin.Before(nextTime) {
+				nextTime = begin
+			}
+		}
+
+		if best >= 0 {
+			c := remaining[best]
+			schedule.Turns = append(schedule.Turns, availabilityTurn{UserID: c.userID, Start: t, Duration: c.duration})
+			t = t.Add(c.duration)
+			remaining = slices.Delete(remaining, best, best+1)
+			continue
+		}
+		if !nextTime.IsZero() {
+			// Nobody can boost yet, wait for the next window to open
+			t = nextTime
+			continue
+		}
+
+		// Everyone left has missed their windows
+		for _, c := range remaining {
+			schedule.Turns = append(schedule.Turns, availabilityTurn{UserID: c.userID, Start: t, Duration: c.duration, Conflict: true})
+			schedule.Conflicts = append(schedule.Conflicts, c.userID)
+			t = t.Add(c.duration)
+		}
+		break
+	}
+	return schedule
+}
+
+// availabilityOrder schedules each boost turn inside the farmer's availability
+type availabilityOrder struct{}
+
+func (availabilityOrder) Info() BoostOrderInfo {
+	return BoostOrderInfo{ID: ContractOrderAvailability, Name: "Availability", Key: "avail",
+		Label: "Availability Order", Description: "Boost turns fit each farmer's availability", Emoji: "signup",
+		Choice: "Availability Ordering"}
+}
+
+func (availabilityOrder) Reorder(contract *Contract) {
+	schedule := scheduleAvailabilityOrder(contract, availabilityReference(contract), time.Now())
+	order := make([]string, 0, len(schedule.Turns))
+	for _, turn := range schedule.Turns {
+		order = append(order, turn.UserID)
+	}
+	contract.Order = order
+	contract.availabilityConflicts = schedule.Conflicts
+}
+
+// availabilityConflictMessage tells the coordinator which farmers couldn't be fit in their availability
+func availabilityConflictMessage(contract *Contract) string {
+	if len(contract.availabilityConflicts) == 0 {
+		return ""
+	}
+	var names []string
+	for _, userID := range contract.availabilityConflicts {
+		if b := contract.Boosters[userID]; b != nil {
+			names = append(names, b.Mention)
+		}
+	}
+	coordinator := ""
+	if len(contract.CreatorID) > 0 {
+		coordinator = fmt.Sprintf("<@%s> ", contract.CreatorID[0])
+	}
+	return fmt.Sprintf("%sNo availability order fits everyone, these farmers were placed at the end outside their availability: %s",
+		coordinator, strings.Join(names, ", "))
+}
+
+// availabilityOrderReport previews the availability order for the coordinator
+func availabilityOrderReport(contract *Contract) string {
+	start := time.Now()
+	if !contract.PlannedStartTime.IsZero() && contract.PlannedStartTime.After(start) {
+		start = contract.PlannedStartTime
+	}
+	schedule := scheduleAvailabilityOrder(contract, availabilityReference(contract), start)
+
+	var report strings.Builder
+	report.WriteString("### Availability Order\n")
+	for idx, turn := range schedule.Turns {
+		name := turn.UserID
+		if b := contract.Boosters[turn.UserID]; b != nil {
+			name = b.Nick
+		}
+		marker := ""
+		if turn.Conflict {
+			marker = " ⚠️ outside availability"
+		}
+		fmt.Fprintf(&report, "> %d. **%s** <t:%d:t>%s\n", idx+1, name, turn.Start.Unix(), marker)
+	}
+	if len(schedule.Conflicts) > 0 {
+		report.WriteString("> *No order fits everyone's availability.*\n")
+	}
+	return report.String()
+}
+
+// sendAvailabilityConflicts warns the coordinator when the availability order couldn't fit everyone
+func sendAvailabilityConflicts(s *discordgo.Session, contract *Contract) {
+	msg := availabilityConflictMessage(contract)
+	if msg == "" {
+		return
+	}
+	for _, loc := range contract.Location {
+		_, _ = s.ChannelMessageSend(loc.ChannelID, msg)
+	}
+	contract.availabilityConflicts = nil
+}
@@ -0,0 +1,162 @@
+package boost
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAvailabilitySlotOffsets(t *testing.T) {
+	ref := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+
+	w, err := parseAvailabilitySlot("02-03", ref)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !w.Start.Equal(ref.Add(2*time.Hour)) || !w.End.Equal(ref.Add(3*time.Hour)) {
+		t.Fatalf("unexpected window %v - %v", w.Start, w.End)
+	}
+
+	w, err = parseAvailabilitySlot("23-24", ref)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !w.Start.Equal(ref.Add(-time.Hour)) || !w.End.Equal(ref) {
+		t.Fatalf("expected the hour before the drop, got %v - %v", w.Start, w.End)
+	}
+}
+
+func TestParseAvailabilitySlotWeekday(t *testing.T) {
+	// Sunday 2026-10-18 12:00 UTC
+	ref := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	pacific, _ := time.LoadLocation("America/Los_Angeles")
+
+	tests := []struct {
+		slot  string
+		start time.Time
+		end   time.Time
+	}{
+		{"Monday 9-11am PT", time.Date(2026, 10, 19, 9, 0, 0, 0, pacific), time.Date(2026, 10, 19, 11, 0, 0, 0, pacific)},
+		{"mon 11-1pm pt", time.Date(2026, 10, 19, 11, 0, 0, 0, pacific), time.Date(2026, 10, 19, 13, 0, 0, 0, pacific)},
+		{"Sat 10pm-1am UTC", time.Date(2026, 10, 24, 22, 0, 0, 0, time.UTC), time.Date(2026, 10, 25, 1, 0, 0, 0, time.UTC)},
+		{"Sunday 12:30-14:00 Europe/London", time.Date(2026, 10, 18, 11, 30, 0, 0, time.UTC), time.Date(2026, 10, 18, 13, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		w, err := parseAvailabilitySlot(tt.slot, ref)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.slot, err)
+		}
+		if !w.Start.Equal(tt.start) || !w.End.Equal(tt.end) {
+			t.Fatalf("%q: expected %v - %v, got %v - %v", tt.slot, tt.start, tt.end, w.Start, w.End)
+		}
+	}
+
+	for _, slot := range []string{"whenever", "Monday 9-11am Mars", "Monday 13-14pm PT", "25-26"} {
+		if _, err := parseAvailabilitySlot(slot, ref); err == nil {
+			t.Fatalf("%q: expected an error", slot)
+		}
+	}
+}
+
+func TestAvailabilityWindowsMergeAdjacentSlots(t *testing.T) {
+	ref := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	windows, invalid := availabilityWindows([]string{"01-02", "00-01", "04-05", "bogus"}, ref)
+	if !reflect.DeepEqual(invalid, []string{"bogus"}) {
+		t.Fatalf("unexpected invalid slots %v", invalid)
+	}
+	if len(windows) != 2 || !windows[0].End.Equal(ref.Add(2*time.Hour)) || !windows[1].Start.Equal(ref.Add(4*time.Hour)) {
+		t.Fatalf("unexpected windows %v", windows)
+	}
+}
+
+func availabilityTestContract(slots map[string][]string, ids ...string) *Contract {
+	boosters := make(map[string]*Booster)
+	for _, id := range ids {
+		boosters[id] = &Booster{
+			UserID:             id,
+			Nick:               id,
+			EstDurationOfBoost: 30 * time.Minute,
+			Availability:       ContractAvailability{Timeslots: slots[id]},
+		}
+	}
+	return &Contract{BoostOrder: ContractOrderAvailability, Order: ids, Boosters: boosters}
+}
+
+func TestScheduleAvailabilityOrder(t *testing.T) {
+	ref := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	// u1 can only boost in the third hour, u2 only in the first, u3 anytime
+	contract := availabilityTestContract(map[string][]string{
+		"u1": {"02-03"},
+		"u2": {"00-01"},
+	}, "u1", "u2", "u3")
+
+	schedule := scheduleAvailabilityOrder(contract, ref, ref)
+	var order []string
+	for _, turn := range schedule.Turns {
+		order = append(order, turn.UserID)
+	}
+	if want := []string{"u2", "u3", "u1"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("expected %v, got %v", want, order)
+	}
+	if len(schedule.Conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %v", schedule.Conflicts)
+	}
+	if !schedule.Turns[2].Start.Equal(ref.Add(2 * time.Hour)) {
+		t.Fatalf("expected u1 to wait for their window, got %v", schedule.Turns[2].Start)
+	}
+}
+
+func TestScheduleAvailabilityOrderConflicts(t *testing.T) {
+	ref := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	// Two 30 minute turns fit in the shared hour, the third farmer misses it
+	contract := availabilityTestContract(map[string][]string{
+		"u1": {"00-01"},
+		"u2": {"00-01"},
+		"u3": {"00-01"},
+	}, "u1", "u2", "u3")
+
+	schedule := scheduleAvailabilityOrder(contract, ref, ref)
+	if !reflect.DeepEqual(schedule.Conflicts, []string{"u3"}) {
+		t.Fatalf("expected u3 to conflict, got %v", schedule.Conflicts)
+	}
+	if !schedule.Turns[2].Conflict {
+		t.Fatalf("expected the last turn to be marked as a conflict")
+	}
+
+	contract.Order = []string{"u1", "u2", "u3"}
+	reorderBoosters(contract)
+	if msg := availabilityConflictMessage(contract); msg == "" {
+		t.Fatalf("expected a conflict warning after reordering")
+	}
+}
+
+func TestScheduleAvailabilityOrderAnyTime(t *testing.T) {
+	ref := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	var everySlot []string
+	for slot := range availabilitySlotLabels {
+		everySlot = append(everySlot, slot)
+	}
+	// Picking every offset is as unrestricted as picking none, even long after the drop
+	contract := availabilityTestContract(map[string][]string{"u1": everySlot}, "u1", "u2")
+
+	schedule := scheduleAvailabilityOrder(contract, ref, ref.Add(12*time.Hour))
+	if len(schedule.Conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %v", schedule.Conflicts)
+	}
+	if schedule.Turns[0].UserID != "u1" {
+		t.Fatalf("expected u1 to keep their place, got %v", schedule.Turns)
+	}
+}
+
+func TestAvailabilityReferenceUsesContractDrop(t *testing.T) {
+	drop := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	contract := &Contract{ValidFrom: drop, PlannedStartTime: drop.Add(5 * time.Hour), StartTime: drop.Add(6 * time.Hour)}
+	if got := availabilityReference(contract); !got.Equal(drop) {
+		t.Errorf("availabilityReference() = %v, want the contract drop %v", got, drop)
+	}
+
+	contract.ValidFrom = time.Time{}
+	if got := availabilityReference(contract); !got.Equal(contract.PlannedStartTime) {
+		t.Errorf("availabilityReference() = %v, want the planned start %v without a drop time", got, contract.PlannedStartTime)
+	}
+}
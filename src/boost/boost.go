@@ -9,11 +9,9 @@ import (
 	"image"
 	"log"
 	"math"
-	"math/rand/v2"
 	"os"
 	"path/filepath"
 	"slices"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -232,6 +230,7 @@ func changeContractState(contract *Contract, newstate int) {
 		contract.Banker.CurrentBanker = ""
 	}
 
+	recordContractEvent(contract, ContractEventState, "", "")
 }
 
 // DeleteContract will delete the contract
@@ -262,6 +261,7 @@ func DeleteContract(s *discordgo.Session, guildID string, channelID string) (str
 	ContractsMutex.Lock()
 	delete(Contracts, coopHash)
 	ContractsMutex.Unlock()
+	discardCoopGroupPlan(coopHash)
 
 	return coopName, nil
 }
@@ -359,8 +359,8 @@ func getBoostOrderString(contract *Contract) string {
 	case ContractOrderELR:
 		return fmt.Sprintf("Egg Lay Rate order (%s)", bottools.GetFormattedCommand("artifact"))
 	}
-	if contract.BoostOrder >= 0 && contract.BoostOrder < len(contractOrderNames) {
-		return contractOrderNames[contract.BoostOrder]
+	if strategy, ok := getBoostOrderStrategy(contract.BoostOrder); ok {
+		return strategy.Info().Name
 	}
 	return "Unknown"
 }
@@ -415,6 +415,9 @@ func AddBoostTokens(s *discordgo.Session, i *discordgo.InteractionCreate, setCou
 	if b.TokensWanted < 0 {
 		b.TokensWanted = 0
 	}
+	if setCountWant > 0 || countWantAdjust != 0 {
+		b.TokensWantedManual = true
+	}
 
 	if (ContractFlagDynamicTokens+ContractFlag8Tokens+ContractFlag6Tokens+ContractFlag4Tokens+ContractFlagThresholdTokens)&contract.Style == 0 {
 		// Only set this if the contract isn't controlling the wanted tokens
@@ -1678,6 +1681,7 @@ func StartContractBoosting(s *discordgo.Session, guildID string, channelID strin
 
 	reorderBoosters(contract)
 	contract.OriginalOrder = append([]string(nil), contract.Order...)
+	sendAvailabilityConflicts(s, contract)
 
 	// Set tokens...
 	for i := range contract.Boosters {
@@ -1704,6 +1708,8 @@ func StartContractBoosting(s *discordgo.Session, guildID string, channelID strin
 		}
 	}
 
+	determineDynamicTokens(contract)
+
 	contract.setCurrentBoosterByIndex(0)
 	contract.StartTime = time.Now()
 	currentBooster := contract.currentBooster()
@@ -1728,8 +1734,8 @@ func StartContractBoosting(s *discordgo.Session, guildID string, channelID strin
 	if contract.Style&ContractFlagAMQP != 0 && len(contract.Location) > 0 {
 		guildID := contract.Location[0].GuildID
 		if guildID != "" {
-			amqpURL := guildstate.GetGuildSettingString(guildID, "amqp_url")
-			if amqpURL != "" {
+			publisher := getGuildEventPublisher(guildID)
+			if publisher != nil {
 				var deliveryTarget float64
 				if contractInfo, ok := ei.EggIncContractsAll[contract.ContractID]; ok && len(contractInfo.TargetAmount) > 0 {
 					deliveryTarget = contractInfo.TargetAmount[len(contractInfo.TargetAmount)-1]
@@ -1745,7 +1751,7 @@ func StartContractBoosting(s *discordgo.Session, guildID string, channelID strin
 					ggMultiplier = gg
 				}
 
-				PublishAMQPContractStart(guildID, amqpURL, AMQPContractStartMessage{
+				PublishContractStart(publisher, AMQPContractStartMessage{
 					Event:           "contract_start",
 					ContractID:      contract.ContractID,
 					CoopID:          contract.CoopID,
@@ -1780,7 +1786,7 @@ func UserBoost(s *discordgo.Session, guildID string, channelID string, userID st
 
 	if currentID := contract.currentBoosterID(); currentID != "" && userID == currentID {
 		// User is using /boost command instead of reaction
-		_ = Boosting(s, guildID, channelID)
+		_ = Boosting(s, guildID, channelID, userID)
 	} else {
 		for i := range contract.Order {
 			if contract.Order[i] == userID {
@@ -1802,6 +1808,7 @@ func UserBoost(s *discordgo.Session, guildID string, channelID string, userID st
 				}
 				contract.Boosters[contract.Order[i]].EndTime = time.Now()
 				contract.Boosters[contract.Order[i]].Duration = time.Since(contract.Boosters[contract.Order[i]].StartTime)
+				recordContractEvent(contract, ContractEventBoost, userID, userID)
 				sendNextNotification(s, contract, false)
 				return nil
 			}
@@ -1812,8 +1819,9 @@ func UserBoost(s *discordgo.Session, guildID string, channelID string, userID st
 	return nil
 }
 
-// Boosting will mark a as boosted and advance to the next in the list
-func Boosting(s *discordgo.Session, guildID string, channelID string) error {
+// Boosting will mark a as boosted and advance to the next in the list,
+// actorID is the user who marked the boost
+func Boosting(s *discordgo.Session, guildID string, channelID string, actorID string) error {
 	var contract = FindContract(channelID)
 	if contract == nil {
 		return errors.New(errorNoContract)
@@ -1897,14 +1905,15 @@ func Boosting(s *discordgo.Session, guildID string, channelID string) error {
 	}
 
 	contract.enforceOnlyOneTokenTimeBooster()
+	recordContractEvent(contract, ContractEventBoost, actorID, currentBoosterID)
 
 	sendNextNotification(s, contract, true)
 
 	return nil
 }
 
-// Unboost will mark a user as unboosted
-func Unboost(s *discordgo.Session, guildID string, channelID string, mention string) error {
+// Unboost will mark a user as unboosted, actorID is the user who asked for it
+func Unboost(s *discordgo.Session, guildID string, channelID string, mention string, actorID string) error {
 	var contract = FindContract(channelID)
 	if contract == nil {
 		return errors.New(errorNoContract)
@@ -1951,6 +1960,7 @@ func Unboost(s *discordgo.Session, guildID string, channelID string, mention str
 		contract.Boosters[userID].BoostState = BoostStateTokenTime
 		contract.setCurrentBoosterByUserIDWithStart(userID)
 		contract.enforceOnlyOneTokenTimeBooster()
+		recordContractEvent(contract, ContractEventUnboost, actorID, userID)
 
 		sendNextNotification(s, contract, true)
 	} else {
@@ -1961,6 +1971,7 @@ func Unboost(s *discordgo.Session, guildID string, channelID string, mention str
 		} else {
 			log.Printf("Unboost warning: user not found in BoostedOrder; contractHash=%s channelID=%s userID=%s state=%d", contract.ContractHash, channelID, userID, contract.State)
 		}
+		recordContractEvent(contract, ContractEventUnboost, actorID, userID)
 		refreshBoostListMessage(s, contract, false)
 	}
 	return nil
@@ -1976,8 +1987,9 @@ func insert(a []string, index int, value string) []string {
 	return a
 }
 
-// SkipBooster will skip the current booster and move to the next
-func SkipBooster(s *discordgo.Session, guildID string, channelID string, userID string) error {
+// SkipBooster will skip the current booster and move to the next,
+// actorID is the user who asked for the skip
+func SkipBooster(s *discordgo.Session, guildID string, channelID string, userID string, actorID string) error {
 	var boosterSwap = false
 	var contract = FindContract(channelID)
 	if contract == nil {
@@ -1996,6 +2008,7 @@ func SkipBooster(s *discordgo.Session, guildID string, channelID string, userID
 		return errors.New(errorNoFarmer)
 	}
 	var selectedUser = currentIdx
+	var skippedID = contract.Order[currentIdx]
 
 	if userID != "" {
 		for i := range contract.Order {
@@ -2056,6 +2069,7 @@ func SkipBooster(s *discordgo.Session, guildID string, channelID string, userID
 	contract.OrderRevision++
 
 	contract.enforceOnlyOneTokenTimeBooster()
+	recordContractEvent(contract, ContractEventSkip, actorID, skippedID)
 
 	sendNextNotification(s, contract, true)
 
@@ -2110,247 +2124,13 @@ func FinishContract(s *discordgo.Session, contract *Contract) {
 }
 
 func reorderBoosters(contract *Contract) {
-
-	type teOrderPair struct {
-		name string
-		te   float64
-	}
-
-	switch contract.BoostOrder {
-	case ContractOrderSignup:
-		// Join Order
-	case ContractOrderReverse:
-		// Reverse Order
-		for i, j := 0, len(contract.Order)-1; i < j; i, j = i+1, j-1 {
-			contract.Order[i], contract.Order[j] = contract.Order[j], contract.Order[i] //reverse the slice
-		}
-	case ContractOrderRandom:
-		rand.Shuffle(len(contract.Order), func(i, j int) {
-			contract.Order[i], contract.Order[j] = contract.Order[j], contract.Order[i]
-		})
-	case ContractOrderELR:
-		type ELRPair struct {
-			Name string
-			ELR  float64
-		}
-
-		var elrPairs []ELRPair
-		for _, el := range contract.Order {
-			elrPairs = append(elrPairs, ELRPair{
-				Name: el,
-				ELR:  contract.Boosters[el].ArtifactSet.LayRate,
-			})
-		}
-
-		sort.Slice(elrPairs, func(i, j int) bool {
-			return elrPairs[i].ELR > elrPairs[j].ELR
-		})
-
-		var orderedNames []string
-		for _, pair := range elrPairs {
-			orderedNames = append(orderedNames, pair.Name)
-		}
-		contract.Order = orderedNames
-		// Reset this to Signup after the initial ELR sort
-		contract.BoostOrder = ContractOrderSignup
-	case ContractOrderTokenAsk:
-		type TokenPair struct {
-			Name string
-			Ask  int
-		}
-
-		var tokenPairs []TokenPair
-		for _, el := range contract.Order {
-			tokenPairs = append(tokenPairs, TokenPair{
-				Name: el,
-				Ask:  contract.Boosters[el].TokensWanted,
-			})
-		}
-
-		sort.Slice(tokenPairs, func(i, j int) bool {
-			return tokenPairs[i].Ask < tokenPairs[j].Ask
-		})
-
-		var orderedNames []string
-		for _, pair := range tokenPairs {
-			orderedNames = append(orderedNames, pair.Name)
-		}
-		contract.Order = orderedNames
-		// Reset this to Signup after the initial Ask sort
-		// contract.BoostOrder = ContractOrderSignup
-
-	case ContractOrderTVal:
-		type TValPair struct {
-			name     string
-			position int
-			val      float64
-			tokenAsk int
-		}
-		var orderedNames []string
-		var lastOrderNames []string
-		var tvalPairs []TValPair
-		lastBoostTime := time.Now()
-		contract.mutex.Lock()
-		allBoosted := true
-		for _, id := range contract.Order {
-			b := contract.Boosters[id]
-			if b == nil || b.BoostState != BoostStateBoosted {
-				allBoosted = false
-				break
-			}
-		}
-		if allBoosted {
-			log.Print("TVal Boosting complete, all boosted, count:", len(contract.Order))
-			contract.BoostOrder = ContractOrderSignup
-			contract.mutex.Unlock()
-			return
-		}
-		if contract.currentBoosterID() == "" {
-			contract.mutex.Unlock()
-			return
-		}
-
-		for _, el := range contract.Order {
-			b := contract.Boosters[el]
-			if b == nil {
-				continue
-			}
-			if b.BoostState == BoostStateBoosted {
-				orderedNames = append(orderedNames, el)
-				lastBoostTime = b.EndTime
-			} else if contract.Style&ContractFlagFastrun != 0 && b.BoostState == BoostStateTokenTime {
-				// Fastrun style keeps current booster in place
-				orderedNames = append(orderedNames, el)
-			} else {
-				pos := SinkBoostFollowOrder
-				if el == contract.Banker.BoostingSinkUserID {
-					pos = contract.Banker.SinkBoostPosition
-				}
-				tvalPairs = append(tvalPairs, TValPair{
-					name:     el,
-					position: pos,
-					val:      b.TokenValue,
-					tokenAsk: b.TokensWanted,
-				})
-			}
-		}
-		sort.SliceStable(tvalPairs, func(i, j int) bool {
-			// Keep Sink First Boost at the front of the list
-			if tvalPairs[i].position == SinkBoostFirst {
-				return true
-			} else if tvalPairs[j].position == SinkBoostFirst {
-				return false
-			}
-			// Keep Sink Last Boost at the end of the list
-			if tvalPairs[i].position == SinkBoostLast {
-				return false
-			} else if tvalPairs[j].position == SinkBoostLast {
-				return true
-			}
-
-			//if tvalPairs[i].tokenWant != tvalPairs[j].tokenWant {
-			//	return tvalPairs[i].tokenWant > tvalPairs[j].tokenWant
-			//}
-
-			return tvalPairs[i].val > tvalPairs[j].val
-		})
-
-		newBoostPosition := len(orderedNames)
-		if contract.Style&ContractFlagFastrun != 0 {
-			newBoostPosition = contract.currentBoosterOrderIndex()
-		}
-
-		// These boosters are all dymanic, any of them could be the next booster
-		for _, pair := range tvalPairs {
-			contract.Boosters[pair.name].BoostState = BoostStateUnboosted
-			contract.Boosters[pair.name].StartTime = lastBoostTime
-			orderedNames = append(orderedNames, pair.name)
-		}
-		if contract.Banker.SinkBoostPosition == SinkBoostLast {
-			orderedNames = append(orderedNames, lastOrderNames...)
-		}
-
-		contract.Order = orderedNames
-		contract.setCurrentBoosterByIndex(newBoostPosition)
-		// Enforce that only current booster has BoostStateTokenTime
-		contract.enforceOnlyOneTokenTimeBooster()
-		contract.mutex.Unlock()
-
-	case ContractOrderTE, ContractOrderTEFuzzy:
-		pairs := make([]teOrderPair, len(contract.Order))
-
-		for i, name := range contract.Order {
-			baseTE := float64(max(contract.Boosters[name].TECount, 0))
-			sortTE := baseTE
-
-			if contract.BoostOrder == ContractOrderTEFuzzy {
-				randomBonusMax := math.Max(
-					baseTE*0.06,       // 6%
-					math.Sqrt(baseTE), // Sqrt
-				)
-				// Maximum of 6% or sqrt of the TE count as a random bonus, can be negative or positive
-				randomOffset := (rand.Float64()*2 - 1) * randomBonusMax
-				sortTE = baseTE + randomOffset
-			}
-
-			pairs[i] = teOrderPair{name: name, te: sortTE}
-		}
-
-		sort.Slice(pairs, func(i, j int) bool {
-			return pairs[i].te > pairs[j].te
-		})
-
-		for i := range pairs {
-			contract.Order[i] = pairs[i].name
-		}
-
-	case ContractOrderIHR, ContractOrderIHRFuzzy:
-		type ihrOrderPair struct {
-			name         string
-			ihr          float64
-			tokensWanted int
-			deflQual     int
-			delQual      int
-			te           int
-		}
-		pairs := make([]ihrOrderPair, len(contract.Order))
-
-		for i, name := range contract.Order {
-			b := contract.Boosters[name]
-			deflQ := getArtifactQualityScore(b, "Deflector")
-			delQ := getArtifactQualityScore(b, "Metronome") + getArtifactQualityScore(b, "Compass") + getArtifactQualityScore(b, "Gusset")
-			baseIHR := b.IHRRate
-			sortIHR := baseIHR
-			if contract.BoostOrder == ContractOrderIHRFuzzy {
-				randomBonusMax := baseIHR * 0.06 // 6%
-				randomOffset := (rand.Float64()*2 - 1) * randomBonusMax
-				sortIHR = baseIHR + randomOffset
-				if b.IHRCalcLog != "" {
-					b.IHRCalcLog = fmt.Sprintf("%s, Fuzzy (Max=%0.2f, Offset=%0.2f, Sorted=%0.2f)", b.IHRCalcLog, randomBonusMax, randomOffset, sortIHR)
-				}
-			}
-			pairs[i] = ihrOrderPair{name: name, ihr: sortIHR, tokensWanted: b.TokensWanted, deflQual: deflQ, delQual: delQ, te: b.TECount}
-		}
-
-		sort.Slice(pairs, func(i, j int) bool {
-			if pairs[i].ihr != pairs[j].ihr {
-				return pairs[i].ihr > pairs[j].ihr
-			}
-			if pairs[i].deflQual != pairs[j].deflQual {
-				return pairs[i].deflQual > pairs[j].deflQual
-			}
-			if pairs[i].delQual != pairs[j].delQual {
-				return pairs[i].delQual > pairs[j].delQual
-			}
-			return pairs[i].te > pairs[j].te
-		})
-
-		for i := range pairs {
-			contract.Order[i] = pairs[i].name
-		}
+	order := contract.BoostOrder
+	if strategy, ok := getBoostOrderStrategy(order); ok {
+		strategy.Reorder(contract)
 	}
 
-	if contract.BoostOrder != ContractOrderTVal {
+	// TVal manages its own token time and sink placement
+	if order != ContractOrderTVal {
 		// Enforce singleton TokenTime invariant after TE/FuzzyTE reordering
 		if contract.State != ContractStateSignup {
 			contract.mutex.Lock()
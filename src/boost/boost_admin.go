@@ -510,6 +510,7 @@ func finishContractByHash(s *discordgo.Session, contractHash string) error {
 	ContractsMutex.Lock()
 	delete(Contracts, contract.ContractHash)
 	ContractsMutex.Unlock()
+	discardCoopGroupPlan(contract.ContractHash)
 
 	return nil
 }
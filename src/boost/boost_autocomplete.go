@@ -44,6 +44,10 @@ func HandleContractAutoComplete(s *discordgo.Session, i *discordgo.InteractionCr
 		handleCoopIDAutoComplete(s, i, opt.StringValue())
 		return
 	}
+	if opt, ok := optionMap["template"]; ok && opt.Focused {
+		handleContractTemplateAutoComplete(s, i, opt.StringValue())
+		return
+	}
 	if opt, ok := optionMap["contract-coop-id-coop-id"]; ok && opt.Focused {
 		handleCoopIDAutoComplete(s, i, opt.StringValue())
 		return
@@ -290,25 +294,13 @@ func handleBoostOrderAutoComplete(s *discordgo.Session, i *discordgo.Interaction
 	searchString = strings.ToLower(searchString)
 	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
 
-	for orderVal, name := range contractOrderNames {
-		if orderVal == ContractOrderFair {
+	for _, strategy := range getBoostOrderStrategies() {
+		info := strategy.Info()
+		if info.Choice == "" {
 			continue
 		}
-		var formattedName string
-		switch orderVal {
-		case ContractOrderSignup:
-			formattedName = "Sign-up Ordering"
-		case ContractOrderTimeBased:
-			formattedName = "Time Based Ordering"
-		case ContractOrderRandom:
-			formattedName = "Random Ordering"
-		case ContractOrderTEFuzzy:
-			formattedName = "Fuzzy TE Ordering"
-		case ContractOrderIHRFuzzy:
-			formattedName = "Fuzzy IHR Ordering"
-		default:
-			formattedName = name + " Ordering"
-		}
+		orderVal := info.ID
+		formattedName := info.Choice
 
 		if searchString == "" || strings.Contains(strings.ToLower(formattedName), searchString) {
 			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
@@ -12,7 +12,6 @@ import (
 	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
-	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/mattn/go-runewidth"
@@ -183,7 +182,7 @@ func buttonReactionBoost(s *discordgo.Session, GuildID string, ChannelID string,
 	}
 
 	if userID == currentBoosterID || votingElection || creatorOfContract(s, contract, cUserID) {
-		_ = Boosting(s, GuildID, ChannelID)
+		_ = Boosting(s, GuildID, ChannelID, cUserID)
 		scheduleCoopStatusPoll(contract)
 		return true
 	}
@@ -219,7 +218,9 @@ func buttonReactionToken(s *discordgo.Session, GuildID string, ChannelID string,
 			contract.mutex.Lock()
 			b.TokensReceived += count
 			contract.TokenLog = append(contract.TokenLog, ei.TokenUnitLog{Time: now, Quantity: count, FromUserID: fromUserID, FromNick: contract.Boosters[fromUserID].Nick, ToUserID: b.UserID, ToNick: b.Nick, Serial: tokenSerial, Boost: false})
+			sentToken := contract.TokenLog[len(contract.TokenLog)-1]
 			contract.mutex.Unlock()
+			recordContractTokenEvent(contract, sentToken)
 			tval := bottools.GetTokenValue(time.Since(contract.StartTime).Seconds(), contract.EstimatedDuration.Seconds())
 			contract.mutex.Lock()
 			contract.Boosters[fromUserID].TokenValue += tval * float64(count)
@@ -228,17 +229,14 @@ func buttonReactionToken(s *discordgo.Session, GuildID string, ChannelID string,
 			if contract.BoostOrder == ContractOrderTVal {
 				reorderBoosters(contract)
 			}
-			/*
-				if contract.Style&ContractFlagDynamicTokens != 0 {
-					// Determine the dynamic tokens
-					determineDynamicTokens(contract)
-				}
-			*/
+			determineDynamicTokens(contract)
 		} else {
 			contract.mutex.Lock()
 			b.TokensReceived += count
 			contract.TokenLog = append(contract.TokenLog, ei.TokenUnitLog{Time: time.Now(), Quantity: count, FromUserID: fromUserID, FromNick: contract.Boosters[fromUserID].Nick, ToUserID: fromUserID, ToNick: contract.Boosters[fromUserID].Nick, Serial: xid.New().String(), Boost: false})
+			sentToken := contract.TokenLog[len(contract.TokenLog)-1]
 			contract.mutex.Unlock()
+			recordContractTokenEvent(contract, sentToken)
 			if contract.BoostOrder == ContractOrderTVal {
 				reorderBoosters(contract)
 			}
@@ -253,7 +251,7 @@ func buttonReactionToken(s *discordgo.Session, GuildID string, ChannelID string,
 			b.TokensReceived >= b.TokensWanted &&
 			b.AltController == "" {
 			// Guest farmer auto boosts
-			_ = Boosting(s, GuildID, ChannelID)
+			_ = Boosting(s, GuildID, ChannelID, fromUserID)
 			return true, false
 		}
 		if bankerID != "" && fromUserID == bankerID {
@@ -296,7 +294,7 @@ func buttonReactionSwap(s *discordgo.Session, GuildID string, ChannelID string,
 	}
 	if cUserID == currentID || creatorOfContract(s, contract, cUserID) {
 		if (currentIdx + 1) < len(contract.Order) {
-			_ = SkipBooster(s, GuildID, ChannelID, "")
+			_ = SkipBooster(s, GuildID, ChannelID, "", cUserID)
 			return true
 		}
 	}
@@ -1268,17 +1266,14 @@ func buttonReactionNonToken(i *discordgo.InteractionCreate, contract *Contract,
 		guildID = contract.Location[0].GuildID
 	}
 
-	if guildID != "" {
-		amqpURL := guildstate.GetGuildSettingString(guildID, "amqp_url")
-		if amqpURL != "" {
-			PublishAMQPNonToken(guildID, amqpURL, contract.ContractID, contract.CoopID, AMQPNonTokenMessage{
-				Event:      "non_token",
-				ContractID: contract.ContractID,
-				CoopID:     contract.CoopID,
-				UserID:     userID,
-				Nick:       contract.Boosters[userID].Nick,
-				Time:       time.Now(),
-			})
-		}
+	if publisher := getGuildEventPublisher(guildID); publisher != nil {
+		PublishNonToken(publisher, AMQPNonTokenMessage{
+			Event:      "non_token",
+			ContractID: contract.ContractID,
+			CoopID:     contract.CoopID,
+			UserID:     userID,
+			Nick:       contract.Boosters[userID].Nick,
+			Time:       time.Now(),
+		})
 	}
 }
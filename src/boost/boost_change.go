@@ -703,6 +703,9 @@ func ChangeContractIDs(s *discordgo.Session, guildID string, channelID string, u
 				len(movedLabels),
 				strings.Join(movedLabels, ", "),
 			)
+			if contract.State == ContractStateSignup {
+				channelMsg += fmt.Sprintf("\nUse %s to split the signup into several coops instead.", bottools.GetFormattedCommand("coop-group"))
+			}
 			if _, err := s.ChannelMessageSend(channelID, channelMsg); err != nil {
 				log.Println("Error sending waitlist movement message:", err)
 			}
@@ -898,6 +901,8 @@ func ChangeBoostOrder(s *discordgo.Session, guildID string, channelID string, us
 		contract.enforceOnlyOneTokenTimeBooster()
 	}
 
+	recordContractEvent(contract, ContractEventOrder, userID, "")
+
 	//sendNextNotification(s, contract, true)
 	if redraw {
 		refreshBoostListMessage(s, contract, false)
@@ -911,6 +916,31 @@ func ChangeBoostOrder(s *discordgo.Session, guildID string, channelID string, us
 	return summaryStr, nil
 }
 
+// ChangeBoostOrderStrategy switches the contract to a registered boost order, it's applied when boosting starts
+func ChangeBoostOrderStrategy(s *discordgo.Session, channelID string, userID string, order int) (string, error) {
+	var contract = FindContract(channelID)
+	if contract == nil {
+		return "", errors.New(errorNoContract)
+	}
+
+	if !creatorOfContract(s, contract, userID) {
+		return "", errors.New("only the contract creator can change the contract")
+	}
+
+	strategy, ok := getBoostOrderStrategy(order)
+	if !ok {
+		return "", fmt.Errorf("unknown boost order %d", order)
+	}
+
+	log.Println("ChangeBoostOrderStrategy", "ChannelID: ", channelID, "UserID: ", userID, "BoostOrder: ", strategy.Info().Name)
+
+	contract.BoostOrder = order
+	if preparer, ok := strategy.(boostOrderPreparer); ok {
+		preparer.Prepare(s, contract)
+	}
+	return fmt.Sprintf("Boost order set to %s", strategy.Info().Name), nil
+}
+
 // MoveBooster will move a booster to a new position in the contract
 func MoveBooster(s *discordgo.Session, guildID string, channelID string, userID string, boosterName string, boosterPosition int, redraw bool) error {
 	var contract = FindContract(channelID)
@@ -973,6 +1003,7 @@ func MoveBooster(s *discordgo.Session, guildID string, channelID string, userID
 		// Enforce that only current booster has BoostStateTokenTime
 		contract.enforceOnlyOneTokenTimeBooster()
 	}
+	recordContractEvent(contract, ContractEventMove, userID, boosterName)
 	if redraw {
 		refreshBoostListMessage(s, contract, false)
 	}
@@ -13,7 +13,6 @@ import (
 	"time"
 
 	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
-	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
 )
 
 var ctx = context.Background()
@@ -350,12 +349,12 @@ func saveSqliteData(contract *Contract) {
 		return
 	}
 
-	// Check if AMQP Publish is enabled and configured
+	// Check if event publishing is enabled and configured
 	if contract.Style&ContractFlagAMQP != 0 {
 		guildID := contract.Location[0].GuildID
 		if guildID != "" {
-			amqpURL := guildstate.GetGuildSettingString(guildID, "amqp_url")
-			if amqpURL != "" {
+			publisher := getGuildEventPublisher(guildID)
+			if publisher != nil {
 				// Process player boost state changes
 				if contract.LastPublishedStates == nil {
 					contract.LastPublishedStates = make(map[string]int)
@@ -364,7 +363,7 @@ func saveSqliteData(contract *Contract) {
 					lastState, exists := contract.LastPublishedStates[userID]
 					if !exists || lastState != booster.BoostState {
 						contract.LastPublishedStates[userID] = booster.BoostState
-						PublishAMQPBoostStatus(guildID, amqpURL, contract.ContractID, contract.CoopID, AMQPBoostStatusMessage{
+						PublishBoostStatus(publisher, AMQPBoostStatusMessage{
 							Event:      "boost_status_change",
 							ContractID: contract.ContractID,
 							CoopID:     contract.CoopID,
@@ -382,7 +381,7 @@ func saveSqliteData(contract *Contract) {
 				}
 				for i := contract.LastPublishedTokenLogIndex; i < len(contract.TokenLog); i++ {
 					entry := contract.TokenLog[i]
-					PublishAMQPTokenLog(guildID, amqpURL, contract.ContractID, contract.CoopID, AMQPTokenMessage{
+					PublishTokenLog(publisher, AMQPTokenMessage{
 						Event:      "token_transfer",
 						ContractID: contract.ContractID,
 						CoopID:     contract.CoopID,
@@ -195,10 +195,17 @@ func DrawBoostList(s *discordgo.Session, contract *Contract) []discordgo.Message
 					a = 70
 				}
 				fmt.Fprintf(&header, ">  📊 %d %s for >= %d TE, %d %s < %d TE\n", x, contract.TokenStr, a, y, contract.TokenStr, a)
+			} else if contract.Style&ContractFlagDynamicTokens != 0 {
+				fmt.Fprintf(&header, ">  🧮 Dynamic %s, each ask is planned to deliver the most eggs\n", contract.TokenStr)
 			}
 		}
 	}
 	fmt.Fprintf(&header, "> Coordinator: <@%s>\n", contract.CreatorID[0])
+	if contract.Style&ContractFlagDynamicTokens != 0 && contract.State != ContractStateSignup && contract.State != ContractStateCompleted {
+		if summary := dynamicTokenSummary(contract); summary != "" {
+			fmt.Fprintf(&header, "> Dynamic %s: %s\n", contract.TokenStr, summary)
+		}
+	}
 	if contract.Location[0].GuildContractRole.ID != "" {
 		fmt.Fprintf(&header, "> Team Role: %s\n", contract.Location[0].RoleMention)
 	}
@@ -8,7 +8,6 @@ import (
 	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
-	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -139,15 +138,15 @@ func getSignupContractSettings(channelID string, hashID string, thread bool) (st
 	hasAMQP := false
 	if len(contract.Location) > 0 {
 		guildID := contract.Location[0].GuildID
-		if guildID != "" && guildstate.GetGuildSettingString(guildID, "amqp_url") != "" {
+		if guildHasEventPublisher(guildID) {
 			hasAMQP = true
 		}
 	}
 
 	if hasAMQP {
 		featuresOptions = append(featuresOptions, discordgo.SelectMenuOption{
-			Label:       "AMQP Publish",
-			Description: "Send token logs and boost status to AMQP queue",
+			Label:       "Publish Events",
+			Description: "Send token logs and boost status to AMQP or webhook",
 			Value:       "amqp",
 			Default:     (contract.Style & ContractFlagAMQP) != 0,
 			Emoji: &discordgo.ComponentEmoji{
@@ -175,78 +174,7 @@ func getSignupContractSettings(channelID string, hashID string, thread bool) (st
 					Placeholder: "Select the boosting order for this contract",
 					MinValues:   &minValues,
 					MaxValues:   1,
-					Options: []discordgo.SelectMenuOption{
-						{
-							Label:       "Sign-up Order",
-							Description: "Boost list is in the order farmers sign up",
-							Value:       "signup",
-							Emoji:       ei.GetBotComponentEmoji("signup"),
-							Default:     contract.BoostOrder == ContractOrderSignup,
-						},
-						{
-							Label:       "Token Value Order",
-							Description: "Highest token value boosts earlier",
-							Value:       "tval",
-							Emoji:       ei.GetBotComponentEmoji("sharing"),
-							Default:     contract.BoostOrder == ContractOrderTVal,
-						},
-						{
-							Label:       "ELR Order",
-							Description: "Highest Egg Lay Rate first",
-							Value:       "elr",
-							Emoji:       ei.GetBotComponentEmoji("elr"),
-							Default:     contract.BoostOrder == ContractOrderELR,
-						},
-						{
-							Label:       "Token Ask Order",
-							Description: "Those asking for less tokens boost earlier",
-							Value:       "ask",
-							Emoji:       ei.GetBotComponentEmoji("ask"),
-							Default:     contract.BoostOrder == ContractOrderTokenAsk,
-						},
-						{
-							Label:       "Random Order",
-							Description: "Boost order is random",
-							Value:       "random",
-							Emoji:       ei.GetBotComponentEmoji("random"),
-							Default:     contract.BoostOrder == ContractOrderRandom,
-						},
-						{
-							Label:       "Reverse Sign-up Order",
-							Description: "Boost list is in the reverse order farmers sign up",
-							Value:       "reverse",
-							Emoji:       ei.GetBotComponentEmoji("reverse"),
-							Default:     contract.BoostOrder == ContractOrderReverse,
-						},
-						{
-							Label:       "TE Order",
-							Description: "Highest Truth Egg count first",
-							Value:       "te",
-							Emoji:       ei.GetBotComponentEmoji("egg_truth"),
-							Default:     contract.BoostOrder == ContractOrderTE,
-						},
-						{
-							Label:       "Fuzzy TE Order",
-							Description: "Highest Truth Egg count first with randomization",
-							Value:       "fuzzyte",
-							Emoji:       ei.GetBotComponentEmoji("egg_truth"),
-							Default:     contract.BoostOrder == ContractOrderTEFuzzy,
-						},
-						{
-							Label:       "Boosting IHR Order",
-							Description: "Highest Boosting IHR first",
-							Value:       "ihr",
-							Emoji:       ei.GetBotComponentEmoji("chalice_T4L"),
-							Default:     contract.BoostOrder == ContractOrderIHR,
-						},
-						{
-							Label:       "Fuzzy Boosting IHR Order",
-							Description: "Highest Boosting IHR first with randomization",
-							Value:       "fuzzyihr",
-							Emoji:       ei.GetBotComponentEmoji("chalice_T4L"),
-							Default:     contract.BoostOrder == ContractOrderIHRFuzzy,
-						},
-					},
+					Options:     boostOrderMenuOptions(contract.BoostOrder),
 				},
 			},
 		},
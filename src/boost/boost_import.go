@@ -134,15 +134,38 @@ func nextWeekdayDate(now time.Time, weekday time.Weekday) time.Time {
 	return GetEggStandardTime(nextDate)
 }
 
-// CreatePredictedContract creates one placeholder contract each for Monday,
-// Wednesday, Friday, and Friday Ultra based on the next predicted release dates.
-func CreatePredictedContract() []ei.EggIncContract {
-	now := time.Now().UTC()
+// nextSeasonalDate returns the next Monday seasonal release after now.
+func nextSeasonalDate(now time.Time) time.Time {
 	nextMon := nextWeekdayDate(now, time.Monday)
 	// Egg Day seasonal contracts are typically delayed from July 14 to July 15.
 	if nextMon.Month() == time.July && nextMon.Day() == 14 {
 		nextMon = nextMon.Add(24 * time.Hour)
 	}
+	return nextMon
+}
+
+// predictedSlotLabel returns the predicted ID label ("monday", "wednesday", "friday"
+// or "ultra") for the release slot of a live contract, or "" if it isn't a weekly slot.
+func predictedSlotLabel(live ei.EggIncContract) string {
+	if live.Ultra {
+		return "ultra"
+	}
+	switch live.ValidFrom.Weekday() {
+	case time.Monday:
+		return "monday"
+	case time.Wednesday:
+		return "wednesday"
+	case time.Friday:
+		return "friday"
+	}
+	return ""
+}
+
+// CreatePredictedContract creates one placeholder contract each for Monday,
+// Wednesday, Friday, and Friday Ultra based on the next predicted release dates.
+func CreatePredictedContract() []ei.EggIncContract {
+	now := time.Now().UTC()
+	nextMon := nextSeasonalDate(now)
 	nextWed := nextWeekdayDate(now, time.Wednesday)
 	nextFri := nextWeekdayDate(now, time.Friday)
 
@@ -234,21 +257,7 @@ func UpdatePredictedSignupContracts(s *discordgo.Session, liveContracts []ei.Egg
 				continue
 			}
 
-			computedLabel := ""
-			if live.Ultra {
-				computedLabel = "ultra"
-			} else {
-				switch live.ValidFrom.Weekday() {
-				case time.Monday:
-					computedLabel = "monday"
-				case time.Wednesday:
-					computedLabel = "wednesday"
-				case time.Friday:
-					computedLabel = "friday"
-				}
-			}
-
-			whatIfID := fmt.Sprintf("%s-%s", computedLabel, live.ValidFrom.Format("2006-01-02"))
+			whatIfID := fmt.Sprintf("%s-%s", predictedSlotLabel(live), live.ValidFrom.Format("2006-01-02"))
 			if contract.ContractID != whatIfID {
 				continue
 			}
@@ -0,0 +1,522 @@
+package boost
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+)
+
+// BoostOrderInfo describes a boost order strategy
+type BoostOrderInfo struct {
+	ID          int    // Value stored in Contract.BoostOrder
+	Name        string // Short name used in listings
+	Key         string // Value in the contract settings menu, empty hides it there
+	Label       string // Contract settings menu label
+	Description string // Contract settings menu description
+	Emoji       string // Bot emoji name for the settings menu
+	Choice      string // Slash command choice name, empty hides it from choices
+}
+
+// BoostOrderStrategy orders the boosters of a contract. Strategies register
+// themselves with RegisterBoostOrderStrategy.
+type BoostOrderStrategy interface {
+	Info() BoostOrderInfo
+	// Reorder rearranges contract.Order
+	Reorder(contract *Contract)
+}
+
+// boostOrderPreparer is implemented by strategies that need fresh farmer data
+// when a contract switches to them.
+type boostOrderPreparer interface {
+	Prepare(s *discordgo.Session, contract *Contract)
+}
+
+var boostOrderStrategies = make(map[int]BoostOrderStrategy)
+
+// RegisterBoostOrderStrategy adds a strategy to the registry, IDs must be unique.
+func RegisterBoostOrderStrategy(strategy BoostOrderStrategy) {
+	info := strategy.Info()
+	if _, ok := boostOrderStrategies[info.ID]; ok {
+		panic(fmt.Sprintf("boost order %d registered twice", info.ID))
+	}
+	boostOrderStrategies[info.ID] = strategy
+}
+
+// getBoostOrderStrategy returns the strategy for a Contract.BoostOrder value
+func getBoostOrderStrategy(order int) (BoostOrderStrategy, bool) {
+	strategy, ok := boostOrderStrategies[order]
+	return strategy, ok
+}
+
+// getBoostOrderStrategyByKey returns the strategy for a settings menu value
+func getBoostOrderStrategyByKey(key string) (BoostOrderStrategy, bool) {
+	for _, strategy := range boostOrderStrategies {
+		if strategy.Info().Key == key {
+			return strategy, true
+		}
+	}
+	return nil, false
+}
+
+// getBoostOrderStrategies returns the registered strategies sorted by ID
+func getBoostOrderStrategies() []BoostOrderStrategy {
+	strategies := make([]BoostOrderStrategy, 0, len(boostOrderStrategies))
+	for _, strategy := range boostOrderStrategies {
+		strategies = append(strategies, strategy)
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].Info().ID < strategies[j].Info().ID
+	})
+	return strategies
+}
+
+// getBoostOrderName returns the short name of a boost order
+func getBoostOrderName(order int) string {
+	if strategy, ok := getBoostOrderStrategy(order); ok {
+		return strategy.Info().Name
+	}
+	return fmt.Sprintf("%d", order)
+}
+
+func init() {
+	RegisterBoostOrderStrategy(signupOrder{})
+	RegisterBoostOrderStrategy(reverseOrder{})
+	RegisterBoostOrderStrategy(randomOrder{})
+	RegisterBoostOrderStrategy(passiveOrder{BoostOrderInfo{ID: ContractOrderFair, Name: "Fair"}})
+	RegisterBoostOrderStrategy(passiveOrder{BoostOrderInfo{ID: ContractOrderTimeBased, Name: "Time-Based", Choice: "Time Based Ordering"}})
+	RegisterBoostOrderStrategy(elrOrder{})
+	RegisterBoostOrderStrategy(tvalOrder{})
+	RegisterBoostOrderStrategy(tokenAskOrder{})
+	RegisterBoostOrderStrategy(teOrder{})
+	RegisterBoostOrderStrategy(teOrder{fuzzy: true})
+	RegisterBoostOrderStrategy(passiveOrder{BoostOrderInfo{ID: ContractManualOrder, Name: "Manual", Choice: "Manual Ordering"}})
+	RegisterBoostOrderStrategy(ihrOrder{})
+	RegisterBoostOrderStrategy(ihrOrder{fuzzy: true})
+	RegisterBoostOrderStrategy(availabilityOrder{})
+}
+
+// passiveOrder keeps the current order, the order is set elsewhere
+type passiveOrder struct {
+	info BoostOrderInfo
+}
+
+func (o passiveOrder) Info() BoostOrderInfo { return o.info }
+
+func (passiveOrder) Reorder(*Contract) {}
+
+// signupOrder keeps farmers in the order they joined
+type signupOrder struct{}
+
+func (signupOrder) Info() BoostOrderInfo {
+	return BoostOrderInfo{ID: ContractOrderSignup, Name: "Signup", Key: "signup",
+		Label: "Sign-up Order", Description: "Boost list is in the order farmers sign up", Emoji: "signup",
+		Choice: "Sign-up Ordering"}
+}
+
+func (signupOrder) Reorder(*Contract) {}
+
+// reverseOrder reverses the join order
+type reverseOrder struct{}
+
+func (reverseOrder) Info() BoostOrderInfo {
+	return BoostOrderInfo{ID: ContractOrderReverse, Name: "Reverse", Key: "reverse",
+		Label: "Reverse Sign-up Order", Description: "Boost list is in the reverse order farmers sign up", Emoji: "reverse",
+		Choice: "Reverse Ordering"}
+}
+
+func (reverseOrder) Reorder(contract *Contract) {
+	for i, j := 0, len(contract.Order)-1; i < j; i, j = i+1, j-1 {
+		contract.Order[i], contract.Order[j] = contract.Order[j], contract.Order[i] //reverse the slice
+	}
+}
+
+// randomOrder shuffles the farmers
+type randomOrder struct{}
+
+func (randomOrder) Info() BoostOrderInfo {
+	return BoostOrderInfo{ID: ContractOrderRandom, Name: "Random", Key: "random",
+		Label: "Random Order", Description: "Boost order is random", Emoji: "random",
+		Choice: "Random Ordering"}
+}
+
+func (randomOrder) Reorder(contract *Contract) {
+	rand.Shuffle(len(contract.Order), func(i, j int) {
+		contract.Order[i], contract.Order[j] = contract.Order[j], contract.Order[i]
+	})
+}
+
+// elrOrder sorts by egg lay rate once, then continues in sign-up order
+type elrOrder struct{}
+
+func (elrOrder) Info() BoostOrderInfo {
+	return BoostOrderInfo{ID: ContractOrderELR, Name: "ELR", Key: "elr",
+		Label: "ELR Order", Description: "Highest Egg Lay Rate first", Emoji: "elr",
+		Choice: "ELR Ordering"}
+}
+
+func (elrOrder) Prepare(_ *discordgo.Session, contract *Contract) {
+	for _, b := range contract.Boosters {
+		// Refresh the user's artifact set
+		contract.Boosters[b.UserID].ArtifactSet = getUserArtifacts(b.UserID, nil)
+	}
+}
+
+func (elrOrder) Reorder(contract *Contract) {
+	type ELRPair struct {
+		Name string
+		ELR  float64
+	}
+
+	var elrPairs []ELRPair
+	for _, el := range contract.Order {
+		elrPairs = append(elrPairs, ELRPair{
+			Name: el,
+			ELR:  contract.Boosters[el].ArtifactSet.LayRate,
+		})
+	}
+
+	sort.Slice(elrPairs, func(i, j int) bool {
+		return elrPairs[i].ELR > elrPairs[j].ELR
+	})
+
+	var orderedNames []string
+	for _, pair := range elrPairs {
+		orderedNames = append(orderedNames, pair.Name)
+	}
+	contract.Order = orderedNames
+	// Reset this to Signup after the initial ELR sort
+	contract.BoostOrder = ContractOrderSignup
+}
+
+// tokenAskOrder boosts those asking for fewer tokens first
+type tokenAskOrder struct{}
+
+func (tokenAskOrder) Info() BoostOrderInfo {
+	return BoostOrderInfo{ID: ContractOrderTokenAsk, Name: "Token-Ask", Key: "ask",
+		Label: "Token Ask Order", Description: "Those asking for less tokens boost earlier", Emoji: "ask",
+		Choice: "Token-Ask Ordering"}
+}
+
+func (tokenAskOrder) Reorder(contract *Contract) {
+	type TokenPair struct {
+		Name string
+		Ask  int
+	}
+
+	var tokenPairs []TokenPair
+	for _, el := range contract.Order {
+		tokenPairs = append(tokenPairs, TokenPair{
+			Name: el,
+			Ask:  contract.Boosters[el].TokensWanted,
+		})
+	}
+
+	sort.Slice(tokenPairs, func(i, j int) bool {
+		return tokenPairs[i].Ask < tokenPairs[j].Ask
+	})
+
+	var orderedNames []string
+	for _, pair := range tokenPairs {
+		orderedNames = append(orderedNames, pair.Name)
+	}
+	contract.Order = orderedNames
+	// Reset this to Signup after the initial Ask sort
+	// contract.BoostOrder = ContractOrderSignup
+}
+
+// tvalOrder keeps the unboosted farmers sorted by token value
+type tvalOrder struct{}
+
+func (tvalOrder) Info() BoostOrderInfo {
+	return BoostOrderInfo{ID: ContractOrderTVal, Name: "TVal", Key: "tval",
+		Label: "Token Value Order", Description: "Highest token value boosts earlier", Emoji: "sharing",
+		Choice: "TVal Ordering"}
+}
+
+func (tvalOrder) Reorder(contract *Contract) {
+	type TValPair struct {
+		name     string
+		position int
+		val      float64
+		tokenAsk int
+	}
+	var orderedNames []string
+	var lastOrderNames []string
+	var tvalPairs []TValPair
+	lastBoostTime := time.Now()
+	contract.mutex.Lock()
+	defer contract.mutex.Unlock()
+	allBoosted := true
+	for _, id := range contract.Order {
+		b := contract.Boosters[id]
+		if b == nil || b.BoostState != BoostStateBoosted {
+			allBoosted = false
+			break
+		}
+	}
+	if allBoosted {
+		log.Print("TVal Boosting complete, all boosted, count:", len(contract.Order))
+		contract.BoostOrder = ContractOrderSignup
+		return
+	}
+	if contract.currentBoosterID() == "" {
+		return
+	}
+
+	for _, el := range contract.Order {
+		b := contract.Boosters[el]
+		if b == nil {
+			continue
+		}
+		if b.BoostState == BoostStateBoosted {
+			orderedNames = append(orderedNames, el)
+			lastBoostTime = b.EndTime
+		} else if contract.Style&ContractFlagFastrun != 0 && b.BoostState == BoostStateTokenTime {
+			// Fastrun style keeps current booster in place
+			orderedNames = append(orderedNames, el)
+		} else {
+			pos := SinkBoostFollowOrder
+			if el == contract.Banker.BoostingSinkUserID {
+				pos = contract.Banker.SinkBoostPosition
+			}
+			tvalPairs = append(tvalPairs, TValPair{
+				name:     el,
+				position: pos,
+				val:      b.TokenValue,
+				tokenAsk: b.TokensWanted,
+			})
+		}
+	}
+	sort.SliceStable(tvalPairs, func(i, j int) bool {
+		// Keep Sink First Boost at the front of the list
+		if tvalPairs[i].position == SinkBoostFirst {
+			return true
+		} else if tvalPairs[j].position == SinkBoostFirst {
+			return false
+		}
+		// Keep Sink Last Boost at the end of the list
+		if tvalPairs[i].position == SinkBoostLast {
+			return false
+		} else if tvalPairs[j].position == SinkBoostLast {
+			return true
+		}
+
+		//if tvalPairs[i].tokenWant != tvalPairs[j].tokenWant {
+		//	return tvalPairs[i].tokenWant > tvalPairs[j].tokenWant
+		//}
+
+		return tvalPairs[i].val > tvalPairs[j].val
+	})
+
+	newBoostPosition := len(orderedNames)
+	if contract.Style&ContractFlagFastrun != 0 {
+		newBoostPosition = contract.currentBoosterOrderIndex()
+	}
+
+	// These boosters are all dymanic, any of them could be the next booster
+	for _, pair := range tvalPairs {
+		contract.Boosters[pair.name].BoostState = BoostStateUnboosted
+		contract.Boosters[pair.name].StartTime = lastBoostTime
+		orderedNames = append(orderedNames, pair.name)
+	}
+	if contract.Banker.SinkBoostPosition == SinkBoostLast {
+		orderedNames = append(orderedNames, lastOrderNames...)
+	}
+
+	contract.Order = orderedNames
+	contract.setCurrentBoosterByIndex(newBoostPosition)
+	// Enforce that only current booster has BoostStateTokenTime
+	contract.enforceOnlyOneTokenTimeBooster()
+}
+
+// teOrder sorts by Truth Eggs, the fuzzy variant adds a random offset
+type teOrder struct {
+	fuzzy bool
+}
+
+func (o teOrder) Info() BoostOrderInfo {
+	if o.fuzzy {
+		return BoostOrderInfo{ID: ContractOrderTEFuzzy, Name: "Fuzzy TE", Key: "fuzzyte",
+			Label: "Fuzzy TE Order", Description: "Highest Truth Egg count first with randomization", Emoji: "egg_truth",
+			Choice: "Fuzzy TE Ordering"}
+	}
+	return BoostOrderInfo{ID: ContractOrderTE, Name: "TE", Key: "te",
+		Label: "TE Order", Description: "Highest Truth Egg count first", Emoji: "egg_truth",
+		Choice: "TE Ordering"}
+}
+
+// Prepare refreshes the egg inc data of farmers with no TE count
+func (teOrder) Prepare(s *discordgo.Session, contract *Contract) {
+	type userToRefresh struct {
+		userID  string
+		booster *Booster
+	}
+	usersToRefresh := make([]userToRefresh, 0, len(contract.Boosters))
+	for userID, b := range contract.Boosters {
+		if b.TECount == 0 {
+			usersToRefresh = append(usersToRefresh, userToRefresh{userID: userID, booster: b})
+		}
+	}
+	for _, item := range usersToRefresh {
+		updateContractFarmerTE(s, item.userID, item.booster, contract)
+	}
+}
+
+func (o teOrder) Reorder(contract *Contract) {
+	type teOrderPair struct {
+		name string
+		te   float64
+	}
+	pairs := make([]teOrderPair, len(contract.Order))
+
+	for i, name := range contract.Order {
+		baseTE := float64(max(contract.Boosters[name].TECount, 0))
+		sortTE := baseTE
+
+		if o.fuzzy {
+			randomBonusMax := math.Max(
+				baseTE*0.06,       // 6%
+				math.Sqrt(baseTE), // Sqrt
+			)
+			// Maximum of 6% or sqrt of the TE count as a random bonus, can be negative or positive
+			randomOffset := (rand.Float64()*2 - 1) * randomBonusMax
+			sortTE = baseTE + randomOffset
+		}
+
+		pairs[i] = teOrderPair{name: name, te: sortTE}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].te > pairs[j].te
+	})
+
+	for i := range pairs {
+		contract.Order[i] = pairs[i].name
+	}
+}
+
+// ihrOrder sorts by boosting IHR, the fuzzy variant adds a random offset
+type ihrOrder struct {
+	fuzzy bool
+}
+
+func (o ihrOrder) Info() BoostOrderInfo {
+	if o.fuzzy {
+		return BoostOrderInfo{ID: ContractOrderIHRFuzzy, Name: "Fuzzy IHR", Key: "fuzzyihr",
+			Label: "Fuzzy Boosting IHR Order", Description: "Highest Boosting IHR first with randomization", Emoji: "chalice_T4L",
+			Choice: "Fuzzy IHR Ordering"}
+	}
+	return BoostOrderInfo{ID: ContractOrderIHR, Name: "Boosting IHR", Key: "ihr",
+		Label: "Boosting IHR Order", Description: "Highest Boosting IHR first", Emoji: "chalice_T4L",
+		Choice: "Boosting IHR Ordering"}
+}
+
+// Prepare recalculates the IHR of every farmer, refreshing those without data
+func (ihrOrder) Prepare(s *discordgo.Session, contract *Contract) {
+	type userToRefresh struct {
+		userID  string
+		booster *Booster
+	}
+	usersToRefresh := make([]userToRefresh, 0, len(contract.Boosters))
+	for userID, b := range contract.Boosters {
+		// Recalculate IHR rate from DB for all boosters so pre-change values excluding deflector stones are updated
+		rate, logStr := CalculateIHRRateFromDB(userID)
+		b.IHRRate = rate
+		b.IHRCalcLog = logStr
+
+		if b.IHRRate == 0 {
+			usersToRefresh = append(usersToRefresh, userToRefresh{userID: userID, booster: b})
+		}
+	}
+	// Call updateContractFarmerTE for each collected user with 0 IHR
+	for _, item := range usersToRefresh {
+		updateContractFarmerTE(s, item.userID, item.booster, contract)
+	}
+}
+
+func (o ihrOrder) Reorder(contract *Contract) {
+	type ihrOrderPair struct {
+		name         string
+		ihr          float64
+		tokensWanted int
+		deflQual     int
+		delQual      int
+		te           int
+	}
+	pairs := make([]ihrOrderPair, len(contract.Order))
+
+	for i, name := range contract.Order {
+		b := contract.Boosters[name]
+		deflQ := getArtifactQualityScore(b, "Deflector")
+		delQ := getArtifactQualityScore(b, "Metronome") + getArtifactQualityScore(b, "Compass") + getArtifactQualityScore(b, "Gusset")
+		baseIHR := b.IHRRate
+		sortIHR := baseIHR
+		if o.fuzzy {
+			randomBonusMax := baseIHR * 0.06 // 6%
+			randomOffset := (rand.Float64()*2 - 1) * randomBonusMax
+			sortIHR = baseIHR + randomOffset
+			if b.IHRCalcLog != "" {
+				b.IHRCalcLog = fmt.Sprintf("%s, Fuzzy (Max=%0.2f, Offset=%0.2f, Sorted=%0.2f)", b.IHRCalcLog, randomBonusMax, randomOffset, sortIHR)
+			}
+		}
+		pairs[i] = ihrOrderPair{name: name, ihr: sortIHR, tokensWanted: b.TokensWanted, deflQual: deflQ, delQual: delQ, te: b.TECount}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].ihr != pairs[j].ihr {
+			return pairs[i].ihr > pairs[j].ihr
+		}
+		if pairs[i].deflQual != pairs[j].deflQual {
+			return pairs[i].deflQual > pairs[j].deflQual
+		}
+		if pairs[i].delQual != pairs[j].delQual {
+			return pairs[i].delQual > pairs[j].delQual
+		}
+		return pairs[i].te > pairs[j].te
+	})
+
+	for i := range pairs {
+		contract.Order[i] = pairs[i].name
+	}
+}
+
+// boostOrderMenuOptions builds the contract settings menu options for the boost order
+func boostOrderMenuOptions(current int) []discordgo.SelectMenuOption {
+	var options []discordgo.SelectMenuOption
+	for _, strategy := range getBoostOrderStrategies() {
+		info := strategy.Info()
+		if info.Key == "" {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       info.Label,
+			Description: info.Description,
+			Value:       info.Key,
+			Emoji:       ei.GetBotComponentEmoji(info.Emoji),
+			Default:     current == info.ID,
+		})
+	}
+	return options
+}
+
+// boostOrderChoices returns slash command choices for the selectable boost orders
+func boostOrderChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, strategy := range getBoostOrderStrategies() {
+		info := strategy.Info()
+		if info.Choice == "" {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  info.Choice,
+			Value: info.ID,
+		})
+	}
+	return choices
+}
@@ -0,0 +1,200 @@
+package boost
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func newStrategyTestContract(order int, boosters map[string]*Booster, ids ...string) *Contract {
+	for id, b := range boosters {
+		b.UserID = id
+	}
+	return &Contract{
+		State:      ContractStateSignup,
+		BoostOrder: order,
+		Order:      ids,
+		Boosters:   boosters,
+	}
+}
+
+func TestBoostOrderRegistryCoversAllOrders(t *testing.T) {
+	strategies := getBoostOrderStrategies()
+	if len(strategies) != ContractOrderAvailability+1 {
+		t.Fatalf("expected %d strategies, got %d", ContractOrderAvailability+1, len(strategies))
+	}
+	keys := make(map[string]bool)
+	for id, strategy := range strategies {
+		info := strategy.Info()
+		if info.ID != id {
+			t.Fatalf("expected strategy %d at index %d", info.ID, id)
+		}
+		if info.Name == "" {
+			t.Fatalf("strategy %d has no name", id)
+		}
+		if info.Key == "" {
+			continue
+		}
+		if keys[info.Key] {
+			t.Fatalf("duplicate key %q", info.Key)
+		}
+		keys[info.Key] = true
+		if got, ok := getBoostOrderStrategyByKey(info.Key); !ok || got.Info().ID != id {
+			t.Fatalf("key %q did not resolve to strategy %d", info.Key, id)
+		}
+	}
+}
+
+func TestRegisterBoostOrderStrategyDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected duplicate registration to panic")
+		}
+	}()
+	RegisterBoostOrderStrategy(signupOrder{})
+}
+
+func TestBoostOrderChoicesAndMenu(t *testing.T) {
+	choices := boostOrderChoices()
+	options := boostOrderMenuOptions(ContractOrderTE)
+	// Time-Based and Manual can be picked by command but aren't in the menu
+	if len(choices) != len(options)+2 {
+		t.Fatalf("expected two more choices than menu options, got %d and %d", len(choices), len(options))
+	}
+	for _, order := range []int{ContractOrderTimeBased, ContractManualOrder} {
+		found := false
+		for _, choice := range choices {
+			found = found || choice.Value == order
+		}
+		if !found {
+			t.Errorf("expected a slash command choice for %s", getBoostOrderName(order))
+		}
+	}
+	defaults := 0
+	for _, option := range options {
+		if option.Default {
+			defaults++
+			if option.Value != "te" {
+				t.Fatalf("expected te to be the default, got %q", option.Value)
+			}
+		}
+	}
+	if defaults != 1 {
+		t.Fatalf("expected one default option, got %d", defaults)
+	}
+	if getBoostOrderName(ContractOrderTokenAsk) != "Token-Ask" || getBoostOrderName(99) != "99" {
+		t.Fatalf("unexpected boost order names")
+	}
+}
+
+func TestSignupAndPassiveOrdersKeepOrder(t *testing.T) {
+	for _, order := range []int{ContractOrderSignup, ContractOrderFair, ContractOrderTimeBased, ContractManualOrder} {
+		contract := newStrategyTestContract(order, map[string]*Booster{"u1": {}, "u2": {}, "u3": {}}, "u2", "u3", "u1")
+		reorderBoosters(contract)
+		if want := []string{"u2", "u3", "u1"}; !reflect.DeepEqual(contract.Order, want) {
+			t.Fatalf("order %d: expected %v, got %v", order, want, contract.Order)
+		}
+	}
+}
+
+func TestReverseOrder(t *testing.T) {
+	contract := newStrategyTestContract(ContractOrderReverse, map[string]*Booster{"u1": {}, "u2": {}, "u3": {}}, "u1", "u2", "u3")
+	reorderBoosters(contract)
+	if want := []string{"u3", "u2", "u1"}; !reflect.DeepEqual(contract.Order, want) {
+		t.Fatalf("expected %v, got %v", want, contract.Order)
+	}
+}
+
+func TestRandomOrderKeepsFarmers(t *testing.T) {
+	contract := newStrategyTestContract(ContractOrderRandom, map[string]*Booster{"u1": {}, "u2": {}, "u3": {}, "u4": {}}, "u1", "u2", "u3", "u4")
+	reorderBoosters(contract)
+	got := slices.Clone(contract.Order)
+	slices.Sort(got)
+	if want := []string{"u1", "u2", "u3", "u4"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the same farmers, got %v", contract.Order)
+	}
+}
+
+func TestELROrderResetsToSignup(t *testing.T) {
+	boosters := map[string]*Booster{"u1": {}, "u2": {}, "u3": {}}
+	boosters["u1"].ArtifactSet.LayRate = 1.5
+	boosters["u2"].ArtifactSet.LayRate = 3.0
+	boosters["u3"].ArtifactSet.LayRate = 2.0
+	contract := newStrategyTestContract(ContractOrderELR, boosters, "u1", "u2", "u3")
+	reorderBoosters(contract)
+	if want := []string{"u2", "u3", "u1"}; !reflect.DeepEqual(contract.Order, want) {
+		t.Fatalf("expected %v, got %v", want, contract.Order)
+	}
+	if contract.BoostOrder != ContractOrderSignup {
+		t.Fatalf("expected ELR to reset to signup order, got %d", contract.BoostOrder)
+	}
+}
+
+func TestTokenAskOrder(t *testing.T) {
+	boosters := map[string]*Booster{"u1": {TokensWanted: 8}, "u2": {TokensWanted: 4}, "u3": {TokensWanted: 6}}
+	contract := newStrategyTestContract(ContractOrderTokenAsk, boosters, "u1", "u2", "u3")
+	reorderBoosters(contract)
+	if want := []string{"u2", "u3", "u1"}; !reflect.DeepEqual(contract.Order, want) {
+		t.Fatalf("expected %v, got %v", want, contract.Order)
+	}
+}
+
+func TestTValOrderSortsUnboosted(t *testing.T) {
+	boosters := map[string]*Booster{
+		"u1": {BoostState: BoostStateBoosted},
+		"u2": {BoostState: BoostStateTokenTime, TokenValue: 1},
+		"u3": {TokenValue: 5},
+		"u4": {TokenValue: 3},
+	}
+	contract := newStrategyTestContract(ContractOrderTVal, boosters, "u1", "u2", "u3", "u4")
+	contract.State = ContractStateWaiting
+	contract.setCurrentBoosterByIndex(1)
+	reorderBoosters(contract)
+	if want := []string{"u1", "u3", "u4", "u2"}; !reflect.DeepEqual(contract.Order, want) {
+		t.Fatalf("expected %v, got %v", want, contract.Order)
+	}
+	if contract.currentBoosterID() != "u3" {
+		t.Fatalf("expected u3 to be the current booster, got %q", contract.currentBoosterID())
+	}
+}
+
+func TestTValOrderAllBoostedResetsToSignup(t *testing.T) {
+	boosters := map[string]*Booster{"u1": {BoostState: BoostStateBoosted}, "u2": {BoostState: BoostStateBoosted}}
+	contract := newStrategyTestContract(ContractOrderTVal, boosters, "u1", "u2")
+	reorderBoosters(contract)
+	if contract.BoostOrder != ContractOrderSignup {
+		t.Fatalf("expected TVal to reset to signup order, got %d", contract.BoostOrder)
+	}
+}
+
+func TestTEOrder(t *testing.T) {
+	boosters := map[string]*Booster{"u1": {TECount: 10}, "u2": {TECount: 40}, "u3": {TECount: 25}}
+	contract := newStrategyTestContract(ContractOrderTE, boosters, "u1", "u2", "u3")
+	reorderBoosters(contract)
+	if want := []string{"u2", "u3", "u1"}; !reflect.DeepEqual(contract.Order, want) {
+		t.Fatalf("expected %v, got %v", want, contract.Order)
+	}
+}
+
+func TestFuzzyTEOrderKeepsDistantFarmersApart(t *testing.T) {
+	// The fuzz is at most sqrt(TE), far smaller than these gaps
+	boosters := map[string]*Booster{"u1": {TECount: 10}, "u2": {TECount: 400}, "u3": {TECount: 150}}
+	contract := newStrategyTestContract(ContractOrderTEFuzzy, boosters, "u1", "u2", "u3")
+	reorderBoosters(contract)
+	if want := []string{"u2", "u3", "u1"}; !reflect.DeepEqual(contract.Order, want) {
+		t.Fatalf("expected %v, got %v", want, contract.Order)
+	}
+}
+
+func TestIHROrderTieBreaksOnTE(t *testing.T) {
+	boosters := map[string]*Booster{
+		"u1": {IHRRate: 1000, TECount: 5},
+		"u2": {IHRRate: 1000, TECount: 50},
+		"u3": {IHRRate: 2000},
+	}
+	contract := newStrategyTestContract(ContractOrderIHR, boosters, "u1", "u2", "u3")
+	reorderBoosters(contract)
+	if want := []string{"u3", "u2", "u1"}; !reflect.DeepEqual(contract.Order, want) {
+		t.Fatalf("expected %v, got %v", want, contract.Order)
+	}
+}
@@ -56,6 +56,7 @@ func ReactionAdd(s *discordgo.Session, r *discordgo.MessageReaction) string {
 					farmerstate.SetTokens(r.UserID, tokenCount)
 				}
 				b.TokensWanted = tokenCount
+				b.TokensWantedManual = true
 				redraw = true
 			}
 		}
@@ -254,10 +255,10 @@ func ReactionAdd(s *discordgo.Session, r *discordgo.MessageReaction) string {
 func updateEstimatedTime(s *discordgo.Session, channelID string, contract *Contract, displayMsg bool, userID string) {
 	if !displayMsg {
 		eeidOverride := farmerstate.GetMiscSettingString(userID, "encrypted_ei_id")
-		coopStartTime, coopDurationSeconds, err := ei.GetCoopStatusStartTimeAndDuration(contract.ContractID, contract.CoopID, eeidOverride)
+		coopStartTime, coopDuration, err := contractSimulationEstimate(contract, eeidOverride)
 		if err == nil {
 			contract.StartTime = coopStartTime
-			contract.EstimatedDuration = time.Duration(coopDurationSeconds) * time.Second
+			contract.EstimatedDuration = coopDuration
 			contract.EstimateUpdateTime = time.Now()
 			refreshBoostListMessage(s, contract, false)
 		}
@@ -268,13 +269,13 @@ func updateEstimatedTime(s *discordgo.Session, channelID string, contract *Contr
 	data.Flags = discordgo.MessageFlagsEphemeral
 	msg, msgErr := s.ChannelMessageSendComplex(channelID, &data)
 	eeidOverride := farmerstate.GetMiscSettingString(userID, "encrypted_ei_id")
-	coopStartTime, coopDurationSeconds, err := ei.GetCoopStatusStartTimeAndDuration(contract.ContractID, contract.CoopID, eeidOverride)
+	coopStartTime, coopDuration, err := contractSimulationEstimate(contract, eeidOverride)
 	if err == nil {
 		if msgErr == nil {
 			_ = s.ChannelMessageDelete(msg.ChannelID, msg.ID)
 		}
 		contract.StartTime = coopStartTime
-		contract.EstimatedDuration = time.Duration(coopDurationSeconds) * time.Second
+		contract.EstimatedDuration = coopDuration
 		contract.EstimateUpdateTime = time.Now()
 		refreshBoostListMessage(s, contract, false)
 	}
@@ -92,7 +92,7 @@ func HandleUnboostCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 	if opt, ok := optionMap["farmer"]; ok {
 		farmer = opt.StringValue()
 	}
-	var err = Unboost(s, i.GuildID, i.ChannelID, farmer)
+	var err = Unboost(s, i.GuildID, i.ChannelID, farmer, getInteractionUserID(i))
 	if err != nil {
 		str = err.Error()
 	} else {
@@ -123,7 +123,7 @@ func HandleSkipCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 	var str = "Skip to Next Booster"
-	var err = SkipBooster(s, i.GuildID, i.ChannelID, "")
+	var err = SkipBooster(s, i.GuildID, i.ChannelID, "", getInteractionUserID(i))
 	if err != nil {
 		str = err.Error()
 	}
@@ -306,14 +306,14 @@ func GetSlashCoopETACommand(cmd string) *discordgo.ApplicationCommand {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "rate",
-				Description: "Hourly production rate (i.e. 15.7q)",
-				Required:    true,
+				Description: "Hourly production rate (i.e. 15.7q), used with timespan",
+				Required:    false,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "timespan",
-				Description: "Time remaining in this contract. Example: 0d7h27m.",
-				Required:    true,
+				Description: "Time remaining in this contract. Example: 0d7h27m. Default simulates this channel's coop.",
+				Required:    false,
 			},
 		},
 	}
@@ -344,10 +344,45 @@ func HandleCoopETACommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 		timespan = opt.StringValue()
 	}
 
-	dur, _ := str2duration.ParseDuration(timespan)
+	if rate != "" && timespan == "" {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Add the time remaining in `timespan` with a rate, or leave both out to simulate this channel's coop.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if timespan == "" {
+		// Simulate the coop in this channel, the coop status may take a moment
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		})
+		_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: getCoopSimulationETA(i.ChannelID, bottools.GetInteractionUserID(i)),
+		})
+		return
+	}
+
+	dur, err := str2duration.ParseDuration(bottools.SanitizeStringDuration(timespan))
+	if err != nil {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Could not parse timespan '%s'. Example: 0d7h27m", timespan),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
 	endTime := t.Add(dur)
 
-	var str = fmt.Sprintf("With a production rate of %s/hr completion <t:%d:R> near <t:%d:f>", rate, endTime.Unix(), endTime.Unix())
+	var str = fmt.Sprintf("Completion <t:%d:R> near <t:%d:f>", endTime.Unix(), endTime.Unix())
+	if rate != "" {
+		str = fmt.Sprintf("With a production rate of %s/hr completion <t:%d:R> near <t:%d:f>", rate, endTime.Unix(), endTime.Unix())
+	}
 
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -654,6 +689,7 @@ func HandleTokenEditCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 			if xid.Counter() == tokenIndex {
 				c.TokenLog[i].ToUserID = c.Boosters[boosterIndex].UserID
 				c.TokenLog[i].ToNick = c.Boosters[boosterIndex].Nick
+				recordContractTokenEditEvent(c, ContractEventTokenMove, userID, c.TokenLog[i])
 				str = fmt.Sprintf("Token moved to %s", c.TokenLog[i].ToNick)
 				break
 			}
@@ -663,6 +699,7 @@ func HandleTokenEditCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 			xid, _ := xid.FromString(t.Serial)
 			if xid.Counter() == tokenIndex {
 				c.TokenLog = append(c.TokenLog[:i], c.TokenLog[i+1:]...)
+				recordContractTokenEditEvent(c, ContractEventTokenDelete, userID, t)
 				str = "Token deleted"
 				break
 			}
@@ -673,6 +710,7 @@ func HandleTokenEditCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 			if xid.Counter() == tokenIndex {
 				c.TokenLog[i].Quantity = int(tokenCount)
 				c.TokenLog[i].Value = bottools.GetTokenValue(c.TokenLog[i].Time.Sub(c.StartTime).Seconds(), c.EstimatedDuration.Seconds()) * float64(c.TokenLog[i].Quantity)
+				recordContractTokenEditEvent(c, ContractEventTokenQuantity, userID, c.TokenLog[i])
 				str = "Token count modified"
 				break
 			}
@@ -765,10 +803,7 @@ func HandleRestartContract(s *discordgo.Session, i *discordgo.InteractionCreate)
 						}
 					}
 
-					orderName := fmt.Sprintf("%d", boostOrder)
-					if boostOrder >= 0 && boostOrder < len(contractOrderNames) {
-						orderName = contractOrderNames[boostOrder]
-					}
+					orderName := getBoostOrderName(boostOrder)
 					playStyleName := contractPlaystyleNames[ContractPlaystyleUnset]
 					if playStyle >= 0 && playStyle < len(contractPlaystyleNames) {
 						playStyleName = contractPlaystyleNames[playStyle]
@@ -0,0 +1,216 @@
+package boost
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"time"
+)
+
+// ChartPoint is one value of a chart series
+type ChartPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// ChartSeries is one line on a chart
+type ChartSeries struct {
+	Label  string
+	Points []ChartPoint // oldest first
+}
+
+// chartColors are the line colors, used in series order
+var chartColors = []color.RGBA{
+	{R: 0x58, G: 0x65, B: 0xf2, A: 255}, // Blurple
+	{R: 0x57, G: 0xf2, B: 0x87, A: 255}, // Green
+	{R: 0xfe, G: 0xe7, B: 0x5c, A: 255}, // Yellow
+	{R: 0xed, G: 0x42, B: 0x45, A: 255}, // Red
+	{R: 0xeb, G: 0x45, B: 0x9e, A: 255}, // Fuchsia
+	{R: 0x3b, G: 0xa5, B: 0x5d, A: 255}, // Dark Green
+}
+
+// RenderLineChartImage renders series as a PNG line chart over time, using
+// formatValue for the value axis labels.
+func RenderLineChartImage(title string, series []ChartSeries, formatValue func(float64) string) ([]byte, error) {
+	lf := loadContractReportFont(20.0)
+	fallbackFace := loadEmojiFallbackFont(18.0)
+
+	metrics := lf.face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+	lineH := ascent + metrics.Descent.Ceil() + 4
+
+	bgColor := color.RGBA{R: 0x1e, G: 0x1f, B: 0x22, A: 255}   // Discord dark theme
+	textColor := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 255} // Pure White
+	gridColor := color.RGBA{R: 0x3a, G: 0x3c, B: 0x43, A: 255} // Dark gray
+	axisColor := color.RGBA{R: 0x4e, G: 0x50, B: 0x58, A: 255}
+
+	// Value and time ranges across every series
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	var minT, maxT time.Time
+	for _, s := range series {
+		for _, p := range s.Points {
+			minV = math.Min(minV, p.Value)
+			maxV = math.Max(maxV, p.Value)
+			if minT.IsZero() || p.Time.Before(minT) {
+				minT = p.Time
+			}
+			if maxT.IsZero() || p.Time.After(maxT) {
+				maxT = p.Time
+			}
+		}
+	}
+	if math.IsInf(minV, 1) {
+		minV, maxV = 0, 1
+		minT = time.Now()
+		maxT = minT
+	}
+	if minV == maxV {
+		pad := math.Max(math.Abs(minV)*0.05, 1)
+		minV -= pad
+		maxV += pad
+	} else {
+		pad := (maxV - minV) * 0.05
+		minV -= pad
+		maxV += pad
+	}
+	if !maxT.After(minT) {
+		minT = minT.Add(-24 * time.Hour)
+		maxT = maxT.Add(24 * time.Hour)
+	}
+
+	const valueTicks = 5
+	tickLabels := make([]string, valueTicks)
+	labelW := 0
+	for n := range tickLabels {
+		tickLabels[n] = formatValue(minV + (maxV-minV)*float64(n)/float64(valueTicks-1))
+		labelW = max(labelW, measureRuneString(lf, fallbackFace, tickLabels[n]))
+	}
+
+	const imgW, plotH = 900, 360
+	padX, padY := 15, 15
+	legendRows := 0
+	if len(series) > 1 {
+		legendRows = (len(series) + 2) / 3
+	}
+	plotLeft := padX + labelW + 10
+	plotRight := imgW - padX - 10
+	plotTop := padY + lineH*(1+legendRows) + 10
+	plotBottom := plotTop + plotH
+	imgH := plotBottom + lineH + padY
+
+	img := image.NewRGBA(image.Rect(0, 0, imgW, imgH))
+	draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
+
+	drawRuneStringAt(img, lf, fallbackFace, title, padX, padY+ascent, textColor)
+
+	// Legend, three series to a row
+	legendColW := (imgW - 2*padX) / 3
+	for n, s := range series {
+		if legendRows == 0 {
+			break
+		}
+		x := padX + (n%3)*legendColW
+		y := padY + lineH*(1+n/3)
+		c := chartColors[n%len(chartColors)]
+		fillChartRect(img, x, y+lineH/2-5, 10, 10, c)
+		drawRuneStringAt(img, lf, fallbackFace, s.Label, x+16, y+ascent, textColor)
+	}
+
+	yFor := func(v float64) int {
+		return plotBottom - int(math.Round((v-minV)/(maxV-minV)*float64(plotBottom-plotTop)))
+	}
+	xFor := func(t time.Time) int {
+		return plotLeft + int(math.Round(float64(t.Sub(minT))/float64(maxT.Sub(minT))*float64(plotRight-plotLeft)))
+	}
+
+	// Value grid and labels
+	for n, label := range tickLabels {
+		y := plotBottom - (plotBottom-plotTop)*n/(valueTicks-1)
+		for x := plotLeft; x <= plotRight; x++ {
+			img.Set(x, y, gridColor)
+		}
+		w := measureRuneString(lf, fallbackFace, label)
+		drawRuneStringAt(img, lf, fallbackFace, label, plotLeft-10-w, y+ascent/2, textColor)
+	}
+
+	// Date labels, spread along the time axis without overlapping
+	dateFormat := "Jan 2"
+	if maxT.Year() != minT.Year() {
+		dateFormat = "Jan 2006"
+	}
+	lastRight := math.MinInt
+	const timeTicks = 5
+	for n := range timeTicks {
+		t := minT.Add(time.Duration(float64(maxT.Sub(minT)) * float64(n) / float64(timeTicks-1)))
+		label := t.Format(dateFormat)
+		w := measureRuneString(lf, fallbackFace, label)
+		x := min(max(xFor(t)-w/2, padX), imgW-padX-w)
+		if x < lastRight+10 {
+			continue
+		}
+		drawRuneStringAt(img, lf, fallbackFace, label, x, plotBottom+6+ascent, textColor)
+		lastRight = x + w
+	}
+
+	// Axes
+	for y := plotTop; y <= plotBottom; y++ {
+		img.Set(plotLeft, y, axisColor)
+	}
+	for x := plotLeft; x <= plotRight; x++ {
+		img.Set(x, plotBottom, axisColor)
+	}
+
+	for n, s := range series {
+		c := chartColors[n%len(chartColors)]
+		for p := range s.Points {
+			x, y := xFor(s.Points[p].Time), yFor(s.Points[p].Value)
+			if p > 0 {
+				drawChartLine(img, xFor(s.Points[p-1].Time), yFor(s.Points[p-1].Value), x, y, c)
+			}
+			fillChartRect(img, x-3, y-3, 7, 7, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawChartLine draws a 2px line between two points
+func drawChartLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := x1-x0, y0-y1
+	sx, sy := 1, 1
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	if dy > 0 {
+		dy, sy = -dy, -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		img.Set(x0+1, y0, c)
+		img.Set(x0, y0+1, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func fillChartRect(img *image.RGBA, x, y, w, h int, c color.Color) {
+	draw.Draw(img, image.Rect(x, y, x+w, y+h), &image.Uniform{c}, image.Point{}, draw.Src)
+}
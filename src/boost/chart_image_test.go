@@ -0,0 +1,35 @@
+package boost
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestRenderLineChartImage(t *testing.T) {
+	start := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	series := []ChartSeries{
+		{Label: "first", Points: []ChartPoint{{start, 1}, {start.Add(week), 3}, {start.Add(2 * week), 2}}},
+		{Label: "second", Points: []ChartPoint{{start.Add(week), 5}}},
+	}
+	data, err := RenderLineChartImage("Test", series, func(v float64) string { return "x" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if img.Bounds().Dx() != 900 {
+		t.Errorf("width = %d, want 900", img.Bounds().Dx())
+	}
+
+	// A single value or no values still render
+	for _, s := range [][]ChartSeries{nil, {{Label: "one", Points: []ChartPoint{{start, 4}}}}} {
+		if _, err := RenderLineChartImage("Test", s, func(v float64) string { return "x" }); err != nil {
+			t.Errorf("render %v: %v", s, err)
+		}
+	}
+}
@@ -109,6 +109,13 @@ func GetSlashContractCommand(cmd string) *discordgo.ApplicationCommand {
 				Description: "Create a thread for this contract? (default: true)",
 				Required:    false,
 			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "template",
+				Description:  "Start from a saved /contract-template, other options override it.",
+				Required:     false,
+				Autocomplete: true,
+			},
 		},
 	}
 }
@@ -160,6 +167,24 @@ func HandleContractCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 
 	optionMap := bottools.GetCommandOptionsMap(i)
 
+	var template *contractTemplate
+	if opt, ok := optionMap["template"]; ok {
+		template, err = loadContractTemplate(i.GuildID, opt.StringValue())
+		if err != nil {
+			_, _ = s.FollowupMessageCreate(i.Interaction, true,
+				&discordgo.WebhookParams{
+					Content:    err.Error(),
+					Flags:      discordgo.MessageFlagsEphemeral,
+					Components: []discordgo.MessageComponent{},
+				},
+			)
+			return
+		}
+		playStyle = template.PlayStyle
+		boostOrder = template.BoostOrder
+		coopSize = template.CoopSize
+	}
+
 	if opt, ok := optionMap["play-style"]; ok {
 		playStyle = int(opt.IntValue())
 	}
@@ -408,6 +433,9 @@ func HandleContractCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 
 	mutex.Lock()
 	contract, err := CreateContract(s, contractID, coopID, playStyle, coopSize, boostOrder, i.GuildID, ChannelID, progenitors, getInteractionUserID(i), plannedStartTime, validFrom)
+	if err == nil && template != nil {
+		template.apply(contract)
+	}
 	mutex.Unlock()
 
 	if err != nil {
@@ -855,71 +883,11 @@ func HandleContractSettingsReactions(s *discordgo.Session, i *discordgo.Interact
 		*/
 
 		values := data.Values
-		switch values[0] {
-		case "signup":
-			contract.BoostOrder = ContractOrderSignup
-		case "reverse":
-			contract.BoostOrder = ContractOrderReverse
-		case "fair":
-			contract.BoostOrder = ContractOrderFair
-		case "random":
-			contract.BoostOrder = ContractOrderRandom
-		case "elr":
-			contract.BoostOrder = ContractOrderELR
-			for _, b := range contract.Boosters {
-				// Refresh the user's artifact set
-				contract.Boosters[b.UserID].ArtifactSet = getUserArtifacts(b.UserID, nil)
-			}
-		case "tval":
-			contract.BoostOrder = ContractOrderTVal
-		case "ask":
-			contract.BoostOrder = ContractOrderTokenAsk
-		case "te", "fuzzyte":
-			type userToRefresh struct {
-				userID  string
-				booster *Booster
-			}
-			if values[0] == "fuzzyte" {
-				contract.BoostOrder = ContractOrderTEFuzzy
-			} else {
-				contract.BoostOrder = ContractOrderTE
-			}
-			// Refresh the user's egg inc data, if they have 0 TE count
-			// Collect userIDs and boosters with TECount == 0
-			usersToRefresh := make([]userToRefresh, 0, len(contract.Boosters))
-			for userID, b := range contract.Boosters {
-				if b.TECount == 0 {
-					usersToRefresh = append(usersToRefresh, userToRefresh{userID: userID, booster: b})
-				}
-			}
-			// Call updateContractFarmerTE for each collected user
-			for _, item := range usersToRefresh {
-				updateContractFarmerTE(s, item.userID, item.booster, contract)
-			}
-		case "ihr", "fuzzyihr":
-			type userToRefresh struct {
-				userID  string
-				booster *Booster
-			}
-			if values[0] == "fuzzyihr" {
-				contract.BoostOrder = ContractOrderIHRFuzzy
-			} else {
-				contract.BoostOrder = ContractOrderIHR
-			}
-			usersToRefresh := make([]userToRefresh, 0, len(contract.Boosters))
-			for userID, b := range contract.Boosters {
-				// Recalculate IHR rate from DB for all boosters so pre-change values excluding deflector stones are updated
-				rate, logStr := CalculateIHRRateFromDB(userID)
-				b.IHRRate = rate
-				b.IHRCalcLog = logStr
-
-				if b.IHRRate == 0 {
-					usersToRefresh = append(usersToRefresh, userToRefresh{userID: userID, booster: b})
-				}
-			}
-			// Call updateContractFarmerTE for each collected user with 0 IHR
-			for _, item := range usersToRefresh {
-				updateContractFarmerTE(s, item.userID, item.booster, contract)
+		if strategy, ok := getBoostOrderStrategyByKey(values[0]); ok {
+			contract.BoostOrder = strategy.Info().ID
+			// Some orders need fresh farmer data before sorting
+			if preparer, ok := strategy.(boostOrderPreparer); ok {
+				preparer.Prepare(s, contract)
 			}
 		}
 	}
@@ -0,0 +1,133 @@
+package boost
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ContractAPIBooster is the read-only view of a booster exposed by the HTTP API
+type ContractAPIBooster struct {
+	UserID         string    `json:"user_id"`
+	Name           string    `json:"name"`
+	Position       int       `json:"position"`
+	BoostState     string    `json:"boost_state"`
+	TokensReceived int       `json:"tokens_received"`
+	TokensWanted   int       `json:"tokens_wanted"`
+	StartTime      time.Time `json:"start_time,omitzero"`
+	EndTime        time.Time `json:"end_time,omitzero"`
+}
+
+// ContractAPIView is the read-only view of a contract exposed by the HTTP API
+type ContractAPIView struct {
+	ContractHash     string               `json:"contract_hash"`
+	ContractID       string               `json:"contract_id"`
+	CoopID           string               `json:"coop_id"`
+	Name             string               `json:"name"`
+	ChannelID        string               `json:"channel_id"`
+	State            string               `json:"state"`
+	BoostOrder       string               `json:"boost_order"`
+	CoopSize         int                  `json:"coop_size"`
+	CurrentBooster   string               `json:"current_booster,omitempty"`
+	StartTime        time.Time            `json:"start_time,omitzero"`
+	EstimatedEndTime time.Time            `json:"estimated_end_time,omitzero"`
+	Order            []ContractAPIBooster `json:"order"`
+	Waitlist         []string             `json:"waitlist,omitempty"`
+}
+
+var boostStateAPINames = map[int]string{
+	BoostStateUnboosted: "unboosted",
+	BoostStateTokenTime: "token_time",
+	BoostStateBoosted:   "boosted",
+}
+
+func contractStateAPIName(state int) string {
+	if state >= 0 && state < len(contractStateNames) {
+		return strings.ToLower(strings.TrimPrefix(contractStateNames[state], "ContractState"))
+	}
+	return "unknown"
+}
+
+// contractLocationForGuild returns the location of the contract within the guild
+func contractLocationForGuild(contract *Contract, guildID string) *LocationData {
+	for _, loc := range contract.Location {
+		if loc != nil && loc.GuildID == guildID {
+			return loc
+		}
+	}
+	return nil
+}
+
+// GetContractAPIView returns the API view of a contract as seen from a guild.
+// Nil is returned when the contract has no thread in that guild.
+func GetContractAPIView(contract *Contract, guildID string) *ContractAPIView {
+	if contract == nil {
+		return nil
+	}
+	loc := contractLocationForGuild(contract, guildID)
+	if loc == nil {
+		return nil
+	}
+
+	contract.mutex.Lock()
+	defer contract.mutex.Unlock()
+
+	view := &ContractAPIView{
+		ContractHash:     contract.ContractHash,
+		ContractID:       contract.ContractID,
+		CoopID:           contract.CoopID,
+		Name:             contract.Name,
+		ChannelID:        loc.ChannelID,
+		State:            contractStateAPIName(contract.State),
+		CoopSize:         contract.CoopSize,
+		CurrentBooster:   contract.CurrentBoosterUserID,
+		StartTime:        contract.StartTime,
+		EstimatedEndTime: contract.EstimatedEndTime,
+		Order:            make([]ContractAPIBooster, 0, len(contract.Order)),
+		Waitlist:         append([]string(nil), contract.WaitlistBoosters...),
+	}
+	if strategy, ok := getBoostOrderStrategy(contract.BoostOrder); ok {
+		view.BoostOrder = strategy.Info().Name
+	}
+
+	for idx, userID := range contract.Order {
+		b := contract.Boosters[userID]
+		if b == nil {
+			continue
+		}
+		view.Order = append(view.Order, ContractAPIBooster{
+			UserID:         b.UserID,
+			Name:           b.Nick,
+			Position:       idx + 1,
+			BoostState:     boostStateAPINames[b.BoostState],
+			TokensReceived: b.TokensReceived,
+			TokensWanted:   b.TokensWanted,
+			StartTime:      b.StartTime,
+			EndTime:        b.EndTime,
+		})
+	}
+	return view
+}
+
+// GetGuildContractAPIViews returns the API views of every running contract in a guild
+func GetGuildContractAPIViews(guildID string) []*ContractAPIView {
+	ContractsMutex.RLock()
+	contracts := make([]*Contract, 0, len(Contracts))
+	for _, c := range Contracts {
+		if c != nil && c.State != ContractStateArchive {
+			contracts = append(contracts, c)
+		}
+	}
+	ContractsMutex.RUnlock()
+
+	views := make([]*ContractAPIView, 0, len(contracts))
+	for _, c := range contracts {
+		if view := GetContractAPIView(c, guildID); view != nil {
+			views = append(views, view)
+		}
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].ContractHash < views[j].ContractHash
+	})
+	return views
+}
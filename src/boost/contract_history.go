@@ -0,0 +1,514 @@
+package boost
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+)
+
+// Contract history event types. Each one is recorded after the transition
+// it names has been applied to the contract.
+const (
+	ContractEventState   = "state"   // changeContractState
+	ContractEventBoost   = "boost"   // Boosting / UserBoost
+	ContractEventSkip    = "skip"    // SkipBooster
+	ContractEventUnboost = "unboost" // Unboost
+	ContractEventMove    = "move"    // MoveBooster
+	ContractEventOrder   = "order"   // ChangeBoostOrder
+	ContractEventToken   = "token"   // Token sent to a farmer
+
+	ContractEventTokenMove     = "token_move"     // Token log entry given to another farmer
+	ContractEventTokenDelete   = "token_delete"   // Token log entry removed
+	ContractEventTokenQuantity = "token_quantity" // Token log entry quantity corrected
+)
+
+const contractHistoryMaxLines = 25
+
+// contractEventSnapshot is the boost-list state captured with each transition.
+// Order changes are stored as their result rather than re-derived on replay so
+// the skip and move rules can evolve without breaking older histories.
+type contractEventSnapshot struct {
+	State                int            `json:"state"`
+	Order                []string       `json:"order,omitempty"`
+	BoostedOrder         []string       `json:"boosted_order,omitempty"`
+	CurrentBoosterUserID string         `json:"current,omitempty"`
+	BoostStates          map[string]int `json:"boost_states,omitempty"`
+}
+
+// contractHistoryEvent is a single entry of the append-only contract_events stream
+type contractHistoryEvent struct {
+	ID       int64
+	Time     time.Time
+	Type     string
+	ActorID  string
+	TargetID string
+	Snapshot *contractEventSnapshot
+	Token    *ei.TokenUnitLog
+}
+
+// contractEventPayload is the JSON stored in the value column
+type contractEventPayload struct {
+	Snapshot *contractEventSnapshot `json:"snapshot,omitempty"`
+	Token    *ei.TokenUnitLog       `json:"token,omitempty"`
+}
+
+func newContractEventSnapshot(contract *Contract) *contractEventSnapshot {
+	snap := &contractEventSnapshot{
+		State:                contract.State,
+		Order:                slices.Clone(contract.Order),
+		BoostedOrder:         slices.Clone(contract.BoostedOrder),
+		CurrentBoosterUserID: contract.CurrentBoosterUserID,
+		BoostStates:          make(map[string]int, len(contract.Boosters)),
+	}
+	for userID, b := range contract.Boosters {
+		if b != nil {
+			snap.BoostStates[userID] = b.BoostState
+		}
+	}
+	return snap
+}
+
+// recordContractEvent appends a state transition to the contract event stream
+func recordContractEvent(contract *Contract, eventType string, actorID string, targetID string) {
+	if contract == nil {
+		return
+	}
+	storeContractEvent(contract, eventType, actorID, targetID, contractEventPayload{Snapshot: newContractEventSnapshot(contract)})
+}
+
+// recordContractTokenEvent appends a token send to the contract event stream
+func recordContractTokenEvent(contract *Contract, token ei.TokenUnitLog) {
+	if contract == nil {
+		return
+	}
+	storeContractEvent(contract, ContractEventToken, token.FromUserID, token.ToUserID, contractEventPayload{Token: &token})
+}
+
+// recordContractTokenEditEvent appends a correction of an earlier token send.
+// The token is stored as it is after the edit, or as it was when deleted.
+func recordContractTokenEditEvent(contract *Contract, eventType string, actorID string, token ei.TokenUnitLog) {
+	if contract == nil {
+		return
+	}
+	storeContractEvent(contract, eventType, actorID, token.ToUserID, contractEventPayload{Token: &token})
+}
+
+func storeContractEvent(contract *Contract, eventType string, actorID string, targetID string, payload contractEventPayload) {
+	if queries == nil || contract.ContractHash == "" {
+		return
+	}
+	channelID := ""
+	if len(contract.Location) > 0 && contract.Location[0] != nil {
+		channelID = contract.Location[0].ChannelID
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling %s event for %s: %v", eventType, contract.ContractHash, err)
+		return
+	}
+
+	err = queries.InsertContractEvent(ctx, InsertContractEventParams{
+		Contracthash: contract.ContractHash,
+		Channelid:    channelID,
+		EventTime:    time.Now().UnixMilli(),
+		EventType:    eventType,
+		Actorid:      actorID,
+		Targetid:     targetID,
+		Value:        sql.NullString{String: string(data), Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error recording %s event for %s: %v", eventType, contract.ContractHash, err)
+	}
+}
+
+func contractHistoryEventFromRow(row ContractEvent) contractHistoryEvent {
+	ev := contractHistoryEvent{
+		ID:       row.ID,
+		Time:     time.UnixMilli(row.EventTime),
+		Type:     row.EventType,
+		ActorID:  row.Actorid,
+		TargetID: row.Targetid,
+	}
+	if row.Value.Valid {
+		var payload contractEventPayload
+		if err := json.Unmarshal([]byte(row.Value.String), &payload); err != nil {
+			log.Printf("Error unmarshaling contract event %d: %v", row.ID, err)
+		} else {
+			ev.Snapshot = payload.Snapshot
+			ev.Token = payload.Token
+		}
+	}
+	return ev
+}
+
+// loadContractHistory returns the event stream for a contract hash. When the
+// hash is empty the stream for the channel is used instead, so the history of
+// a contract whose blob can no longer be loaded can still be shown.
+func loadContractHistory(contractHash string, channelID string) ([]contractHistoryEvent, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+
+	var rows []ContractEvent
+	var err error
+	if contractHash != "" {
+		rows, err = queries.GetContractEvents(ctx, contractHash)
+	} else {
+		rows, err = queries.GetContractEventsByChannel(ctx, channelID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]contractHistoryEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, contractHistoryEventFromRow(row))
+	}
+	return events, nil
+}
+
+// replayContractEvents rebuilds the boost state of a contract from its events.
+// Events after `at` are ignored; a zero `at` replays the whole stream.
+func replayContractEvents(events []contractHistoryEvent, at time.Time) *Contract {
+	contract := &Contract{
+		Boosters: make(map[string]*Booster),
+	}
+
+	ensureBooster := func(userID string) *Booster {
+		if userID == "" {
+			return nil
+		}
+		b, ok := contract.Boosters[userID]
+		if !ok {
+			b = &Booster{UserID: userID, Name: userID, Nick: userID, Mention: fmt.Sprintf("<@%s>", userID)}
+			contract.Boosters[userID] = b
+		}
+		return b
+	}
+
+	for _, ev := range events {
+		if !at.IsZero() && ev.Time.After(at) {
+			break
+		}
+
+		if snap := ev.Snapshot; snap != nil {
+			contract.State = snap.State
+			contract.Order = slices.Clone(snap.Order)
+			contract.BoostedOrder = slices.Clone(snap.BoostedOrder)
+			contract.CurrentBoosterUserID = snap.CurrentBoosterUserID
+			for _, userID := range snap.Order {
+				ensureBooster(userID)
+			}
+			for userID, state := range snap.BoostStates {
+				ensureBooster(userID).BoostState = state
+			}
+			if contract.StartTime.IsZero() && snap.State != ContractStateSignup {
+				contract.StartTime = ev.Time
+			}
+			if snap.State == ContractStateCompleted && contract.EndTime.IsZero() {
+				contract.EndTime = ev.Time
+			}
+		}
+
+		if tok := ev.Token; tok != nil {
+			switch ev.Type {
+			case ContractEventTokenMove, ContractEventTokenDelete, ContractEventTokenQuantity:
+				idx := slices.IndexFunc(contract.TokenLog, func(t ei.TokenUnitLog) bool {
+					return tok.Serial != "" && t.Serial == tok.Serial
+				})
+				if idx == -1 {
+					break
+				}
+				if b := contract.Boosters[contract.TokenLog[idx].ToUserID]; b != nil {
+					b.TokensReceived -= contract.TokenLog[idx].Quantity
+				}
+				if ev.Type == ContractEventTokenDelete {
+					contract.TokenLog = slices.Delete(contract.TokenLog, idx, idx+1)
+					break
+				}
+				contract.TokenLog[idx] = *tok
+				if b := ensureBooster(tok.ToUserID); b != nil {
+					b.TokensReceived += tok.Quantity
+				}
+			default:
+				contract.TokenLog = append(contract.TokenLog, *tok)
+				if b := ensureBooster(tok.ToUserID); b != nil {
+					b.TokensReceived += tok.Quantity
+				}
+			}
+		}
+	}
+
+	return contract
+}
+
+// applyReplayedContract overwrites the boost state of a live contract with
+// the result of a replay, keeping the live booster metadata where it exists.
+func applyReplayedContract(contract *Contract, replay *Contract) {
+	contract.State = replay.State
+	contract.Order = slices.Clone(replay.Order)
+	contract.BoostedOrder = slices.Clone(replay.BoostedOrder)
+	contract.CurrentBoosterUserID = replay.CurrentBoosterUserID
+	contract.OrderRevision++
+
+	if contract.Boosters == nil {
+		contract.Boosters = make(map[string]*Booster)
+	}
+	for userID, rb := range replay.Boosters {
+		b, ok := contract.Boosters[userID]
+		if !ok {
+			contract.Boosters[userID] = rb
+			continue
+		}
+		b.BoostState = rb.BoostState
+		if len(replay.TokenLog) >= len(contract.TokenLog) {
+			b.TokensReceived = rb.TokensReceived
+		}
+	}
+
+	// Only replace the token log when the events have at least as much detail
+	if len(replay.TokenLog) >= len(contract.TokenLog) {
+		contract.TokenLog = slices.Clone(replay.TokenLog)
+	}
+	contract.enforceOnlyOneTokenTimeBooster()
+}
+
+func contractHistoryName(contract *Contract, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	if contract != nil {
+		if b, ok := contract.Boosters[userID]; ok && b != nil && b.Nick != "" && b.Nick != userID {
+			return b.Nick
+		}
+	}
+	return fmt.Sprintf("<@%s>", userID)
+}
+
+// describeContractEvent returns a single line summary of an event
+func describeContractEvent(contract *Contract, ev contractHistoryEvent) string {
+	target := contractHistoryName(contract, ev.TargetID)
+	actor := contractHistoryName(contract, ev.ActorID)
+
+	var str string
+	switch ev.Type {
+	case ContractEventState:
+		state := "Unknown"
+		if ev.Snapshot != nil && ev.Snapshot.State < len(contractStateNames) {
+			state = strings.TrimPrefix(contractStateNames[ev.Snapshot.State], "ContractState")
+		}
+		str = "State changed to **" + state + "**"
+	case ContractEventBoost:
+		str = fmt.Sprintf("**%s** boosted", target)
+	case ContractEventSkip:
+		str = fmt.Sprintf("**%s** skipped", target)
+	case ContractEventUnboost:
+		str = fmt.Sprintf("**%s** unboosted", target)
+	case ContractEventMove:
+		str = fmt.Sprintf("**%s** moved", target)
+		if ev.Snapshot != nil {
+			if idx := slices.Index(ev.Snapshot.Order, ev.TargetID); idx != -1 {
+				str += fmt.Sprintf(" to position %d", idx+1)
+			}
+		}
+	case ContractEventOrder:
+		str = "Boost order changed"
+	case ContractEventToken:
+		if ev.Token != nil {
+			str = fmt.Sprintf("%s sent %d to %s", actor, ev.Token.Quantity, target)
+		} else {
+			str = "Token sent"
+		}
+	case ContractEventTokenMove:
+		str = fmt.Sprintf("Token moved to **%s**", target)
+	case ContractEventTokenDelete:
+		str = "Token deleted"
+		if ev.Token != nil {
+			str = fmt.Sprintf("Token of %d from %s to %s deleted", ev.Token.Quantity, contractHistoryName(contract, ev.Token.FromUserID), target)
+		}
+	case ContractEventTokenQuantity:
+		str = "Token quantity changed"
+		if ev.Token != nil {
+			str = fmt.Sprintf("Token to %s changed to %d", target, ev.Token.Quantity)
+		}
+	default:
+		str = ev.Type
+	}
+
+	if actor != "" && ev.Type != ContractEventToken && ev.ActorID != ev.TargetID {
+		str += " by " + actor
+	}
+	return fmt.Sprintf("%s %s", bottools.WrapTimestamp(ev.Time.Unix(), bottools.TimestampLongTime), str)
+}
+
+// getContractHistoryString renders the timeline and the replayed boost list
+func getContractHistoryString(contract *Contract, events []contractHistoryEvent, at time.Time) string {
+	var builder strings.Builder
+
+	var shown []contractHistoryEvent
+	for _, ev := range events {
+		if !at.IsZero() && ev.Time.After(at) {
+			break
+		}
+		shown = append(shown, ev)
+	}
+
+	replay := replayContractEvents(events, at)
+
+	if at.IsZero() {
+		fmt.Fprintf(&builder, "## Contract history: %d events\n", len(shown))
+	} else {
+		fmt.Fprintf(&builder, "## Contract history at %s: %d of %d events\n", bottools.WrapTimestamp(at.Unix(), bottools.TimestampLongDateTime), len(shown), len(events))
+	}
+
+	skipped := max(len(shown)-contractHistoryMaxLines, 0)
+	if skipped > 0 {
+		fmt.Fprintf(&builder, "-# %d earlier events not shown\n", skipped)
+	}
+	for _, ev := range shown[skipped:] {
+		builder.WriteString("> " + describeContractEvent(contract, ev) + "\n")
+	}
+
+	builder.WriteString("### Boost order\n")
+	if len(replay.Order) == 0 {
+		builder.WriteString("> No boost order recorded yet.\n")
+	}
+	for i, userID := range replay.Order {
+		marker := ""
+		if b := replay.Boosters[userID]; b != nil {
+			switch b.BoostState {
+			case BoostStateBoosted:
+				marker = " " + boostIcon
+			case BoostStateTokenTime:
+				marker = " ⏳"
+			}
+			if b.TokensReceived > 0 {
+				marker += fmt.Sprintf(" (%d)", b.TokensReceived)
+			}
+		}
+		fmt.Fprintf(&builder, "> %d - %s%s\n", i+1, contractHistoryName(contract, userID), marker)
+	}
+	return builder.String()
+}
+
+// GetSlashContractHistoryCommand returns the command definition for /contract-history
+func GetSlashContractHistoryCommand(cmd string) *discordgo.ApplicationCommand {
+	minMinutes := float64(0)
+	return &discordgo.ApplicationCommand{
+		Name:        cmd,
+		Description: "Replay the boost history of the contract in this thread.",
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+		},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "minutes",
+				Description: "Show the contract as it was this many minutes after the first event.",
+				MinValue:    &minMinutes,
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "rebuild",
+				Description: "Coordinator only. Restore the boost list from the recorded history.",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// HandleContractHistoryCommand handles the /contract-history command
+func HandleContractHistoryCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := bottools.GetInteractionUserID(i)
+	flags := discordgo.MessageFlagsEphemeral | discordgo.MessageFlagsSuppressNotifications
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Processing request...",
+			Flags:   flags,
+		},
+	})
+
+	minutes := int64(-1)
+	rebuild := false
+	optionMap := bottools.GetCommandOptionsMap(i)
+	if opt, ok := optionMap["minutes"]; ok {
+		minutes = opt.IntValue()
+	}
+	if opt, ok := optionMap["rebuild"]; ok {
+		rebuild = opt.BoolValue()
+	}
+
+	contract := FindContract(i.ChannelID)
+	contractHash := ""
+	if contract != nil {
+		contractHash = contract.ContractHash
+	}
+
+	var str string
+	events, err := loadContractHistory(contractHash, i.ChannelID)
+	switch {
+	case err != nil:
+		str = "Unable to load the contract history: " + err.Error()
+	case len(events) == 0:
+		str = "No history has been recorded for this contract."
+	case rebuild:
+		str = rebuildContractFromHistory(s, contract, events, userID)
+	default:
+		var at time.Time
+		if minutes >= 0 {
+			at = events[0].Time.Add(time.Duration(minutes) * time.Minute)
+		}
+		str = getContractHistoryString(contract, events, at)
+	}
+
+	_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: truncateContractHistory(str),
+		Flags:   flags,
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+}
+
+func rebuildContractFromHistory(s *discordgo.Session, contract *Contract, events []contractHistoryEvent, userID string) string {
+	if contract == nil {
+		return "The contract for this thread can't be loaded, so there is no boost list to restore. Run `/contract-history` without `rebuild` to see what was recorded."
+	}
+	if !creatorOfContract(s, contract, userID) {
+		return errorNotContractCreator
+	}
+
+	replay := replayContractEvents(events, time.Time{})
+	applyReplayedContract(contract, replay)
+	saveData(contract.ContractHash)
+	refreshBoostListMessage(s, contract, false)
+
+	return fmt.Sprintf("Boost list rebuilt from %d recorded events.", len(events))
+}
+
+func truncateContractHistory(str string) string {
+	const limit = 2000
+	if len(str) <= limit {
+		return str
+	}
+	cut := strings.LastIndex(str[:limit-4], "\n")
+	if cut <= 0 {
+		cut = limit - 4
+	}
+	return str[:cut] + "\n..."
+}
@@ -0,0 +1,169 @@
+package boost
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	_ "modernc.org/sqlite"
+)
+
+func TestContractHistoryRecordAndReplay(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if _, err = db.Exec(ddl); err != nil {
+		t.Fatalf("failed to execute DDL: %v", err)
+	}
+
+	origQueries := queries
+	origDBConn := dbConn
+	defer func() {
+		queries = origQueries
+		dbConn = origDBConn
+	}()
+	dbConn = db
+	queries = New(db)
+
+	contract := &Contract{
+		ContractHash: "history-test",
+		Location:     []*LocationData{{ChannelID: "chan-1"}},
+		State:        ContractStateSignup,
+		Order:        []string{"a", "b", "c"},
+		Boosters: map[string]*Booster{
+			"a": {UserID: "a", Nick: "Alpha"},
+			"b": {UserID: "b", Nick: "Bravo"},
+			"c": {UserID: "c", Nick: "Charlie"},
+		},
+	}
+
+	changeContractState(contract, ContractStateFastrun)
+	contract.setCurrentBoosterByIndex(0)
+	contract.Boosters["a"].BoostState = BoostStateTokenTime
+	recordContractEvent(contract, ContractEventOrder, "coord", "")
+
+	recordContractTokenEvent(contract, ei.TokenUnitLog{Time: time.Now(), Quantity: 2, FromUserID: "b", ToUserID: "a", Serial: "t1"})
+	recordContractTokenEvent(contract, ei.TokenUnitLog{Time: time.Now(), Quantity: 1, FromUserID: "c", ToUserID: "a", Serial: "t2"})
+
+	// a skips, b becomes current
+	contract.Order = []string{"b", "a", "c"}
+	contract.Boosters["a"].BoostState = BoostStateUnboosted
+	contract.Boosters["b"].BoostState = BoostStateTokenTime
+	contract.setCurrentBoosterByIndex(0)
+	recordContractEvent(contract, ContractEventSkip, "coord", "a")
+
+	events, err := loadContractHistory("history-test", "")
+	if err != nil {
+		t.Fatalf("loadContractHistory failed: %v", err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(events))
+	}
+
+	if got := describeContractEvent(contract, events[4]); !strings.Contains(got, "**Alpha** skipped by <@coord>") {
+		t.Errorf("skip event = %q, want the coordinator who skipped", got)
+	}
+
+	replay := replayContractEvents(events, time.Time{})
+	if replay.State != ContractStateFastrun {
+		t.Errorf("replayed state = %d, want %d", replay.State, ContractStateFastrun)
+	}
+	if !reflect.DeepEqual(replay.Order, []string{"b", "a", "c"}) {
+		t.Errorf("replayed order = %v", replay.Order)
+	}
+	if replay.CurrentBoosterUserID != "b" {
+		t.Errorf("replayed current booster = %q, want b", replay.CurrentBoosterUserID)
+	}
+	if got := replay.Boosters["a"].TokensReceived; got != 3 {
+		t.Errorf("replayed tokens for a = %d, want 3", got)
+	}
+	if len(replay.TokenLog) != 2 {
+		t.Errorf("replayed token log length = %d, want 2", len(replay.TokenLog))
+	}
+
+	// Replaying up to the first order event should keep a as current
+	partial := replayContractEvents(events[:2], events[1].Time)
+	if partial.CurrentBoosterUserID != "a" {
+		t.Errorf("partial replay current booster = %q, want a", partial.CurrentBoosterUserID)
+	}
+
+	byChannel, err := loadContractHistory("", "chan-1")
+	if err != nil || len(byChannel) != len(events) {
+		t.Errorf("channel lookup returned %d events, err=%v", len(byChannel), err)
+	}
+}
+
+func TestApplyReplayedContractKeepsBoosterMetadata(t *testing.T) {
+	live := &Contract{
+		Order: []string{"a"},
+		Boosters: map[string]*Booster{
+			"a": {UserID: "a", Nick: "Alpha", BoostState: BoostStateUnboosted},
+		},
+	}
+	replay := &Contract{
+		State:                ContractStateBanker,
+		Order:                []string{"b", "a"},
+		CurrentBoosterUserID: "b",
+		Boosters: map[string]*Booster{
+			"a": {UserID: "a", BoostState: BoostStateBoosted},
+			"b": {UserID: "b", BoostState: BoostStateTokenTime},
+		},
+	}
+
+	applyReplayedContract(live, replay)
+
+	if live.Boosters["a"].Nick != "Alpha" || live.Boosters["a"].BoostState != BoostStateBoosted {
+		t.Errorf("booster a not merged correctly: %+v", live.Boosters["a"])
+	}
+	if live.Boosters["b"] == nil {
+		t.Fatalf("booster b was not restored")
+	}
+	if live.State != ContractStateBanker || live.currentBoosterID() != "b" {
+		t.Errorf("unexpected state %d / current %q", live.State, live.currentBoosterID())
+	}
+}
+
+func TestReplayContractTokenEdits(t *testing.T) {
+	sent := []ei.TokenUnitLog{
+		{Quantity: 2, FromUserID: "b", ToUserID: "a", Serial: "t1"},
+		{Quantity: 1, FromUserID: "c", ToUserID: "a", Serial: "t2"},
+		{Quantity: 3, FromUserID: "a", ToUserID: "b", Serial: "t3"},
+	}
+	moved := sent[0]
+	moved.ToUserID = "c"
+	recounted := sent[2]
+	recounted.Quantity = 5
+
+	now := time.Now()
+	events := []contractHistoryEvent{
+		{Time: now, Type: ContractEventToken, Token: &sent[0]},
+		{Time: now, Type: ContractEventToken, Token: &sent[1]},
+		{Time: now, Type: ContractEventToken, Token: &sent[2]},
+		{Time: now, Type: ContractEventTokenMove, ActorID: "coord", Token: &moved},
+		{Time: now, Type: ContractEventTokenDelete, ActorID: "coord", Token: &sent[1]},
+		{Time: now, Type: ContractEventTokenQuantity, ActorID: "coord", Token: &recounted},
+	}
+
+	replay := replayContractEvents(events, time.Time{})
+	var serials []string
+	for _, tok := range replay.TokenLog {
+		serials = append(serials, tok.Serial)
+	}
+	if !reflect.DeepEqual(serials, []string{"t1", "t3"}) {
+		t.Fatalf("replayed token log = %v, want [t1 t3]", serials)
+	}
+	if replay.TokenLog[0].ToUserID != "c" || replay.TokenLog[1].Quantity != 5 {
+		t.Errorf("token edits not replayed: %+v", replay.TokenLog)
+	}
+	for userID, want := range map[string]int{"a": 0, "b": 5, "c": 2} {
+		if got := replay.Boosters[userID].TokensReceived; got != want {
+			t.Errorf("replayed tokens for %s = %d, want %d", userID, got, want)
+		}
+	}
+}
@@ -2,7 +2,13 @@ package boost
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei/eifake"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestDeduplicateContributors(t *testing.T) {
@@ -110,3 +116,52 @@ func TestDeduplicateContributors(t *testing.T) {
 		})
 	}
 }
+
+func TestContractReportFromFakeServer(t *testing.T) {
+	server, status := startFakeCoop(t)
+	contractID, coopID := status.GetContractIdentifier(), status.GetCoopIdentifier()
+
+	// The report only runs against finished coops
+	status.SecondsSinceAllGoalsAchieved = proto.Float64(3600)
+	if err := server.CoopStatus(status); err != nil {
+		t.Fatalf("register coop status: %v", err)
+	}
+	archive := &ei.ContractsArchive{}
+	if err := eifake.LoadFixture(eifake.FixtureContractsArchive, archive); err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	if err := server.ContractsArchive(archive); err != nil {
+		t.Fatalf("register archive: %v", err)
+	}
+
+	callerArchive, _ := ei.GetContractArchiveFromAPI(nil, "EI0000000000000001", "caller", true, false)
+	callerEval := evalForContract(callerArchive, contractID, coopID)
+	if callerEval == nil {
+		t.Fatalf("expected the caller evaluation in the archive")
+	}
+	completed, _, _, err := ei.GetCoopStatusForCompletedContracts(contractID, coopID, "")
+	if err != nil {
+		t.Fatalf("GetCoopStatusForCompletedContracts: %v", err)
+	}
+	if completed.GetSecondsSinceAllGoalsAchieved() <= 0 {
+		t.Fatalf("expected a finished coop")
+	}
+
+	teammateArchive, _ := ei.GetContractArchiveFromAPI(nil, "EI0000000000000002", "teammate", true, false)
+	evByName := evalsForContractParallel(map[string][]*ei.LocalContract{"FakeFarmerTwo": teammateArchive}, contractID, coopID)
+	c := ei.EggIncContractsAll[contractID]
+	p := contractReportParameters{contractID: contractID, coopID: coopID, contract: &c}
+	p.thresholds = deriveThresholds(&p)
+	p.playerEvalsMetrics, p.metricPeaks = buildAndSortEvals("FakeFarmerOne", callerEval, evByName)
+
+	components, files := printContractReport(&p, false, false, false)
+	if len(files) != 0 || len(components) != 2 {
+		t.Fatalf("expected a header and an ANSI table, got %d components and %d files", len(components), len(files))
+	}
+	table := components[1].(*discordgo.TextDisplay).Content
+	for _, name := range []string{"FakeFarmerOne", "FakeFarmerTwo"} {
+		if !strings.Contains(table, ei.NormalizePlayerNameForDisplay(name)[:nameW]) {
+			t.Fatalf("expected %s in the report table:\n%s", name, table)
+		}
+	}
+}
@@ -0,0 +1,516 @@
+package boost
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// Contract schedule modes
+const (
+	contractScheduleModePredicted = "predicted" // run relative to the predicted drop time
+	contractScheduleModeLive      = "live"      // run after the contract shows up in the periodicals
+)
+
+// Contract schedule job states
+const (
+	contractScheduleJobPending = "pending"
+	contractScheduleJobDone    = "done"
+	contractScheduleJobFailed  = "failed"
+	contractScheduleJobMissed  = "missed"
+)
+
+// maxContractSchedules limits how many schedules a guild can have
+const maxContractSchedules = 10
+
+// contractScheduleMaxDelay is how late a job may still run, e.g. after a restart
+const contractScheduleMaxDelay = 6 * time.Hour
+
+// contractScheduleLiveWindow is how long after a drop a live contract still triggers a job
+const contractScheduleLiveWindow = 24 * time.Hour
+
+// contractScheduleSlots are the weekly release slots that can be scheduled
+var contractScheduleSlots = []string{"monday", "wednesday", "friday", "ultra"}
+
+// nextSlotDropTime returns the next predicted drop for a release slot after now
+func nextSlotDropTime(now time.Time, slot string) time.Time {
+	switch slot {
+	case "monday":
+		return nextSeasonalDate(now)
+	case "wednesday":
+		return nextWeekdayDate(now, time.Wednesday)
+	default:
+		return nextWeekdayDate(now, time.Friday)
+	}
+}
+
+// contractScheduleSlotKey identifies one drop of a slot, it matches the predicted contract ID
+func contractScheduleSlotKey(slot string, drop time.Time) string {
+	loc, _ := time.LoadLocation("America/Los_Angeles")
+	return fmt.Sprintf("%s-%s", slot, drop.In(loc).Format("2006-01-02"))
+}
+
+// planPredictedContractJobs queues the next job of every predicted mode schedule.
+// Jobs are unique per schedule and drop so this is safe to run repeatedly.
+func planPredictedContractJobs(now time.Time) {
+	if queries == nil {
+		sqliteInit()
+	}
+	schedules, err := queries.GetContractSchedules(ctx)
+	if err != nil {
+		log.Println("GetContractSchedules:", err)
+		return
+	}
+	for _, sched := range schedules {
+		if sched.Mode != contractScheduleModePredicted {
+			continue
+		}
+		offset := time.Duration(sched.OffsetMinutes) * time.Minute
+		// Pick the first drop whose run time is still ahead
+		drop := nextSlotDropTime(now.Add(-offset), sched.Slot)
+		slotKey := contractScheduleSlotKey(sched.Slot, drop)
+		err := queries.InsertContractScheduleJob(ctx, InsertContractScheduleJobParams{
+			Scheduleid: sched.ID,
+			SlotKey:    slotKey,
+			Contractid: slotKey,
+			RunAt:      drop.Add(offset).Unix(),
+		})
+		if err != nil {
+			log.Println("InsertContractScheduleJob:", err)
+		}
+	}
+}
+
+// ScheduleLiveContractJobs queues jobs for live mode schedules when a new
+// contract for their slot appears in the periodicals.
+func ScheduleLiveContractJobs(liveContracts []ei.EggIncContract) {
+	if queries == nil {
+		sqliteInit()
+	}
+	schedules, err := queries.GetContractSchedules(ctx)
+	if err != nil {
+		log.Println("GetContractSchedules:", err)
+		return
+	}
+	now := time.Now()
+	for _, sched := range schedules {
+		if sched.Mode != contractScheduleModeLive {
+			continue
+		}
+		for _, live := range liveContracts {
+			if live.Predicted || predictedSlotLabel(live) != sched.Slot {
+				continue
+			}
+			// Only contracts which dropped recently and after the schedule was made
+			if now.Sub(live.ValidFrom) > contractScheduleLiveWindow || live.ValidFrom.Unix() < sched.CreatedAt {
+				continue
+			}
+			err := queries.InsertContractScheduleJob(ctx, InsertContractScheduleJobParams{
+				Scheduleid: sched.ID,
+				SlotKey:    contractScheduleSlotKey(sched.Slot, live.ValidFrom),
+				Contractid: live.ID,
+				RunAt:      now.Add(time.Duration(sched.OffsetMinutes) * time.Minute).Unix(),
+			})
+			if err != nil {
+				log.Println("InsertContractScheduleJob:", err)
+			}
+		}
+	}
+}
+
+// StartContractScheduler plans and runs scheduled contract signups once a minute
+func StartContractScheduler(s *discordgo.Session) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		planPredictedContractJobs(now)
+		runDueContractJobs(s, now)
+		<-ticker.C
+	}
+}
+
+// runDueContractJobs creates the signup threads for every job that is due
+func runDueContractJobs(s *discordgo.Session, now time.Time) {
+	jobs, err := queries.GetDueContractScheduleJobs(ctx, now.Unix())
+	if err != nil {
+		log.Println("GetDueContractScheduleJobs:", err)
+		return
+	}
+	for _, job := range jobs {
+		status := contractScheduleJobDone
+		sched, err := queries.GetContractSchedule(ctx, job.Scheduleid)
+		switch {
+		case err != nil:
+			// The schedule was removed
+			status = contractScheduleJobFailed
+		case now.Sub(time.Unix(job.RunAt, 0)) > contractScheduleMaxDelay:
+			log.Printf("Contract schedule %d: skipped %s, it is %s late", sched.ID, job.SlotKey, now.Sub(time.Unix(job.RunAt, 0)).Round(time.Minute))
+			status = contractScheduleJobMissed
+		default:
+			if err := runContractScheduleJob(s, sched, job); err != nil {
+				log.Printf("Contract schedule %d: %s: %v", sched.ID, job.SlotKey, err)
+				status = contractScheduleJobFailed
+			}
+		}
+		err = queries.UpdateContractScheduleJobStatus(ctx, UpdateContractScheduleJobStatusParams{
+			Status: status,
+			ID:     job.ID,
+		})
+		if err != nil {
+			log.Println("UpdateContractScheduleJobStatus:", err)
+		}
+	}
+}
+
+// resolveScheduledContractID returns the contract to use for a job, preferring
+// the live contract when a predicted drop has already happened.
+func resolveScheduledContractID(job ContractScheduleJob) (ei.EggIncContract, bool) {
+	for _, c := range ei.EggIncContracts {
+		if !c.Predicted && contractScheduleSlotKey(predictedSlotLabel(c), c.ValidFrom) == job.SlotKey {
+			return c, true
+		}
+	}
+	c, ok := ei.EggIncContractsAll[job.Contractid]
+	return c, ok
+}
+
+// runContractScheduleJob opens a signup thread in the schedule's channel
+func runContractScheduleJob(s *discordgo.Session, sched ContractSchedule, job ContractScheduleJob) error {
+	contractInfo, ok := resolveScheduledContractID(job)
+	if !ok {
+		return fmt.Errorf("contract %s is not known", job.Contractid)
+	}
+
+	playStyle := int(sched.PlayStyle)
+	boostOrder := -1
+	coopSize := 0
+	var template *contractTemplate
+	if sched.Template != "" {
+		var err error
+		template, err = loadContractTemplate(sched.Guildid, sched.Template)
+		if err != nil {
+			return err
+		}
+		if playStyle == ContractPlaystyleUnset {
+			playStyle = template.PlayStyle
+		}
+		boostOrder = template.BoostOrder
+		coopSize = template.CoopSize
+	}
+	if playStyle <= ContractPlaystyleUnset || playStyle >= len(contractPlaystyleNames) {
+		playStyle = ContractPlaystyleChill
+	}
+
+	threadStyleIcons := []string{"", "🟦 ", "🟩 ", "🟧 ", "🟥 "}
+	threadName := fmt.Sprintf("%s%s Signup (%s)", threadStyleIcons[playStyle], contractInfo.Name, contractPlaystyleNames[playStyle])
+	if len(threadName) > 100 {
+		threadName = threadName[:100]
+	}
+	thread, err := s.ThreadStart(sched.Channelid, threadName, discordgo.ChannelTypeGuildPublicThread, 60*24)
+	if err != nil {
+		return err
+	}
+	_ = s.ThreadMemberAdd(thread.ID, sched.CreatedBy)
+
+	mutex.Lock()
+	contract, err := CreateContract(s, contractInfo.ID, "TBD", playStyle, coopSize, boostOrder, sched.Guildid, thread.ID,
+		[]string{sched.CreatedBy}, sched.CreatedBy, time.Time{}, GetEggStandardTime(contractInfo.ValidFrom))
+	if err == nil && template != nil {
+		template.apply(contract)
+	}
+	mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	postContractMessages(s, contract, thread.ID)
+
+	if sched.Roleid != "" {
+		_, err = s.ChannelMessageSendComplex(thread.ID, &discordgo.MessageSend{
+			Content: fmt.Sprintf("<@&%s> signups are open for **%s**.", sched.Roleid, contractInfo.Name),
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Roles: []string{sched.Roleid},
+			},
+		})
+		if err != nil {
+			log.Println("Contract schedule ping:", err)
+		}
+	}
+	return nil
+}
+
+// GetSlashContractScheduleCommand returns the /contract-schedule command
+func GetSlashContractScheduleCommand(cmd string) *discordgo.ApplicationCommand {
+	slotChoices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(contractScheduleSlots))
+	for _, slot := range contractScheduleSlots {
+		slotChoices = append(slotChoices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  strings.ToUpper(slot[:1]) + slot[1:],
+			Value: slot,
+		})
+	}
+	minOffset := float64(-24 * 60)
+
+	return &discordgo.ApplicationCommand{
+		Name:        cmd,
+		Description: "Automatically create signup threads when contracts drop.",
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+		},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Create signup threads for a weekly contract slot",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "slot",
+						Description: "Contract release slot",
+						Required:    true,
+						Choices:     slotChoices,
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Channel for the signup threads, default is this channel",
+						Required:     false,
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Name:        "role",
+						Description: "Role to ping when signups open",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "mode",
+						Description: "When to create the thread, default is at the predicted drop",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Predicted drop time", Value: contractScheduleModePredicted},
+							{Name: "After the contract is live", Value: contractScheduleModeLive},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "minutes",
+						Description: "Minutes after the drop, negative runs before a predicted drop",
+						Required:    false,
+						MinValue:    &minOffset,
+						MaxValue:    24 * 60,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "play-style",
+						Description: "Contract Play Style, default is the template's or Chill",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "🟦 Chill", Value: ContractPlaystyleChill},
+							{Name: "🟩 ACO Cooperative", Value: ContractPlaystyleACOCooperative},
+							{Name: "🟧 Fastrun", Value: ContractPlaystyleFastrun},
+							{Name: "🟥 Leaderboard", Value: ContractPlaystyleLeaderboard},
+						},
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "template",
+						Description:  "Contract template to apply",
+						Required:     false,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List the contract schedules of this server",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a contract schedule",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Description: "Schedule ID from /contract-schedule list",
+						Required:    true,
+					},
+				},
+			},
+		},
+	}
+}
+
+// HandleContractScheduleCommand handles the /contract-schedule subcommands
+func HandleContractScheduleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := getInteractionUserID(i)
+	flags := discordgo.MessageFlagsEphemeral
+	bottools.AcknowledgeResponse(s, i, flags)
+
+	if queries == nil {
+		sqliteInit()
+	}
+
+	data := i.ApplicationCommandData()
+	optionMap := bottools.GetCommandOptionsMap(i)
+
+	var str string
+	switch data.Options[0].Name {
+	case "add":
+		if !guildstate.IsGuildCoordinator(i.GuildID, userID) && !isAdminCommandCaller(s, i) {
+			str = "Only coordinators can schedule contracts."
+			break
+		}
+		str = addContractSchedule(s, i, userID, optionMap)
+	case "list":
+		str = getContractScheduleList(i.GuildID)
+	case "remove":
+		if !guildstate.IsGuildCoordinator(i.GuildID, userID) && !isAdminCommandCaller(s, i) {
+			str = "Only coordinators can remove contract schedules."
+			break
+		}
+		id := optionMap["remove-id"].IntValue()
+		n, err := queries.DeleteContractSchedule(ctx, DeleteContractScheduleParams{ID: id, Guildid: i.GuildID})
+		switch {
+		case err != nil:
+			str = "Unable to remove the schedule: " + err.Error()
+		case n == 0:
+			str = fmt.Sprintf("No contract schedule with ID %d.", id)
+		default:
+			if err := queries.DeleteContractScheduleJobs(ctx, id); err != nil {
+				log.Println("DeleteContractScheduleJobs:", err)
+			}
+			str = fmt.Sprintf("Removed contract schedule %d.", id)
+		}
+	}
+
+	_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: str,
+		Flags:   flags,
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+}
+
+func addContractSchedule(s *discordgo.Session, i *discordgo.InteractionCreate, userID string, optionMap map[string]*discordgo.ApplicationCommandInteractionDataOption) string {
+	existing, err := queries.GetContractSchedulesForGuild(ctx, i.GuildID)
+	if err != nil {
+		return "Unable to read the contract schedules: " + err.Error()
+	}
+	if len(existing) >= maxContractSchedules {
+		return fmt.Sprintf("This server already has %d contract schedules.", maxContractSchedules)
+	}
+
+	params := InsertContractScheduleParams{
+		Guildid:   i.GuildID,
+		Channelid: i.ChannelID,
+		Mode:      contractScheduleModePredicted,
+		PlayStyle: ContractPlaystyleUnset,
+		CreatedBy: userID,
+		CreatedAt: time.Now().Unix(),
+	}
+	if opt, ok := optionMap["add-slot"]; ok {
+		params.Slot = opt.StringValue()
+	}
+	if opt, ok := optionMap["add-channel"]; ok {
+		params.Channelid = opt.ChannelValue(s).ID
+	}
+	if opt, ok := optionMap["add-role"]; ok {
+		params.Roleid = opt.RoleValue(s, i.GuildID).ID
+	}
+	if opt, ok := optionMap["add-mode"]; ok {
+		params.Mode = opt.StringValue()
+	}
+	if opt, ok := optionMap["add-minutes"]; ok {
+		params.OffsetMinutes = opt.IntValue()
+	}
+	if opt, ok := optionMap["add-play-style"]; ok {
+		params.PlayStyle = opt.IntValue()
+	}
+	if opt, ok := optionMap["add-template"]; ok {
+		name, err := guildstate.NormalizeContractTemplateName(opt.StringValue())
+		if err != nil {
+			return "Invalid template name: " + err.Error()
+		}
+		if _, err := loadContractTemplate(i.GuildID, name); err != nil {
+			return err.Error()
+		}
+		params.Template = name
+	}
+	if params.Mode == contractScheduleModeLive && params.OffsetMinutes < 0 {
+		return "Live schedules can't run before the contract appears."
+	}
+
+	sched, err := queries.InsertContractSchedule(ctx, params)
+	if err != nil {
+		return "Unable to save the schedule: " + err.Error()
+	}
+	planPredictedContractJobs(time.Now())
+	return "Scheduled: " + formatContractSchedule(sched)
+}
+
+func formatContractSchedule(sched ContractSchedule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%d** %s in <#%s>", sched.ID, sched.Slot, sched.Channelid)
+	switch {
+	case sched.Mode == contractScheduleModeLive:
+		fmt.Fprintf(&b, ", %d min after it is live", sched.OffsetMinutes)
+	case sched.OffsetMinutes < 0:
+		fmt.Fprintf(&b, ", %d min before the predicted drop", -sched.OffsetMinutes)
+	default:
+		fmt.Fprintf(&b, ", %d min after the predicted drop", sched.OffsetMinutes)
+	}
+	if sched.PlayStyle > ContractPlaystyleUnset && int(sched.PlayStyle) < len(contractPlaystyleNames) {
+		fmt.Fprintf(&b, " | Style: %s", contractPlaystyleNames[sched.PlayStyle])
+	}
+	if sched.Template != "" {
+		fmt.Fprintf(&b, " | Template: %s", sched.Template)
+	}
+	if sched.Roleid != "" {
+		fmt.Fprintf(&b, " | Ping: <@&%s>", sched.Roleid)
+	}
+	if jobs, err := queries.GetPendingContractScheduleJobs(ctx, sched.ID); err == nil && len(jobs) > 0 {
+		fmt.Fprintf(&b, " | Next: <t:%d:f>", jobs[0].RunAt)
+	}
+	return b.String()
+}
+
+func getContractScheduleList(guildID string) string {
+	schedules, err := queries.GetContractSchedulesForGuild(ctx, guildID)
+	if err != nil {
+		log.Println("GetContractSchedulesForGuild:", err)
+		return "Unable to read the contract schedules."
+	}
+	if len(schedules) == 0 {
+		return "No contract schedules. Use `/contract-schedule add` to create one."
+	}
+	var b strings.Builder
+	for _, sched := range schedules {
+		b.WriteString(formatContractSchedule(sched))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// HandleContractScheduleAutoComplete offers template names for /contract-schedule
+func HandleContractScheduleAutoComplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	for _, opt := range bottools.GetCommandOptionsMap(i) {
+		if opt.Focused {
+			handleContractTemplateAutoComplete(s, i, opt.StringValue())
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package boost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+)
+
+func TestContractSchedulePlanning(t *testing.T) {
+	useInMemoryContractDB(t)
+
+	loc, _ := time.LoadLocation("America/Los_Angeles")
+	// Tuesday before the Wednesday drop
+	now := time.Date(2024, 5, 14, 12, 0, 0, 0, loc)
+	created := now.Add(-time.Hour).Unix()
+
+	predicted, err := queries.InsertContractSchedule(ctx, InsertContractScheduleParams{
+		Guildid: "g", Channelid: "c", Slot: "wednesday", Mode: contractScheduleModePredicted,
+		OffsetMinutes: -30, PlayStyle: ContractPlaystyleFastrun, CreatedBy: "u", CreatedAt: created,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, err := queries.InsertContractSchedule(ctx, InsertContractScheduleParams{
+		Guildid: "g", Channelid: "c", Slot: "ultra", Mode: contractScheduleModeLive,
+		OffsetMinutes: 15, CreatedBy: "u", CreatedAt: created,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Planning twice must not duplicate the job
+	planPredictedContractJobs(now)
+	planPredictedContractJobs(now.Add(time.Minute))
+	jobs, err := queries.GetPendingContractScheduleJobs(ctx, predicted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("predicted jobs = %d, want 1", len(jobs))
+	}
+	wantRun := time.Date(2024, 5, 15, 8, 30, 0, 0, loc)
+	if jobs[0].SlotKey != "wednesday-2024-05-15" || jobs[0].RunAt != wantRun.Unix() {
+		t.Errorf("predicted job = %s at %s, want wednesday-2024-05-15 at %s", jobs[0].SlotKey, time.Unix(jobs[0].RunAt, 0).In(loc), wantRun)
+	}
+
+	// Once the run time has passed the following week is planned
+	planPredictedContractJobs(wantRun.Add(time.Minute))
+	if jobs, _ = queries.GetPendingContractScheduleJobs(ctx, predicted.ID); len(jobs) != 2 || jobs[1].SlotKey != "wednesday-2024-05-22" {
+		t.Errorf("expected the next week to be planned, got %+v", jobs)
+	}
+
+	// Live jobs only come from real contracts of the matching slot
+	drop := time.Date(2024, 5, 17, 9, 0, 0, 0, loc)
+	ScheduleLiveContractJobs([]ei.EggIncContract{
+		{ID: "old-ultra", Ultra: true, ValidFrom: time.Now().Add(-48 * time.Hour)},
+		{ID: "friday-plain", ValidFrom: drop},
+		{ID: "ultra-2024-05-17", Ultra: true, Predicted: true, ValidFrom: drop},
+	})
+	if jobs, _ = queries.GetPendingContractScheduleJobs(ctx, live.ID); len(jobs) != 0 {
+		t.Errorf("unexpected live jobs %+v", jobs)
+	}
+
+	recent := time.Now().Add(-time.Hour)
+	contract := ei.EggIncContract{ID: "new-ultra", Ultra: true, ValidFrom: recent}
+	ScheduleLiveContractJobs([]ei.EggIncContract{contract})
+	ScheduleLiveContractJobs([]ei.EggIncContract{contract})
+	jobs, _ = queries.GetPendingContractScheduleJobs(ctx, live.ID)
+	if len(jobs) != 1 || jobs[0].Contractid != "new-ultra" || jobs[0].SlotKey != contractScheduleSlotKey("ultra", recent) {
+		t.Errorf("live jobs = %+v", jobs)
+	}
+}
@@ -0,0 +1,180 @@
+package boost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/contractsim"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+)
+
+// giftTokensPerHour is the gift token rate of each farmer assumed by the estimates
+const giftTokensPerHour = 6.0
+
+// contractSimulation builds the simulation of a running coop. Farmers still
+// waiting on the boost list boost in list order, starting with the tokens
+// they hold, and tokens keep arriving as CalculateFutureTokenLogs predicts.
+func contractSimulation(contract *Contract, coopStatus *ei.ContractCoopStatusResponse, target float64) contractsim.Coop {
+	coop := ei.CoopStatusSimulation(coopStatus, target)
+	if contract == nil || len(coop.Farmers) == 0 {
+		return coop
+	}
+
+	byName := make(map[string]int, len(coop.Farmers))
+	for n, f := range coop.Farmers {
+		byName[strings.ToLower(f.Name)] = n
+	}
+	listed := make(map[string]int)
+	waiting := 0
+	for pos, userID := range contract.Order {
+		b := contract.Boosters[userID]
+		if b == nil {
+			continue
+		}
+		n, ok := byName[strings.ToLower(farmerstate.GetEggIncName(userID))]
+		if !ok {
+			continue
+		}
+		listed[strings.ToLower(coop.Farmers[n].Name)] = pos
+		if b.BoostState != BoostStateBoosted {
+			f := &coop.Farmers[n]
+			f.Boosted = false
+			f.BoostTokens = b.TokensWanted
+			f.Tokens = b.TokensReceived
+			f.BoostMultiplier = calcBoostMulti(float64(b.TokensWanted)) * boostMonocleMultiplier
+			waiting++
+		}
+	}
+	sort.SliceStable(coop.Farmers, func(i, j int) bool {
+		pi, iok := listed[strings.ToLower(coop.Farmers[i].Name)]
+		pj, jok := listed[strings.ToLower(coop.Farmers[j].Name)]
+		if iok != jok {
+			return iok
+		}
+		return pi < pj
+	})
+	if waiting == 0 {
+		return coop
+	}
+
+	duration := contract.EstimatedDuration
+	if duration <= 0 {
+		duration = time.Duration(contract.LengthInSeconds) * time.Second
+	}
+	minutesPerToken := max(contract.MinutesPerToken, 1)
+	// Every farmer's timer adds a token, so the coop sees them that much more often
+	timerMinutes := max(minutesPerToken/len(coop.Farmers), 1)
+	secondsPerGift := 3600.0 / (giftTokensPerHour * float64(len(coop.Farmers)))
+
+	now := time.Now()
+	// Enough gifts to cover the rest of the contract, however long it runs
+	maxGifts := int(coop.Length.Seconds()/secondsPerGift) + 1
+	tokens, tokensGG := bottools.CalculateFutureTokenLogs(maxGifts, contract.StartTime, timerMinutes, duration, secondsPerGift)
+	if _, _, endGG := ei.GetGenerousGiftEvent(); endGG.After(now) {
+		tokens = tokensGG
+	}
+	for _, t := range tokens {
+		if at := t.Time.Sub(now); at >= 0 && at <= coop.Length {
+			coop.TokenArrivals = append(coop.TokenArrivals, at)
+		}
+	}
+	return coop
+}
+
+// contractSimulationEstimate returns the start time of the coop running a
+// contract and how long it runs. A coop still going is simulated with the
+// boost list, so farmers yet to boost finish the way the list expects.
+func contractSimulationEstimate(contract *Contract, eeidOverride string) (time.Time, time.Duration, error) {
+	eiContract, ok := ei.GetEggIncContract(contract.ContractID)
+	if !ok || eiContract.ID == "" {
+		return time.Time{}, 0, fmt.Errorf("invalid contract ID")
+	}
+	coopStatus, _, _, err := ei.GetCoopStatus(contract.ContractID, contract.CoopID, eeidOverride)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if coopStatus.GetResponseStatus() != ei.ContractCoopStatusResponse_NO_ERROR {
+		return time.Time{}, 0, fmt.Errorf("%s", ei.ContractCoopStatusResponse_ResponseStatus_name[int32(coopStatus.GetResponseStatus())])
+	}
+	grade := int(coopStatus.GetGrade())
+	if grade < 0 || grade >= len(eiContract.Grade) || len(eiContract.Grade[grade].TargetAmount) == 0 {
+		return time.Time{}, 0, fmt.Errorf("grade %d out of range for contract %s", grade, contract.ContractID)
+	}
+
+	now := time.Now()
+	startTime := now.Add(time.Duration(coopStatus.GetSecondsRemaining()) * time.Second)
+	startTime = startTime.Add(-time.Duration(eiContract.Grade[grade].LengthInSeconds) * time.Second)
+	if coopStatus.GetSecondsSinceAllGoalsAchieved() > 0 {
+		endTime := now.Add(-time.Duration(coopStatus.GetSecondsSinceAllGoalsAchieved()) * time.Second)
+		return startTime, endTime.Sub(startTime), nil
+	}
+
+	targets := eiContract.Grade[grade].TargetAmount
+	result := contractsim.Run(contractSimulation(contract, coopStatus, targets[len(targets)-1]))
+	remaining := time.Duration(coopStatus.GetSecondsRemaining()) * time.Second
+	if result.Duration > 0 || result.Completed {
+		remaining = result.Duration
+	}
+	return startTime, now.Add(remaining).Sub(startTime), nil
+}
+
+// getCoopSimulationETA simulates the coop running in a channel and describes
+// when it finishes
+func getCoopSimulationETA(channelID string, userID string) string {
+	contract := FindContract(channelID)
+	if contract == nil {
+		return "No contract found in this channel, provide a timespan."
+	}
+	eiContract, ok := ei.GetEggIncContract(contract.ContractID)
+	if !ok {
+		return "Unknown contract " + contract.ContractID
+	}
+	coopStatus, _, _, err := ei.GetCoopStatus(contract.ContractID, contract.CoopID, farmerstate.GetMiscSettingString(userID, "encrypted_ei_id"))
+	if err != nil {
+		return err.Error()
+	}
+	if coopStatus.GetResponseStatus() != ei.ContractCoopStatusResponse_NO_ERROR {
+		return ei.ContractCoopStatusResponse_ResponseStatus_name[int32(coopStatus.GetResponseStatus())]
+	}
+	grade := int(coopStatus.GetGrade())
+	if grade < 0 || grade >= len(eiContract.Grade) || len(eiContract.Grade[grade].TargetAmount) == 0 {
+		return "No grade found for this coop"
+	}
+	if coopStatus.GetSecondsSinceAllGoalsAchieved() > 0 {
+		return fmt.Sprintf("%s/%s has completed.", contract.ContractID, contract.CoopID)
+	}
+
+	targets := eiContract.Grade[grade].TargetAmount
+	coop := contractSimulation(contract, coopStatus, targets[len(targets)-1])
+	result := contractsim.Run(coop)
+	now := time.Now()
+	endTime := now.Add(result.Duration)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Simulated completion %s near %s", bottools.WrapTimestamp(endTime.Unix(), bottools.TimestampRelativeTime), bottools.WrapTimestamp(endTime.Unix(), bottools.TimestampShortDateTime))
+	if !result.Completed {
+		builder.WriteString(", after the contract ends")
+	}
+	builder.WriteString("\n")
+
+	var lastBoost time.Duration = -1
+	waiting := 0
+	for n, f := range coop.Farmers {
+		if !f.Boosted {
+			waiting++
+			lastBoost = max(lastBoost, result.BoostEnd[n])
+		}
+	}
+	if waiting > 0 {
+		fmt.Fprintf(&builder, "%d still to boost", waiting)
+		if lastBoost >= 0 {
+			fmt.Fprintf(&builder, ", the last boost done %s", bottools.WrapTimestamp(now.Add(lastBoost).Unix(), bottools.TimestampRelativeTime))
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,49 @@
+package boost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestContractSimulationTokensCoverLongContracts(t *testing.T) {
+	const length = 3 * 24 * time.Hour
+	farmerstate.SetEggIncName("sim-user", "SimFarmer")
+
+	coopStatus := &ei.ContractCoopStatusResponse{
+		SecondsRemaining: proto.Float64(length.Seconds()),
+		Contributors: []*ei.ContractCoopStatusResponse_ContributionInfo{
+			{UserName: proto.String("SimFarmer"), ContributionRate: proto.Float64(1)},
+			{UserName: proto.String("Unlisted"), ContributionRate: proto.Float64(1)},
+		},
+	}
+	contract := &Contract{
+		Order:             []string{"sim-user"},
+		Boosters:          map[string]*Booster{"sim-user": {UserID: "sim-user", TokensWanted: 6}},
+		MinutesPerToken:   60,
+		StartTime:         time.Now(),
+		EstimatedDuration: length,
+	}
+
+	coop := contractSimulation(contract, coopStatus, 1e18)
+	// Waiting farmers boost with the monocle the token estimates assume
+	if got, want := coop.Farmers[0].BoostMultiplier, calcBoostMulti(6)*boostMonocleMultiplier; got != want {
+		t.Errorf("boost multiplier = %v, want %v", got, want)
+	}
+	if len(coop.TokenArrivals) == 0 {
+		t.Fatal("expected token arrivals")
+	}
+	lastDay := 0
+	for _, at := range coop.TokenArrivals {
+		if at > length-24*time.Hour {
+			lastDay++
+		}
+	}
+	// Gifts arrive every five minutes right up to the contract end
+	if lastDay < 24*12 {
+		t.Errorf("%d tokens arrive in the final day, want gifts until the %v contract ends", lastDay, length)
+	}
+}
@@ -0,0 +1,320 @@
+package boost
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// contractTemplateStyleMask holds the Style flags a template carries over
+const contractTemplateStyleMask = ContractFlagFastrun | ContractFlagBanker |
+	ContractFlag4Tokens | ContractFlag6Tokens | ContractFlag8Tokens |
+	ContractFlagDynamicTokens | ContractFlagThresholdTokens | ContractFlagAMQP
+
+// contractTemplate is the reusable part of a contract's settings
+type contractTemplate struct {
+	PlayStyle          int    `json:"play_style"`
+	BoostOrder         int    `json:"boost_order"`
+	CoopSize           int    `json:"coop_size,omitempty"` // 0 uses the EI contract size
+	Style              int64  `json:"style"`
+	ThresholdTokensX   int    `json:"threshold_x"`
+	ThresholdTokensY   int    `json:"threshold_y"`
+	ThresholdTokensA   int    `json:"threshold_a"`
+	SinkBoostPosition  int    `json:"sink_boost_position"`
+	BoostingSinkUserID string `json:"boosting_sink,omitempty"`
+	PostSinkUserID     string `json:"post_sink,omitempty"`
+}
+
+// newContractTemplate captures the template settings of a contract
+func newContractTemplate(contract *Contract) contractTemplate {
+	t := contractTemplate{
+		PlayStyle:          contract.PlayStyle,
+		BoostOrder:         contract.BoostOrder,
+		Style:              contract.Style & contractTemplateStyleMask,
+		ThresholdTokensX:   contract.ThresholdTokensX,
+		ThresholdTokensY:   contract.ThresholdTokensY,
+		ThresholdTokensA:   contract.ThresholdTokensA,
+		SinkBoostPosition:  contract.Banker.SinkBoostPosition,
+		BoostingSinkUserID: contract.Banker.BoostingSinkUserID,
+		PostSinkUserID:     contract.Banker.PostSinkUserID,
+	}
+	// Only keep a coop size that was overridden
+	if info, ok := ei.EggIncContractsAll[contract.ContractID]; !ok || info.MaxCoopSize != contract.CoopSize {
+		t.CoopSize = contract.CoopSize
+	}
+	return t
+}
+
+// apply copies the settings that CreateContract doesn't take as parameters.
+// Sinks are only assigned when they are already in the contract.
+func (t *contractTemplate) apply(contract *Contract) {
+	contract.Style = contract.Style&^contractTemplateStyleMask | t.Style&contractTemplateStyleMask
+	contract.ThresholdTokensX = t.ThresholdTokensX
+	contract.ThresholdTokensY = t.ThresholdTokensY
+	contract.ThresholdTokensA = t.ThresholdTokensA
+	contract.Banker.SinkBoostPosition = t.SinkBoostPosition
+	if t.BoostingSinkUserID != "" && UserInContract(contract, t.BoostingSinkUserID) {
+		contract.Banker.BoostingSinkUserID = t.BoostingSinkUserID
+	}
+	if t.PostSinkUserID != "" && UserInContract(contract, t.PostSinkUserID) {
+		contract.Banker.PostSinkUserID = t.PostSinkUserID
+	}
+}
+
+// String summarizes the template for listings
+func (t *contractTemplate) String() string {
+	playStyleName := contractPlaystyleNames[ContractPlaystyleUnset]
+	if t.PlayStyle >= 0 && t.PlayStyle < len(contractPlaystyleNames) {
+		playStyleName = contractPlaystyleNames[t.PlayStyle]
+	}
+	orderName := getBoostOrderName(t.BoostOrder)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Style: %s | Order: %s", playStyleName, orderName)
+	if t.CoopSize > 0 {
+		fmt.Fprintf(&b, " | Size: %d", t.CoopSize)
+	}
+	var styleFlags []string
+	for _, f := range contractFlagNames {
+		if t.Style&f.Flag != 0 {
+			styleFlags = append(styleFlags, f.Name)
+		}
+	}
+	if len(styleFlags) > 0 {
+		fmt.Fprintf(&b, " | Flags: %s", strings.Join(styleFlags, ", "))
+	}
+	if t.Style&ContractFlagThresholdTokens != 0 {
+		fmt.Fprintf(&b, " (%d/%d @ %d TE)", t.ThresholdTokensX, t.ThresholdTokensY, t.ThresholdTokensA)
+	}
+	if t.BoostingSinkUserID != "" {
+		fmt.Fprintf(&b, " | Sink: <@%s>", t.BoostingSinkUserID)
+	}
+	if t.PostSinkUserID != "" && t.PostSinkUserID != t.BoostingSinkUserID {
+		fmt.Fprintf(&b, " | Post sink: <@%s>", t.PostSinkUserID)
+	}
+	return b.String()
+}
+
+// loadContractTemplate reads a named template for the guild
+func loadContractTemplate(guildID, name string) (*contractTemplate, error) {
+	record, err := guildstate.GetContractTemplate(guildID, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no contract template named %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var t contractTemplate
+	if err := json.Unmarshal([]byte(record.Value), &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// contractTemplateExists reports whether the guild already has a template with this name
+func contractTemplateExists(guildID, name string) bool {
+	_, err := guildstate.GetContractTemplate(guildID, name)
+	return err == nil
+}
+
+// saveContractTemplate stores the settings of a contract under a name
+func saveContractTemplate(guildID, name, userID string, contract *Contract) error {
+	data, err := json.Marshal(newContractTemplate(contract))
+	if err != nil {
+		return err
+	}
+	return guildstate.SaveContractTemplate(guildID, name, string(data), userID)
+}
+
+// GetSlashContractTemplateCommand returns the /contract-template command
+func GetSlashContractTemplateCommand(cmd string) *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd,
+		Description: "Save and reuse contract settings for /contract.",
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+		},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "save",
+				Description: "Save the settings of the contract in this channel as a template",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "name",
+						Description:  "Template name, an existing template is replaced",
+						Required:     true,
+						Autocomplete: true,
+						MaxLength:    32,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List the contract templates of this server",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Delete a contract template",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "name",
+						Description:  "Template name",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+// HandleContractTemplateCommand handles the /contract-template subcommands
+func HandleContractTemplateCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := getInteractionUserID(i)
+	flags := discordgo.MessageFlagsEphemeral
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Processing request...",
+			Flags:   flags,
+		},
+	})
+
+	data := i.ApplicationCommandData()
+	optionMap := bottools.GetCommandOptionsMap(i)
+	name := ""
+	if opt, ok := optionMap[data.Options[0].Name+"-name"]; ok {
+		name = opt.StringValue()
+	}
+
+	var str string
+	if data.Options[0].Name != "list" {
+		normalized, err := guildstate.NormalizeContractTemplateName(name)
+		if err != nil {
+			_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+				Content: "Invalid template name: " + err.Error(),
+				Flags:   flags,
+			})
+			return
+		}
+		name = normalized
+	}
+
+	switch data.Options[0].Name {
+	case "save":
+		contract := FindContract(i.ChannelID)
+		switch {
+		case contract == nil:
+			str = "Run this in the channel of a contract whose settings you want to save."
+		case !creatorOfContract(s, contract, userID):
+			str = errorNotContractCreator
+		case contractTemplateExists(i.GuildID, name) && !guildstate.IsGuildCoordinator(i.GuildID, userID) && !isAdminCommandCaller(s, i):
+			str = fmt.Sprintf("Only coordinators can replace the existing contract template **%s**.", name)
+		default:
+			if err := saveContractTemplate(i.GuildID, name, userID, contract); err != nil {
+				str = "Unable to save the template: " + err.Error()
+			} else {
+				str = fmt.Sprintf("Saved contract template **%s**. Use it with `/contract template:%s`.", name, name)
+			}
+		}
+	case "list":
+		str = getContractTemplateList(i.GuildID)
+	case "delete":
+		if !guildstate.IsGuildCoordinator(i.GuildID, userID) && !isAdminCommandCaller(s, i) {
+			str = "Only coordinators can delete contract templates."
+			break
+		}
+		found, err := guildstate.DeleteContractTemplate(i.GuildID, name)
+		switch {
+		case err != nil:
+			str = "Unable to delete the template: " + err.Error()
+		case !found:
+			str = fmt.Sprintf("No contract template named **%s**.", name)
+		default:
+			str = fmt.Sprintf("Deleted contract template **%s**.", name)
+		}
+	}
+
+	_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: str,
+		Flags:   flags,
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+}
+
+func getContractTemplateList(guildID string) string {
+	records, err := guildstate.GetContractTemplates(guildID)
+	if err != nil {
+		log.Println("GetContractTemplates:", err)
+		return "Unable to read the contract templates."
+	}
+	if len(records) == 0 {
+		return "No contract templates saved. Use `/contract-template save` in a contract channel."
+	}
+
+	var b strings.Builder
+	for _, r := range records {
+		var t contractTemplate
+		if err := json.Unmarshal([]byte(r.Value), &t); err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "**%s** - %s\n", r.Name, t.String())
+	}
+	return b.String()
+}
+
+// handleContractTemplateAutoComplete offers the guild's template names
+func handleContractTemplateAutoComplete(s *discordgo.Session, i *discordgo.InteractionCreate, search string) {
+	search = strings.ToLower(strings.TrimSpace(search))
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
+	records, err := guildstate.GetContractTemplates(i.GuildID)
+	if err != nil {
+		log.Println("GetContractTemplates:", err)
+	}
+	for _, r := range records {
+		if search != "" && !strings.Contains(r.Name, search) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  r.Name,
+			Value: r.Name,
+		})
+	}
+	choices = limitContractChoices(choices, maxAutocompleteChoices)
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Contract Template",
+			Choices: choices,
+		}})
+}
+
+// HandleContractTemplateAutoComplete handles autocomplete for /contract-template
+func HandleContractTemplateAutoComplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	for _, opt := range bottools.GetCommandOptionsMap(i) {
+		if opt.Focused {
+			handleContractTemplateAutoComplete(s, i, opt.StringValue())
+			return
+		}
+	}
+}
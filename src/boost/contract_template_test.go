@@ -0,0 +1,49 @@
+package boost
+
+import "testing"
+
+func TestContractTemplateRoundTrip(t *testing.T) {
+	source := &Contract{
+		ContractID:       "template-test-unknown",
+		CoopSize:         7,
+		PlayStyle:        ContractPlaystyleLeaderboard,
+		BoostOrder:       ContractOrderTE,
+		Style:            ContractFlagBanker | ContractFlagThresholdTokens | ContractFlagCrt,
+		ThresholdTokensX: 3,
+		ThresholdTokensY: 6,
+		ThresholdTokensA: 80,
+		Banker: BankerInfo{
+			BoostingSinkUserID: "sink",
+			PostSinkUserID:     "absent",
+			SinkBoostPosition:  SinkBoostFollowOrder,
+		},
+	}
+
+	tpl := newContractTemplate(source)
+	if tpl.CoopSize != 7 {
+		t.Errorf("coop size for a contract without EI data = %d, want 7", tpl.CoopSize)
+	}
+	if tpl.Style&ContractFlagCrt != 0 {
+		t.Error("template kept a flag outside the template mask")
+	}
+
+	target := &Contract{
+		Style:    ContractStyleFastrun | ContractFlag6Tokens | ContractFlagSelfRuns,
+		Order:    []string{"sink"},
+		Boosters: map[string]*Booster{"sink": {UserID: "sink"}},
+	}
+	tpl.apply(target)
+
+	if target.Style != ContractFlagBanker|ContractFlagThresholdTokens|ContractFlagSelfRuns {
+		t.Errorf("applied style = %#x", target.Style)
+	}
+	if target.ThresholdTokensX != 3 || target.ThresholdTokensY != 6 || target.ThresholdTokensA != 80 {
+		t.Errorf("thresholds not applied: %d/%d/%d", target.ThresholdTokensX, target.ThresholdTokensY, target.ThresholdTokensA)
+	}
+	if target.Banker.SinkBoostPosition != SinkBoostFollowOrder || target.Banker.BoostingSinkUserID != "sink" {
+		t.Errorf("sink settings not applied: %+v", target.Banker)
+	}
+	if target.Banker.PostSinkUserID != "" {
+		t.Errorf("post sink %q assigned although not in the contract", target.Banker.PostSinkUserID)
+	}
+}
@@ -0,0 +1,439 @@
+package boost
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+)
+
+// Metrics a coop group can be balanced by
+const (
+	coopGroupBalanceTE       = "te"
+	coopGroupBalanceIHR      = "ihr"
+	coopGroupBalanceELR      = "elr"
+	coopGroupBalanceDuration = "duration" // minimize the spread of predicted completion times
+)
+
+// coopGroupPlaceholder is replaced by the coop number in the coop ID pattern
+const coopGroupPlaceholder = "{n}"
+
+// coopGroupUnit is a farmer together with their alts, they always share a coop
+type coopGroupUnit struct {
+	userIDs []string
+	metric  float64
+}
+
+// coopGroupMetric returns the balancing value of a booster
+func coopGroupMetric(b *Booster, balance string) float64 {
+	if b == nil {
+		return 0
+	}
+	switch balance {
+	case coopGroupBalanceIHR:
+		return b.IHRRate
+	case coopGroupBalanceELR:
+		return b.ArtifactSet.LayRate
+	default:
+		return float64(max(b.TECount, 0))
+	}
+}
+
+// getCoopGroupUnits collects the farmers of a signup, keeping alts with their
+// controller. Waitlisted farmers are included after the boost list.
+func getCoopGroupUnits(contract *Contract, balance string) []coopGroupUnit {
+	var units []coopGroupUnit
+	seen := make(map[string]bool)
+	for _, userID := range contract.Order {
+		b := contract.Boosters[userID]
+		if b == nil || seen[userID] {
+			continue
+		}
+		if b.AltController != "" && contract.Boosters[b.AltController] != nil {
+			// Added with the controller
+			continue
+		}
+		unit := coopGroupUnit{userIDs: []string{userID}, metric: coopGroupMetric(b, balance)}
+		seen[userID] = true
+		for _, altID := range b.Alts {
+			if contract.Boosters[altID] == nil || seen[altID] {
+				continue
+			}
+			unit.userIDs = append(unit.userIDs, altID)
+			unit.metric += coopGroupMetric(contract.Boosters[altID], balance)
+			seen[altID] = true
+		}
+		units = append(units, unit)
+	}
+	for _, userID := range contract.WaitlistBoosters {
+		if !seen[userID] {
+			units = append(units, coopGroupUnit{userIDs: []string{userID}})
+			seen[userID] = true
+		}
+	}
+	return units
+}
+
+// splitCoopGroup spreads the units over the coops so that each coop has a
+// similar number of farmers and a similar total metric. The strongest units are
+// placed first, each into the weakest coop that still has room.
+func splitCoopGroup(units []coopGroupUnit, coops int) [][]string {
+	if coops <= 0 {
+		return nil
+	}
+	total := 0
+	for _, u := range units {
+		total += len(u.userIDs)
+	}
+	capacity := (total + coops - 1) / coops
+
+	pending := slices.Clone(units)
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].metric > pending[j].metric
+	})
+
+	groups := make([][]string, coops)
+	sums := make([]float64, coops)
+	for len(pending) > 0 {
+		u := pending[0]
+		pending = pending[1:]
+
+		best := -1
+		for i := range groups {
+			if len(groups[i])+len(u.userIDs) > capacity {
+				continue
+			}
+			if best == -1 || sums[i] < sums[best] || (sums[i] == sums[best] && len(groups[i]) < len(groups[best])) {
+				best = i
+			}
+		}
+		if best == -1 {
+			// No coop can take the whole unit, place its farmers individually
+			for _, userID := range u.userIDs {
+				pending = append(pending, coopGroupUnit{userIDs: []string{userID}, metric: u.metric / float64(len(u.userIDs))})
+			}
+			continue
+		}
+		groups[best] = append(groups[best], u.userIDs...)
+		sums[best] += u.metric
+	}
+	return groups
+}
+
+// coopGroupCoopID builds the coop ID for the nth coop of a group
+func coopGroupCoopID(pattern string, n int) string {
+	if strings.Contains(pattern, coopGroupPlaceholder) {
+		return strings.ReplaceAll(pattern, coopGroupPlaceholder, strconv.Itoa(n))
+	}
+	return pattern + strconv.Itoa(n)
+}
+
+// GetSlashCoopGroupCommand returns the /coop-group command
+func GetSlashCoopGroupCommand(cmd string) *discordgo.ApplicationCommand {
+	minCoops := float64(2)
+	return &discordgo.ApplicationCommand{
+		Name:        cmd,
+		Description: "Split the signup in this channel into several balanced coops.",
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+		},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "coop-id",
+				Description: "Coop ID pattern, {n} is replaced by the coop number. Default is the current coop ID.",
+				Required:    false,
+				MaxLength:   40,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "balance",
+				Description: "Balance the coops by, default is TE",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Truth Eggs", Value: coopGroupBalanceTE},
+					{Name: "Boosting IHR", Value: coopGroupBalanceIHR},
+					{Name: "ELR", Value: coopGroupBalanceELR},
+					{Name: "Predicted duration (contract archives)", Value: coopGroupBalanceDuration},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "coops",
+				Description: "Number of coops, default is as few as the coop size allows",
+				Required:    false,
+				MinValue:    &minCoops,
+				MaxValue:    20,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "preview",
+				Description: "Show the assignment with a button to create the coops",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// HandleCoopGroupCommand fans a signup out into child contracts, each with its own thread
+func HandleCoopGroupCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	flags := discordgo.MessageFlagsEphemeral
+	bottools.AcknowledgeResponse(s, i, flags)
+
+	optionMap := bottools.GetCommandOptionsMap(i)
+	pattern := ""
+	if opt, ok := optionMap["coop-id"]; ok {
+		pattern = strings.ReplaceAll(opt.StringValue(), " ", "")
+	}
+	balance := coopGroupBalanceTE
+	if opt, ok := optionMap["balance"]; ok {
+		balance = opt.StringValue()
+	}
+	coops := 0
+	if opt, ok := optionMap["coops"]; ok {
+		coops = int(opt.IntValue())
+	}
+
+	preview := false
+	if opt, ok := optionMap["preview"]; ok {
+		preview = opt.BoolValue()
+	}
+
+	parent, err := checkCoopGroupParent(s, i.ChannelID, getInteractionUserID(i))
+	var plan *coopGroupPlan
+	if err == nil {
+		plan, err = planCoopGroup(s, parent, pattern, balance, coops)
+	}
+	if err != nil {
+		_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: err.Error(),
+			Flags:   flags,
+		})
+		return
+	}
+
+	if preview {
+		sendCoopGroupPreview(s, i, parent, plan)
+		return
+	}
+	_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: createCoopGroup(s, i.GuildID, parent, plan),
+		Flags:   flags,
+	})
+}
+
+// coopGroupPlan is the machine readable assignment of farmers to child coops
+type coopGroupPlan struct {
+	ContractHash string          `json:"contract_hash"`
+	ContractID   string          `json:"contract_id"`
+	Balance      string          `json:"balance"`
+	Coops        []coopGroupCoop `json:"coops"`
+}
+
+// coopGroupCoop is one child coop of a plan
+type coopGroupCoop struct {
+	CoopID           string   `json:"coop_id"`
+	Farmers          []string `json:"farmers"`
+	PredictedSeconds int64    `json:"predicted_seconds,omitempty"`
+}
+
+// checkCoopGroupParent verifies the caller can split the signup in the channel
+func checkCoopGroupParent(s *discordgo.Session, channelID string, userID string) (*Contract, error) {
+	parent := FindContract(channelID)
+	if parent == nil {
+		return nil, errors.New(errorNoContract)
+	}
+	if !creatorOfContract(s, parent, userID) {
+		return nil, errors.New(errorNotContractCreator)
+	}
+	if parent.State != ContractStateSignup {
+		return nil, errors.New("only contracts still in signup can be split into coops")
+	}
+	if parent.PredictionSignup {
+		return nil, errors.New("wait for the contract to be released before splitting the signup")
+	}
+	if parent.CoopSize <= 0 {
+		return nil, errors.New("the coop size of this contract is unknown")
+	}
+	return parent, nil
+}
+
+// planCoopGroup assigns the farmers of a signup to child coops
+func planCoopGroup(s *discordgo.Session, parent *Contract, pattern string, balance string, coops int) (*coopGroupPlan, error) {
+	if pattern == "" {
+		pattern = parent.CoopID
+	}
+
+	units := getCoopGroupUnits(parent, balance)
+	farmers := 0
+	for _, u := range units {
+		farmers += len(u.userIDs)
+	}
+	if coops == 0 {
+		coops = (farmers + parent.CoopSize - 1) / parent.CoopSize
+	}
+	if coops < 2 {
+		return nil, fmt.Errorf("%d farmers fit into a single coop of %d", farmers, parent.CoopSize)
+	}
+	if (farmers+coops-1)/coops > parent.CoopSize {
+		return nil, fmt.Errorf("%d farmers don't fit into %d coops of %d", farmers, coops, parent.CoopSize)
+	}
+
+	var groups [][]string
+	var predicted []time.Duration
+	if balance == coopGroupBalanceDuration {
+		var err error
+		groups, predicted, err = solveCoopGroupDurations(s, parent, units, coops)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		groups = splitCoopGroup(units, coops)
+	}
+
+	plan := &coopGroupPlan{
+		ContractHash: parent.ContractHash,
+		ContractID:   parent.ContractID,
+		Balance:      balance,
+	}
+	for n, group := range groups {
+		coop := coopGroupCoop{CoopID: coopGroupCoopID(pattern, n+1), Farmers: group}
+		if n < len(predicted) {
+			coop.PredictedSeconds = int64(predicted[n].Seconds())
+		}
+		plan.Coops = append(plan.Coops, coop)
+	}
+	return plan, nil
+}
+
+// createCoopGroup creates the child contracts of a plan and recycles the signup
+func createCoopGroup(s *discordgo.Session, guildID string, parent *Contract, plan *coopGroupPlan) string {
+	parentChannelID := parent.Location[0].ChannelID
+	// Threads are created next to the signup thread
+	channelID := parentChannelID
+	if ch, err := s.Channel(parentChannelID); err == nil && ch.IsThread() {
+		channelID = ch.ParentID
+	}
+
+	tmpl := newContractTemplate(parent)
+	tokensWanted := make(map[string]int)
+	tokensManual := make(map[string]bool)
+	for userID, b := range parent.Boosters {
+		tokensWanted[userID] = b.TokensWanted
+		tokensManual[userID] = b.TokensWantedManual
+	}
+	contractID := parent.ContractID
+	coordinatorID := parent.CreatorID[0]
+	coordinators := slices.Clone(parent.CreatorID)
+	playStyle := parent.PlayStyle
+	coopSize := parent.CoopSize
+	boostOrder := parent.BoostOrder
+	plannedStartTime := parent.PlannedStartTime
+	validFrom := parent.ValidFrom
+
+	threadStyleIcons := []string{"", "🟦 ", "🟩 ", "🟧 ", "🟥 "}
+	icon := ""
+	if playStyle >= 0 && playStyle < len(threadStyleIcons) {
+		icon = threadStyleIcons[playStyle]
+	}
+
+	farmers := 0
+	for _, coop := range plan.Coops {
+		farmers += len(coop.Farmers)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Split %d farmers into %d coops by %s:\n", farmers, len(plan.Coops), strings.ToUpper(plan.Balance))
+	created := 0
+	for _, coop := range plan.Coops {
+		if len(coop.Farmers) == 0 {
+			continue
+		}
+		thread, err := s.ThreadStart(channelID, icon+coop.CoopID, discordgo.ChannelTypeGuildPublicThread, 60*24)
+		if err != nil {
+			log.Print(err)
+			fmt.Fprintf(&b, "**%s**: unable to create a thread\n", coop.CoopID)
+			continue
+		}
+
+		mutex.Lock()
+		child, err := CreateContract(s, contractID, coop.CoopID, playStyle, coopSize, boostOrder, guildID, thread.ID, coop.Farmers, coordinatorID, plannedStartTime, validFrom)
+		if err == nil {
+			// CreateContract would promote a farmer when the coordinator isn't in this coop
+			child.CreatorID = slices.Clone(coordinators)
+			tmpl.apply(child)
+			for userID, booster := range child.Boosters {
+				if tokens, ok := tokensWanted[userID]; ok {
+					booster.TokensWanted = tokens
+					booster.TokensWantedManual = tokensManual[userID]
+				}
+			}
+		}
+		mutex.Unlock()
+		if err != nil {
+			fmt.Fprintf(&b, "**%s**: %v\n", coop.CoopID, err)
+			continue
+		}
+		UpdateThreadName(s, child)
+		postContractMessages(s, child, thread.ID)
+		fmt.Fprintf(&b, "<#%s> %d farmers\n", thread.ID, len(coop.Farmers))
+		created++
+	}
+
+	if created == 0 {
+		return b.String()
+	}
+
+	// The farmers now live in the child contracts
+	for _, loc := range parent.Location {
+		_ = s.ChannelMessageUnpin(loc.ChannelID, loc.ReactionID)
+	}
+	if _, err := DeleteContract(s, guildID, parentChannelID); err != nil {
+		log.Print(err)
+	}
+	_, _ = s.ChannelMessageSend(parentChannelID, b.String())
+	return b.String()
+}
+
+// postContractMessages sends the boost list and signup messages of a new contract
+func postContractMessages(s *discordgo.Session, contract *Contract, channelID string) {
+	createMsg := DrawBoostList(s, contract)
+	buttonComponents := getContractReactionsComponents(contract)
+	if len(buttonComponents) > 0 {
+		createMsg = append(createMsg, buttonComponents...)
+	}
+	var listData discordgo.MessageSend
+	listData.Components = createMsg
+	listData.Flags = discordgo.MessageFlagsIsComponentsV2
+	msg, err := s.ChannelMessageSendComplex(channelID, &listData)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	SetListMessageID(contract, channelID, msg.ID)
+
+	contentStr, comp := GetSignupComponents(contract)
+	var components []discordgo.MessageComponent
+	components = append(components, &discordgo.TextDisplay{Content: contentStr})
+	components = append(components, comp...)
+	var signupData discordgo.MessageSend
+	signupData.Flags = discordgo.MessageFlagsIsComponentsV2
+	signupData.Components = components
+	reactionMsg, err := s.ChannelMessageSendComplex(channelID, &signupData)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	SetReactionID(contract, channelID, reactionMsg.ID)
+	_ = s.ChannelMessagePin(channelID, reactionMsg.ID)
+}
@@ -0,0 +1,451 @@
+package boost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+)
+
+// coopSolverMaxPasses bounds the swap search of the duration solver
+const coopSolverMaxPasses = 200
+
+// coopSolverArchiveContracts is how many archived contracts rate a farmer
+const coopSolverArchiveContracts = 20
+
+// Pending plans from /coop-group preview, by parent contract hash
+var (
+	coopGroupPlans      = make(map[string]*coopGroupPlan)
+	coopGroupPlansMutex sync.Mutex
+)
+
+// coopSolverUnit is a farmer with their alts and their summed strength
+type coopSolverUnit struct {
+	userIDs []string
+	te      float64 // sum of Truth Eggs
+	perf    float64 // sum of performance factors, 1.0 is an average farmer
+}
+
+// coopDurationFunc predicts a coop's completion time from its size and averages
+type coopDurationFunc func(size int, avgTE float64, avgPerf float64) time.Duration
+
+// archivePerformance rates a farmer by their average contribution ratio in
+// archived coop contracts, clamped to [0.5, 2]. Returns false without history.
+func archivePerformance(archive []*ei.LocalContract) (float64, bool) {
+	total := 0.0
+	count := 0
+	for _, lc := range archive {
+		ev := lc.GetEvaluation()
+		if ev == nil || ev.GetSolo() || ev.GetContributionRatio() <= 0 {
+			continue
+		}
+		total += ev.GetContributionRatio()
+		count++
+		if count == coopSolverArchiveContracts {
+			break
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return math.Min(math.Max(total/float64(count), 0.5), 2.0), true
+}
+
+// coopGroupDurations predicts the completion time of each group
+func coopGroupDurations(groups [][]coopSolverUnit, duration coopDurationFunc) []time.Duration {
+	durations := make([]time.Duration, len(groups))
+	for i, group := range groups {
+		size := 0
+		te, perf := 0.0, 0.0
+		for _, u := range group {
+			size += len(u.userIDs)
+			te += u.te
+			perf += u.perf
+		}
+		if size == 0 {
+			continue
+		}
+		durations[i] = duration(size, te/float64(size), perf/float64(size))
+	}
+	return durations
+}
+
+// durationVariance returns the variance of the durations in hours²
+func durationVariance(durations []time.Duration) float64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	mean := 0.0
+	for _, d := range durations {
+		mean += d.Hours()
+	}
+	mean /= float64(len(durations))
+	variance := 0.0
+	for _, d := range durations {
+		variance += (d.Hours() - mean) * (d.Hours() - mean)
+	}
+	return variance / float64(len(durations))
+}
+
+// solveCoopGroup partitions the units into coops minimizing the variance of the
+// predicted completion times. Coops start from a greedy split by strength and
+// then units of the same number of farmers are swapped between coops while
+// the variance drops.
+func solveCoopGroup(units []coopSolverUnit, coops int, duration coopDurationFunc) ([][]string, []time.Duration) {
+	if coops <= 0 {
+		return nil, nil
+	}
+	byUser := make(map[string]coopSolverUnit)
+	greedyUnits := make([]coopGroupUnit, 0, len(units))
+	for _, u := range units {
+		greedyUnits = append(greedyUnits, coopGroupUnit{userIDs: u.userIDs, metric: u.perf})
+		for _, userID := range u.userIDs {
+			byUser[userID] = u
+		}
+	}
+
+	// Rebuild the units inside each greedy group, a unit the greedy split broke
+	// up continues as single farmers
+	groups := make([][]coopSolverUnit, coops)
+	for g, userIDs := range splitCoopGroup(greedyUnits, coops) {
+		placed := make(map[string]bool)
+		for _, userID := range userIDs {
+			if placed[userID] {
+				continue
+			}
+			u := byUser[userID]
+			whole := true
+			for _, id := range u.userIDs {
+				whole = whole && slices.Contains(userIDs, id)
+			}
+			if whole {
+				groups[g] = append(groups[g], u)
+				for _, id := range u.userIDs {
+					placed[id] = true
+				}
+				continue
+			}
+			share := 1 / float64(len(u.userIDs))
+			groups[g] = append(groups[g], coopSolverUnit{userIDs: []string{userID}, te: u.te * share, perf: u.perf * share})
+			placed[userID] = true
+		}
+	}
+
+	best := durationVariance(coopGroupDurations(groups, duration))
+	for range coopSolverMaxPasses {
+		var bestGroups [][]coopSolverUnit
+		for a := range groups {
+			for b := range groups {
+				if a == b {
+					continue
+				}
+				for i, u := range groups[a] {
+					// Swap with an equally sized unit, or a pair with two single farmers
+					var candidates [][]int
+					for j, v := range groups[b] {
+						if a < b && len(v.userIDs) == len(u.userIDs) {
+							candidates = append(candidates, []int{j})
+						}
+						if len(u.userIDs) != 2 || len(v.userIDs) != 1 {
+							continue
+						}
+						for k := j + 1; k < len(groups[b]); k++ {
+							if len(groups[b][k].userIDs) == 1 {
+								candidates = append(candidates, []int{j, k})
+							}
+						}
+					}
+					for _, swap := range candidates {
+						trial := swapCoopSolverUnits(groups, a, i, b, swap)
+						if v := durationVariance(coopGroupDurations(trial, duration)); v < best-1e-9 {
+							best = v
+							bestGroups = trial
+						}
+					}
+				}
+			}
+		}
+		if bestGroups == nil {
+			break
+		}
+		groups = bestGroups
+	}
+
+	result := make([][]string, len(groups))
+	for g, group := range groups {
+		for _, u := range group {
+			result[g] = append(result[g], u.userIDs...)
+		}
+	}
+	return result, coopGroupDurations(groups, duration)
+}
+
+// swapCoopSolverUnits returns a copy of the groups with unit i of group a
+// exchanged for the listed units of group b
+func swapCoopSolverUnits(groups [][]coopSolverUnit, a int, i int, b int, others []int) [][]coopSolverUnit {
+	trial := make([][]coopSolverUnit, len(groups))
+	for g := range groups {
+		trial[g] = slices.Clone(groups[g])
+	}
+	moved := trial[a][i]
+	trial[a] = slices.Delete(trial[a], i, i+1)
+	for n := len(others) - 1; n >= 0; n-- {
+		j := others[n]
+		trial[a] = append(trial[a], groups[b][j])
+		trial[b] = slices.Delete(trial[b], j, j+1)
+	}
+	trial[b] = append(trial[b], moved)
+	return trial
+}
+
+// solveCoopGroupDurations rates the farmers of a signup from their contract
+// archives and splits them with solveCoopGroup.
+func solveCoopGroupDurations(s *discordgo.Session, parent *Contract, units []coopGroupUnit, coops int) ([][]string, []time.Duration, error) {
+	c, ok := ei.EggIncContractsAll[parent.ContractID]
+	if !ok || len(c.TargetAmount) == 0 || c.LengthInSeconds == 0 {
+		return nil, nil, errors.New("no contract data to predict completion times, pick another balance")
+	}
+
+	var userIDs []string
+	for _, u := range units {
+		userIDs = append(userIDs, u.userIDs...)
+	}
+	names := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		names[i] = farmerstate.GetMiscSettingString(userID, "ei_ign")
+	}
+	archives, fetched, _, err := GetContractArchivesForNames(s, names, "cxp_v0_2_0", false, true)
+	if err != nil {
+		log.Println("GetContractArchivesForNames:", err)
+	}
+	perf := make(map[string]float64)
+	for i, userID := range userIDs {
+		perf[userID] = 1.0
+		if err == nil && fetched[i] {
+			if p, ok := archivePerformance(archives[i]); ok {
+				perf[userID] = p
+			}
+		}
+	}
+
+	solverUnits := make([]coopSolverUnit, 0, len(units))
+	for _, u := range units {
+		su := coopSolverUnit{userIDs: u.userIDs}
+		for _, userID := range u.userIDs {
+			if b := parent.Boosters[userID]; b != nil {
+				su.te += float64(max(b.TECount, 0))
+			}
+			su.perf += perf[userID]
+		}
+		solverUnits = append(solverUnits, su)
+	}
+
+	// The leggy estimate is the only one that uses TE, a stronger or weaker
+	// history scales it from there
+	type estimateKey struct{ size, te int }
+	estimates := make(map[estimateKey]time.Duration)
+	duration := func(size int, avgTE float64, avgPerf float64) time.Duration {
+		key := estimateKey{size, int(math.Round(avgTE))}
+		base, ok := estimates[key]
+		if !ok {
+			est := getContractDurationEstimate(c, c.TargetAmount[len(c.TargetAmount)-1], float64(size), c.LengthInSeconds,
+				c.ModifierSR, c.ModifierELR, c.ModifierHabCap, false, float64(key.te))
+			base = est.Max
+			estimates[key] = base
+		}
+		return time.Duration(float64(base) / avgPerf)
+	}
+
+	groups, durations := solveCoopGroup(solverUnits, coops, duration)
+	return groups, durations, nil
+}
+
+// reconcileCoopGroupPlan updates a previewed plan with the current signup.
+// Farmers who left are dropped and new farmers join the smallest coop with
+// room for them, failing when none has.
+func reconcileCoopGroupPlan(plan *coopGroupPlan, parent *Contract) error {
+	current := make(map[string]bool)
+	for _, u := range getCoopGroupUnits(parent, coopGroupBalanceTE) {
+		for _, userID := range u.userIDs {
+			current[userID] = true
+		}
+	}
+	planned := make(map[string]bool)
+	for n := range plan.Coops {
+		plan.Coops[n].Farmers = slices.DeleteFunc(plan.Coops[n].Farmers, func(userID string) bool {
+			return !current[userID]
+		})
+		for _, userID := range plan.Coops[n].Farmers {
+			planned[userID] = true
+		}
+	}
+	for _, u := range getCoopGroupUnits(parent, coopGroupBalanceTE) {
+		var missing []string
+		for _, userID := range u.userIDs {
+			if !planned[userID] {
+				missing = append(missing, userID)
+			}
+		}
+		if len(missing) == 0 || len(plan.Coops) == 0 {
+			continue
+		}
+		smallest := -1
+		for n := range plan.Coops {
+			size := len(plan.Coops[n].Farmers)
+			if parent.CoopSize > 0 && size+len(missing) > parent.CoopSize {
+				continue
+			}
+			if smallest < 0 || size < len(plan.Coops[smallest].Farmers) {
+				smallest = n
+			}
+		}
+		if smallest < 0 {
+			return fmt.Errorf("the coops of this plan are full, run `/coop-group` again to place %d new farmers", len(missing))
+		}
+		plan.Coops[smallest].Farmers = append(plan.Coops[smallest].Farmers, missing...)
+	}
+	return nil
+}
+
+// discardCoopGroupPlan forgets the previewed plan of a contract
+func discardCoopGroupPlan(contractHash string) {
+	coopGroupPlansMutex.Lock()
+	delete(coopGroupPlans, contractHash)
+	coopGroupPlansMutex.Unlock()
+}
+
+// sendCoopGroupPreview shows a plan with its JSON and buttons to accept or discard it
+func sendCoopGroupPreview(s *discordgo.Session, i *discordgo.InteractionCreate, parent *Contract, plan *coopGroupPlan) {
+	coopGroupPlansMutex.Lock()
+	coopGroupPlans[plan.ContractHash] = plan
+	coopGroupPlansMutex.Unlock()
+
+	var fields []*discordgo.MessageEmbedField
+	var durations []time.Duration
+	for _, coop := range plan.Coops {
+		mentions := make([]string, 0, len(coop.Farmers))
+		for _, userID := range coop.Farmers {
+			if b := parent.Boosters[userID]; b != nil && b.Mention != "" {
+				mentions = append(mentions, b.Mention)
+			} else {
+				mentions = append(mentions, "<@"+userID+">")
+			}
+		}
+		value := strings.Join(mentions, " ")
+		if len(value) > 1024 {
+			value = value[:1020] + "..."
+		}
+		name := fmt.Sprintf("%s (%d farmers)", coop.CoopID, len(coop.Farmers))
+		if coop.PredictedSeconds > 0 {
+			d := time.Duration(coop.PredictedSeconds) * time.Second
+			durations = append(durations, d)
+			name += " ~" + bottools.FmtDuration(d)
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: name, Value: value})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Coop plan for %s", plan.ContractID),
+		Description: fmt.Sprintf("Balanced by %s. Create the coops to move the farmers into their threads.", strings.ToUpper(plan.Balance)),
+		Fields:      fields,
+	}
+	if len(durations) > 1 {
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Predicted spread %s, std dev %s",
+				bottools.FmtDuration(slices.Max(durations)-slices.Min(durations)),
+				bottools.FmtDuration(time.Duration(math.Sqrt(durationVariance(durations))*float64(time.Hour)))),
+		}
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		log.Println("coop plan:", err)
+	}
+	_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Flags:  discordgo.MessageFlagsEphemeral,
+		Files: []*discordgo.File{
+			{Name: "coop-plan.json", ContentType: "application/json", Reader: bytes.NewReader(data)},
+		},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Create coops",
+						Style:    discordgo.SuccessButton,
+						CustomID: "coop_plan#accept#" + plan.ContractHash,
+					},
+					discordgo.Button{
+						Label:    "Discard",
+						Style:    discordgo.SecondaryButton,
+						CustomID: "coop_plan#cancel#" + plan.ContractHash,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.Println("coop plan preview:", err)
+	}
+}
+
+// HandleCoopGroupPlanButtons accepts or discards a previewed coop plan
+func HandleCoopGroupPlanButtons(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// coop_plan#accept#<contract hash>
+	parts := strings.Split(i.MessageComponentData().CustomID, "#")
+	if len(parts) != 3 {
+		return
+	}
+	action, hash := parts[1], parts[2]
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+
+	coopGroupPlansMutex.Lock()
+	plan := coopGroupPlans[hash]
+	delete(coopGroupPlans, hash)
+	coopGroupPlansMutex.Unlock()
+
+	str := "Coop plan discarded."
+	if action == "accept" {
+		ContractsMutex.RLock()
+		parent := Contracts[hash]
+		ContractsMutex.RUnlock()
+		switch {
+		case plan == nil:
+			str = "This coop plan has expired, run `/coop-group` again."
+		case parent == nil:
+			str = errorNoContract
+		default:
+			if _, err := checkCoopGroupParent(s, parent.Location[0].ChannelID, getInteractionUserID(i)); err != nil {
+				str = err.Error()
+				break
+			}
+			if err := reconcileCoopGroupPlan(plan, parent); err != nil {
+				str = err.Error()
+				break
+			}
+			str = createCoopGroup(s, i.GuildID, parent, plan)
+		}
+	}
+
+	_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: str,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+}
@@ -0,0 +1,146 @@
+package boost
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSplitCoopGroupBalances(t *testing.T) {
+	contract := &Contract{
+		Order: []string{"a", "b", "c", "d", "e", "f", "g"},
+		Boosters: map[string]*Booster{
+			"a": {UserID: "a", TECount: 100, Alts: []string{"b"}},
+			"b": {UserID: "b", TECount: 10, AltController: "a"},
+			"c": {UserID: "c", TECount: 90},
+			"d": {UserID: "d", TECount: 50},
+			"e": {UserID: "e", TECount: 40},
+			"f": {UserID: "f", TECount: 5},
+			"g": {UserID: "g", TECount: -1},
+		},
+		WaitlistBoosters: []string{"h"},
+	}
+
+	units := getCoopGroupUnits(contract, coopGroupBalanceTE)
+	if len(units) != 7 {
+		t.Fatalf("units = %d, want 7", len(units))
+	}
+	if !slices.Equal(units[0].userIDs, []string{"a", "b"}) || units[0].metric != 110 {
+		t.Errorf("alt not grouped with controller: %+v", units[0])
+	}
+
+	groups := splitCoopGroup(units, 2)
+	if len(groups) != 2 || len(groups[0]) != 4 || len(groups[1]) != 4 {
+		t.Fatalf("groups = %v, want two coops of 4", groups)
+	}
+	sum := func(group []string) int {
+		total := 0
+		for _, id := range group {
+			if b := contract.Boosters[id]; b != nil {
+				total += max(b.TECount, 0)
+			}
+		}
+		return total
+	}
+	for _, g := range groups {
+		if slices.Contains(g, "a") != slices.Contains(g, "b") {
+			t.Errorf("alt split from controller: %v", groups)
+		}
+	}
+	if diff := sum(groups[0]) - sum(groups[1]); diff > 20 || diff < -20 {
+		t.Errorf("unbalanced TE %d vs %d: %v", sum(groups[0]), sum(groups[1]), groups)
+	}
+}
+
+func TestCoopGroupCoopID(t *testing.T) {
+	if got := coopGroupCoopID("team-{n}-x", 3); got != "team-3-x" {
+		t.Errorf("got %q", got)
+	}
+	if got := coopGroupCoopID("abc", 2); got != "abc2" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSolveCoopGroupEvensDurations(t *testing.T) {
+	// Completion time only depends on the average strength of the coop
+	duration := func(size int, avgTE float64, avgPerf float64) time.Duration {
+		return time.Duration(float64(10*time.Hour) / avgPerf)
+	}
+	units := []coopSolverUnit{
+		{userIDs: []string{"a"}, perf: 2.0},
+		{userIDs: []string{"b"}, perf: 1.9},
+		{userIDs: []string{"c"}, perf: 1.0},
+		{userIDs: []string{"d"}, perf: 1.0},
+		{userIDs: []string{"e", "e-alt"}, perf: 1.2},
+		{userIDs: []string{"f", "f-alt"}, perf: 2.4},
+		{userIDs: []string{"g"}, perf: 0.5},
+		{userIDs: []string{"h"}, perf: 0.6},
+	}
+
+	groups, durations := solveCoopGroup(units, 2, duration)
+	if len(groups) != 2 || len(groups[0]) != 5 || len(groups[1]) != 5 {
+		t.Fatalf("groups = %v, want two coops of 5", groups)
+	}
+	for _, g := range groups {
+		if slices.Contains(g, "e") != slices.Contains(g, "e-alt") || slices.Contains(g, "f") != slices.Contains(g, "f-alt") {
+			t.Errorf("alt split from controller: %v", groups)
+		}
+	}
+	if spread := (durations[0] - durations[1]).Abs(); spread > 30*time.Minute {
+		t.Errorf("predicted spread %s too large: %v %v", spread, groups, durations)
+	}
+}
+
+func TestReconcileCoopGroupPlan(t *testing.T) {
+	parent := &Contract{
+		Order: []string{"a", "c", "d"},
+		Boosters: map[string]*Booster{
+			"a": {UserID: "a"},
+			"c": {UserID: "c"},
+			"d": {UserID: "d"},
+		},
+	}
+	plan := &coopGroupPlan{Coops: []coopGroupCoop{
+		{CoopID: "x1", Farmers: []string{"a", "b"}},
+		{CoopID: "x2", Farmers: []string{"c"}},
+	}}
+	if err := reconcileCoopGroupPlan(plan, parent); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if slices.Contains(plan.Coops[0].Farmers, "b") {
+		t.Errorf("departed farmer kept: %v", plan.Coops[0].Farmers)
+	}
+	if !slices.Contains(plan.Coops[0].Farmers, "d") && !slices.Contains(plan.Coops[1].Farmers, "d") {
+		t.Errorf("new farmer not placed: %+v", plan.Coops)
+	}
+}
+
+func TestReconcileCoopGroupPlanCoopSize(t *testing.T) {
+	parent := &Contract{
+		CoopSize: 2,
+		Order:    []string{"a", "b", "c", "d"},
+		Boosters: map[string]*Booster{
+			"a": {UserID: "a"},
+			"b": {UserID: "b"},
+			"c": {UserID: "c"},
+			"d": {UserID: "d"},
+		},
+	}
+	plan := &coopGroupPlan{Coops: []coopGroupCoop{
+		{CoopID: "x1", Farmers: []string{"a", "b"}},
+		{CoopID: "x2", Farmers: []string{"c"}},
+	}}
+	// Only the second coop has room for d
+	if err := reconcileCoopGroupPlan(plan, parent); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if !slices.Equal(plan.Coops[1].Farmers, []string{"c", "d"}) {
+		t.Errorf("new farmer not sent to the coop with room: %+v", plan.Coops)
+	}
+
+	parent.Order = append(parent.Order, "e")
+	parent.Boosters["e"] = &Booster{UserID: "e"}
+	if err := reconcileCoopGroupPlan(plan, parent); err == nil {
+		t.Errorf("expected full coops to be rejected: %+v", plan.Coops)
+	}
+}
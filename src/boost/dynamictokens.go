@@ -1,8 +1,10 @@
 package boost
 
 import (
-	"log"
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
@@ -28,31 +30,104 @@ type DynamicTokenData struct {
 	ColleggtibleIHR       float64
 }
 
-// Returns an slice of integers for all contract players with needed token counts
-func calculateDynamicTokens(dt *DynamicTokenData, count int, tpm float64, heldTokens int) []int {
-	var retSlice []int
-
-	log.Print("Calculating dynamic tokens for ", dt.Name, " for ", count, " players")
+const (
+	dynamicTokenMin            = 4   // Fewest tokens a dynamic boost is planned with
+	dynamicTokenMax            = 12  // Most tokens a dynamic boost is planned with
+	dynamicTokenDefaultHorizon = 120 // Minutes to plan for when the contract end isn't known
+	dynamicTokenReplanMinutes  = 15  // Horizon change that triggers a new plan
+	boostMonocleMultiplier     = 1.3 // T4L Monocle assumed by the estimates, only for boosts
+)
 
-	log.Print("TPM: ", tpm)
-	log.Print("Held Tokens: ", heldTokens)
+// dynamicTokenFarmer is a farmer who hasn't boosted yet, in boost order
+type dynamicTokenFarmer struct {
+	Data     *DynamicTokenData
+	ELR      float64 // Relative egg laying rate once boosted, 0 counts as 1
+	Received int     // Tokens already received toward the boost
+	Fixed    int     // Tokens the farmer asked for themselves, 0 to plan them
+}
 
-	// Want the highest eggs at 120 minutes
-	//eggsAt120 := boostedELR * (120 - timeToFinishBoostInMin) / 60
+// dynamicTokenEggs estimates the eggs a farmer delivers before the horizon when
+// they start boosting at start minutes with tokens. Chickens fill the habs
+// linearly over the boost time from getBoostTimeSeconds, then lay at full rate.
+func dynamicTokenEggs(f dynamicTokenFarmer, tokens int, start float64, horizon float64) float64 {
+	elr := f.ELR
+	if elr <= 0 {
+		elr = 1
+	}
+	boost, _ := getBoostTimeSeconds(f.Data, tokens)
+	fill := boost.Minutes()
+	remaining := horizon - start
+	if remaining <= 0 || fill <= 0 {
+		return 0
+	}
+	if remaining < fill {
+		return elr * remaining * remaining / (2 * fill)
+	}
+	return elr * (remaining - fill/2)
+}
 
-	// Data needed...
-	// Time of start of contract
-	// For Boosted - time of player boosts and tokens spent.
-	// For Unboosted -  zero boost time and count of tokens on hand
+// calculateDynamicTokens returns the token count for each farmer still to
+// boost that maximizes coop eggs delivered by the horizon (minutes from now).
+// Tokens arrive at tpm per minute on top of heldTokens, so every extra token
+// a farmer takes delays everyone after them.
+func calculateDynamicTokens(farmers []dynamicTokenFarmer, tpm float64, heldTokens int, horizon float64) []int {
+	n := len(farmers)
+	if n == 0 {
+		return nil
+	}
 
-	// 1. Get fixed values from those that already boosted
-	// 2. Calculate the estimates using mix of token boosts
+	// startAt is when the pool has delivered spent tokens
+	startAt := func(spent int) float64 {
+		needed := spent - heldTokens
+		if needed <= 0 {
+			return 0
+		}
+		if tpm <= 0 {
+			return math.Inf(1)
+		}
+		return float64(needed) / tpm
+	}
 
-	// Add 6, 6, 6, 8 to retSlice
-	retSlice = append(retSlice, 6, 6, 6, 8)
+	// best[k][c] is the most eggs farmers k.. can deliver once c pool tokens are spent
+	maxSpent := 1
+	for _, f := range farmers {
+		maxSpent += max(f.Fixed, dynamicTokenMax)
+	}
+	best := make([][]float64, n+1)
+	choice := make([][]int, n+1)
+	for k := range best {
+		best[k] = make([]float64, maxSpent)
+		choice[k] = make([]int, maxSpent)
+	}
+	for k := n - 1; k >= 0; k-- {
+		f := farmers[k]
+		low, high := max(dynamicTokenMin, min(f.Received, dynamicTokenMax)), dynamicTokenMax
+		if f.Fixed > 0 {
+			low, high = f.Fixed, f.Fixed
+		}
+		for c := range maxSpent {
+			best[k][c] = math.Inf(-1)
+			for tokens := low; tokens <= high; tokens++ {
+				spent := c + max(0, tokens-f.Received)
+				if spent >= maxSpent {
+					break
+				}
+				eggs := dynamicTokenEggs(f, tokens, startAt(spent), horizon) + best[k+1][spent]
+				if eggs > best[k][c] {
+					best[k][c] = eggs
+					choice[k][c] = tokens
+				}
+			}
+		}
+	}
 
-	// For every booster
-	return retSlice
+	tokens := make([]int, n)
+	spent := 0
+	for k := range farmers {
+		tokens[k] = choice[k][spent]
+		spent += max(0, tokens[k]-farmers[k].Received)
+	}
+	return tokens
 }
 
 // getBoostTimeSeconds returns the boost time as a time.Duration for a given number of tokens
@@ -61,7 +136,7 @@ func getBoostTimeSeconds(dt *DynamicTokenData, tokens int) (time.Duration, time.
 	// This protects the parameters of the next function call
 	if tokens < 0 {
 		tokens = 0
-	} else if tokens > len(dt.BoostTimeMinutes) {
+	} else if tokens >= len(dt.BoostTimeMinutes) {
 		tokens = len(dt.BoostTimeMinutes) - 1
 	}
 	return time.Duration(dt.BoostTimeMinutes[tokens] * float64(time.Minute)), time.Duration(dt.ChickenRunTimeMinutes[tokens] * float64(time.Minute))
@@ -87,7 +162,6 @@ func createDynamicTokenData(TE int64) *DynamicTokenData {
 	// IHR multiplier should NOT reapply colleggibles - those are already in IhrBase
 	chickenRunPercent := 0.70 // Chicken run is 70.0% of normal boost time
 	chaliceMultiplier := 1.4  // T4L Chalice
-	monocleMultiplier := 1.3  // T4L Monocle, only for boosts
 	ihrSlots := 9.0           // IHR stone slots
 	dt.IHRMultiplier = chaliceMultiplier * math.Pow(1.04, ihrSlots) * math.Pow(1.01, float64(dt.TE))
 	dt.MaxHab = 14_175_000_000.0 * colleggtibleHab
@@ -96,7 +170,7 @@ func createDynamicTokenData(TE int64) *DynamicTokenData {
 	// 14.825K/min/hab (×1.993)
 	for i := range len(dt.TokenBoost) {
 		mult := calcBoostMulti(float64(i))
-		dt.TokenBoost[i] = mult * monocleMultiplier
+		dt.TokenBoost[i] = mult * boostMonocleMultiplier
 		ihr := float64(dt.TokenBoost[i]) * dt.IHRMultiplier * float64(dt.FourHabsOffline) // per minute
 		// Minimum time is 1 minute due to away time calculation
 		dt.BoostTimeMinutes[i] = max(1.0, float64(dt.MaxHab)/ihr)
@@ -105,48 +179,120 @@ func createDynamicTokenData(TE int64) *DynamicTokenData {
 	return dt
 }
 
+// determineDynamicTokens sets TokensWanted for the farmers still to boost.
+// Farmers who picked their own count keep it, and the plan is only redone
+// when what it depends on changes.
 func determineDynamicTokens(c *Contract) {
 
 	if c == nil {
 		return
 	}
 
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	if c.Style&ContractFlagDynamicTokens == 0 {
 		return
 	}
-	// Determine the dynamic tokens
-	// For everyone in the contract
-	// 1. Determine boosted ELR
-	// 2. Determine start boost time ane end boost time based on tokens IHR
-	// 3. Determine how much fully boosted minutes at ELR (120 - minutes to boost)
-	dt := createDynamicTokenData(50)
 
-	// Initially assign 6 token boosts to everyone,
-	// In reverse order start calculating using 8 token boosts
-	// stop when the 120 minute delivered eggs amount is less than the previous amount
+	now := time.Now()
+	receivedByUser, sentByUser, _ := buildTokenTotalsFromLog(c)
+
+	// Token rate so far, falling back to the contract's minutes per token
+	tpm := 0.0
+	if !c.StartTime.IsZero() && now.Sub(c.StartTime) >= time.Minute {
+		total := 0
+		for _, entry := range c.TokenLog {
+			total += entry.Quantity
+		}
+		tpm = float64(total) / now.Sub(c.StartTime).Minutes()
+	}
+	if tpm == 0 && c.MinutesPerToken > 0 {
+		tpm = 1 / float64(c.MinutesPerToken)
+	}
+
+	// Tokens sitting with the banker are ready to hand out
+	heldTokens := 0
+	if c.Style&ContractFlagBanker != 0 && c.Banker.CurrentBanker != "" {
+		heldTokens = max(0, receivedByUser[c.Banker.CurrentBanker]-sentByUser[c.Banker.CurrentBanker])
+	}
 
-	tpm := float64(len(c.TokenLog)) / time.Since(c.StartTime).Minutes()
+	horizon := float64(dynamicTokenDefaultHorizon)
+	if c.EstimatedEndTime.After(now) {
+		horizon = c.EstimatedEndTime.Sub(now).Minutes()
+	} else if c.EstimatedDuration > 0 && !c.StartTime.IsZero() && c.StartTime.Add(c.EstimatedDuration).After(now) {
+		horizon = c.StartTime.Add(c.EstimatedDuration).Sub(now).Minutes()
+	}
 
-	calculateDynamicTokens(dt, 4, tpm, 0)
+	var userIDs []string
+	var farmers []dynamicTokenFarmer
+	var inputs strings.Builder
+	// The rate and horizon drift every call, so they only count once they move noticeably
+	fmt.Fprintf(&inputs, "%.2f %d %d", tpm, heldTokens, int(horizon/dynamicTokenReplanMinutes))
+	for _, userID := range c.Order {
+		b := c.Boosters[userID]
+		if b == nil || b.BoostState == BoostStateBoosted {
+			continue
+		}
+		f := dynamicTokenFarmer{
+			ELR:      b.ArtifactSet.LayRate,
+			Received: b.TokensReceived,
+		}
+		if b.TokensWantedManual {
+			f.Fixed = b.TokensWanted
+		}
+		fmt.Fprintf(&inputs, "|%s %d %g %d %d", userID, b.TECount, f.ELR, f.Received, f.Fixed)
+		userIDs = append(userIDs, userID)
+		farmers = append(farmers, f)
+	}
+	if inputs.String() == c.dynamicTokenInputs {
+		return
+	}
+	c.dynamicTokenInputs = inputs.String()
 
+	for i, userID := range userIDs {
+		farmers[i].Data = createDynamicTokenData(int64(c.Boosters[userID].TECount))
+	}
+	for i, tokens := range calculateDynamicTokens(farmers, tpm, heldTokens, horizon) {
+		if b := c.Boosters[userIDs[i]]; !b.TokensWantedManual {
+			b.TokensWanted = tokens
+		}
+	}
 }
 
-// setEstimatedBoostTimings calculates and sets the estimated boost timings on a booster
-func setEstimatedBoostTimings(booster *Booster) {
+// dynamicTokenSummary lists the planned token counts for the farmers still to boost
+func dynamicTokenSummary(c *Contract) string {
+	var counts []string
+	for _, userID := range c.Order {
+		if b := c.Boosters[userID]; b != nil && b.BoostState != BoostStateBoosted {
+			counts = append(counts, strconv.Itoa(b.TokensWanted))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
+
+// estimateBoostDuration estimates how long a booster takes to boost based on TE and tokens wanted
+func estimateBoostDuration(booster *Booster) (time.Duration, time.Duration, bool) {
 	// These fields are for the dynamic token assignment, per user based on TE
 	dt := createDynamicTokenData(int64(booster.TECount))
+	if dt == nil {
+		return 0, 0, false
+	}
+	// Adjust the pure boost time to compensate for wiggle room for launching
+	// boosts and checking in for truck delivery and chicken runs
+	// Estimating about 20 seconds for each interruption
+	slopTime := 20 * time.Second // Add 20 seconds of slop
+	boostDuration, chickenRunDuration := getBoostTimeSeconds(dt, booster.TokensWanted)
+	bonusStep := 220 * time.Second // 3m40s per step, truck timings
+	extraBoost := time.Duration(boostDuration/bonusStep) * slopTime
+	return boostDuration + extraBoost, chickenRunDuration, true
+}
 
+// setEstimatedBoostTimings calculates and sets the estimated boost timings on a booster
+func setEstimatedBoostTimings(booster *Booster) {
 	// Estimate the boost timings
-	if dt != nil {
-		// Adjust the pure boost time to compensate for wiggle room for launching
-		// boosts and checking in for truck delivery and chicken runs
-		// Estimating about 20 seconds for each interruption
-		slopTime := 20 * time.Second // Add 20 seconds of slop
-		wiggleRoom := time.Duration(slopTime)
-		boostDuration, chickenRunDuration := getBoostTimeSeconds(dt, booster.TokensWanted)
-		bonusStep := 220 * time.Second // 3m40s per step, truck timings
-		extraBoost := time.Duration(boostDuration/bonusStep) * slopTime
-		totalBoostDuration := boostDuration + extraBoost
+	if totalBoostDuration, chickenRunDuration, ok := estimateBoostDuration(booster); ok {
+		wiggleRoom := 20 * time.Second
 		booster.EstDurationOfBoost = totalBoostDuration
 		booster.EstEndOfBoost = time.Now().Add(totalBoostDuration).Add(wiggleRoom)
 		booster.EstRequestChickenRuns = time.Now().Add(chickenRunDuration).Add(wiggleRoom)
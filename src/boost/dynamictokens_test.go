@@ -2,6 +2,7 @@ package boost
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -147,36 +148,221 @@ func TestCreateDynamicTokenData(t *testing.T) {
 }
 */
 
-func TestDynamicTokens(t *testing.T) {
+// dynamicTokenTotalEggs scores an allocation with the same model the optimizer uses
+func dynamicTokenTotalEggs(farmers []dynamicTokenFarmer, tokens []int, tpm float64, held int, horizon float64) float64 {
+	total := 0.0
+	spent := 0
+	for i, f := range farmers {
+		spent += max(0, tokens[i]-f.Received)
+		start := 0.0
+		if spent > held {
+			start = float64(spent-held) / tpm
+		}
+		total += dynamicTokenEggs(f, tokens[i], start, horizon)
+	}
+	return total
+}
 
+func TestDynamicTokenEggsFollowsBoostTime(t *testing.T) {
 	dt := createDynamicTokenData(50)
+	f := dynamicTokenFarmer{Data: dt, ELR: 2}
+	for tokens := dynamicTokenMin; tokens <= dynamicTokenMax; tokens++ {
+		boost, _ := getBoostTimeSeconds(dt, tokens)
+		want := 2 * (120 - boost.Minutes()/2)
+		if got := dynamicTokenEggs(f, tokens, 0, 120); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("%d tokens: expected %f eggs, got %f", tokens, want, got)
+		}
+	}
+	if got := dynamicTokenEggs(f, 8, 130, 120); got != 0 {
+		t.Fatalf("expected no eggs when boosting after the horizon, got %f", got)
+	}
+}
 
-	// Initially assign 6 token boosts to everyone,
-	// In reverse order start calculating using 8 token boosts
-	// stop when the 120 minute delivered eggs amount is less than the previous amount
-	result := calculateDynamicTokens(dt, 4, 0.39, 0)
+func TestCalculateDynamicTokens(t *testing.T) {
+	farmers := func(tes ...int64) []dynamicTokenFarmer {
+		var out []dynamicTokenFarmer
+		for _, te := range tes {
+			out = append(out, dynamicTokenFarmer{Data: createDynamicTokenData(te)})
+		}
+		return out
+	}
 
-	// result should be 4 integers with the the last one being an 8
-	if len(result) != 4 {
-		t.Errorf("calculateDynamicTokens() = %v, want %v", len(result), 4)
+	testCases := []struct {
+		name    string
+		farmers []dynamicTokenFarmer
+		tpm     float64
+		held    int
+		horizon float64
+		check   func(t *testing.T, tokens []int)
+	}{
+		{
+			name:    "held tokens cover every max boost",
+			farmers: farmers(50, 50, 50, 50),
+			tpm:     0.4,
+			held:    4 * dynamicTokenMax,
+			horizon: 120,
+			check: func(t *testing.T, tokens []int) {
+				for i, n := range tokens {
+					if n != dynamicTokenMax {
+						t.Fatalf("farmer %d: expected %d tokens, got %v", i, dynamicTokenMax, tokens)
+					}
+				}
+			},
+		},
+		{
+			name:    "slow tokens favor smaller boosts",
+			farmers: farmers(50, 50, 50, 50, 50, 50),
+			tpm:     0.1,
+			horizon: 600,
+			check: func(t *testing.T, tokens []int) {
+				for i, n := range tokens[:len(tokens)-1] {
+					if n >= dynamicTokenMax {
+						t.Fatalf("farmer %d: expected fewer than %d tokens with slow tokens, got %v", i, dynamicTokenMax, tokens)
+					}
+				}
+			},
+		},
+		{
+			name:    "last booster delays nobody",
+			farmers: farmers(50, 50, 50),
+			tpm:     1,
+			horizon: 240,
+			check: func(t *testing.T, tokens []int) {
+				if tokens[len(tokens)-1] < tokens[0] {
+					t.Fatalf("expected the last booster to take at least as many tokens as the first, got %v", tokens)
+				}
+			},
+		},
+		{
+			name:    "received tokens are kept",
+			farmers: []dynamicTokenFarmer{{Data: createDynamicTokenData(50), Received: 10}, {Data: createDynamicTokenData(50)}},
+			tpm:     0.05,
+			horizon: 300,
+			check: func(t *testing.T, tokens []int) {
+				if tokens[0] < 10 {
+					t.Fatalf("expected at least the 10 received tokens, got %v", tokens)
+				}
+			},
+		},
+		{
+			name:    "no farmers",
+			tpm:     1,
+			horizon: 120,
+			check: func(t *testing.T, tokens []int) {
+				if len(tokens) != 0 {
+					t.Fatalf("expected no tokens, got %v", tokens)
+				}
+			},
+		},
 	}
 
-	if result[0] != 6 {
-		t.Errorf("calculateDynamicTokens() = %v, want %v", result[0], 6)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := calculateDynamicTokens(tc.farmers, tc.tpm, tc.held, tc.horizon)
+			if len(tokens) != len(tc.farmers) {
+				t.Fatalf("expected %d token counts, got %v", len(tc.farmers), tokens)
+			}
+			tc.check(t, tokens)
+		})
 	}
+}
 
-	if result[1] != 6 {
-		t.Errorf("calculateDynamicTokens() = %v, want %v", result[1], 6)
+func TestCalculateDynamicTokensIsOptimal(t *testing.T) {
+	testCases := []struct {
+		name    string
+		tes     []int64
+		elr     []float64
+		tpm     float64
+		held    int
+		horizon float64
+	}{
+		{"fast tokens", []int64{50, 50, 50}, []float64{1, 1, 1}, 1, 0, 120},
+		{"slow tokens", []int64{50, 50, 50}, []float64{1, 1, 1}, 0.2, 0, 240},
+		{"held tokens", []int64{10, 100, 200}, []float64{1, 1, 1}, 0.3, 10, 180},
+		{"mixed lay rates", []int64{50, 50, 50}, []float64{3, 1, 0.5}, 0.25, 0, 200},
 	}
 
-	if result[2] != 6 {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var farmers []dynamicTokenFarmer
+			for i, te := range tc.tes {
+				farmers = append(farmers, dynamicTokenFarmer{Data: createDynamicTokenData(te), ELR: tc.elr[i]})
+			}
+			tokens := calculateDynamicTokens(farmers, tc.tpm, tc.held, tc.horizon)
+			got := dynamicTokenTotalEggs(farmers, tokens, tc.tpm, tc.held, tc.horizon)
+
+			// Exhaustive search over every allocation
+			bestEggs := 0.0
+			for a := dynamicTokenMin; a <= dynamicTokenMax; a++ {
+				for b := dynamicTokenMin; b <= dynamicTokenMax; b++ {
+					for c := dynamicTokenMin; c <= dynamicTokenMax; c++ {
+						bestEggs = max(bestEggs, dynamicTokenTotalEggs(farmers, []int{a, b, c}, tc.tpm, tc.held, tc.horizon))
+					}
+				}
+			}
+			if math.Abs(got-bestEggs) > 1e-6 {
+				t.Fatalf("allocation %v delivers %f eggs, best is %f", tokens, got, bestEggs)
+			}
+		})
+	}
+}
 
-		t.Errorf("calculateDynamicTokens() = %v, want %v", result[2], 6)
+func TestDetermineDynamicTokensSetsAsks(t *testing.T) {
+	c := &Contract{
+		Style:           ContractFlagDynamicTokens | ContractFlagFastrun,
+		MinutesPerToken: 3,
+		Boosters:        make(map[string]*Booster),
+	}
+	for _, name := range []string{"u1", "u2", "u3"} {
+		c.Boosters[name] = &Booster{UserID: name, TECount: 50}
+		c.Order = append(c.Order, name)
 	}
+	c.Boosters["u1"].BoostState = BoostStateBoosted
+	c.Boosters["u1"].TokensWanted = 6
 
-	if result[3] != 8 {
+	determineDynamicTokens(c)
 
-		t.Errorf("calculateDynamicTokens() = %v, want %v", result[3], 8)
+	if c.Boosters["u1"].TokensWanted != 6 {
+		t.Fatalf("expected boosted farmer to keep their tokens, got %d", c.Boosters["u1"].TokensWanted)
+	}
+	for _, name := range []string{"u2", "u3"} {
+		if n := c.Boosters[name].TokensWanted; n < dynamicTokenMin || n > dynamicTokenMax {
+			t.Fatalf("%s: expected a planned token count, got %d", name, n)
+		}
 	}
+	if summary := dynamicTokenSummary(c); summary == "" {
+		t.Fatalf("expected a dynamic token summary")
+	}
+}
 
+func TestDetermineDynamicTokensKeepsManualAsks(t *testing.T) {
+	c := &Contract{
+		Style:           ContractFlagDynamicTokens | ContractFlagFastrun,
+		MinutesPerToken: 3,
+		Boosters:        make(map[string]*Booster),
+	}
+	for _, name := range []string{"u1", "u2", "u3"} {
+		c.Boosters[name] = &Booster{UserID: name, TECount: 50}
+		c.Order = append(c.Order, name)
+	}
+	c.Boosters["u2"].TokensWanted = 9
+	c.Boosters["u2"].TokensWantedManual = true
+
+	determineDynamicTokens(c)
+	if n := c.Boosters["u2"].TokensWanted; n != 9 {
+		t.Fatalf("expected the manual ask to be kept, got %d", n)
+	}
+
+	// Nothing the plan depends on changed, so it isn't redone
+	c.Boosters["u3"].TokensWanted = 1
+	determineDynamicTokens(c)
+	if n := c.Boosters["u3"].TokensWanted; n != 1 {
+		t.Fatalf("expected no new plan with the same inputs, got %d", n)
+	}
+
+	c.Boosters["u3"].TokensReceived = 2
+	determineDynamicTokens(c)
+	if n := c.Boosters["u3"].TokensWanted; n < dynamicTokenMin || n > dynamicTokenMax {
+		t.Fatalf("expected a new plan once tokens arrived, got %d", n)
+	}
 }
@@ -0,0 +1,101 @@
+package boost
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// Guild settings selecting where contract events are published
+const (
+	eventSinkSetting     = "event_sink" // "amqp" or "webhook", empty picks whichever is configured
+	amqpURLSetting       = "amqp_url"
+	webhookURLSetting    = "webhook_url"
+	webhookSecretSetting = "webhook_secret"
+)
+
+// EventPublisher delivers a JSON encoded contract event to a guild's sink.
+type EventPublisher interface {
+	Publish(body []byte) error
+	Close()
+}
+
+// getGuildEventPublisher returns the publisher configured for the guild, or nil
+// when the guild has no event sink.
+func getGuildEventPublisher(guildID string) EventPublisher {
+	if guildID == "" {
+		return nil
+	}
+	sink := strings.ToLower(strings.TrimSpace(guildstate.GetGuildSettingString(guildID, eventSinkSetting)))
+	amqpURL := guildstate.GetGuildSettingString(guildID, amqpURLSetting)
+	webhookURL := guildstate.GetGuildSettingString(guildID, webhookURLSetting)
+
+	switch sink {
+	case "amqp":
+		webhookURL = ""
+	case "webhook":
+		amqpURL = ""
+	}
+
+	if amqpURL != "" {
+		return getAMQPClient(guildID, amqpURL)
+	}
+	if webhookURL != "" {
+		return getWebhookClient(guildID, webhookURL, guildstate.GetGuildSettingString(guildID, webhookSecretSetting))
+	}
+	return nil
+}
+
+// guildHasEventPublisher returns true if contract events can be published for the guild
+func guildHasEventPublisher(guildID string) bool {
+	return getGuildEventPublisher(guildID) != nil
+}
+
+// publishEvent marshals the payload and hands it to the publisher in the background.
+func publishEvent(publisher EventPublisher, what string, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Events: error marshaling %s: %v", what, err)
+		return
+	}
+
+	// Queue before going async so events keep the order they were raised in
+	if outbox, ok := publisher.(outboxPublisher); ok {
+		if err := outbox.enqueue(body); err == nil {
+			go func() {
+				if err := outbox.flushOutbox(); err != nil {
+					log.Printf("Events: %s queued, broker unavailable: %v", what, err)
+				}
+			}()
+			return
+		}
+	}
+
+	go func() {
+		if err := publisher.Publish(body); err != nil {
+			log.Printf("Events: error publishing %s: %v", what, err)
+		}
+	}()
+}
+
+// PublishTokenLog publishes a token log to the guild's event sink.
+func PublishTokenLog(publisher EventPublisher, logEntry AMQPTokenMessage) {
+	publishEvent(publisher, "token log", logEntry)
+}
+
+// PublishBoostStatus publishes a boost status update to the guild's event sink.
+func PublishBoostStatus(publisher EventPublisher, status AMQPBoostStatusMessage) {
+	publishEvent(publisher, "boost status", status)
+}
+
+// PublishContractStart publishes a contract start event to the guild's event sink.
+func PublishContractStart(publisher EventPublisher, status AMQPContractStartMessage) {
+	publishEvent(publisher, "contract start", status)
+}
+
+// PublishNonToken publishes a non-token event to the guild's event sink.
+func PublishNonToken(publisher EventPublisher, status AMQPNonTokenMessage) {
+	publishEvent(publisher, "non-token", status)
+}
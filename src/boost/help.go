@@ -124,6 +124,10 @@ func GetHelp(s *discordgo.Session, guildID string, channelID string, userID stri
 			fmt.Fprintf(&strBuilder, "%s : Change the ping role to something else.\n", bottools.GetFormattedCommand("change-ping-role"))
 			fmt.Fprintf(&strBuilder, "%s : Move a single booster to a different position.\n", bottools.GetFormattedCommand("change-one-booster"))
 			fmt.Fprintf(&strBuilder, "%s : Redraw the Boost List message.\n", bottools.GetFormattedCommand("bump"))
+			fmt.Fprintf(&strBuilder, "%s : Audit who boosted, skipped or moved, and when.\n", bottools.GetFormattedCommand("contract-history"))
+			fmt.Fprintf(&strBuilder, "%s : Save this contract's settings to reuse with `/contract template`.\n", bottools.GetFormattedCommand("contract-template"))
+			fmt.Fprintf(&strBuilder, "%s : Create signup threads automatically when contracts drop.\n", bottools.GetFormattedCommand("contract-schedule"))
+			fmt.Fprintf(&strBuilder, "%s : Split a large signup into several balanced coops.\n", bottools.GetFormattedCommand("coop-group"))
 
 			field = append(field, &discordgo.MessageEmbedField{
 				Name:   "COORDINATOR COMMANDS",
@@ -161,6 +161,35 @@ func leaderboardMostRecentSeason() (string, int, bool) {
 	return bestName, bestYear, true
 }
 
+// CurrentLeaderboardSeasonID returns the running contract season, e.g. "summer_2026".
+// It is false until the periodicals name the season, so a fallback list can't end one early.
+func CurrentLeaderboardSeasonID() (string, bool) {
+	name, year, _ := ei.GetEggIncCurrentSeason()
+	seasonID := leaderboardSeasonID(strings.ToLower(strings.TrimSpace(name)), year)
+	if _, _, ok := leaderboardParseSeasonID(seasonID); !ok {
+		return "", false
+	}
+	return seasonID, true
+}
+
+// LeaderboardSeasonIsAfter reports whether seasonID comes after otherID.
+func LeaderboardSeasonIsAfter(seasonID, otherID string) bool {
+	name, year, ok := leaderboardParseSeasonID(seasonID)
+	otherName, otherYear, otherOK := leaderboardParseSeasonID(otherID)
+	if !ok || !otherOK {
+		return false
+	}
+	if year != otherYear {
+		return year > otherYear
+	}
+	return leaderboardSeasonIndex(name) > leaderboardSeasonIndex(otherName)
+}
+
+// LeaderboardSeasonLabel returns the display name of a season ID, e.g. "Summer 2026".
+func LeaderboardSeasonLabel(seasonID string) string {
+	return leaderboardSeasonLabel(seasonID)
+}
+
 // leaderboardSeasons returns all known seasonal scopes from periodicals-loaded contracts,
 // with All Time always pinned first. Falls back to a static list until data is loaded.
 func leaderboardSeasons() []leaderboardSeason {
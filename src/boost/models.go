@@ -8,6 +8,13 @@ import (
 	"database/sql"
 )
 
+type AmqpOutbox struct {
+	ID        int64
+	Guildid   string
+	Body      string
+	CreatedAt int64
+}
+
 type ContractComplaint struct {
 	Contractid string
 	Complaint  string
@@ -20,7 +27,51 @@ type ContractDatum struct {
 	Value      sql.NullString
 }
 
+type ContractEvent struct {
+	ID           int64
+	Contracthash string
+	Channelid    string
+	EventTime    int64
+	EventType    string
+	Actorid      string
+	Targetid     string
+	Value        sql.NullString
+}
+
 type ContractRole struct {
 	Contractid string
 	RoleName   string
 }
+
+type ContractSchedule struct {
+	ID            int64
+	Guildid       string
+	Channelid     string
+	Roleid        string
+	Slot          string
+	Mode          string
+	OffsetMinutes int64
+	PlayStyle     int64
+	Template      string
+	CreatedBy     string
+	CreatedAt     int64
+}
+
+type ContractScheduleJob struct {
+	ID         int64
+	Scheduleid int64
+	SlotKey    string
+	Contractid string
+	RunAt      int64
+	Status     string
+}
+
+type WebhookDeadLetter struct {
+	ID        int64
+	Guildid   string
+	Url       string
+	Body      string
+	Error     string
+	Attempts  int64
+	CreatedAt int64
+}
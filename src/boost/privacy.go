@@ -0,0 +1,485 @@
+package boost
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+)
+
+// privacyDeletedNick replaces the names of an erased farmer in contract
+// history that is shared with the rest of the coop.
+const privacyDeletedNick = "Deleted User"
+
+func init() {
+	farmerstate.RegisterPrivacyStore("contracts", farmerstate.PrivacyStore{
+		Export: exportContractsPrivacy,
+		Erase:  eraseContractsPrivacy,
+	})
+	// Events are erased with their contracts so alts get the same tombstone IDs
+	farmerstate.RegisterPrivacyStore("contract_events", farmerstate.PrivacyStore{
+		Export: exportContractEventsPrivacy,
+	})
+	farmerstate.RegisterPrivacyStore("contract_schedules", farmerstate.PrivacyStore{
+		Export: exportContractSchedulesPrivacy,
+		Erase:  eraseContractSchedulesPrivacy,
+	})
+	farmerstate.RegisterPrivacyStore("api_cache", farmerstate.PrivacyStore{
+		Export: func(userID string) (any, error) {
+			cache, err := ei.ExportUserCache(userID)
+			if len(cache) == 0 {
+				return nil, err
+			}
+			return cache, err
+		},
+		Erase: func(userID string, _ string) error {
+			return ei.DeleteUserCache(userID)
+		},
+	})
+}
+
+// privacyContract is the part of a contract that belongs to one farmer
+type privacyContract struct {
+	ContractID string            `json:"contract_id"`
+	CoopID     string            `json:"coop_id"`
+	ChannelID  string            `json:"channel_id,omitempty"`
+	StartTime  time.Time         `json:"start_time"`
+	Creator    bool              `json:"creator,omitempty"`
+	Boosters   []*Booster        `json:"boosters,omitempty"`
+	TokenLog   []ei.TokenUnitLog `json:"token_log,omitempty"`
+}
+
+// privacyContractUserIDs returns the user and the alts they control in a contract
+func privacyContractUserIDs(contract *Contract, userID string) []string {
+	ids := []string{userID}
+	if b := contract.Boosters[userID]; b != nil {
+		ids = append(ids, b.Alts...)
+	}
+	for id, b := range contract.Boosters {
+		if b != nil && b.AltController == userID && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func newPrivacyContract(contract *Contract, userID string) *privacyContract {
+	out := &privacyContract{
+		ContractID: contract.ContractID,
+		CoopID:     contract.CoopID,
+		StartTime:  contract.StartTime,
+		Creator:    slices.Contains(contract.CreatorID, userID),
+	}
+	if len(contract.Location) > 0 && contract.Location[0] != nil {
+		out.ChannelID = contract.Location[0].ChannelID
+	}
+	ids := privacyContractUserIDs(contract, userID)
+	for _, id := range ids {
+		if b := contract.Boosters[id]; b != nil {
+			out.Boosters = append(out.Boosters, b)
+		}
+	}
+	for _, t := range contract.TokenLog {
+		if slices.Contains(ids, t.FromUserID) || slices.Contains(ids, t.ToUserID) {
+			out.TokenLog = append(out.TokenLog, t)
+		}
+	}
+	if !out.Creator && len(out.Boosters) == 0 && len(out.TokenLog) == 0 {
+		return nil
+	}
+	return out
+}
+
+// liveContractsForUser returns the running contracts that mention userID
+func liveContractsForUser(userID string) []*Contract {
+	ContractsMutex.RLock()
+	defer ContractsMutex.RUnlock()
+	var contracts []*Contract
+	for _, c := range Contracts {
+		if c == nil {
+			continue
+		}
+		if _, ok := c.Boosters[userID]; ok || slices.Contains(c.CreatorID, userID) || slices.Contains(c.WaitlistBoosters, userID) {
+			contracts = append(contracts, c)
+			continue
+		}
+		for _, t := range c.TokenLog {
+			if t.FromUserID == userID || t.ToUserID == userID {
+				contracts = append(contracts, c)
+				break
+			}
+		}
+	}
+	return contracts
+}
+
+func exportContractsPrivacy(userID string) (any, error) {
+	var out []*privacyContract
+	seen := make(map[string]bool)
+	for _, c := range liveContractsForUser(userID) {
+		c.mutex.Lock()
+		if pc := newPrivacyContract(c, userID); pc != nil {
+			out = append(out, pc)
+		}
+		seen[c.ContractHash] = true
+		c.mutex.Unlock()
+	}
+
+	if queries != nil {
+		rows, err := queries.GetContractDataForUser(ctx, userID)
+		if err != nil {
+			return out, err
+		}
+		for _, row := range rows {
+			var c Contract
+			if !row.Value.Valid || json.Unmarshal([]byte(row.Value.String), &c) != nil || seen[c.ContractHash] {
+				continue
+			}
+			if pc := newPrivacyContract(&c, userID); pc != nil {
+				out = append(out, pc)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// anonymizeContractUser replaces userID and the alts they control with
+// tombstone IDs and returns the renames. The contract history stays intact
+// for the rest of the coop.
+func anonymizeContractUser(contract *Contract, userID string, tombstone string) map[string]string {
+	renames := make(map[string]string)
+	for n, id := range privacyContractUserIDs(contract, userID) {
+		newID := tombstone
+		if n > 0 {
+			newID = fmt.Sprintf("%s-alt%d", tombstone, n)
+		}
+		renameContractUser(contract, id, newID)
+		renames[id] = newID
+	}
+	return renames
+}
+
+// renameContractUser moves every reference to oldID in a contract to newID
+func renameContractUser(contract *Contract, oldID string, newID string) {
+	replaceID := func(ids []string) {
+		for n, id := range ids {
+			if id == oldID {
+				ids[n] = newID
+			}
+		}
+	}
+	replaceString := func(id *string) {
+		if *id == oldID {
+			*id = newID
+		}
+	}
+
+	replaceID(contract.CreatorID)
+	replaceID(contract.WaitlistBoosters)
+	replaceID(contract.Order)
+	replaceID(contract.OriginalOrder)
+	replaceID(contract.BoostedOrder)
+	replaceString(&contract.CurrentBoosterUserID)
+	replaceString(&contract.Banker.CurrentBanker)
+	replaceString(&contract.Banker.BoostingSinkUserID)
+	replaceString(&contract.Banker.PostSinkUserID)
+
+	if b, ok := contract.Boosters[oldID]; ok {
+		delete(contract.Boosters, oldID)
+		if b != nil {
+			b.UserID = newID
+			b.Name = privacyDeletedNick
+			b.Nick = privacyDeletedNick
+			b.Unique = privacyDeletedNick
+			b.GlobalName = privacyDeletedNick
+			b.UserName = privacyDeletedNick
+			b.Mention = privacyDeletedNick
+			b.GuildName = ""
+			b.ChannelName = ""
+			b.IHRCalcLog = ""
+		}
+		contract.Boosters[newID] = b
+	}
+	for _, b := range contract.Boosters {
+		if b == nil {
+			continue
+		}
+		replaceID(b.VotingList)
+		replaceID(b.RanChickensOn)
+		replaceID(b.Alts)
+		replaceString(&b.AltController)
+	}
+	if state, ok := contract.LastPublishedStates[oldID]; ok {
+		delete(contract.LastPublishedStates, oldID)
+		contract.LastPublishedStates[newID] = state
+	}
+	for n := range contract.TokenLog {
+		t := &contract.TokenLog[n]
+		if t.FromUserID == oldID {
+			t.FromUserID = newID
+			t.FromNick = privacyDeletedNick
+		}
+		if t.ToUserID == oldID {
+			t.ToUserID = newID
+			t.ToNick = privacyDeletedNick
+		}
+	}
+}
+
+// replacePrivacyText is the fallback for stored JSON, catching any
+// reference to userID the typed rename doesn't know about. Only whole string
+// values and keys, and mentions, are replaced so a longer ID that contains
+// userID is left alone.
+func replacePrivacyText(data string, userID string, tombstone string) string {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return data
+	}
+	var out strings.Builder
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(replacePrivacyValue(value, userID, tombstone)); err != nil {
+		return data
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func replacePrivacyValue(value any, userID string, tombstone string) any {
+	switch v := value.(type) {
+	case string:
+		if v == userID {
+			return tombstone
+		}
+		v = strings.ReplaceAll(v, "<@"+userID+">", "<@"+tombstone+">")
+		return strings.ReplaceAll(v, "<@!"+userID+">", "<@!"+tombstone+">")
+	case []any:
+		for n := range v {
+			v[n] = replacePrivacyValue(v[n], userID, tombstone)
+		}
+		return v
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if key == userID {
+				key = tombstone
+			}
+			out[key] = replacePrivacyValue(item, userID, tombstone)
+		}
+		return out
+	}
+	return value
+}
+
+func eraseContractsPrivacy(userID string, tombstone string) error {
+	renames := make(map[string]map[string]string)
+	for _, c := range liveContractsForUser(userID) {
+		c.mutex.Lock()
+		renames[c.ContractHash] = anonymizeContractUser(c, userID, tombstone)
+		c.buttonComponents = nil
+		saveSqliteData(c)
+		c.mutex.Unlock()
+	}
+	flushPendingSaves()
+
+	if queries == nil {
+		return nil
+	}
+	rows, err := queries.GetContractDataForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, row := range rows {
+		value := row.Value.String
+		var c Contract
+		if err := json.Unmarshal([]byte(value), &c); err == nil {
+			renames[c.ContractHash] = anonymizeContractUser(&c, userID, tombstone)
+			if data, err := json.Marshal(&c); err == nil {
+				value = string(data)
+			}
+		}
+		value = replacePrivacyText(value, userID, tombstone)
+		if err := queries.UpdateContractValue(ctx, UpdateContractValueParams{
+			Value:     sql.NullString{String: value, Valid: true},
+			Channelid: row.Channelid,
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := eraseContractEventsPrivacy(userID, tombstone, renames); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Failed deliveries and queued broker messages carry the raw ID as well
+	deadLetters, err := queries.GetWebhookDeadLettersForUser(ctx, userID)
+	errs = append(errs, err)
+	for _, row := range deadLetters {
+		errs = append(errs, queries.UpdateWebhookDeadLetterBody(ctx, UpdateWebhookDeadLetterBodyParams{
+			Body: replacePrivacyText(row.Body, userID, tombstone), ID: row.ID,
+		}))
+	}
+	outbox, err := queries.GetAMQPOutboxForUser(ctx, userID)
+	errs = append(errs, err)
+	for _, row := range outbox {
+		errs = append(errs, queries.UpdateAMQPOutboxBody(ctx, UpdateAMQPOutboxBodyParams{
+			Body: replacePrivacyText(row.Body, userID, tombstone), ID: row.ID,
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+func findContractEventsForUser(userID string) ([]ContractEvent, error) {
+	if queries == nil {
+		return nil, nil
+	}
+	return queries.GetContractEventsForUser(ctx, userID)
+}
+
+func exportContractEventsPrivacy(userID string) (any, error) {
+	rows, err := findContractEventsForUser(userID)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	events := make([]contractHistoryEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, contractHistoryEventFromRow(row))
+	}
+	return events, nil
+}
+
+// anonymizeContractEventPayload applies the contract's renames to a stored
+// event payload, token entries lose the nickname along with the ID
+func anonymizeContractEventPayload(payload *contractEventPayload, renames map[string]string) {
+	if t := payload.Token; t != nil {
+		if newID, ok := renames[t.FromUserID]; ok {
+			t.FromUserID = newID
+			t.FromNick = privacyDeletedNick
+		}
+		if newID, ok := renames[t.ToUserID]; ok {
+			t.ToUserID = newID
+			t.ToNick = privacyDeletedNick
+		}
+	}
+	if snap := payload.Snapshot; snap != nil {
+		for _, ids := range [][]string{snap.Order, snap.BoostedOrder} {
+			for n, id := range ids {
+				if newID, ok := renames[id]; ok {
+					ids[n] = newID
+				}
+			}
+		}
+		if newID, ok := renames[snap.CurrentBoosterUserID]; ok {
+			snap.CurrentBoosterUserID = newID
+		}
+		for oldID, newID := range renames {
+			if state, ok := snap.BoostStates[oldID]; ok {
+				delete(snap.BoostStates, oldID)
+				snap.BoostStates[newID] = state
+			}
+		}
+	}
+}
+
+// eraseContractEventsPrivacy rewrites the event streams of the contracts in
+// renames, and any other event that still mentions userID
+func eraseContractEventsPrivacy(userID string, tombstone string, renames map[string]map[string]string) error {
+	rows, err := findContractEventsForUser(userID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[int64]bool, len(rows))
+	for _, row := range rows {
+		seen[row.ID] = true
+	}
+	for hash := range renames {
+		events, err := queries.GetContractEvents(ctx, hash)
+		if err != nil {
+			return err
+		}
+		for _, row := range events {
+			if !seen[row.ID] {
+				seen[row.ID] = true
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	var errs []error
+	for _, row := range rows {
+		contractRenames := renames[row.Contracthash]
+		if contractRenames == nil {
+			contractRenames = map[string]string{userID: tombstone}
+		}
+		value := row.Value.String
+		var payload contractEventPayload
+		if json.Unmarshal([]byte(value), &payload) == nil {
+			anonymizeContractEventPayload(&payload, contractRenames)
+			if data, err := json.Marshal(payload); err == nil {
+				value = string(data)
+			}
+		}
+		actorID, targetID := row.Actorid, row.Targetid
+		if newID, ok := contractRenames[actorID]; ok {
+			actorID = newID
+		}
+		if newID, ok := contractRenames[targetID]; ok {
+			targetID = newID
+		}
+		if err := queries.UpdateContractEvent(ctx, UpdateContractEventParams{
+			Actorid:  actorID,
+			Targetid: targetID,
+			Value:    sql.NullString{String: replacePrivacyText(value, userID, tombstone), Valid: row.Value.Valid},
+			ID:       row.ID,
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func exportContractSchedulesPrivacy(userID string) (any, error) {
+	if queries == nil {
+		return nil, nil
+	}
+	schedules, err := queries.GetContractSchedulesCreatedBy(ctx, userID)
+	if err != nil || len(schedules) == 0 {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// eraseContractSchedulesPrivacy removes the schedules the user created. A
+// scheduled signup is opened on behalf of its creator, so it can't keep
+// running under a tombstone.
+func eraseContractSchedulesPrivacy(userID string, tombstone string) error {
+	if queries == nil {
+		return nil
+	}
+	schedules, err := queries.GetContractSchedulesCreatedBy(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, sched := range schedules {
+		if _, err := queries.DeleteContractSchedule(ctx, DeleteContractScheduleParams{ID: sched.ID, Guildid: sched.Guildid}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, queries.DeleteContractScheduleJobs(ctx, sched.ID))
+		log.Printf("Privacy erase: removed contract schedule %d in guild %s", sched.ID, sched.Guildid)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,262 @@
+package boost
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+	_ "modernc.org/sqlite"
+)
+
+// usePrivacyTestDB swaps in an in-memory contract database for the test
+func usePrivacyTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec(ddl); err != nil {
+		t.Fatalf("failed to execute DDL: %v", err)
+	}
+	origQueries, origDBConn := queries, dbConn
+	t.Cleanup(func() {
+		queries, dbConn = origQueries, origDBConn
+		_ = db.Close()
+	})
+	queries, dbConn = New(db), db
+	return db
+}
+
+func newPrivacyTestContract(hash string, channelID string, userID string) *Contract {
+	return &Contract{
+		ContractHash:         hash,
+		ContractID:           "privacy-contract",
+		CoopID:               hash,
+		Location:             []*LocationData{{ChannelID: channelID, GuildID: "privacy-guild"}},
+		CreatorID:            []string{userID},
+		Order:                []string{userID, "other", "FarmAlt"},
+		BoostedOrder:         []string{userID},
+		CurrentBoosterUserID: userID,
+		Banker:               BankerInfo{CurrentBanker: userID},
+		Boosters: map[string]*Booster{
+			userID:    {UserID: userID, Name: "Private Farmer", Nick: "Private Farmer", Mention: "<@" + userID + ">", Alts: []string{"FarmAlt"}},
+			"FarmAlt": {UserID: "FarmAlt", Name: "FarmAlt", Nick: "FarmAlt", AltController: userID},
+			"other":   {UserID: "other", Nick: "Other", VotingList: []string{userID}, RanChickensOn: []string{userID}},
+		},
+		TokenLog: []ei.TokenUnitLog{
+			{Time: time.Now(), Quantity: 2, FromUserID: userID, FromNick: "Private Farmer", ToUserID: "other", ToNick: "Other"},
+			{Time: time.Now(), Quantity: 1, FromUserID: "other", FromNick: "Other", ToUserID: userID, ToNick: "Private Farmer"},
+		},
+	}
+}
+
+func TestEraseUserDataLeavesNothingBehind(t *testing.T) {
+	db := usePrivacyTestDB(t)
+	userID := fmt.Sprintf("9%017d", time.Now().UnixNano()%1e17)
+	guildID := "privacy-guild"
+
+	// A running contract and a finished one that only exists in the database
+	live := newPrivacyTestContract("privacy-live", "privacy-live-chan", userID)
+	ContractsMutex.Lock()
+	Contracts[live.ContractHash] = live
+	ContractsMutex.Unlock()
+	t.Cleanup(func() {
+		ContractsMutex.Lock()
+		delete(Contracts, live.ContractHash)
+		ContractsMutex.Unlock()
+	})
+	archived := newPrivacyTestContract("privacy-old", "privacy-old-chan", userID)
+	archived.State = ContractStateArchive
+	data, _ := json.Marshal(archived)
+	if err := queries.InsertContract(ctx, InsertContractParams{
+		Channelid:  archived.Location[0].ChannelID,
+		Contractid: archived.ContractID,
+		Coopid:     archived.CoopID,
+		Value:      sql.NullString{String: string(data), Valid: true},
+	}); err != nil {
+		t.Fatalf("insert contract: %v", err)
+	}
+
+	recordContractEvent(live, ContractEventOrder, userID, "")
+	recordContractTokenEvent(live, live.TokenLog[0])
+	if _, err := queries.InsertContractSchedule(ctx, InsertContractScheduleParams{
+		Guildid: guildID, Channelid: "chan", Slot: "monday", Mode: "live", CreatedBy: userID, CreatedAt: time.Now().Unix(),
+	}); err != nil {
+		t.Fatalf("insert schedule: %v", err)
+	}
+	if err := queries.InsertWebhookDeadLetter(ctx, InsertWebhookDeadLetterParams{
+		Guildid: guildID, Url: "https://example.com", Body: `{"user_id":"` + userID + `"}`, Error: "timeout", Attempts: 3, CreatedAt: time.Now().Unix(),
+	}); err != nil {
+		t.Fatalf("insert dead letter: %v", err)
+	}
+
+	if err := guildstate.AddGuildCoordinator(guildID, userID, "someone"); err != nil {
+		t.Fatalf("add coordinator: %v", err)
+	}
+	if err := guildstate.AddGuildCoordinator(guildID, "someone-else", userID); err != nil {
+		t.Fatalf("add coordinator: %v", err)
+	}
+	t.Cleanup(func() { _ = guildstate.RemoveGuildCoordinator(guildID, "someone-else") })
+
+	farmerstate.SetEggIncName(userID, "PrivateFarmer")
+	farmerstate.SetMiscSettingString(userID, "dashboard_bookmarks", `[{"channel_id":"chan"}]`)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	cacheFile := filepath.Join("ttbb-data", "eiuserdata", "firstcontact-"+userID+".json")
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o755); err != nil {
+		t.Fatalf("mkdir cache: %v", err)
+	}
+	if err := os.WriteFile(cacheFile, []byte(`{"backup":{}}`), 0o644); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+
+	exported := farmerstate.GetFullUserData(userID)
+	for _, store := range []string{"contracts", "contract_events", "contract_schedules", "api_cache", "guild_coordinators"} {
+		if exported.Stores[store] == nil {
+			t.Errorf("export is missing the %s store, errors %v", store, exported.StoreErrors)
+		}
+	}
+	if contracts, ok := exported.Stores["contracts"].([]*privacyContract); !ok || len(contracts) != 2 {
+		t.Errorf("expected both contracts in the export, got %#v", exported.Stores["contracts"])
+	}
+
+	if err := farmerstate.EraseUserData(userID); err != nil {
+		t.Fatalf("EraseUserData: %v", err)
+	}
+
+	// Every table in the contract database
+	tables, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	var names []string
+	for tables.Next() {
+		var name string
+		_ = tables.Scan(&name)
+		names = append(names, name)
+	}
+	_ = tables.Close()
+	for _, table := range names {
+		rows, err := db.Query("SELECT * FROM " + table)
+		if err != nil {
+			t.Fatalf("read %s: %v", table, err)
+		}
+		cols, _ := rows.Columns()
+		for rows.Next() {
+			values := make([]sql.NullString, len(cols))
+			ptrs := make([]any, len(cols))
+			for n := range values {
+				ptrs[n] = &values[n]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				t.Fatalf("scan %s: %v", table, err)
+			}
+			for n, v := range values {
+				if strings.Contains(v.String, userID) || strings.Contains(v.String, "Private Farmer") || strings.Contains(v.String, "FarmAlt") {
+					t.Errorf("%s.%s still references the user: %s", table, cols[n], v.String)
+				}
+			}
+		}
+		_ = rows.Close()
+	}
+
+	live.mutex.Lock()
+	liveJSON, _ := json.Marshal(live)
+	live.mutex.Unlock()
+	if strings.Contains(string(liveJSON), userID) || strings.Contains(string(liveJSON), "Private Farmer") {
+		t.Errorf("running contract still references the user: %s", liveJSON)
+	}
+	if len(live.Boosters) != 3 || len(live.TokenLog) != 2 || live.Boosters["other"].VotingList[0] != live.CreatorID[0] {
+		t.Errorf("expected the contract history to be kept under a tombstone, got %+v", live)
+	}
+
+	coordinators, _ := guildstate.GetCoordinatorList(guildID)
+	for _, c := range coordinators {
+		if c.UserID == userID || c.AddedBy == userID {
+			t.Errorf("coordinator record still references the user: %+v", c)
+		}
+	}
+	if _, err := os.Stat(cacheFile); !os.IsNotExist(err) {
+		t.Errorf("expected the API cache to be deleted, got %v", err)
+	}
+
+	remaining := farmerstate.GetFullUserData(userID)
+	if len(remaining.Stores) != 0 || len(remaining.StoreErrors) != 0 {
+		t.Errorf("expected no stored data after erasure, got %+v %v", remaining.Stores, remaining.StoreErrors)
+	}
+	if remaining.FarmerState != nil && remaining.FarmerState.EggIncName != "" {
+		t.Errorf("expected the farmer record to be deleted, got %+v", remaining.FarmerState)
+	}
+}
+
+func TestContractScheduleAfterErasure(t *testing.T) {
+	usePrivacyTestDB(t)
+	userID := fmt.Sprintf("8%017d", time.Now().UnixNano()%1e17)
+	sched, err := queries.InsertContractSchedule(ctx, InsertContractScheduleParams{
+		Guildid: "privacy-guild", Channelid: "chan", Slot: "wednesday", Mode: contractScheduleModeLive, CreatedBy: userID, CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		t.Fatalf("insert schedule: %v", err)
+	}
+	if err := queries.InsertContractScheduleJob(ctx, InsertContractScheduleJobParams{
+		Scheduleid: sched.ID, SlotKey: "wednesday-2026-10-14", Contractid: "privacy-contract", RunAt: time.Now().Add(-time.Minute).Unix(),
+	}); err != nil {
+		t.Fatalf("insert schedule job: %v", err)
+	}
+
+	if err := farmerstate.EraseUserData(userID); err != nil {
+		t.Fatalf("EraseUserData: %v", err)
+	}
+
+	// A job left behind would open a signup thread for the tombstone, which
+	// needs a Discord session this test doesn't have.
+	runDueContractJobs(nil, time.Now())
+
+	if _, err := queries.GetContractSchedule(ctx, sched.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the erased user's schedule to be removed, got %v", err)
+	}
+	if jobs, _ := queries.GetDueContractScheduleJobs(ctx, time.Now().Unix()); len(jobs) != 0 {
+		t.Errorf("expected no scheduled jobs after erasure, got %+v", jobs)
+	}
+}
+
+func TestPrivacyMatchesWholeIDs(t *testing.T) {
+	usePrivacyTestDB(t)
+	userID := "123"
+	body := `{"user_id":"123","ids":["1234"],"123":{"note":"ping <@123> and <@!123>, not <@1234>"}}`
+	want := `{"ids":["1234"],"tomb":{"note":"ping <@tomb> and <@!tomb>, not <@1234>"},"user_id":"tomb"}`
+	if got := replacePrivacyText(body, userID, "tomb"); got != want {
+		t.Errorf("replacePrivacyText = %s, want %s", got, want)
+	}
+
+	for _, b := range []string{body, `{"user_id":"1234"}`, "not json 123"} {
+		if err := queries.InsertAMQPOutbox(ctx, InsertAMQPOutboxParams{Guildid: "privacy-guild", Body: b, CreatedAt: time.Now().UnixMilli()}); err != nil {
+			t.Fatalf("insert outbox: %v", err)
+		}
+	}
+	rows, err := queries.GetAMQPOutboxForUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetAMQPOutboxForUser: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Body != body {
+		t.Errorf("expected only the message with the exact ID, got %+v", rows)
+	}
+}
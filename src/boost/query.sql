@@ -58,3 +58,151 @@ DELETE FROM contract_complaints;
 -- name: DeleteContractComplaints :exec
 DELETE FROM contract_complaints WHERE contractID = ?;
 
+
+-- name: InsertContractEvent :exec
+INSERT INTO contract_events (contractHash, channelID, event_time, event_type, actorID, targetID, value)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetContractEvents :many
+SELECT * FROM contract_events
+WHERE contractHash = ?
+ORDER BY id;
+
+-- name: GetContractEventsByChannel :many
+SELECT * FROM contract_events
+WHERE channelID = ?
+ORDER BY id;
+
+-- name: DeleteContractEvents :exec
+DELETE FROM contract_events WHERE contractHash = ?;
+
+-- name: InsertWebhookDeadLetter :exec
+INSERT INTO webhook_dead_letters (guildID, url, body, error, attempts, created_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetWebhookDeadLetters :many
+SELECT * FROM webhook_dead_letters
+WHERE guildID = ?
+ORDER BY id;
+
+-- name: InsertAMQPOutbox :exec
+INSERT INTO amqp_outbox (guildID, body, created_at)
+VALUES (?, ?, ?);
+
+-- name: GetAMQPOutbox :many
+SELECT * FROM amqp_outbox
+WHERE guildID = ?
+ORDER BY id
+LIMIT ?;
+
+-- name: DeleteAMQPOutbox :exec
+DELETE FROM amqp_outbox WHERE id = ?;
+
+-- name: DeleteAMQPOutboxBefore :execrows
+DELETE FROM amqp_outbox WHERE guildID = ? AND created_at < ?;
+
+-- name: DeleteAMQPOutboxOverflow :execrows
+DELETE FROM amqp_outbox WHERE id IN (
+    SELECT id FROM amqp_outbox
+    WHERE guildID = ?
+    ORDER BY id DESC
+    LIMIT -1 OFFSET ?
+);
+
+-- name: GetAMQPOutboxStats :many
+SELECT guildID, COUNT(*) AS depth, CAST(MIN(created_at) AS INTEGER) AS oldest
+FROM amqp_outbox
+GROUP BY guildID
+ORDER BY guildID;
+
+-- name: InsertContractSchedule :one
+INSERT INTO contract_schedule (guildID, channelID, roleID, slot, mode, offset_minutes, play_style, template, created_by, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetContractSchedules :many
+SELECT * FROM contract_schedule
+ORDER BY id;
+
+-- name: GetContractSchedule :one
+SELECT * FROM contract_schedule
+WHERE id = ? LIMIT 1;
+
+-- name: GetContractSchedulesForGuild :many
+SELECT * FROM contract_schedule
+WHERE guildID = ?
+ORDER BY id;
+
+-- name: DeleteContractSchedule :execrows
+DELETE FROM contract_schedule WHERE id = ? AND guildID = ?;
+
+-- name: InsertContractScheduleJob :exec
+INSERT INTO contract_schedule_job (scheduleID, slot_key, contractID, run_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(scheduleID, slot_key) DO NOTHING;
+
+-- name: GetDueContractScheduleJobs :many
+SELECT * FROM contract_schedule_job
+WHERE status = 'pending' AND run_at <= ?
+ORDER BY run_at, id;
+
+-- name: GetPendingContractScheduleJobs :many
+SELECT * FROM contract_schedule_job
+WHERE scheduleID = ? AND status = 'pending'
+ORDER BY run_at;
+
+-- name: UpdateContractScheduleJobStatus :exec
+UPDATE contract_schedule_job SET status = ? WHERE id = ?;
+
+-- name: DeleteContractScheduleJobs :exec
+DELETE FROM contract_schedule_job WHERE scheduleID = ?;
+
+-- name: GetContractDataForUser :many
+SELECT * FROM contract_data
+WHERE CASE WHEN json_valid(contract_data.value) THEN EXISTS (
+    SELECT 1 FROM json_tree(contract_data.value)
+    WHERE atom = ?1 OR key = ?1 OR atom LIKE '%<@%' || ?1 || '>%'
+) ELSE 0 END;
+
+-- name: UpdateContractValue :exec
+UPDATE contract_data SET value = ? WHERE channelID = ?;
+
+-- name: GetContractEventsForUser :many
+SELECT * FROM contract_events
+WHERE actorID = ?1 OR targetID = ?1 OR CASE WHEN json_valid(contract_events.value) THEN EXISTS (
+    SELECT 1 FROM json_tree(contract_events.value)
+    WHERE atom = ?1 OR key = ?1 OR atom LIKE '%<@%' || ?1 || '>%'
+) ELSE 0 END
+ORDER BY id;
+
+-- name: UpdateContractEvent :exec
+UPDATE contract_events
+SET actorID = ?, targetID = ?, value = ?
+WHERE id = ?;
+
+-- name: GetContractSchedulesCreatedBy :many
+SELECT * FROM contract_schedule
+WHERE created_by = ?
+ORDER BY id;
+
+-- name: GetWebhookDeadLettersForUser :many
+SELECT * FROM webhook_dead_letters
+WHERE CASE WHEN json_valid(webhook_dead_letters.body) THEN EXISTS (
+    SELECT 1 FROM json_tree(webhook_dead_letters.body)
+    WHERE atom = ?1 OR key = ?1 OR atom LIKE '%<@%' || ?1 || '>%'
+) ELSE 0 END
+ORDER BY id;
+
+-- name: UpdateWebhookDeadLetterBody :exec
+UPDATE webhook_dead_letters SET body = ? WHERE id = ?;
+
+-- name: GetAMQPOutboxForUser :many
+SELECT * FROM amqp_outbox
+WHERE CASE WHEN json_valid(amqp_outbox.body) THEN EXISTS (
+    SELECT 1 FROM json_tree(amqp_outbox.body)
+    WHERE atom = ?1 OR key = ?1 OR atom LIKE '%<@%' || ?1 || '>%'
+) ELSE 0 END
+ORDER BY id;
+
+-- name: UpdateAMQPOutboxBody :exec
+UPDATE amqp_outbox SET body = ? WHERE id = ?;
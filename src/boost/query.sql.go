@@ -24,6 +24,54 @@ func (q *Queries) CountContractsByChannel(ctx context.Context, channelid string)
 	return count, err
 }
 
+const deleteAMQPOutbox = `-- name: DeleteAMQPOutbox :exec
+DELETE FROM amqp_outbox WHERE id = ?
+`
+
+func (q *Queries) DeleteAMQPOutbox(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAMQPOutbox, id)
+	return err
+}
+
+const deleteAMQPOutboxBefore = `-- name: DeleteAMQPOutboxBefore :execrows
+DELETE FROM amqp_outbox WHERE guildID = ? AND created_at < ?
+`
+
+type DeleteAMQPOutboxBeforeParams struct {
+	Guildid   string
+	CreatedAt int64
+}
+
+func (q *Queries) DeleteAMQPOutboxBefore(ctx context.Context, arg DeleteAMQPOutboxBeforeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAMQPOutboxBefore, arg.Guildid, arg.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAMQPOutboxOverflow = `-- name: DeleteAMQPOutboxOverflow :execrows
+DELETE FROM amqp_outbox WHERE id IN (
+    SELECT id FROM amqp_outbox
+    WHERE guildID = ?
+    ORDER BY id DESC
+    LIMIT -1 OFFSET ?
+)
+`
+
+type DeleteAMQPOutboxOverflowParams struct {
+	Guildid string
+	Offset  int64
+}
+
+func (q *Queries) DeleteAMQPOutboxOverflow(ctx context.Context, arg DeleteAMQPOutboxOverflowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAMQPOutboxOverflow, arg.Guildid, arg.Offset)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteAllContractComplaints = `-- name: DeleteAllContractComplaints :exec
 DELETE FROM contract_complaints
 `
@@ -61,6 +109,15 @@ func (q *Queries) DeleteContractComplaints(ctx context.Context, contractid strin
 	return err
 }
 
+const deleteContractEvents = `-- name: DeleteContractEvents :exec
+DELETE FROM contract_events WHERE contractHash = ?
+`
+
+func (q *Queries) DeleteContractEvents(ctx context.Context, contracthash string) error {
+	_, err := q.db.ExecContext(ctx, deleteContractEvents, contracthash)
+	return err
+}
+
 const deleteContractRoles = `-- name: DeleteContractRoles :exec
 DELETE FROM contract_roles WHERE contractID = ?
 `
@@ -70,6 +127,145 @@ func (q *Queries) DeleteContractRoles(ctx context.Context, contractid string) er
 	return err
 }
 
+const deleteContractSchedule = `-- name: DeleteContractSchedule :execrows
+DELETE FROM contract_schedule WHERE id = ? AND guildID = ?
+`
+
+type DeleteContractScheduleParams struct {
+	ID      int64
+	Guildid string
+}
+
+func (q *Queries) DeleteContractSchedule(ctx context.Context, arg DeleteContractScheduleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteContractSchedule, arg.ID, arg.Guildid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteContractScheduleJobs = `-- name: DeleteContractScheduleJobs :exec
+DELETE FROM contract_schedule_job WHERE scheduleID = ?
+`
+
+func (q *Queries) DeleteContractScheduleJobs(ctx context.Context, scheduleid int64) error {
+	_, err := q.db.ExecContext(ctx, deleteContractScheduleJobs, scheduleid)
+	return err
+}
+
+const getAMQPOutbox = `-- name: GetAMQPOutbox :many
+SELECT id, guildid, body, created_at FROM amqp_outbox
+WHERE guildID = ?
+ORDER BY id
+LIMIT ?
+`
+
+type GetAMQPOutboxParams struct {
+	Guildid string
+	Limit   int64
+}
+
+func (q *Queries) GetAMQPOutbox(ctx context.Context, arg GetAMQPOutboxParams) ([]AmqpOutbox, error) {
+	rows, err := q.db.QueryContext(ctx, getAMQPOutbox, arg.Guildid, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AmqpOutbox
+	for rows.Next() {
+		var i AmqpOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Guildid,
+			&i.Body,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAMQPOutboxForUser = `-- name: GetAMQPOutboxForUser :many
+SELECT id, guildid, body, created_at FROM amqp_outbox
+WHERE CASE WHEN json_valid(amqp_outbox.body) THEN EXISTS (
+    SELECT 1 FROM json_tree(amqp_outbox.body)
+    WHERE atom = ?1 OR key = ?1 OR atom LIKE '%<@%' || ?1 || '>%'
+) ELSE 0 END
+ORDER BY id
+`
+
+func (q *Queries) GetAMQPOutboxForUser(ctx context.Context, userID string) ([]AmqpOutbox, error) {
+	rows, err := q.db.QueryContext(ctx, getAMQPOutboxForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AmqpOutbox
+	for rows.Next() {
+		var i AmqpOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Guildid,
+			&i.Body,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAMQPOutboxStats = `-- name: GetAMQPOutboxStats :many
+SELECT guildID, COUNT(*) AS depth, CAST(MIN(created_at) AS INTEGER) AS oldest
+FROM amqp_outbox
+GROUP BY guildID
+ORDER BY guildID
+`
+
+type GetAMQPOutboxStatsRow struct {
+	Guildid string
+	Depth   int64
+	Oldest  int64
+}
+
+func (q *Queries) GetAMQPOutboxStats(ctx context.Context) ([]GetAMQPOutboxStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAMQPOutboxStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAMQPOutboxStatsRow
+	for rows.Next() {
+		var i GetAMQPOutboxStatsRow
+		if err := rows.Scan(&i.Guildid, &i.Depth, &i.Oldest); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getActiveContracts = `-- name: GetActiveContracts :many
 SELECT value->>'ContractHash' AS ContractHash,value FROM contract_data WHERE value->>'State' != 4
 `
@@ -146,6 +342,159 @@ func (q *Queries) GetContractComplaints(ctx context.Context) ([]ContractComplain
 	return items, nil
 }
 
+const getContractDataForUser = `-- name: GetContractDataForUser :many
+SELECT channelid, contractid, coopid, value FROM contract_data
+WHERE CASE WHEN json_valid(contract_data.value) THEN EXISTS (
+    SELECT 1 FROM json_tree(contract_data.value)
+    WHERE atom = ?1 OR key = ?1 OR atom LIKE '%<@%' || ?1 || '>%'
+) ELSE 0 END
+`
+
+func (q *Queries) GetContractDataForUser(ctx context.Context, userID string) ([]ContractDatum, error) {
+	rows, err := q.db.QueryContext(ctx, getContractDataForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractDatum
+	for rows.Next() {
+		var i ContractDatum
+		if err := rows.Scan(
+			&i.Channelid,
+			&i.Contractid,
+			&i.Coopid,
+			&i.Value,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getContractEvents = `-- name: GetContractEvents :many
+SELECT id, contracthash, channelid, event_time, event_type, actorid, targetid, value FROM contract_events
+WHERE contractHash = ?
+ORDER BY id
+`
+
+func (q *Queries) GetContractEvents(ctx context.Context, contracthash string) ([]ContractEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getContractEvents, contracthash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractEvent
+	for rows.Next() {
+		var i ContractEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contracthash,
+			&i.Channelid,
+			&i.EventTime,
+			&i.EventType,
+			&i.Actorid,
+			&i.Targetid,
+			&i.Value,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getContractEventsByChannel = `-- name: GetContractEventsByChannel :many
+SELECT id, contracthash, channelid, event_time, event_type, actorid, targetid, value FROM contract_events
+WHERE channelID = ?
+ORDER BY id
+`
+
+func (q *Queries) GetContractEventsByChannel(ctx context.Context, channelid string) ([]ContractEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getContractEventsByChannel, channelid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractEvent
+	for rows.Next() {
+		var i ContractEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contracthash,
+			&i.Channelid,
+			&i.EventTime,
+			&i.EventType,
+			&i.Actorid,
+			&i.Targetid,
+			&i.Value,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getContractEventsForUser = `-- name: GetContractEventsForUser :many
+SELECT id, contracthash, channelid, event_time, event_type, actorid, targetid, value FROM contract_events
+WHERE actorID = ?1 OR targetID = ?1 OR CASE WHEN json_valid(contract_events.value) THEN EXISTS (
+    SELECT 1 FROM json_tree(contract_events.value)
+    WHERE atom = ?1 OR key = ?1 OR atom LIKE '%<@%' || ?1 || '>%'
+) ELSE 0 END
+ORDER BY id
+`
+
+func (q *Queries) GetContractEventsForUser(ctx context.Context, userID string) ([]ContractEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getContractEventsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractEvent
+	for rows.Next() {
+		var i ContractEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contracthash,
+			&i.Channelid,
+			&i.EventTime,
+			&i.EventType,
+			&i.Actorid,
+			&i.Targetid,
+			&i.Value,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getContractRoles = `-- name: GetContractRoles :many
 SELECT contractID, role_name FROM contract_roles
 `
@@ -173,6 +522,317 @@ func (q *Queries) GetContractRoles(ctx context.Context) ([]ContractRole, error)
 	return items, nil
 }
 
+const getContractSchedule = `-- name: GetContractSchedule :one
+SELECT id, guildid, channelid, roleid, slot, mode, offset_minutes, play_style, template, created_by, created_at FROM contract_schedule
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetContractSchedule(ctx context.Context, id int64) (ContractSchedule, error) {
+	row := q.db.QueryRowContext(ctx, getContractSchedule, id)
+	var i ContractSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Guildid,
+		&i.Channelid,
+		&i.Roleid,
+		&i.Slot,
+		&i.Mode,
+		&i.OffsetMinutes,
+		&i.PlayStyle,
+		&i.Template,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getContractSchedules = `-- name: GetContractSchedules :many
+SELECT id, guildid, channelid, roleid, slot, mode, offset_minutes, play_style, template, created_by, created_at FROM contract_schedule
+ORDER BY id
+`
+
+func (q *Queries) GetContractSchedules(ctx context.Context) ([]ContractSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, getContractSchedules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractSchedule
+	for rows.Next() {
+		var i ContractSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Guildid,
+			&i.Channelid,
+			&i.Roleid,
+			&i.Slot,
+			&i.Mode,
+			&i.OffsetMinutes,
+			&i.PlayStyle,
+			&i.Template,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getContractSchedulesCreatedBy = `-- name: GetContractSchedulesCreatedBy :many
+SELECT id, guildid, channelid, roleid, slot, mode, offset_minutes, play_style, template, created_by, created_at FROM contract_schedule
+WHERE created_by = ?
+ORDER BY id
+`
+
+func (q *Queries) GetContractSchedulesCreatedBy(ctx context.Context, createdBy string) ([]ContractSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, getContractSchedulesCreatedBy, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractSchedule
+	for rows.Next() {
+		var i ContractSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Guildid,
+			&i.Channelid,
+			&i.Roleid,
+			&i.Slot,
+			&i.Mode,
+			&i.OffsetMinutes,
+			&i.PlayStyle,
+			&i.Template,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getContractSchedulesForGuild = `-- name: GetContractSchedulesForGuild :many
+SELECT id, guildid, channelid, roleid, slot, mode, offset_minutes, play_style, template, created_by, created_at FROM contract_schedule
+WHERE guildID = ?
+ORDER BY id
+`
+
+func (q *Queries) GetContractSchedulesForGuild(ctx context.Context, guildid string) ([]ContractSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, getContractSchedulesForGuild, guildid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractSchedule
+	for rows.Next() {
+		var i ContractSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Guildid,
+			&i.Channelid,
+			&i.Roleid,
+			&i.Slot,
+			&i.Mode,
+			&i.OffsetMinutes,
+			&i.PlayStyle,
+			&i.Template,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueContractScheduleJobs = `-- name: GetDueContractScheduleJobs :many
+SELECT id, scheduleid, slot_key, contractid, run_at, status FROM contract_schedule_job
+WHERE status = 'pending' AND run_at <= ?
+ORDER BY run_at, id
+`
+
+func (q *Queries) GetDueContractScheduleJobs(ctx context.Context, runAt int64) ([]ContractScheduleJob, error) {
+	rows, err := q.db.QueryContext(ctx, getDueContractScheduleJobs, runAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractScheduleJob
+	for rows.Next() {
+		var i ContractScheduleJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Scheduleid,
+			&i.SlotKey,
+			&i.Contractid,
+			&i.RunAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingContractScheduleJobs = `-- name: GetPendingContractScheduleJobs :many
+SELECT id, scheduleid, slot_key, contractid, run_at, status FROM contract_schedule_job
+WHERE scheduleID = ? AND status = 'pending'
+ORDER BY run_at
+`
+
+func (q *Queries) GetPendingContractScheduleJobs(ctx context.Context, scheduleid int64) ([]ContractScheduleJob, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingContractScheduleJobs, scheduleid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractScheduleJob
+	for rows.Next() {
+		var i ContractScheduleJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Scheduleid,
+			&i.SlotKey,
+			&i.Contractid,
+			&i.RunAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDeadLetters = `-- name: GetWebhookDeadLetters :many
+SELECT id, guildid, url, body, error, attempts, created_at FROM webhook_dead_letters
+WHERE guildID = ?
+ORDER BY id
+`
+
+func (q *Queries) GetWebhookDeadLetters(ctx context.Context, guildid string) ([]WebhookDeadLetter, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeadLetters, guildid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDeadLetter
+	for rows.Next() {
+		var i WebhookDeadLetter
+		if err := rows.Scan(
+			&i.ID,
+			&i.Guildid,
+			&i.Url,
+			&i.Body,
+			&i.Error,
+			&i.Attempts,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDeadLettersForUser = `-- name: GetWebhookDeadLettersForUser :many
+SELECT id, guildid, url, body, error, attempts, created_at FROM webhook_dead_letters
+WHERE CASE WHEN json_valid(webhook_dead_letters.body) THEN EXISTS (
+    SELECT 1 FROM json_tree(webhook_dead_letters.body)
+    WHERE atom = ?1 OR key = ?1 OR atom LIKE '%<@%' || ?1 || '>%'
+) ELSE 0 END
+ORDER BY id
+`
+
+func (q *Queries) GetWebhookDeadLettersForUser(ctx context.Context, userID string) ([]WebhookDeadLetter, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeadLettersForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDeadLetter
+	for rows.Next() {
+		var i WebhookDeadLetter
+		if err := rows.Scan(
+			&i.ID,
+			&i.Guildid,
+			&i.Url,
+			&i.Body,
+			&i.Error,
+			&i.Attempts,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAMQPOutbox = `-- name: InsertAMQPOutbox :exec
+INSERT INTO amqp_outbox (guildID, body, created_at)
+VALUES (?, ?, ?)
+`
+
+type InsertAMQPOutboxParams struct {
+	Guildid   string
+	Body      string
+	CreatedAt int64
+}
+
+func (q *Queries) InsertAMQPOutbox(ctx context.Context, arg InsertAMQPOutboxParams) error {
+	_, err := q.db.ExecContext(ctx, insertAMQPOutbox, arg.Guildid, arg.Body, arg.CreatedAt)
+	return err
+}
+
 const insertContract = `-- name: InsertContract :exec
 INSERT INTO contract_data (channelID, contractID, coopID, value)
 VALUES (?, ?, ?, ?)
@@ -210,6 +870,34 @@ func (q *Queries) InsertContractComplaint(ctx context.Context, arg InsertContrac
 	return err
 }
 
+const insertContractEvent = `-- name: InsertContractEvent :exec
+INSERT INTO contract_events (contractHash, channelID, event_time, event_type, actorID, targetID, value)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type InsertContractEventParams struct {
+	Contracthash string
+	Channelid    string
+	EventTime    int64
+	EventType    string
+	Actorid      string
+	Targetid     string
+	Value        sql.NullString
+}
+
+func (q *Queries) InsertContractEvent(ctx context.Context, arg InsertContractEventParams) error {
+	_, err := q.db.ExecContext(ctx, insertContractEvent,
+		arg.Contracthash,
+		arg.Channelid,
+		arg.EventTime,
+		arg.EventType,
+		arg.Actorid,
+		arg.Targetid,
+		arg.Value,
+	)
+	return err
+}
+
 const insertContractRole = `-- name: InsertContractRole :exec
 INSERT INTO contract_roles (contractID, role_name) VALUES (?, ?)
 ON CONFLICT(contractID, role_name) DO NOTHING
@@ -225,6 +913,118 @@ func (q *Queries) InsertContractRole(ctx context.Context, arg InsertContractRole
 	return err
 }
 
+const insertContractSchedule = `-- name: InsertContractSchedule :one
+INSERT INTO contract_schedule (guildID, channelID, roleID, slot, mode, offset_minutes, play_style, template, created_by, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, guildid, channelid, roleid, slot, mode, offset_minutes, play_style, template, created_by, created_at
+`
+
+type InsertContractScheduleParams struct {
+	Guildid       string
+	Channelid     string
+	Roleid        string
+	Slot          string
+	Mode          string
+	OffsetMinutes int64
+	PlayStyle     int64
+	Template      string
+	CreatedBy     string
+	CreatedAt     int64
+}
+
+func (q *Queries) InsertContractSchedule(ctx context.Context, arg InsertContractScheduleParams) (ContractSchedule, error) {
+	row := q.db.QueryRowContext(ctx, insertContractSchedule,
+		arg.Guildid,
+		arg.Channelid,
+		arg.Roleid,
+		arg.Slot,
+		arg.Mode,
+		arg.OffsetMinutes,
+		arg.PlayStyle,
+		arg.Template,
+		arg.CreatedBy,
+		arg.CreatedAt,
+	)
+	var i ContractSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Guildid,
+		&i.Channelid,
+		&i.Roleid,
+		&i.Slot,
+		&i.Mode,
+		&i.OffsetMinutes,
+		&i.PlayStyle,
+		&i.Template,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertContractScheduleJob = `-- name: InsertContractScheduleJob :exec
+INSERT INTO contract_schedule_job (scheduleID, slot_key, contractID, run_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(scheduleID, slot_key) DO NOTHING
+`
+
+type InsertContractScheduleJobParams struct {
+	Scheduleid int64
+	SlotKey    string
+	Contractid string
+	RunAt      int64
+}
+
+func (q *Queries) InsertContractScheduleJob(ctx context.Context, arg InsertContractScheduleJobParams) error {
+	_, err := q.db.ExecContext(ctx, insertContractScheduleJob,
+		arg.Scheduleid,
+		arg.SlotKey,
+		arg.Contractid,
+		arg.RunAt,
+	)
+	return err
+}
+
+const insertWebhookDeadLetter = `-- name: InsertWebhookDeadLetter :exec
+INSERT INTO webhook_dead_letters (guildID, url, body, error, attempts, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type InsertWebhookDeadLetterParams struct {
+	Guildid   string
+	Url       string
+	Body      string
+	Error     string
+	Attempts  int64
+	CreatedAt int64
+}
+
+func (q *Queries) InsertWebhookDeadLetter(ctx context.Context, arg InsertWebhookDeadLetterParams) error {
+	_, err := q.db.ExecContext(ctx, insertWebhookDeadLetter,
+		arg.Guildid,
+		arg.Url,
+		arg.Body,
+		arg.Error,
+		arg.Attempts,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const updateAMQPOutboxBody = `-- name: UpdateAMQPOutboxBody :exec
+UPDATE amqp_outbox SET body = ? WHERE id = ?
+`
+
+type UpdateAMQPOutboxBodyParams struct {
+	Body string
+	ID   int64
+}
+
+func (q *Queries) UpdateAMQPOutboxBody(ctx context.Context, arg UpdateAMQPOutboxBodyParams) error {
+	_, err := q.db.ExecContext(ctx, updateAMQPOutboxBody, arg.Body, arg.ID)
+	return err
+}
+
 const updateContract = `-- name: UpdateContract :execrows
 UPDATE contract_data
 SET value = ?
@@ -276,6 +1076,43 @@ func (q *Queries) UpdateContractCoopID(ctx context.Context, arg UpdateContractCo
 	return err
 }
 
+const updateContractEvent = `-- name: UpdateContractEvent :exec
+UPDATE contract_events
+SET actorID = ?, targetID = ?, value = ?
+WHERE id = ?
+`
+
+type UpdateContractEventParams struct {
+	Actorid  string
+	Targetid string
+	Value    sql.NullString
+	ID       int64
+}
+
+func (q *Queries) UpdateContractEvent(ctx context.Context, arg UpdateContractEventParams) error {
+	_, err := q.db.ExecContext(ctx, updateContractEvent,
+		arg.Actorid,
+		arg.Targetid,
+		arg.Value,
+		arg.ID,
+	)
+	return err
+}
+
+const updateContractScheduleJobStatus = `-- name: UpdateContractScheduleJobStatus :exec
+UPDATE contract_schedule_job SET status = ? WHERE id = ?
+`
+
+type UpdateContractScheduleJobStatusParams struct {
+	Status string
+	ID     int64
+}
+
+func (q *Queries) UpdateContractScheduleJobStatus(ctx context.Context, arg UpdateContractScheduleJobStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateContractScheduleJobStatus, arg.Status, arg.ID)
+	return err
+}
+
 const updateContractState = `-- name: UpdateContractState :exec
 UPDATE contract_data
 SET value = json_replace(value, '$.State', ?)
@@ -298,3 +1135,31 @@ func (q *Queries) UpdateContractState(ctx context.Context, arg UpdateContractSta
 	)
 	return err
 }
+
+const updateContractValue = `-- name: UpdateContractValue :exec
+UPDATE contract_data SET value = ? WHERE channelID = ?
+`
+
+type UpdateContractValueParams struct {
+	Value     sql.NullString
+	Channelid string
+}
+
+func (q *Queries) UpdateContractValue(ctx context.Context, arg UpdateContractValueParams) error {
+	_, err := q.db.ExecContext(ctx, updateContractValue, arg.Value, arg.Channelid)
+	return err
+}
+
+const updateWebhookDeadLetterBody = `-- name: UpdateWebhookDeadLetterBody :exec
+UPDATE webhook_dead_letters SET body = ? WHERE id = ?
+`
+
+type UpdateWebhookDeadLetterBodyParams struct {
+	Body string
+	ID   int64
+}
+
+func (q *Queries) UpdateWebhookDeadLetterBody(ctx context.Context, arg UpdateWebhookDeadLetterBodyParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhookDeadLetterBody, arg.Body, arg.ID)
+	return err
+}
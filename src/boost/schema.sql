@@ -17,3 +17,64 @@ CREATE TABLE IF NOT EXISTS contract_complaints (
     PRIMARY KEY (contractID, complaint)
 );
 
+
+CREATE TABLE IF NOT EXISTS contract_events (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    contractHash text NOT NULL,
+    channelID    text NOT NULL,
+    event_time   INTEGER NOT NULL, -- Unix milliseconds
+    event_type   text NOT NULL,
+    actorID      text NOT NULL DEFAULT '',
+    targetID     text NOT NULL DEFAULT '',
+    value        text -- Store JSON data as TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_events_hash ON contract_events(contractHash, id);
+CREATE INDEX IF NOT EXISTS idx_contract_events_channel ON contract_events(channelID, id);
+
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    guildID     text NOT NULL,
+    url         text NOT NULL,
+    body        text NOT NULL, -- JSON payload that failed delivery
+    error       text NOT NULL,
+    attempts    INTEGER NOT NULL,
+    created_at  INTEGER NOT NULL -- Unix seconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_guild ON webhook_dead_letters(guildID, id);
+
+CREATE TABLE IF NOT EXISTS amqp_outbox (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    guildID     text NOT NULL,
+    body        text NOT NULL, -- JSON message waiting for the broker
+    created_at  INTEGER NOT NULL -- Unix milliseconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_amqp_outbox_guild ON amqp_outbox(guildID, id);
+
+CREATE TABLE IF NOT EXISTS contract_schedule (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    guildID         text NOT NULL,
+    channelID       text NOT NULL,
+    roleID          text NOT NULL DEFAULT '',
+    slot            text NOT NULL, -- monday, wednesday, friday or ultra
+    mode            text NOT NULL, -- predicted or live
+    offset_minutes  INTEGER NOT NULL DEFAULT 0,
+    play_style      INTEGER NOT NULL,
+    template        text NOT NULL DEFAULT '',
+    created_by      text NOT NULL,
+    created_at      INTEGER NOT NULL -- Unix seconds
+);
+
+CREATE TABLE IF NOT EXISTS contract_schedule_job (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    scheduleID  INTEGER NOT NULL,
+    slot_key    text NOT NULL, -- slot and drop date, e.g. wednesday-2024-05-15
+    contractID  text NOT NULL,
+    run_at      INTEGER NOT NULL, -- Unix seconds
+    status      text NOT NULL DEFAULT 'pending',
+    UNIQUE (scheduleID, slot_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_schedule_job_due ON contract_schedule_job(status, run_at);
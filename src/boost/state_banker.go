@@ -38,6 +38,7 @@ func buttonReactionBag(s *discordgo.Session, GuildID string, ChannelID string, c
 			//	sink.TokensReceived -= b.TokensWanted
 			//	sink.TokensReceived = max(0, sink.TokensReceived) // Avoid missing self farmed tokens
 			contract.TokenLog = append(contract.TokenLog, ei.TokenUnitLog{Time: time.Now(), Quantity: b.TokensWanted, FromUserID: cUserID, FromNick: contract.Boosters[cUserID].Nick, ToUserID: b.UserID, ToNick: b.Nick, Serial: xid.New().String(), Boost: true})
+			recordContractTokenEvent(contract, contract.TokenLog[len(contract.TokenLog)-1])
 			sink.TokensReceived = getTokensReceivedFromLog(contract, sink.UserID) - getTokensSentFromLog(contract, sink.UserID)
 		} else {
 			log.Printf("Sink sent %d tokens to booster\n", b.TokensWanted)
@@ -52,7 +53,9 @@ func buttonReactionBag(s *discordgo.Session, GuildID string, ChannelID string, c
 			contract.mutex.Lock()
 
 			contract.TokenLog = append(contract.TokenLog, ei.TokenUnitLog{Time: time.Now(), Quantity: tokensToSend, FromUserID: cUserID, FromNick: contract.Boosters[cUserID].Nick, ToUserID: b.UserID, ToNick: b.Nick, Serial: tokenSerial, Boost: true})
+			sentToken := contract.TokenLog[len(contract.TokenLog)-1]
 			contract.mutex.Unlock()
+			recordContractTokenEvent(contract, sentToken)
 			//if contract.BoostOrder == ContractOrderTVal {
 			tval := bottools.GetTokenValue(time.Since(contract.StartTime).Seconds(), contract.EstimatedDuration.Seconds())
 			contract.Boosters[cUserID].TokenValue += tval * float64(tokensToSend)
@@ -60,12 +63,7 @@ func buttonReactionBag(s *discordgo.Session, GuildID string, ChannelID string, c
 
 			// Set the estimated boost timings for the booster
 			setEstimatedBoostTimings(b)
-			/*
-				if contract.Style&ContractFlagDynamicTokens != 0 {
-					// Determine the dynamic tokens
-					determineDynamicTokens(contract)
-				}
-			*/
+			determineDynamicTokens(contract)
 		}
 
 		str := fmt.Sprintf("**%s** ", contract.Boosters[b.UserID].Mention)
@@ -76,7 +74,7 @@ func buttonReactionBag(s *discordgo.Session, GuildID string, ChannelID string, c
 
 		_, _ = s.ChannelMessageSend(contract.Location[0].ChannelID, str)
 
-		_ = Boosting(s, GuildID, ChannelID)
+		_ = Boosting(s, GuildID, ChannelID, cUserID)
 
 		return false, redraw
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/contractsim"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
 )
@@ -332,12 +333,9 @@ func DownloadCoopStatusTeamwork(channelID string, contractID string, coopID stri
 	contribution := make([]float64, len(coopStatus.GetContributors()))
 	contractDurationInDays := int(math.Ceil(float64(eiContract.Grade[grade].LengthInSeconds) / 86400.0))
 
-	var totalContributions float64
 	var contributionRatePerSecond float64
 	// Need to figure out how much longer this contract will run
 	for _, c := range coopStatus.GetContributors() {
-		totalContributions += c.GetContributionAmount()
-		totalContributions += -(c.GetContributionRate() * c.GetFarmInfo().GetTimestamp()) // offline eggs
 		contributionRatePerSecond += c.GetContributionRate()
 	}
 
@@ -359,7 +357,8 @@ func DownloadCoopStatusTeamwork(channelID string, contractID string, coopID stri
 		prefix = "Est. "
 		startTime = startTime.Add(time.Duration(secondsRemaining) * time.Second)
 		startTime = startTime.Add(-time.Duration(eiContract.Grade[grade].LengthInSeconds) * time.Second)
-		calcSecondsRemaining = (totalRequired - totalContributions) / contributionRatePerSecond
+		simulation := contractsim.Run(contractSimulation(FindContractByIDs(channelID, contractID, coopID), coopStatus, totalRequired))
+		calcSecondsRemaining = simulation.Duration.Seconds()
 		endTime = nowTime.Add(time.Duration(calcSecondsRemaining) * time.Second)
 		contractDurationSeconds = endTime.Sub(startTime).Seconds()
 		fmt.Fprintf(&builder, "In Progress %s %s/[**%s**](%s)\nOn target to complete %s\n", ei.GetBotEmojiMarkdown("contract_grade_"+ei.GetContractGradeString(grade)), coopStatus.GetContractIdentifier(), coopStatus.GetCoopIdentifier(), fmt.Sprintf("%s/%s/%s", "https://eicoop-carpet.netlify.app", contractID, coopID), bottools.WrapTimestamp(endTime.Unix(), bottools.TimestampRelativeTime))
@@ -0,0 +1,91 @@
+package boost
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei/eifake"
+)
+
+// startFakeCoop serves the recorded fake-contract/fake-coop status from a
+// fake API server and registers a matching contract definition.
+func startFakeCoop(t *testing.T) (*eifake.Server, *ei.ContractCoopStatusResponse) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir temp dir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	status := &ei.ContractCoopStatusResponse{}
+	if err := eifake.LoadFixture(eifake.FixtureCoopStatus, status); err != nil {
+		t.Fatalf("load coop status: %v", err)
+	}
+
+	grades := make([]ei.ContractGrade, 6)
+	grades[ei.Contract_GRADE_AAA] = ei.ContractGrade{
+		TargetAmount:    []float64{1e15, 5e15},
+		LengthInSeconds: 4 * 86400,
+	}
+	previous, existed := ei.EggIncContractsAll[status.GetContractIdentifier()]
+	ei.EggIncContractsAll[status.GetContractIdentifier()] = ei.EggIncContract{
+		ID:              status.GetContractIdentifier(),
+		Name:            "Fake Contract",
+		MaxCoopSize:     4,
+		ChickenRuns:     3,
+		TargetAmount:    []float64{1e15, 5e15},
+		LengthInSeconds: 4 * 86400,
+		Grade:           grades,
+	}
+	t.Cleanup(func() {
+		if existed {
+			ei.EggIncContractsAll[status.GetContractIdentifier()] = previous
+		} else {
+			delete(ei.EggIncContractsAll, status.GetContractIdentifier())
+		}
+	})
+
+	server := eifake.Start(t)
+	if err := server.CoopStatus(status); err != nil {
+		t.Fatalf("register coop status: %v", err)
+	}
+	return server, status
+}
+
+func TestDownloadCoopStatusTeamworkFromFakeServer(t *testing.T) {
+	server, status := startFakeCoop(t)
+
+	summary, fields, _ := DownloadCoopStatusTeamwork("", status.GetContractIdentifier(), status.GetCoopIdentifier(), false, "")
+	if !strings.Contains(summary, "In Progress") {
+		t.Fatalf("expected an in progress summary, got %q", summary)
+	}
+	for _, c := range status.GetContributors() {
+		name := strings.ToLower(c.GetUserName())
+		if len(fields[name]) == 0 {
+			t.Fatalf("expected teamwork output for %s, got keys %v", name, fields)
+		}
+	}
+	if len(server.Requests()) != 1 {
+		t.Fatalf("expected a single coop status request, got %d", len(server.Requests()))
+	}
+}
+
+func TestDownloadCoopStatusTeamworkUnknownContract(t *testing.T) {
+	server, _ := startFakeCoop(t)
+
+	summary, fields, _ := DownloadCoopStatusTeamwork("", "not-a-contract", "fake-coop", false, "")
+	if summary != "Invalid contract ID." || fields != nil {
+		t.Fatalf("unexpected result %q %v", summary, fields)
+	}
+	if len(server.Requests()) != 0 {
+		t.Fatalf("expected no API requests for an unknown contract")
+	}
+}
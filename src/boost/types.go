@@ -53,22 +53,6 @@ var contractPlaystyleNames = []string{
 	"GG Rerun",
 }
 
-var contractOrderNames = []string{
-	"Signup",       // ContractOrderSignup
-	"Reverse",      // ContractOrderReverse
-	"Random",       // ContractOrderRandom
-	"Fair",         // ContractOrderFair
-	"Time-Based",   // ContractOrderTimeBased
-	"ELR",          // ContractOrderELR
-	"TVal",         // ContractOrderTVal
-	"Token-Ask",    // ContractOrderTokenAsk
-	"TE",           // ContractOrderTE
-	"Fuzzy TE",     // ContractOrderTEFuzzy
-	"Manual",       // ContractManualOrder
-	"Boosting IHR", // ContractOrderIHR
-	"Fuzzy IHR",    // ContractOrderIHRFuzzy
-}
-
 var contractFlagNames = []struct {
 	Flag int64
 	Name string
@@ -83,19 +67,20 @@ var contractFlagNames = []struct {
 
 // Constnts for the contract
 const (
-	ContractOrderSignup    = 0  // Signup order
-	ContractOrderReverse   = 1  // Reverse order
-	ContractOrderRandom    = 2  // Randomized when the contract starts. After 20 minutes the order changes to Sign-up.
-	ContractOrderFair      = 3  // Fair based on position percentile of each farmers last 5 contracts. Those with no history use 50th percentile
-	ContractOrderTimeBased = 4  // Time based order
-	ContractOrderELR       = 5  // ELR based order
-	ContractOrderTVal      = 6  // Token Value based order
-	ContractOrderTokenAsk  = 7  // Token Ask order, less tokens boosts earlier
-	ContractOrderTE        = 8  // Truth Egg based order
-	ContractOrderTEFuzzy   = 9  // Truth Egg + randomization
-	ContractManualOrder    = 10 // Manual order set by contract creator
-	ContractOrderIHR       = 11 // IHR based order
-	ContractOrderIHRFuzzy  = 12 // Fuzzy IHR based order
+	ContractOrderSignup       = 0  // Signup order
+	ContractOrderReverse      = 1  // Reverse order
+	ContractOrderRandom       = 2  // Randomized when the contract starts. After 20 minutes the order changes to Sign-up.
+	ContractOrderFair         = 3  // Fair based on position percentile of each farmers last 5 contracts. Those with no history use 50th percentile
+	ContractOrderTimeBased    = 4  // Time based order
+	ContractOrderELR          = 5  // ELR based order
+	ContractOrderTVal         = 6  // Token Value based order
+	ContractOrderTokenAsk     = 7  // Token Ask order, less tokens boosts earlier
+	ContractOrderTE           = 8  // Truth Egg based order
+	ContractOrderTEFuzzy      = 9  // Truth Egg + randomization
+	ContractManualOrder       = 10 // Manual order set by contract creator
+	ContractOrderIHR          = 11 // IHR based order
+	ContractOrderIHRFuzzy     = 12 // Fuzzy IHR based order
+	ContractOrderAvailability = 13 // Turns scheduled inside each farmer's availability
 
 	ContractStateSignup    = 0 // Contract is in signup phase
 	ContractStateFastrun   = 1 // Contract in Boosting as fastrun
@@ -205,6 +190,7 @@ type Booster struct {
 	BoostState             int                  // Indicates if current booster
 	TokensReceived         int                  // indicate number of boost tokens
 	TokensWanted           int                  // indicate number of boost tokens
+	TokensWantedManual     bool                 // TokensWanted was set by the farmer, dynamic tokens leave it alone
 	TokenValue             float64              // Current Token Value
 	TokenRequestFlag       bool                 // Flag to indicate if the token request is active
 	StartTime              time.Time            // Time Farmer started boost turn
@@ -355,7 +341,9 @@ type Contract struct {
 	LastSaveTime               time.Time // The last time the contract was saved
 	ThematicComplaints         []string  `json:"thematic_complaints,omitempty"`
 
-	mutex sync.Mutex // Keep this contract thread safe
+	availabilityConflicts []string   // Farmers the availability order couldn't fit, reported at start
+	dynamicTokenInputs    string     // Inputs of the last dynamic token plan
+	mutex                 sync.Mutex // Keep this contract thread safe
 }
 
 // Bookmark represents a bookmark for a specific channel in the dashboard
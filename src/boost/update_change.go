@@ -85,6 +85,13 @@ func GetSlashChangeCommand(cmd string) *discordgo.ApplicationCommand {
 						Description: "Change the current booster. Example: @farmer",
 						Required:    false,
 					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "strategy",
+						Description: "Switch to a boost ordering strategy",
+						Required:    false,
+						Choices:     boostOrderChoices(),
+					},
 				},
 			}},
 	}
@@ -106,6 +113,7 @@ func HandleChangeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	contractIDValue := ""
 	currentBooster := ""
 	boostOrder := ""
+	boostStrategy := -1
 
 	// Get the subcommand group and subcommand from nested options
 	if len(i.ApplicationCommandData().Options) > 0 {
@@ -135,6 +143,9 @@ func HandleChangeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		if opt, ok := optionMap["order-boost-order"]; ok {
 			boostOrder = strings.TrimSpace(opt.StringValue())
 		}
+		if opt, ok := optionMap["order-strategy"]; ok {
+			boostStrategy = int(opt.IntValue())
+		}
 	}
 
 	resultMsg := ""
@@ -190,7 +201,20 @@ func HandleChangeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			resultMsg = "❌ Contract not found in this channel"
 		} else {
 			defer saveData(contract.ContractHash)
+			if boostStrategy >= 0 {
+				resultStr, err := ChangeBoostOrderStrategy(s, i.ChannelID, i.Member.User.ID, boostStrategy)
+				if err != nil {
+					resultMsg += fmt.Sprintf("❌ %s", err.Error())
+				} else {
+					resultMsg += fmt.Sprintf("✅ %s", resultStr)
+					refreshBoostListMessage(s, contract, false)
+				}
+			}
+
 			if boostOrder != "" {
+				if resultMsg != "" {
+					resultMsg += "\n"
+				}
 				resultStr, err := ChangeBoostOrder(s, i.GuildID, i.ChannelID, i.Member.User.ID, boostOrder, currentBooster == "")
 				if err != nil {
 					resultMsg += fmt.Sprintf("❌ %s", err.Error())
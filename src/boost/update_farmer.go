@@ -302,6 +302,7 @@ func updateFarmerInContracts(s *discordgo.Session, userID string, subcommand str
 			switch subcommand {
 			case "boost-tokens":
 				booster.TokensWanted = int(value)
+				booster.TokensWantedManual = true
 			case "te":
 				booster.TECount = int(value)
 				rate, logStr := CalculateIHRRateFromDB(userID)
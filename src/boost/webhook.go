@@ -0,0 +1,161 @@
+package boost
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers sent with every webhook delivery. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	WebhookTimestampHeader = "X-TTBB-Timestamp"
+	WebhookSignatureHeader = "X-TTBB-Signature"
+)
+
+// webhookRetryDelays is the backoff between delivery attempts
+var webhookRetryDelays = []time.Duration{2 * time.Second, 10 * time.Second, 30 * time.Second}
+
+var (
+	webhookClients   = make(map[string]*WebhookClient)
+	webhookClientsMu sync.Mutex
+)
+
+// WebhookClient posts contract events for a single guild to an HTTP endpoint.
+type WebhookClient struct {
+	guildID     string
+	url         string
+	secret      string
+	httpClient  *http.Client
+	retryDelays []time.Duration
+	mu          sync.Mutex
+}
+
+// getWebhookClient retrieves or creates a webhook client for a guild.
+func getWebhookClient(guildID string, url string, secret string) *WebhookClient {
+	webhookClientsMu.Lock()
+	defer webhookClientsMu.Unlock()
+
+	client, exists := webhookClients[guildID]
+	if !exists || client.url != url || client.secret != secret {
+		if exists {
+			client.Close()
+		}
+		if secret == "" {
+			log.Printf("Webhook: guild %s has no %s, deliveries are unsigned", guildID, webhookSecretSetting)
+		}
+		client = newWebhookClient(guildID, url, secret)
+		webhookClients[guildID] = client
+	}
+	return client
+}
+
+func newWebhookClient(guildID string, url string, secret string) *WebhookClient {
+	return &WebhookClient{
+		guildID:     guildID,
+		url:         url,
+		secret:      secret,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		retryDelays: webhookRetryDelays,
+	}
+}
+
+// SignWebhookBody returns the signature header value for a webhook body.
+func SignWebhookBody(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks a signature produced by SignWebhookBody.
+func VerifyWebhookSignature(secret string, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookBody(secret, timestamp, body)), []byte(signature))
+}
+
+// Close releases idle connections held by the client.
+func (c *WebhookClient) Close() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// Publish posts the body to the webhook, retrying with backoff. When every
+// attempt fails the body is kept in the dead-letter table.
+func (c *WebhookClient) Publish(body []byte) error {
+	attempts := 0
+	var err error
+	for {
+		var retry bool
+		attempts++
+		// Hold the lock for a single attempt only so the backoff
+		// doesn't stall every other event for this guild
+		c.mu.Lock()
+		retry, err = c.deliver(body)
+		c.mu.Unlock()
+		if err == nil {
+			return nil
+		}
+		if !retry || attempts > len(c.retryDelays) {
+			break
+		}
+		time.Sleep(c.retryDelays[attempts-1])
+	}
+
+	c.deadLetter(body, err, attempts)
+	return err
+}
+
+// deliver makes a single delivery attempt and reports whether a failure is worth retrying.
+func (c *WebhookClient) deliver(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TokenTimeBoostBot")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	if c.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookBody(c.secret, timestamp, body))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook returned %s", resp.Status)
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, err
+}
+
+// deadLetter persists an undeliverable body so it isn't silently lost
+func (c *WebhookClient) deadLetter(body []byte, deliveryErr error, attempts int) {
+	if queries == nil {
+		sqliteInit()
+	}
+	err := queries.InsertWebhookDeadLetter(ctx, InsertWebhookDeadLetterParams{
+		Guildid:   c.guildID,
+		Url:       c.url,
+		Body:      string(body),
+		Error:     deliveryErr.Error(),
+		Attempts:  int64(attempts),
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		log.Printf("Webhook: error saving dead letter for guild %s: %v", c.guildID, err)
+	}
+}
@@ -0,0 +1,178 @@
+package boost
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func useInMemoryContractDB(t *testing.T) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory db: %v", err)
+	}
+	if _, err = db.Exec(ddl); err != nil {
+		t.Fatalf("failed to execute DDL: %v", err)
+	}
+
+	origQueries := queries
+	origDBConn := dbConn
+	t.Cleanup(func() {
+		queries = origQueries
+		dbConn = origDBConn
+		_ = db.Close()
+	})
+	dbConn = db
+	queries = New(db)
+}
+
+func TestWebhookPublishSignsAndRetries(t *testing.T) {
+	useInMemoryContractDB(t)
+
+	const secret = "s3cret"
+	var calls atomic.Int32
+	received := make(chan AMQPTokenMessage, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhookSignature(secret, r.Header.Get(WebhookTimestampHeader), body, r.Header.Get(WebhookSignatureHeader)) {
+			t.Errorf("invalid signature %q", r.Header.Get(WebhookSignatureHeader))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// Fail the first delivery to exercise the retry
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var msg AMQPTokenMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Errorf("bad payload: %v", err)
+		}
+		received <- msg
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client := newWebhookClient("guild-1", srv.URL, secret)
+	client.retryDelays = []time.Duration{time.Millisecond, time.Millisecond}
+
+	body, _ := json.Marshal(AMQPTokenMessage{Event: "token_transfer", ContractID: "c1", Quantity: 2})
+	if err := client.Publish(body); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if msg.ContractID != "c1" || msg.Quantity != 2 {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	default:
+		t.Fatal("webhook did not receive the message")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 delivery attempts, got %d", got)
+	}
+
+	letters, err := queries.GetWebhookDeadLetters(ctx, "guild-1")
+	if err != nil || len(letters) != 0 {
+		t.Errorf("expected no dead letters, got %d (err=%v)", len(letters), err)
+	}
+}
+
+func TestWebhookPublishDeadLetters(t *testing.T) {
+	useInMemoryContractDB(t)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client := newWebhookClient("guild-2", srv.URL, "")
+	client.retryDelays = []time.Duration{time.Millisecond, time.Millisecond}
+
+	body := []byte(`{"event":"non_token"}`)
+	if err := client.Publish(body); err == nil {
+		t.Fatal("expected Publish to fail")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 delivery attempts, got %d", got)
+	}
+
+	letters, err := queries.GetWebhookDeadLetters(ctx, "guild-2")
+	if err != nil {
+		t.Fatalf("GetWebhookDeadLetters failed: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(letters))
+	}
+	if letters[0].Body != string(body) || letters[0].Attempts != 3 || letters[0].Url != srv.URL {
+		t.Errorf("unexpected dead letter %+v", letters[0])
+	}
+}
+
+func TestWebhookClientErrorIsNotRetried(t *testing.T) {
+	useInMemoryContractDB(t)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	client := newWebhookClient("guild-3", srv.URL, "")
+	client.retryDelays = []time.Duration{time.Millisecond}
+	if err := client.Publish([]byte(`{}`)); err == nil {
+		t.Fatal("expected Publish to fail")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected a single attempt for a 4xx response, got %d", got)
+	}
+}
+
+func TestWebhookBackoffDoesNotBlockOtherEvents(t *testing.T) {
+	useInMemoryContractDB(t)
+
+	var failed atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		// Fail the first delivery of the slow event so it sits in its backoff
+		if string(body) == "slow" && failed.CompareAndSwap(false, true) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client := newWebhookClient("guild-4", srv.URL, "")
+	client.retryDelays = []time.Duration{time.Second}
+
+	slow := make(chan error, 1)
+	go func() { slow <- client.Publish([]byte("slow")) }()
+	for !failed.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	if err := client.Publish([]byte("fast")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if waited := time.Since(start); waited >= time.Second/2 {
+		t.Errorf("second event waited %v behind the first event's backoff", waited)
+	}
+	if err := <-slow; err != nil {
+		t.Errorf("retried Publish failed: %v", err)
+	}
+}
@@ -318,6 +318,31 @@ func WrapTimestamp[N TimestampNumber](ts N, format DiscordTimestampFormat) strin
 	return fmt.Sprintf("<t:%d:%s>", ts, format)
 }
 
+// timeZoneAbbreviations maps the common abbreviations farmers use to locations
+var timeZoneAbbreviations = map[string]string{
+	"PT": "America/Los_Angeles", "PST": "America/Los_Angeles", "PDT": "America/Los_Angeles",
+	"MT": "America/Denver", "MST": "America/Denver", "MDT": "America/Denver",
+	"CT": "America/Chicago", "CST": "America/Chicago", "CDT": "America/Chicago",
+	"ET": "America/New_York", "EST": "America/New_York", "EDT": "America/New_York",
+	"UTC": "UTC", "GMT": "UTC", "Z": "UTC",
+	"BST": "Europe/London", "CET": "Europe/Berlin", "CEST": "Europe/Berlin",
+	"IST": "Asia/Kolkata", "JST": "Asia/Tokyo",
+	"AEST": "Australia/Sydney", "AEDT": "Australia/Sydney",
+}
+
+// ParseTimeZone resolves a time zone abbreviation such as PT or an IANA name
+func ParseTimeZone(zone string) (*time.Location, error) {
+	zone = strings.TrimSpace(zone)
+	if name, ok := timeZoneAbbreviations[strings.ToUpper(zone)]; ok {
+		zone = name
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", zone)
+	}
+	return loc, nil
+}
+
 // RefreshMap creates and returns a shallow copy of the given map
 func RefreshMap[K comparable, V any](m map[K]V) map[K]V {
 	newMap := make(map[K]V, len(m))
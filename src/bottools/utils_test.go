@@ -118,3 +118,23 @@ Let me know if you need more!`,
 		})
 	}
 }
+
+func TestParseTimeZone(t *testing.T) {
+	tests := []struct {
+		zone string
+		want string
+	}{
+		{"PT", "America/Los_Angeles"},
+		{" est ", "America/New_York"},
+		{"Europe/Paris", "Europe/Paris"},
+	}
+	for _, tt := range tests {
+		loc, err := ParseTimeZone(tt.zone)
+		if err != nil || loc.String() != tt.want {
+			t.Errorf("ParseTimeZone(%q) = %v, %v, want %s", tt.zone, loc, err, tt.want)
+		}
+	}
+	if _, err := ParseTimeZone("Mars/Olympus"); err == nil {
+		t.Error("ParseTimeZone(Mars/Olympus) should fail")
+	}
+}
@@ -50,6 +50,10 @@ var (
 	DevelopmentStaff []string
 	// Key is the encryption key used for encrypting sensitive data.
 	Key string
+	// APIListenAddr is the address for the read-only contract HTTP API, empty to disable.
+	APIListenAddr string
+	// LeaderboardKeepHistory keeps thinned history for leaderboards that otherwise keep only the latest snapshot.
+	LeaderboardKeepHistory bool
 
 	config *configStruct
 )
@@ -76,6 +80,9 @@ type configStruct struct {
 	BannerURL        string   `json:"BannerURL"`
 	DevelopmentStaff []string `json:"DevelopmentStaff"`
 	Key              string   `json:"Key"`
+	APIListenAddr    string   `json:"APIListenAddr"`
+
+	LeaderboardKeepHistory bool `json:"LeaderboardKeepHistory"`
 }
 
 // ReadConfig will load the configuration files for API tokens.
@@ -115,6 +122,8 @@ func ReadConfig(cfgFile string) error {
 	BannerURL = config.BannerURL
 	DevelopmentStaff = config.DevelopmentStaff
 	Key = config.Key
+	APIListenAddr = config.APIListenAddr
+	LeaderboardKeepHistory = config.LeaderboardKeepHistory
 
 	if Key == "" {
 		// We need a encryption key for a few things, if it's missing
@@ -0,0 +1,256 @@
+// Package contractsim steps a coop through a contract minute by minute so
+// every completion estimate in the bot shares one model of population
+// growth, boosting, token arrivals, chicken runs and coop buffs.
+package contractsim
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Defaults used when a Coop leaves them unset
+const (
+	DefaultStep       = time.Minute
+	DefaultSample     = 10 * time.Minute
+	DefaultChickenRun = 0.05 // share of the habs filled by each chicken run
+	// ChickenRunLimit is the most of the habs the chicken runs fill once a
+	// boost ends, however large the coop. The boost fills the rest.
+	ChickenRunLimit = 0.30
+)
+
+// Farmer is one coop member at the start of a simulation
+type Farmer struct {
+	Name            string       `json:"name"`
+	Population      float64      `json:"population"`             // chickens on the farm
+	HabCapacity     float64      `json:"hab_capacity"`           // chickens the habs hold with a gusset equipped
+	IHR             float64      `json:"ihr"`                    // chickens per minute across the farm, unboosted
+	LayingRate      float64      `json:"laying_rate"`            // eggs per chicken per hour before deflectors
+	ShippingRate    float64      `json:"shipping_rate"`          // eggs per hour the farm can ship, 0 for no limit
+	Deflector       float64      `json:"deflector"`              // laying bonus given to every other farmer
+	SIAB            bool         `json:"siab"`                   // wears a SIAB in place of the gusset until boosted
+	Gusset          float64      `json:"gusset"`                 // hab bonus of the gusset swapped in for the SIAB
+	BoostTokens     int          `json:"boost_tokens"`           // tokens the boost costs
+	BoostMultiplier float64      `json:"boost_multiplier"`       // IHR multiplier while boosting
+	BoostPhases     []BoostPhase `json:"boost_phases,omitempty"` // larger boosts added part way through
+	Tokens          int          `json:"tokens"`                 // tokens already held
+	Boosted         bool         `json:"boosted"`                // done boosting, or never boosting
+	Delivered       float64      `json:"delivered"`              // eggs already shipped
+}
+
+// BoostPhase is a larger boost a farmer switches to part way through boosting,
+// paid for with tokens they already hold
+type BoostPhase struct {
+	After      time.Duration `json:"after"`      // time since the boost started
+	Tokens     int           `json:"tokens"`     // extra tokens the larger boost costs
+	Multiplier float64       `json:"multiplier"` // IHR multiplier from then on
+}
+
+// Coop is the state a simulation starts from
+type Coop struct {
+	Target        float64         `json:"target"` // eggs to ship
+	Length        time.Duration   `json:"length"` // time left on the contract
+	Farmers       []Farmer        `json:"farmers"`
+	TokenArrivals []time.Duration `json:"token_arrivals"` // tokens reaching the coop, sent to the next booster
+	ChickenRun    float64         `json:"chicken_run"`    // share of the habs each chicken run fills
+	Step          time.Duration   `json:"step"`
+	Sample        time.Duration   `json:"sample"` // spacing of the returned time series
+}
+
+// Point is the coop at one moment of a simulation
+type Point struct {
+	Elapsed    time.Duration
+	Delivered  float64 // eggs shipped by the coop
+	Rate       float64 // eggs per hour
+	Population float64
+	Boosting   int // farmers filling their habs with a boost
+	Boosted    int // farmers done boosting
+}
+
+// Result is the outcome of a simulation
+type Result struct {
+	Completed bool
+	// Duration is when the target is reached. When the contract runs out first
+	// it's extrapolated past the length at the final rate.
+	Duration   time.Duration
+	Delivered  float64 // eggs shipped by the end of the simulation
+	Series     []Point
+	BoostStart []time.Duration // per farmer, -1 when it never boosted
+	BoostEnd   []time.Duration
+}
+
+// TokenArrivals returns a token every interval until the horizon
+func TokenArrivals(interval time.Duration, horizon time.Duration) []time.Duration {
+	if interval <= 0 {
+		return nil
+	}
+	var arrivals []time.Duration
+	for at := interval; at <= horizon; at += interval {
+		arrivals = append(arrivals, at)
+	}
+	return arrivals
+}
+
+type farmerState struct {
+	Farmer
+	boosting   bool
+	multiplier float64 // IHR multiplier of the running boost
+	phase      int     // next entry of BoostPhases
+}
+
+// habCapacity is the capacity with the artifacts equipped right now
+func (f *farmerState) habCapacity() float64 {
+	if f.SIAB && !f.Boosted && f.Gusset > 1 {
+		return f.HabCapacity / f.Gusset
+	}
+	return f.HabCapacity
+}
+
+// Run simulates a coop until it ships the target or the contract ends
+func Run(coop Coop) Result {
+	step := coop.Step
+	if step <= 0 {
+		step = DefaultStep
+	}
+	sample := coop.Sample
+	if sample <= 0 {
+		sample = DefaultSample
+	}
+	chickenRun := coop.ChickenRun
+	if chickenRun <= 0 {
+		chickenRun = DefaultChickenRun
+	}
+	arrivals := append([]time.Duration(nil), coop.TokenArrivals...)
+	sort.Slice(arrivals, func(i, j int) bool { return arrivals[i] < arrivals[j] })
+
+	farmers := make([]farmerState, len(coop.Farmers))
+	result := Result{
+		BoostStart: make([]time.Duration, len(coop.Farmers)),
+		BoostEnd:   make([]time.Duration, len(coop.Farmers)),
+	}
+	deflectors := 0.0
+	for n, f := range coop.Farmers {
+		farmers[n] = farmerState{Farmer: f}
+		result.BoostStart[n] = -1
+		result.BoostEnd[n] = -1
+		deflectors += f.Deflector
+		result.Delivered += f.Delivered
+	}
+	if result.Delivered >= coop.Target {
+		result.Completed = true
+		return result
+	}
+
+	hours := step.Hours()
+	minutes := step.Minutes()
+	tokenPool := 0
+	nextToken := 0
+	rate := 0.0
+	var elapsed time.Duration
+	for elapsed < coop.Length {
+		// Tokens reaching the coop go to the boosters in order
+		for nextToken < len(arrivals) && arrivals[nextToken] <= elapsed {
+			tokenPool++
+			nextToken++
+		}
+		// Like the boost list, the turn passes on as soon as a farmer boosts
+		for n := range farmers {
+			f := &farmers[n]
+			if f.Boosted || f.boosting {
+				continue
+			}
+			f.Tokens += tokenPool
+			tokenPool = 0
+			if f.Tokens < f.BoostTokens {
+				break
+			}
+			f.Tokens -= f.BoostTokens
+			f.boosting = true
+			f.multiplier = f.BoostMultiplier
+			result.BoostStart[n] = elapsed
+		}
+
+		rate = 0
+		population := 0.0
+		boosting := 0
+		for n := range farmers {
+			f := &farmers[n]
+			if f.boosting {
+				boosting++
+			}
+			if f.boosting && f.phase < len(f.BoostPhases) {
+				p := f.BoostPhases[f.phase]
+				if elapsed-result.BoostStart[n] >= p.After && f.Tokens >= p.Tokens {
+					f.Tokens -= p.Tokens
+					f.multiplier = p.Multiplier
+					f.phase++
+				}
+			}
+			growth := f.IHR * minutes
+			if f.boosting {
+				growth *= max(f.multiplier, 1)
+			}
+			f.Population = math.Min(f.habCapacity(), f.Population+growth)
+
+			laying := f.Population * f.LayingRate * (1 + deflectors - f.Deflector)
+			if f.ShippingRate > 0 {
+				laying = math.Min(laying, f.ShippingRate)
+			}
+			f.Delivered += laying * hours
+			rate += laying
+			population += f.Population
+		}
+		elapsed += step
+
+		// A boost ends once it has filled the habs the chicken runs from the
+		// rest of the coop can't, and the runs top them up afterwards
+		runShare := math.Min(chickenRun*float64(len(farmers)-1), ChickenRunLimit)
+		done := 0
+		for n := range farmers {
+			f := &farmers[n]
+			if f.boosting {
+				runs := runShare * f.HabCapacity
+				if f.Population+runs >= f.habCapacity() {
+					f.boosting = false
+					f.Boosted = true
+					f.Population = math.Min(f.habCapacity(), f.Population+runs)
+					result.BoostEnd[n] = elapsed
+				}
+			}
+			if f.Boosted {
+				done++
+			}
+		}
+
+		delivered := 0.0
+		for n := range farmers {
+			delivered += farmers[n].Delivered
+		}
+		result.Delivered = delivered
+		if elapsed%sample == 0 || delivered >= coop.Target {
+			result.Series = append(result.Series, Point{
+				Elapsed:    elapsed,
+				Delivered:  delivered,
+				Rate:       rate,
+				Population: population,
+				Boosting:   boosting,
+				Boosted:    done,
+			})
+		}
+
+		if delivered >= coop.Target {
+			// Back up to when the target was crossed within the step
+			over := (delivered - coop.Target) / (rate * hours)
+			result.Completed = true
+			result.Duration = elapsed - time.Duration(over*float64(step))
+			return result
+		}
+	}
+
+	result.Duration = elapsed
+	if rate > 0 {
+		remaining := (coop.Target - result.Delivered) / rate
+		result.Duration += time.Duration(remaining * float64(time.Hour))
+	}
+	return result
+}
@@ -0,0 +1,264 @@
+package contractsim
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestRunFullHabs(t *testing.T) {
+	// Two full farms laying 1e6 eggs an hour each finish 4e6 eggs in 2 hours
+	farmer := Farmer{Population: 1000, HabCapacity: 1000, LayingRate: 1000, Boosted: true}
+	result := Run(Coop{Target: 4e6, Length: 24 * time.Hour, Farmers: []Farmer{farmer, farmer}})
+	if !result.Completed {
+		t.Fatal("expected the coop to complete")
+	}
+	if result.Duration != 2*time.Hour {
+		t.Errorf("duration = %v, want 2h", result.Duration)
+	}
+	if last := result.Series[len(result.Series)-1]; last.Rate != 2e6 {
+		t.Errorf("rate = %v, want 2e6", last.Rate)
+	}
+}
+
+func TestRunExtrapolates(t *testing.T) {
+	// The contract ends after an hour with 1e6 of 3e6 shipped
+	farmer := Farmer{Population: 1000, HabCapacity: 1000, LayingRate: 1000, Boosted: true}
+	result := Run(Coop{Target: 3e6, Length: time.Hour, Farmers: []Farmer{farmer}})
+	if result.Completed {
+		t.Fatal("expected the contract to run out")
+	}
+	if result.Duration != 3*time.Hour {
+		t.Errorf("duration = %v, want 3h", result.Duration)
+	}
+	if math.Abs(result.Delivered-1e6) > 1 {
+		t.Errorf("delivered = %v, want 1e6", result.Delivered)
+	}
+}
+
+func TestRunAlreadyDelivered(t *testing.T) {
+	result := Run(Coop{Target: 10, Length: time.Hour, Farmers: []Farmer{{Delivered: 20}}})
+	if !result.Completed || result.Duration != 0 {
+		t.Errorf("result = %+v, want completed at 0", result)
+	}
+}
+
+func TestRunBoostOrder(t *testing.T) {
+	farmer := Farmer{Population: 0, HabCapacity: 1e6, IHR: 1000, LayingRate: 1, BoostTokens: 4, BoostMultiplier: 50}
+	first, second := farmer, farmer
+	first.Tokens = 4
+	coop := Coop{
+		Target:        1e12,
+		Length:        2 * time.Hour,
+		Farmers:       []Farmer{first, second},
+		TokenArrivals: TokenArrivals(5*time.Minute, 2*time.Hour),
+	}
+	result := Run(coop)
+
+	// The first farmer boosts right away, the second once four tokens arrive
+	if result.BoostStart[0] != 0 {
+		t.Errorf("first boost start = %v, want 0", result.BoostStart[0])
+	}
+	if result.BoostStart[1] != 20*time.Minute {
+		t.Errorf("second boost start = %v, want 20m", result.BoostStart[1])
+	}
+	// 50k chickens a minute plus a 5% chicken run fills 1M habs in 19 minutes
+	if result.BoostEnd[0] != 19*time.Minute {
+		t.Errorf("first boost end = %v, want 19m", result.BoostEnd[0])
+	}
+}
+
+func TestRunLargeCoopBoost(t *testing.T) {
+	// In a 40 farmer coop the chicken runs would fill the habs twice over, but
+	// the boost still has to fill the 70% they can't
+	boosted := Farmer{Population: 1e6, HabCapacity: 1e6, LayingRate: 1, Boosted: true}
+	farmers := []Farmer{{HabCapacity: 1e6, IHR: 1000, LayingRate: 1, BoostTokens: 4, Tokens: 4, BoostMultiplier: 50}}
+	for range 39 {
+		farmers = append(farmers, boosted)
+	}
+	result := Run(Coop{Target: 1e18, Length: time.Hour, Farmers: farmers})
+	// 50k chickens a minute fill 700k in 14 minutes
+	if result.BoostEnd[0] != 14*time.Minute {
+		t.Errorf("boost end = %v, want 14m", result.BoostEnd[0])
+	}
+	if got := result.Series[len(result.Series)-1].Population; got != 40e6 {
+		t.Errorf("population = %v, want the habs full at 40e6", got)
+	}
+}
+
+func TestRunBoostPhases(t *testing.T) {
+	// 4 tokens for two minutes at 10x, then 4 more for 100x
+	farmer := Farmer{HabCapacity: 1e9, IHR: 1000, LayingRate: 1, BoostTokens: 4, Tokens: 8, BoostMultiplier: 10,
+		BoostPhases: []BoostPhase{{After: 2 * time.Minute, Tokens: 4, Multiplier: 100}}}
+	result := Run(Coop{Target: 1e18, Length: 10 * time.Minute, Farmers: []Farmer{farmer}, Sample: time.Minute})
+	// 10k, 10k, then 100k chickens a minute
+	if got := result.Series[4].Population; got != 320000 {
+		t.Errorf("population after 5 minutes = %v, want 320000", got)
+	}
+
+	// Without the tokens for it the boost stays at 10x
+	farmer.Tokens = 4
+	result = Run(Coop{Target: 1e18, Length: 10 * time.Minute, Farmers: []Farmer{farmer}, Sample: time.Minute})
+	if got := result.Series[4].Population; got != 50000 {
+		t.Errorf("population after 5 minutes without tokens = %v, want 50000", got)
+	}
+}
+
+func TestRunSIAB(t *testing.T) {
+	farmer := Farmer{Population: 500, HabCapacity: 1000, LayingRate: 1, SIAB: true, Gusset: 1.25, Boosted: true}
+	if f := (farmerState{Farmer: farmer}); f.habCapacity() != 1000 {
+		t.Errorf("boosted capacity = %v, want 1000", f.habCapacity())
+	}
+	farmer.Boosted = false
+	if f := (farmerState{Farmer: farmer}); f.habCapacity() != 800 {
+		t.Errorf("SIAB capacity = %v, want 800", f.habCapacity())
+	}
+}
+
+func TestRunDeflectors(t *testing.T) {
+	// Each farmer gets the other's 10% deflector, but not their own
+	farmer := Farmer{Population: 1000, HabCapacity: 1000, LayingRate: 1000, Deflector: 0.1, Boosted: true}
+	result := Run(Coop{Target: 1e12, Length: time.Hour, Farmers: []Farmer{farmer, farmer}})
+	if last := result.Series[len(result.Series)-1]; math.Abs(last.Rate-2.2e6) > 1e-6 {
+		t.Errorf("rate = %v, want 2.2e6", last.Rate)
+	}
+}
+
+func TestRunShippingLimit(t *testing.T) {
+	farmer := Farmer{Population: 1000, HabCapacity: 1000, LayingRate: 1000, ShippingRate: 5e5, Boosted: true}
+	result := Run(Coop{Target: 1e6, Length: 24 * time.Hour, Farmers: []Farmer{farmer}})
+	if result.Duration != 2*time.Hour {
+		t.Errorf("duration = %v, want 2h", result.Duration)
+	}
+}
+
+// goldenCoop is a coop from testdata, with times in minutes
+type goldenCoop struct {
+	Target        float64  `json:"target"`
+	LengthMinutes float64  `json:"length_minutes"`
+	TokenMinutes  float64  `json:"token_minutes"` // minutes between tokens reaching the coop
+	ChickenRun    float64  `json:"chicken_run"`
+	Farmers       []Farmer `json:"farmers"`
+}
+
+func (g goldenCoop) coop() Coop {
+	length := time.Duration(g.LengthMinutes * float64(time.Minute))
+	return Coop{
+		Target:        g.Target,
+		Length:        length,
+		Farmers:       g.Farmers,
+		TokenArrivals: TokenArrivals(time.Duration(g.TokenMinutes*float64(time.Minute)), length),
+		ChickenRun:    g.ChickenRun,
+		Sample:        time.Hour,
+	}
+}
+
+// summarize describes a result in a stable form for the golden files
+func summarize(coop Coop, result Result) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "completed %v in %v, delivered %.4g\n", result.Completed, result.Duration.Round(time.Second), result.Delivered)
+	for n, f := range coop.Farmers {
+		if result.BoostStart[n] < 0 {
+			fmt.Fprintf(&builder, "%s no boost\n", f.Name)
+			continue
+		}
+		fmt.Fprintf(&builder, "%s boost %v-%v\n", f.Name, result.BoostStart[n], result.BoostEnd[n])
+	}
+	for _, p := range result.Series {
+		fmt.Fprintf(&builder, "%v delivered %.4g rate %.4g/h population %.4g boosting %d boosted %d\n",
+			p.Elapsed, p.Delivered, p.Rate, p.Population, p.Boosting, p.Boosted)
+	}
+	return builder.String()
+}
+
+func TestRunGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no coops in testdata")
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var g goldenCoop
+			if err := json.Unmarshal(data, &g); err != nil {
+				t.Fatal(err)
+			}
+			coop := g.coop()
+			got := summarize(coop, Run(coop))
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v, run with -update to create it", err)
+			}
+			if got != string(want) {
+				t.Errorf("simulation changed, run with -update if intended\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// completedCoop is a real coop from testdata/completed. Farms start empty with
+// the rates they finished with, converted from the coop status units: IHR from
+// chickens per second per hab, ELR from eggs per chicken per second and
+// shipping from eggs per second.
+type completedCoop struct {
+	goldenCoop
+	Contract        string  `json:"contract"`
+	Source          string  `json:"source"`
+	FinishedMinutes float64 `json:"finished_minutes"`
+}
+
+// completedTolerance is how far the simulated finish may be from the real one
+const completedTolerance = 0.15
+
+func TestRunCompletedCoops(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "completed", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no completed coops in testdata")
+	}
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var c completedCoop
+			if err := json.Unmarshal(data, &c); err != nil {
+				t.Fatal(err)
+			}
+			result := Run(c.coop())
+			if !result.Completed {
+				t.Fatalf("%s did not complete in the simulation", c.Contract)
+			}
+			finished := time.Duration(c.FinishedMinutes * float64(time.Minute))
+			if diff := math.Abs(result.Duration.Hours()/finished.Hours() - 1); diff > completedTolerance {
+				t.Errorf("%s simulated in %v, finished in %v (%.0f%% off)", c.Contract, result.Duration.Round(time.Minute), finished.Round(time.Minute), diff*100)
+			}
+		})
+	}
+}
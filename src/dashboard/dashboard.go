@@ -247,6 +247,16 @@ func init() {
 		UpdateDashboardsForUser(s, userID, "")
 	}
 
+	// Bookmarks live in the farmer's settings, which /privacy delete already
+	// removes, so only the export needs to decode them
+	farmerstate.RegisterPrivacyStore("dashboard_bookmarks", farmerstate.PrivacyStore{
+		Export: exportDashboardPrivacy,
+	})
+	farmerstate.RegisterPrivacyStore("shared_timers", farmerstate.PrivacyStore{
+		Export: exportSharedTimerPrivacy,
+		Erase:  eraseSharedTimerPrivacy,
+	})
+
 	go func() {
 		ticker := time.NewTicker(15 * time.Minute)
 		for range ticker.C {
@@ -605,25 +615,46 @@ func drawDashboard(s *discordgo.Session, userID string, showExternal bool) []dis
 	// Active Timers
 	timerCount := 0
 	var timerBuilder strings.Builder
+	var timerOptions []discordgo.SelectMenuOption
 	timersMutex.Lock()
 	now := time.Now()
 	for _, t := range timers {
-		if t.UserID == userID && now.Before(t.Reminder) {
+		if t.UserID == userID && (now.Before(t.Reminder) || t.Recurrence != nil) {
 			timerCount++
-			displayMessage := t.Message
-			if t.OriginalChannelID != "" {
-				displayMessage = fmt.Sprintf("%s in <#%s>", displayMessage, t.OriginalChannelID)
-			}
-			fmt.Fprintf(&timerBuilder, "⏱️ **%s**\n", displayMessage)
+			fmt.Fprintf(&timerBuilder, "⏱️ **%s**\n", timerDisplayMessage(t))
 			fmt.Fprintf(&timerBuilder, "-# _       _ Reminder: <t:%d:R>\n", t.Reminder.Unix())
+			// Discord allows 25 options in a select menu
+			if len(timerOptions) < 25 {
+				label := t.Message
+				if runes := []rune(label); len(runes) > 90 {
+					label = string(runes[:90])
+				}
+				timerOptions = append(timerOptions, discordgo.SelectMenuOption{
+					Label: fmt.Sprintf("%d. %s", timerCount, label),
+					Value: t.ID,
+				})
+			}
 		}
 	}
 	timersMutex.Unlock()
 
 	if timerCount > 0 {
+		timerComponents := []discordgo.MessageComponent{discordgo.TextDisplay{Content: "## ⏱️ Active Timers\n" + timerBuilder.String()}}
+		minValues := 1
+		timerComponents = append(timerComponents, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    "dashboard_btn#cancel_timer",
+					Placeholder: "Cancel a timer",
+					Options:     timerOptions,
+					MinValues:   &minValues,
+					MaxValues:   1,
+				},
+			},
+		})
 		components = append(components, discordgo.Container{
 			AccentColor: &colorTimers,
-			Components:  []discordgo.MessageComponent{discordgo.TextDisplay{Content: "## ⏱️ Active Timers\n" + timerBuilder.String()}},
+			Components:  timerComponents,
 		})
 	}
 
@@ -707,6 +738,23 @@ func drawDashboard(s *discordgo.Session, userID string, showExternal bool) []dis
 	return components
 }
 
+// dashboardPrivacyData is the decoded form of a user's dashboard bookmarks
+type dashboardPrivacyData struct {
+	Bookmarks         []boost.Bookmark                 `json:"bookmarks,omitempty"`
+	ExternalContracts []boost.ExternalContractBookmark `json:"external_contracts,omitempty"`
+}
+
+func exportDashboardPrivacy(userID string) (any, error) {
+	data := &dashboardPrivacyData{
+		Bookmarks:         getDashboardBookmarks(userID),
+		ExternalContracts: getExternalContractBookmarks(userID),
+	}
+	if len(data.Bookmarks) == 0 && len(data.ExternalContracts) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
 func getExternalContractBookmarks(userID string) []boost.ExternalContractBookmark {
 	str := farmerstate.GetMiscSettingString(userID, "ext_contract_bookmarks")
 	var bms []boost.ExternalContractBookmark
@@ -1190,6 +1238,23 @@ func HandleDashboardInteraction(s *discordgo.Session, i *discordgo.InteractionCr
 			UpdateDashboardsForUser(s, userID, i.Message.ID)
 		}
 
+	case "cancel_timer":
+		for _, id := range i.MessageComponentData().Values {
+			// Only the owner's timers can be cancelled
+			if timerOwnedBy(id, userID) {
+				timerCancel(id)
+			}
+		}
+		components := drawDashboard(s, userID, false)
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Components: components,
+				Flags:      flags,
+			},
+		})
+		UpdateDashboardsForUser(s, userID, i.Message.ID)
+
 	case "refresh":
 		components := drawDashboard(s, userID, false)
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
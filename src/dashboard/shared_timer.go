@@ -0,0 +1,365 @@
+package dashboard
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+	"github.com/rs/xid"
+)
+
+// SharedTimer is a guild timer posted to a channel. Participants opt in
+// from the channel message and are pinged there when it fires.
+type SharedTimer struct {
+	ID        string        `json:"id"`
+	GuildID   string        `json:"guild_id"`
+	ChannelID string        `json:"channel_id"`
+	MsgID     string        `json:"msg_id"`
+	CreatedBy string        `json:"created_by"`
+	Reminder  time.Time     `json:"reminder"`
+	Message   string        `json:"message"`
+	timer     *time.Timer   `json:"-"`
+	done      chan struct{} `json:"-"`
+}
+
+var sharedTimersMutex sync.Mutex
+var sharedTimers []SharedTimer
+
+// sharedTimerLateLimit is how long after its time a shared timer that was
+// missed while the bot was down still fires
+const sharedTimerLateLimit = time.Hour
+
+// sharedTimerMentionsPerMessage keeps each ping message under Discord's limit
+const sharedTimerMentionsPerMessage = 60
+
+func getSharedTimer(id string) (SharedTimer, bool) {
+	sharedTimersMutex.Lock()
+	defer sharedTimersMutex.Unlock()
+	for _, t := range sharedTimers {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return SharedTimer{}, false
+}
+
+// removeSharedTimer takes a timer out of the running list and ends its
+// goroutine. It returns false when the timer already fired or was cancelled.
+func removeSharedTimer(id string) (SharedTimer, bool) {
+	sharedTimersMutex.Lock()
+	defer sharedTimersMutex.Unlock()
+	for n, t := range sharedTimers {
+		if t.ID == id {
+			sharedTimers = append(sharedTimers[:n], sharedTimers[n+1:]...)
+			if t.timer != nil {
+				t.timer.Stop()
+			}
+			if t.done != nil {
+				close(t.done)
+			}
+			return t, true
+		}
+	}
+	return SharedTimer{}, false
+}
+
+func getSharedTimerContent(t SharedTimer, participants int) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "## ⏰ %s\n", t.Message)
+	fmt.Fprintf(&builder, "Fires <t:%d:R> at <t:%d:t> • set by <@%s>\n", t.Reminder.Unix(), t.Reminder.Unix(), t.CreatedBy)
+	switch participants {
+	case 0:
+		builder.WriteString("-# Press **Notify Me** to be pinged here when it fires.")
+	case 1:
+		builder.WriteString("-# 1 farmer will be pinged. Press **Notify Me** to join or leave.")
+	default:
+		fmt.Fprintf(&builder, "-# %d farmers will be pinged. Press **Notify Me** to join or leave.", participants)
+	}
+	return builder.String()
+}
+
+func getSharedTimerComponents(id string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Notify Me",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("timer_btn#shared_join#%s", id),
+					Emoji:    &discordgo.ComponentEmoji{Name: "🔔"},
+				},
+				discordgo.Button{
+					Label:    "Cancel",
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("timer_btn#shared_cancel#%s", id),
+				},
+			},
+		},
+	}
+}
+
+// createSharedTimer posts a shared timer to a channel and starts it. The
+// creator is opted in so they are pinged with everyone else.
+func createSharedTimer(s *discordgo.Session, guildID string, channelID string, createdBy string, message string, reminder time.Time) (*SharedTimer, error) {
+	t := SharedTimer{
+		ID:        xid.New().String(),
+		GuildID:   guildID,
+		ChannelID: channelID,
+		CreatedBy: createdBy,
+		Reminder:  reminder,
+		Message:   message,
+	}
+
+	msg, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content:         getSharedTimerContent(t, 1),
+		Components:      getSharedTimerComponents(t.ID),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.MsgID = msg.ID
+
+	farmerstate.AddSharedTimer(t.ID, t.GuildID, t.ChannelID, t.MsgID, t.CreatedBy, t.Reminder, t.Message)
+	farmerstate.SetSharedTimerParticipant(t.ID, createdBy, true)
+
+	t.timer = time.NewTimer(time.Until(reminder))
+	t.done = make(chan struct{})
+	sharedTimersMutex.Lock()
+	sharedTimers = append(sharedTimers, t)
+	sharedTimersMutex.Unlock()
+	startSharedTimer(s, t)
+	return &t, nil
+}
+
+func startSharedTimer(s *discordgo.Session, t SharedTimer) {
+	go func(t SharedTimer) {
+		select {
+		case <-t.timer.C:
+			fireSharedTimer(s, t.ID)
+		case <-t.done:
+		}
+	}(t)
+}
+
+// fireSharedTimer pings every participant in the timer's channel
+func fireSharedTimer(s *discordgo.Session, id string) {
+	t, ok := removeSharedTimer(id)
+	if !ok {
+		// Cancelled while it was waiting
+		return
+	}
+	participants := farmerstate.GetSharedTimerParticipants(id)
+	farmerstate.DeleteSharedTimer(id)
+
+	if t.MsgID != "" {
+		content := fmt.Sprintf("## ⏰ %s\nFired <t:%d:R> • set by <@%s>", t.Message, t.Reminder.Unix(), t.CreatedBy)
+		components := []discordgo.MessageComponent{}
+		_, _ = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:              t.MsgID,
+			Channel:         t.ChannelID,
+			Content:         &content,
+			Components:      &components,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+	}
+
+	if len(participants) == 0 {
+		return
+	}
+	for start := 0; start < len(participants); start += sharedTimerMentionsPerMessage {
+		end := min(start+sharedTimerMentionsPerMessage, len(participants))
+		var builder strings.Builder
+		if start == 0 {
+			fmt.Fprintf(&builder, "⏰ **%s**\n", t.Message)
+		}
+		for _, userID := range participants[start:end] {
+			fmt.Fprintf(&builder, "<@%s> ", userID)
+		}
+		send := &discordgo.MessageSend{
+			Content:         strings.TrimSpace(builder.String()),
+			AllowedMentions: &discordgo.MessageAllowedMentions{Users: participants[start:end]},
+		}
+		if start == 0 && t.MsgID != "" {
+			send.Reference = &discordgo.MessageReference{MessageID: t.MsgID, ChannelID: t.ChannelID, GuildID: t.GuildID}
+		}
+		if _, err := s.ChannelMessageSendComplex(t.ChannelID, send); err != nil {
+			log.Printf("Error sending shared timer %s: %v", t.ID, err)
+			return
+		}
+	}
+}
+
+// launchSharedTimers restarts the stored shared timers. Ones that were
+// missed while the bot was down fire right away when they are recent.
+func launchSharedTimers(s *discordgo.Session) {
+	dbTimers := farmerstate.GetAllSharedTimers()
+	now := time.Now()
+
+	var late []string
+	sharedTimersMutex.Lock()
+	sharedTimers = make([]SharedTimer, 0, len(dbTimers))
+	for _, dt := range dbTimers {
+		if now.Sub(dt.Reminder) > sharedTimerLateLimit {
+			farmerstate.DeleteSharedTimer(dt.ID)
+			continue
+		}
+		t := SharedTimer{
+			ID:        dt.ID,
+			GuildID:   dt.GuildID,
+			ChannelID: dt.ChannelID,
+			MsgID:     dt.MsgID,
+			CreatedBy: dt.CreatedBy,
+			Reminder:  dt.Reminder,
+			Message:   dt.Message,
+		}
+		if !now.Before(t.Reminder) {
+			late = append(late, t.ID)
+		} else {
+			t.timer = time.NewTimer(time.Until(t.Reminder))
+			t.done = make(chan struct{})
+			startSharedTimer(s, t)
+		}
+		sharedTimers = append(sharedTimers, t)
+	}
+	sharedTimersMutex.Unlock()
+
+	for _, id := range late {
+		go fireSharedTimer(s, id)
+	}
+}
+
+func handleSharedTimerJoin(s *discordgo.Session, i *discordgo.InteractionCreate, timerID string) {
+	t, ok := getSharedTimer(timerID)
+	if !ok {
+		respondTimerEphemeral(s, i, "This timer has already fired or was cancelled.")
+		return
+	}
+	userID := bottools.GetInteractionUserID(i)
+
+	participants := farmerstate.GetSharedTimerParticipants(timerID)
+	joined := true
+	for _, p := range participants {
+		if p == userID {
+			joined = false
+			break
+		}
+	}
+	farmerstate.SetSharedTimerParticipant(timerID, userID, joined)
+
+	if joined {
+		respondTimerEphemeral(s, i, fmt.Sprintf("You'll be pinged here <t:%d:R> for **%s**.", t.Reminder.Unix(), t.Message))
+	} else {
+		respondTimerEphemeral(s, i, fmt.Sprintf("You won't be pinged for **%s**.", t.Message))
+	}
+
+	content := getSharedTimerContent(t, len(farmerstate.GetSharedTimerParticipants(timerID)))
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:              i.Message.ID,
+		Channel:         i.ChannelID,
+		Content:         &content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		log.Printf("Error updating shared timer %s: %v", timerID, err)
+	}
+}
+
+func handleSharedTimerCancel(s *discordgo.Session, i *discordgo.InteractionCreate, timerID string) {
+	t, ok := getSharedTimer(timerID)
+	if !ok {
+		respondTimerEphemeral(s, i, "This timer has already fired or was cancelled.")
+		return
+	}
+	userID := bottools.GetInteractionUserID(i)
+	if userID != t.CreatedBy && !guildstate.IsGuildCoordinator(i.GuildID, userID) {
+		respondTimerEphemeral(s, i, "Only the farmer who set this timer or a coordinator can cancel it.")
+		return
+	}
+	if _, ok := removeSharedTimer(timerID); !ok {
+		respondTimerEphemeral(s, i, "This timer has already fired or was cancelled.")
+		return
+	}
+	farmerstate.DeleteSharedTimer(timerID)
+
+	content := fmt.Sprintf("## ⏰ ~~%s~~\nCancelled by <@%s>", t.Message, userID)
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Components:      []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to shared timer cancel: %v", err)
+	}
+}
+
+// handleSharedTimerFromButton shares a timer offered by another command,
+// such as the mission returns of /launch-helper
+func handleSharedTimerFromButton(s *discordgo.Session, i *discordgo.InteractionCreate, parts []string) {
+	if len(parts) < 4 {
+		return
+	}
+	userID := bottools.GetInteractionUserID(i)
+	if i.GuildID == "" || !guildstate.IsGuildCoordinator(i.GuildID, userID) {
+		respondTimerEphemeral(s, i, "Only guild coordinators can share timers with a channel.")
+		return
+	}
+	var unix int64
+	if _, err := fmt.Sscanf(parts[2], "%d", &unix); err != nil {
+		return
+	}
+	reminder := time.Unix(unix, 0)
+	if !time.Now().Before(reminder) {
+		respondTimerEphemeral(s, i, "That time has already passed.")
+		return
+	}
+
+	t, err := createSharedTimer(s, i.GuildID, i.ChannelID, userID, parts[3], reminder)
+	if err != nil {
+		respondTimerEphemeral(s, i, fmt.Sprintf("Could not post the shared timer: %v", err))
+		return
+	}
+	respondTimerEphemeral(s, i, fmt.Sprintf("Shared timer **%s** posted for <t:%d:R>.", t.Message, t.Reminder.Unix()))
+}
+
+func respondTimerEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to timer interaction: %v", err)
+	}
+}
+
+// exportSharedTimerPrivacy lists the shared timers a user created or joined
+func exportSharedTimerPrivacy(userID string) (any, error) {
+	timers, err := farmerstate.GetSharedTimersForUser(userID)
+	if err != nil || len(timers) == 0 {
+		return nil, err
+	}
+	return timers, nil
+}
+
+func eraseSharedTimerPrivacy(userID string, tombstone string) error {
+	sharedTimersMutex.Lock()
+	for n := range sharedTimers {
+		if sharedTimers[n].CreatedBy == userID {
+			sharedTimers[n].CreatedBy = tombstone
+		}
+	}
+	sharedTimersMutex.Unlock()
+	return farmerstate.EraseSharedTimerUser(userID, tombstone)
+}
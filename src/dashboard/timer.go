@@ -3,6 +3,7 @@ package dashboard
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -10,23 +11,26 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
 	"github.com/rs/xid"
 	"github.com/xhit/go-str2duration/v2"
 )
 
 // BotTimer holds the data for each timer
 type BotTimer struct {
-	ID                string        `json:"id"`
-	Reminder          time.Time     `json:"reminder"`
-	timer             *time.Timer   `json:"-"`
-	Message           string        `json:"message"`
-	UserID            string        `json:"user_id"`
-	ChannelID         string        `json:"channel_id"`
-	MsgID             string        `json:"msg_id"`
-	Duration          time.Duration `json:"duration"`
-	OriginalChannelID string        `json:"original_channel_id"`
-	OriginalMsgID     string        `json:"original_msg_id"`
-	Active            bool          `json:"active"`
+	ID                string           `json:"id"`
+	Reminder          time.Time        `json:"reminder"`
+	timer             *time.Timer      `json:"-"`
+	done              chan struct{}    `json:"-"`
+	Message           string           `json:"message"`
+	UserID            string           `json:"user_id"`
+	ChannelID         string           `json:"channel_id"`
+	MsgID             string           `json:"msg_id"`
+	Duration          time.Duration    `json:"duration"`
+	OriginalChannelID string           `json:"original_channel_id"`
+	OriginalMsgID     string           `json:"original_msg_id"`
+	Active            bool             `json:"active"`
+	Recurrence        *timerRecurrence `json:"recurrence,omitempty"`
 }
 
 var timersMutex sync.Mutex
@@ -36,6 +40,9 @@ const (
 	processingRequestMessage = "Processing request..."
 )
 
+// timerSnoozeDurations are offered on every reminder
+var timerSnoozeDurations = []time.Duration{10 * time.Minute, time.Hour}
+
 func timerDelete(id string) {
 	timersMutex.Lock()
 	for i, t := range timers {
@@ -48,6 +55,51 @@ func timerDelete(id string) {
 	farmerstate.DeleteTimer(id)
 }
 
+func timerExists(id string) bool {
+	timersMutex.Lock()
+	defer timersMutex.Unlock()
+	for _, t := range timers {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// timerReschedule moves a recurring timer to its next reminder
+func timerReschedule(id string, reminder time.Time, timer *time.Timer) {
+	timersMutex.Lock()
+	for i := range timers {
+		if timers[i].ID == id {
+			timers[i].Reminder = reminder
+			timers[i].timer = timer
+			timers[i].Active = true
+			break
+		}
+	}
+	timersMutex.Unlock()
+	farmerstate.UpdateTimerReminder(id, reminder, true)
+}
+
+// timerCancel stops a pending timer, ends its goroutine and removes it
+func timerCancel(id string) {
+	timersMutex.Lock()
+	for i := range timers {
+		if timers[i].ID == id {
+			if timers[i].timer != nil {
+				timers[i].timer.Stop()
+			}
+			if timers[i].done != nil {
+				close(timers[i].done)
+				timers[i].done = nil
+			}
+			break
+		}
+	}
+	timersMutex.Unlock()
+	timerDelete(id)
+}
+
 func getTimerMsgDuration(userID string) time.Duration {
 	stickyMsgDur := farmerstate.GetMiscSettingString(userID, "timer_dm_timeout")
 	if stickyMsgDur == "" {
@@ -66,17 +118,66 @@ func getTimerMsgDuration(userID string) time.Duration {
 
 func startTimer(s *discordgo.Session, t *BotTimer) {
 	deleteDuration := getTimerMsgDuration(t.UserID)
-	go func(t *BotTimer) {
-		<-t.timer.C
-		u, err := s.UserChannelCreate(t.UserID)
-		if err != nil {
-			log.Printf("Error creating user channel: %v\n", err)
-			return
+	go func(t BotTimer) {
+		for {
+			select {
+			case <-t.timer.C:
+			case <-t.done:
+				return
+			}
+			// Cancelled while it was waiting
+			if !timerExists(t.ID) {
+				return
+			}
+			msg := sendTimerReminder(s, &t, deleteDuration)
+
+			if t.Recurrence == nil {
+				timerSetActiveState(t.ID, false)
+			}
+			if msg != nil {
+				timerSetMsgID(t.ID, msg.ChannelID, msg.ID)
+				if deleteDuration > 0 {
+					recurring := t.Recurrence != nil
+					time.AfterFunc(deleteDuration, func() {
+						err := s.ChannelMessageDelete(msg.ChannelID, msg.ID)
+						if err != nil {
+							log.Println(err)
+						}
+						if !recurring {
+							timerDelete(t.ID)
+						}
+					})
+				}
+			}
+			if t.Recurrence == nil {
+				return
+			}
+
+			next, err := t.Recurrence.next(time.Now())
+			if err != nil {
+				log.Printf("Stopping recurring timer %s: %v", t.ID, err)
+				timerSetActiveState(t.ID, false)
+				return
+			}
+			t.Reminder = next
+			t.timer = time.NewTimer(time.Until(next))
+			timerReschedule(t.ID, next, t.timer)
 		}
+	}(*t)
+}
+
+// sendTimerReminder DMs the reminder with its snooze and repeat buttons
+func sendTimerReminder(s *discordgo.Session, t *BotTimer, deleteDuration time.Duration) *discordgo.Message {
+	u, err := s.UserChannelCreate(t.UserID)
+	if err != nil {
+		log.Printf("Error creating user channel: %v\n", err)
+		return nil
+	}
 
-		var components []discordgo.MessageComponent
-		var actionRowComponents []discordgo.MessageComponent
+	var components []discordgo.MessageComponent
+	var actionRowComponents []discordgo.MessageComponent
 
+	if t.Recurrence == nil {
 		// Repeat button
 		actionRowComponents = append(actionRowComponents, discordgo.Button{
 			Label:    fmt.Sprintf("Repeat %s Timer", bottools.FmtDuration(t.Duration)),
@@ -93,47 +194,58 @@ func startTimer(s *discordgo.Session, t *BotTimer) {
 				CustomID: fmt.Sprintf("timer_btn#repeat_3m40s#%s", t.ID),
 			})
 		}
+	}
+
+	// Snooze buttons
+	for n, snooze := range timerSnoozeDurations {
+		if t.Recurrence == nil && n > 0 {
+			// Keep one-shot reminders to a single row
+			break
+		}
+		actionRowComponents = append(actionRowComponents, discordgo.Button{
+			Label:    "Snooze " + bottools.FmtDuration(snooze),
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("timer_btn#snooze_%d#%s", int(snooze.Minutes()), t.ID),
+		})
+	}
 
-		// Close button
+	if t.Recurrence != nil {
 		actionRowComponents = append(actionRowComponents, discordgo.Button{
-			Label:    "Close",
+			Label:    "Stop Repeating",
 			Style:    discordgo.DangerButton,
-			CustomID: fmt.Sprintf("timer_btn#close#%s", t.ID),
+			CustomID: fmt.Sprintf("timer_btn#stop#%s", t.ID),
 		})
+	}
 
-		components = append(components, discordgo.ActionsRow{Components: actionRowComponents})
+	// Close button
+	actionRowComponents = append(actionRowComponents, discordgo.Button{
+		Label:    "Close",
+		Style:    discordgo.DangerButton,
+		CustomID: fmt.Sprintf("timer_btn#close#%s", t.ID),
+	})
 
-		finalMessage := t.Message
-		if t.OriginalChannelID != "" {
-			finalMessage = fmt.Sprintf("%s in <#%s>", t.Message, t.OriginalChannelID)
-		}
-		if deleteDuration > 0 {
-			finalMessage = fmt.Sprintf("%s\nReminder deleting <t:%d:R>", finalMessage, time.Now().Add(deleteDuration).Unix())
-		}
+	components = append(components, discordgo.ActionsRow{Components: actionRowComponents})
 
-		msg, err := s.ChannelMessageSendComplex(u.ID, &discordgo.MessageSend{
-			Content:    finalMessage,
-			Components: components,
-		})
-		if err != nil {
-			log.Printf("Error sending message: %v\n", err)
-			return
-		}
+	finalMessage := t.Message
+	if t.OriginalChannelID != "" {
+		finalMessage = fmt.Sprintf("%s in <#%s>", t.Message, t.OriginalChannelID)
+	}
+	if t.Recurrence != nil {
+		finalMessage = fmt.Sprintf("%s\n-# Repeats %s", finalMessage, t.Recurrence)
+	}
+	if deleteDuration > 0 {
+		finalMessage = fmt.Sprintf("%s\nReminder deleting <t:%d:R>", finalMessage, time.Now().Add(deleteDuration).Unix())
+	}
 
-		timerSetActiveState(t.ID, false)
-		if msg != nil {
-			timerSetMsgID(t.ID, u.ID, msg.ID)
-			if deleteDuration > 0 {
-				time.AfterFunc(deleteDuration, func() {
-					err := s.ChannelMessageDelete(msg.ChannelID, msg.ID)
-					if err != nil {
-						log.Println(err)
-					}
-					timerDelete(t.ID)
-				})
-			}
-		}
-	}(t)
+	msg, err := s.ChannelMessageSendComplex(u.ID, &discordgo.MessageSend{
+		Content:    finalMessage,
+		Components: components,
+	})
+	if err != nil {
+		log.Printf("Error sending message: %v\n", err)
+		return nil
+	}
+	return msg
 }
 
 func purgeOldTimers(s *discordgo.Session) {
@@ -172,10 +284,19 @@ func LaunchIndependentTimers(s *discordgo.Session) {
 	now := time.Now()
 	timersMutex.Lock()
 	for i := range timers {
+		if !now.Before(timers[i].Reminder) && timers[i].Recurrence != nil {
+			// Missed while the bot was down, pick up the next occurrence
+			if next, err := timers[i].Recurrence.next(now); err == nil {
+				timers[i].Reminder = next
+				timers[i].Active = true
+				farmerstate.UpdateTimerReminder(timers[i].ID, next, true)
+			}
+		}
 		if now.Before(timers[i].Reminder) {
 			nextTimer := time.Until(timers[i].Reminder)
 			if nextTimer >= 0 {
 				timers[i].timer = time.NewTimer(nextTimer)
+				timers[i].done = make(chan struct{})
 				startTimer(s, &timers[i])
 			}
 		} else {
@@ -186,6 +307,7 @@ func LaunchIndependentTimers(s *discordgo.Session) {
 	timersMutex.Unlock()
 
 	farmerstate.DeleteInactiveTimers()
+	launchSharedTimers(s)
 }
 
 // GetSlashTimer will return the discord command for calculating ideal stone set
@@ -221,6 +343,42 @@ func GetSlashTimer(cmd string) *discordgo.ApplicationCommand {
 				Description: "How long the message stays in DM (e.g. 30s, 5m). 0 to keep until closed. [Sticky]",
 				Required:    false,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "every",
+				Description: "Repeat the reminder at this interval. Example: 2h or 30m",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "weekdays",
+				Description: "Repeat on these days, e.g. mon,wed,fri, weekdays or daily. Use with at",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "at",
+				Description: "Time of day for a weekday reminder, e.g. 9am or 21:30",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "zone",
+				Description: "Time zone for weekday reminders, e.g. America/New_York or UTC-5. [Sticky]",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "contract-drop",
+				Description: "Remind me at every predicted contract drop",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "share",
+				Description: "Coordinators: post the timer to this channel so anyone can opt in to a ping",
+				Required:    false,
+			},
 		},
 	}
 }
@@ -295,17 +453,51 @@ func HandleTimerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 	}
 
+	recurrence, err := getTimerRecurrence(userID, optionMap)
+	if err != nil {
+		_, _ = s.FollowupMessageCreate(i.Interaction, true,
+			&discordgo.WebhookParams{
+				Content: err.Error(),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			})
+		return
+	}
+
+	if opt, ok := optionMap["share"]; ok && opt.BoolValue() {
+		handleSharedTimerCommand(s, i, userID, recurrence, message, duration)
+		return
+	}
+
+	reminder := time.Now().Add(duration)
+	if recurrence != nil {
+		if recurrence.Kind == timerRepeatInterval {
+			duration = recurrence.Every
+			reminder = time.Now().Add(duration)
+		} else if reminder, err = recurrence.next(time.Now()); err != nil {
+			_, _ = s.FollowupMessageCreate(i.Interaction, true,
+				&discordgo.WebhookParams{
+					Content: err.Error(),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				})
+			return
+		}
+		if _, ok := optionMap["message"]; !ok {
+			message = "Recurring reminder"
+		}
+	}
+
 	t := BotTimer{
 		ID:                xid.New().String(),
-		Reminder:          time.Now().Add(duration),
+		Reminder:          reminder,
 		Message:           message,
 		UserID:            userID,
-		timer:             time.NewTimer(duration),
+		timer:             time.NewTimer(time.Until(reminder)),
+		done:              make(chan struct{}),
 		Active:            true,
 		Duration:          duration,
 		OriginalChannelID: i.ChannelID,
+		Recurrence:        recurrence,
 	}
-	startTimer(s, &t)
 
 	var builder strings.Builder
 	if statusMessage != "" {
@@ -319,15 +511,11 @@ func HandleTimerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	var newTimers []BotTimer
 	now := time.Now()
 	for i := range timers {
-		if now.Before(timers[i].Reminder) {
-			// Only move over new timers
+		if now.Before(timers[i].Reminder) || timers[i].Recurrence != nil {
+			// Only move over new and recurring timers
 			newTimers = append(newTimers, timers[i])
 			if timers[i].UserID == userID {
-				displayMessage := timers[i].Message
-				if timers[i].OriginalChannelID != "" {
-					displayMessage = fmt.Sprintf("%s in <#%s>", displayMessage, timers[i].OriginalChannelID)
-				}
-				fmt.Fprintf(&builder, "\n> <t:%d:R> %s", timers[i].Reminder.Unix(), displayMessage)
+				fmt.Fprintf(&builder, "\n> <t:%d:R> %s", timers[i].Reminder.Unix(), timerDisplayMessage(timers[i]))
 			}
 		} else {
 			if timers[i].ChannelID != "" && timers[i].MsgID != "" {
@@ -340,7 +528,8 @@ func HandleTimerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	timers = newTimers
 	timersMutex.Unlock()
 
-	farmerstate.AddTimer(t.ID, t.UserID, t.ChannelID, t.MsgID, t.Reminder, t.Message, int64(t.Duration), t.OriginalChannelID, t.OriginalMsgID, t.Active)
+	farmerstate.AddTimer(t.ID, t.UserID, t.ChannelID, t.MsgID, t.Reminder, t.Message, int64(t.Duration), t.OriginalChannelID, t.OriginalMsgID, t.Active, t.Recurrence.encode())
+	startTimer(s, &t)
 	for _, id := range purgedIDs {
 		farmerstate.DeleteTimer(id)
 	}
@@ -397,6 +586,7 @@ func loadTimerData() {
 			OriginalChannelID: dt.OriginalChannelID,
 			OriginalMsgID:     dt.OriginalMsgID,
 			Active:            dt.Active,
+			Recurrence:        decodeTimerRecurrence(dt.Recurrence),
 		})
 	}
 	timersMutex.Unlock()
@@ -415,9 +605,146 @@ func HandleTimerInteraction(s *discordgo.Session, i *discordgo.InteractionCreate
 	case "repeat_3m40s":
 		handleTimerRepeat(s, i, timerID, 3*time.Minute+40*time.Second)
 		timerDelete(timerID)
+	case "stop":
+		handleTimerStop(s, i, timerID)
+	case "share":
+		handleSharedTimerFromButton(s, i, parts)
+	case "shared_join":
+		handleSharedTimerJoin(s, i, timerID)
+	case "shared_cancel":
+		handleSharedTimerCancel(s, i, timerID)
 	case "close":
 		handleTimerClose(s, i, timerID)
+	default:
+		if minutes, ok := strings.CutPrefix(action, "snooze_"); ok {
+			snooze, err := strconv.Atoi(minutes)
+			if err != nil || snooze <= 0 {
+				return
+			}
+			recurring := timerIsRecurring(timerID)
+			handleTimerRepeat(s, i, timerID, time.Duration(snooze)*time.Minute)
+			// The snoozed copy replaces a one-shot reminder
+			if !recurring {
+				timerDelete(timerID)
+			}
+		}
+	}
+}
+
+func timerOwnedBy(id string, userID string) bool {
+	timersMutex.Lock()
+	defer timersMutex.Unlock()
+	for _, t := range timers {
+		if t.ID == id {
+			return t.UserID == userID
+		}
+	}
+	return false
+}
+
+func timerIsRecurring(id string) bool {
+	timersMutex.Lock()
+	defer timersMutex.Unlock()
+	for _, t := range timers {
+		if t.ID == id {
+			return t.Recurrence != nil
+		}
+	}
+	return false
+}
+
+func handleTimerStop(s *discordgo.Session, i *discordgo.InteractionCreate, timerID string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    "Recurring timer stopped.",
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		log.Printf("Error responding to timer stop: %v", err)
+	}
+	timerCancel(timerID)
+	time.AfterFunc(10*time.Second, func() {
+		if i.Message != nil {
+			_ = s.ChannelMessageDelete(i.ChannelID, i.Message.ID)
+		}
+	})
+}
+
+// timerDisplayMessage is the timer message as shown in listings
+func timerDisplayMessage(t BotTimer) string {
+	displayMessage := t.Message
+	if t.OriginalChannelID != "" {
+		displayMessage = fmt.Sprintf("%s in <#%s>", displayMessage, t.OriginalChannelID)
+	}
+	if t.Recurrence != nil {
+		displayMessage = fmt.Sprintf("%s 🔁 %s", displayMessage, t.Recurrence)
 	}
+	return displayMessage
+}
+
+// getTimerRecurrence reads the repeat options of /timer. Only one kind of
+// repeat can be given, no options means a one-shot timer.
+func getTimerRecurrence(userID string, optionMap map[string]*discordgo.ApplicationCommandInteractionDataOption) (*timerRecurrence, error) {
+	var kinds []*timerRecurrence
+
+	if opt, ok := optionMap["every"]; ok {
+		every, err := str2duration.ParseDuration(bottools.SanitizeStringDuration(opt.StringValue()))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse every '%s'", opt.StringValue())
+		}
+		kinds = append(kinds, &timerRecurrence{Kind: timerRepeatInterval, Every: every})
+	}
+
+	_, hasWeekdays := optionMap["weekdays"]
+	_, hasAt := optionMap["at"]
+	if hasWeekdays || hasAt {
+		r := &timerRecurrence{Kind: timerRepeatWeekly}
+		days := "daily"
+		if hasWeekdays {
+			days = optionMap["weekdays"].StringValue()
+		}
+		var err error
+		if r.Weekdays, err = parseTimerWeekdays(days); err != nil {
+			return nil, err
+		}
+		if !hasAt {
+			return nil, fmt.Errorf("weekdays needs a time of day in at, e.g. 9am")
+		}
+		if r.Hour, r.Minute, err = parseTimerClock(optionMap["at"].StringValue()); err != nil {
+			return nil, err
+		}
+		r.Zone = farmerstate.GetMiscSettingString(userID, "timer_zone")
+		if opt, ok := optionMap["zone"]; ok {
+			r.Zone = strings.TrimSpace(opt.StringValue())
+		}
+		if r.Zone == "" {
+			r.Zone = "UTC"
+		}
+		if _, err := bottools.ParseTimeZone(r.Zone); err != nil {
+			return nil, err
+		}
+		if _, ok := optionMap["zone"]; ok {
+			farmerstate.SetMiscSettingString(userID, "timer_zone", r.Zone)
+		}
+		kinds = append(kinds, r)
+	}
+
+	if opt, ok := optionMap["contract-drop"]; ok && opt.BoolValue() {
+		kinds = append(kinds, &timerRecurrence{Kind: timerRepeatDrop})
+	}
+
+	switch len(kinds) {
+	case 0:
+		return nil, nil
+	case 1:
+		if _, err := kinds[0].next(time.Now()); err != nil {
+			return nil, err
+		}
+		return kinds[0], nil
+	}
+	return nil, fmt.Errorf("choose only one of every, weekdays/at or contract-drop")
 }
 
 func handleTimerClose(s *discordgo.Session, i *discordgo.InteractionCreate, timerID string) {
@@ -428,7 +755,10 @@ func handleTimerClose(s *discordgo.Session, i *discordgo.InteractionCreate, time
 		log.Printf("Error responding to timer close: %v", err)
 	}
 	_ = s.ChannelMessageDelete(i.ChannelID, i.Message.ID)
-	timerDelete(timerID)
+	// Closing one reminder keeps the rest of a recurring timer
+	if !timerIsRecurring(timerID) {
+		timerDelete(timerID)
+	}
 }
 
 func handleTimerRepeat(s *discordgo.Session, i *discordgo.InteractionCreate, oldTimerID string, newDuration time.Duration) {
@@ -487,7 +817,7 @@ func handleTimerRepeat(s *discordgo.Session, i *discordgo.InteractionCreate, old
 
 	// Create and start new timer
 	t := BotTimer{
-		ID: xid.New().String(), Reminder: time.Now().Add(duration), Message: originalTimer.Message, UserID: userID, timer: time.NewTimer(duration), Active: true, Duration: duration, OriginalChannelID: originalTimer.OriginalChannelID,
+		ID: xid.New().String(), Reminder: time.Now().Add(duration), Message: originalTimer.Message, UserID: userID, timer: time.NewTimer(duration), done: make(chan struct{}), Active: true, Duration: duration, OriginalChannelID: originalTimer.OriginalChannelID,
 	}
 	startTimer(s, &t)
 
@@ -495,5 +825,54 @@ func handleTimerRepeat(s *discordgo.Session, i *discordgo.InteractionCreate, old
 	timers = append(timers, t)
 	timersMutex.Unlock()
 
-	farmerstate.AddTimer(t.ID, t.UserID, t.ChannelID, t.MsgID, t.Reminder, t.Message, int64(t.Duration), t.OriginalChannelID, t.OriginalMsgID, t.Active)
+	farmerstate.AddTimer(t.ID, t.UserID, t.ChannelID, t.MsgID, t.Reminder, t.Message, int64(t.Duration), t.OriginalChannelID, t.OriginalMsgID, t.Active, t.Recurrence.encode())
+}
+
+// AddReminderTimer schedules a one-shot DM reminder for a user, as if they
+// had set it with /timer in the original channel
+func AddReminderTimer(s *discordgo.Session, userID string, message string, reminder time.Time, originalChannelID string) {
+	duration := time.Until(reminder)
+	t := BotTimer{
+		ID:                xid.New().String(),
+		Reminder:          reminder,
+		Message:           message,
+		UserID:            userID,
+		timer:             time.NewTimer(duration),
+		done:              make(chan struct{}),
+		Active:            true,
+		Duration:          duration,
+		OriginalChannelID: originalChannelID,
+	}
+
+	timersMutex.Lock()
+	timers = append(timers, t)
+	timersMutex.Unlock()
+
+	farmerstate.AddTimer(t.ID, t.UserID, t.ChannelID, t.MsgID, t.Reminder, t.Message, int64(t.Duration), t.OriginalChannelID, t.OriginalMsgID, t.Active, t.Recurrence.encode())
+	startTimer(s, &t)
+}
+
+// handleSharedTimerCommand posts a /timer to the channel instead of a DM
+func handleSharedTimerCommand(s *discordgo.Session, i *discordgo.InteractionCreate, userID string, recurrence *timerRecurrence, message string, duration time.Duration) {
+	var content string
+	switch {
+	case i.GuildID == "":
+		content = "Shared timers can only be posted in a server channel."
+	case !guildstate.IsGuildCoordinator(i.GuildID, userID):
+		content = "Only guild coordinators can share timers with a channel."
+	case recurrence != nil:
+		content = "Shared timers can't repeat, remove every, weekdays/at and contract-drop."
+	default:
+		t, err := createSharedTimer(s, i.GuildID, i.ChannelID, userID, message, time.Now().Add(duration))
+		if err != nil {
+			content = fmt.Sprintf("Could not post the shared timer: %v", err)
+		} else {
+			content = fmt.Sprintf("Shared timer **%s** posted for <t:%d:R>.", t.Message, t.Reminder.Unix())
+		}
+	}
+	_, _ = s.FollowupMessageCreate(i.Interaction, true,
+		&discordgo.WebhookParams{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
 }
@@ -0,0 +1,188 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/boost"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+)
+
+// Recurring timer kinds
+const (
+	timerRepeatInterval = "interval"      // every N minutes/hours
+	timerRepeatWeekly   = "weekly"        // chosen weekdays at a time of day
+	timerRepeatDrop     = "contract_drop" // each predicted contract drop
+)
+
+// timerMinInterval keeps an interval timer from flooding a DM channel
+const timerMinInterval = 5 * time.Minute
+
+// timerRecurrence is the repeat rule of a recurring timer. It is stored as
+// JSON in the timers table.
+type timerRecurrence struct {
+	Kind     string         `json:"kind"`
+	Every    time.Duration  `json:"every,omitempty"`
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+	Hour     int            `json:"hour,omitempty"`
+	Minute   int            `json:"minute,omitempty"`
+	Zone     string         `json:"zone,omitempty"`
+}
+
+// timerNextContractDrop returns the first predicted contract drop after a time
+var timerNextContractDrop = func(after time.Time) (time.Time, bool) {
+	contractTimes, _ := boost.GetPredictedTimes()
+	var next time.Time
+	for _, t := range contractTimes {
+		if t.After(after) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next, !next.IsZero()
+}
+
+func (r *timerRecurrence) encode() string {
+	if r == nil {
+		return ""
+	}
+	data, _ := json.Marshal(r)
+	return string(data)
+}
+
+func decodeTimerRecurrence(data string) *timerRecurrence {
+	if data == "" {
+		return nil
+	}
+	var r timerRecurrence
+	if err := json.Unmarshal([]byte(data), &r); err != nil || r.Kind == "" {
+		return nil
+	}
+	return &r
+}
+
+// next returns the first reminder time strictly after the given time
+func (r *timerRecurrence) next(after time.Time) (time.Time, error) {
+	switch r.Kind {
+	case timerRepeatInterval:
+		if r.Every < timerMinInterval {
+			return time.Time{}, fmt.Errorf("timers can't repeat more often than every %s", bottools.FmtDuration(timerMinInterval))
+		}
+		return after.Add(r.Every), nil
+
+	case timerRepeatWeekly:
+		if len(r.Weekdays) == 0 {
+			return time.Time{}, fmt.Errorf("no weekdays to repeat on")
+		}
+		loc, err := bottools.ParseTimeZone(r.Zone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		local := after.In(loc)
+		for offset := 0; offset <= 7; offset++ {
+			t := time.Date(local.Year(), local.Month(), local.Day()+offset, r.Hour, r.Minute, 0, 0, loc)
+			if t.After(after) && slices.Contains(r.Weekdays, t.Weekday()) {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("no upcoming weekday")
+
+	case timerRepeatDrop:
+		// A drop that is firing right now is still predicted for a moment
+		if t, ok := timerNextContractDrop(after.Add(time.Minute)); ok {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("no contract drop is predicted")
+	}
+	return time.Time{}, fmt.Errorf("unknown timer recurrence %q", r.Kind)
+}
+
+// String describes the rule for timer listings
+func (r *timerRecurrence) String() string {
+	switch r.Kind {
+	case timerRepeatInterval:
+		return "every " + bottools.FmtDuration(r.Every)
+	case timerRepeatWeekly:
+		days := make([]string, 0, len(r.Weekdays))
+		for _, d := range r.Weekdays {
+			days = append(days, d.String()[:3])
+		}
+		if len(days) == 7 {
+			days = []string{"Daily"}
+		}
+		return fmt.Sprintf("%s at %d:%02d %s", strings.Join(days, ", "), r.Hour, r.Minute, r.Zone)
+	case timerRepeatDrop:
+		return "every contract drop"
+	}
+	return r.Kind
+}
+
+var timerWeekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseTimerWeekdays accepts day names such as "mon,wed,fri", "weekdays",
+// "weekends" or "daily"
+func parseTimerWeekdays(input string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, field := range strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '/'
+	}) {
+		switch field {
+		case "daily", "everyday", "all":
+			return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}, nil
+		case "weekdays":
+			days = append(days, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+			continue
+		case "weekends":
+			days = append(days, time.Saturday, time.Sunday)
+			continue
+		}
+		if len(field) < 3 {
+			return nil, fmt.Errorf("unknown weekday %q", field)
+		}
+		d, ok := timerWeekdayNames[field[:3]]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", field)
+		}
+		days = append(days, d)
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no weekdays given")
+	}
+	slices.Sort(days)
+	return slices.Compact(days), nil
+}
+
+var timerClockRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+// parseTimerClock reads a time of day such as "9am", "9:30pm" or "21:30"
+func parseTimerClock(input string) (hour int, minute int, err error) {
+	m := timerClockRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(input)))
+	if m == nil {
+		return 0, 0, fmt.Errorf("could not read the time %q", input)
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid hour in %q", input)
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time %q", input)
+	}
+	return hour, minute, nil
+}
@@ -0,0 +1,103 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimerRecurrenceWeeklyUsesZone(t *testing.T) {
+	r := &timerRecurrence{Kind: timerRepeatWeekly, Weekdays: []time.Weekday{time.Monday, time.Friday}, Hour: 9, Zone: "America/New_York"}
+
+	// Sunday 2026-03-08 is the US switch to daylight saving time
+	after := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	next, err := r.next(after)
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if want := time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next.UTC())
+	}
+
+	// Exactly at a reminder moves on to the following day in the list
+	next, err = r.next(next)
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if want := time.Date(2026, 3, 13, 13, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("expected %v, got %v", want, next.UTC())
+	}
+}
+
+func TestTimerRecurrenceEncodeRoundTrip(t *testing.T) {
+	r := &timerRecurrence{Kind: timerRepeatWeekly, Weekdays: []time.Weekday{time.Tuesday}, Hour: 21, Minute: 30, Zone: "UTC"}
+	got := decodeTimerRecurrence(r.encode())
+	if got == nil || got.String() != r.String() {
+		t.Errorf("expected %v after decoding, got %v", r, got)
+	}
+	if decodeTimerRecurrence("") != nil || (*timerRecurrence)(nil).encode() != "" {
+		t.Error("expected one-shot timers to store no recurrence")
+	}
+}
+
+func TestTimerRecurrenceInterval(t *testing.T) {
+	now := time.Now()
+	if _, err := (&timerRecurrence{Kind: timerRepeatInterval, Every: time.Minute}).next(now); err == nil {
+		t.Error("expected intervals below the minimum to be rejected")
+	}
+	next, err := (&timerRecurrence{Kind: timerRepeatInterval, Every: 2 * time.Hour}).next(now)
+	if err != nil || !next.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("expected a reminder in 2h, got %v %v", next, err)
+	}
+}
+
+func TestTimerRecurrenceContractDrop(t *testing.T) {
+	drop := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	orig := timerNextContractDrop
+	t.Cleanup(func() { timerNextContractDrop = orig })
+	timerNextContractDrop = func(after time.Time) (time.Time, bool) {
+		if after.Before(drop) {
+			return drop, true
+		}
+		return time.Time{}, false
+	}
+
+	r := &timerRecurrence{Kind: timerRepeatDrop}
+	if next, err := r.next(drop.Add(-time.Hour)); err != nil || !next.Equal(drop) {
+		t.Errorf("expected the predicted drop, got %v %v", next, err)
+	}
+	// Firing at the drop must not schedule the same drop again
+	if _, err := r.next(drop); err == nil {
+		t.Error("expected no drop after the last prediction")
+	}
+}
+
+func TestParseTimerWeekdaysAndClock(t *testing.T) {
+	days, err := parseTimerWeekdays("fri, Monday weekends")
+	if err != nil {
+		t.Fatalf("parseTimerWeekdays: %v", err)
+	}
+	want := []time.Weekday{time.Sunday, time.Monday, time.Friday, time.Saturday}
+	if len(days) != len(want) {
+		t.Fatalf("expected %v, got %v", want, days)
+	}
+	for n := range want {
+		if days[n] != want[n] {
+			t.Errorf("expected %v, got %v", want, days)
+		}
+	}
+	if _, err := parseTimerWeekdays("someday"); err == nil {
+		t.Error("expected an unknown weekday to be rejected")
+	}
+
+	for input, hm := range map[string][2]int{"9am": {9, 0}, "12am": {0, 0}, "9:30pm": {21, 30}, "21:05": {21, 5}} {
+		hour, minute, err := parseTimerClock(input)
+		if err != nil || hour != hm[0] || minute != hm[1] {
+			t.Errorf("%s: expected %v, got %d:%02d %v", input, hm, hour, minute, err)
+		}
+	}
+	for _, input := range []string{"13pm", "25:00", "noon"} {
+		if _, _, err := parseTimerClock(input); err == nil {
+			t.Errorf("expected %q to be rejected", input)
+		}
+	}
+}
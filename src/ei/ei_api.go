@@ -9,8 +9,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -48,7 +46,7 @@ func DecryptEID(encryptedString string) string {
 // GetFirstContactFromAPI will download the player data from the Egg Inc API
 func GetFirstContactFromAPI(s *discordgo.Session, eggIncID string, discordID string, okayToSave bool) (*Backup, bool) {
 	eiUserID := DecryptEID(eggIncID)
-	reqURL := "https://www.auxbrain.com/ei/bot_first_contact"
+	reqURL := "/ei/bot_first_contact"
 
 	clientVersion := DefaultClientVersion
 	platform := DefaultPlatform
@@ -129,7 +127,7 @@ func RedactUserInfo(s, eiUserID string) string {
 // GetContractArchiveFromAPI will download the events from the Egg Inc API
 func GetContractArchiveFromAPI(s *discordgo.Session, eggIncID string, discordID string, forceRefresh bool, okayToSave bool) ([]*LocalContract, bool) {
 	eiUserID := DecryptEID(eggIncID)
-	reqURL := "https://www.auxbrain.com/ei_ctx/get_contracts_archive"
+	reqURL := "/ei_ctx/get_contracts_archive"
 	clientVersion := DefaultClientVersion
 
 	contractArchiveRequest := BasicRequestInfo{
@@ -184,7 +182,7 @@ func GetContractArchiveFromAPI(s *discordgo.Session, eggIncID string, discordID
 
 // GetConfigFromAPI will download the config data from the Egg Inc API and write it to ei-config.json
 func GetConfigFromAPI(s *discordgo.Session) bool {
-	reqURL := "https://www.auxbrain.com/ei/get_config"
+	reqURL := "/ei/get_config"
 
 	clientVersion := DefaultClientVersion
 	platformString := DefaultPlatformString
@@ -282,19 +280,28 @@ func loadFromCache(savefilename string, cacheDuration time.Duration) ([]byte, bo
 		return nil, false
 	}
 
-	data, err := os.ReadFile(savefilename)
+	protoData, err := readCacheFile(savefilename)
 	if err != nil {
 		return nil, false
 	}
+	return protoData, true
+}
+
+// readCacheFile decrypts a cached payload and gunzips it when compressed
+func readCacheFile(savefilename string) ([]byte, error) {
+	data, err := os.ReadFile(savefilename)
+	if err != nil {
+		return nil, err
+	}
 
 	encryptionKey, err := base64.StdEncoding.DecodeString(config.Key)
 	if err != nil {
-		return nil, false
+		return nil, err
 	}
 
 	decryptedData, err := config.DecryptCombined(encryptionKey, data)
 	if err != nil {
-		return nil, false
+		return nil, err
 	}
 
 	protoData := decryptedData
@@ -307,7 +314,7 @@ func loadFromCache(savefilename string, cacheDuration time.Duration) ([]byte, bo
 			_ = gr.Close()
 		}
 	}
-	return protoData, true
+	return protoData, nil
 }
 
 // saveToCache compresses, encrypts, and saves the payload in the background
@@ -344,6 +351,28 @@ func saveToCache(savefilename string, payloadToSave []byte) {
 // If unwrapAuthEnvelope is true, it decodes an AuthenticatedMessage envelope and returns its message payload.
 // If unwrapAuthEnvelope is false, it returns the base64-decoded response payload directly.
 func APICall(reqURL string, request proto.Message, okayToSave bool, cacheDuration time.Duration, savefilename string, unwrapAuthEnvelope bool) ([]byte, bool) {
+	return DefaultClient().APICall(reqURL, request, okayToSave, cacheDuration, savefilename, unwrapAuthEnvelope)
+}
+
+type apiCallResult struct {
+	data   []byte
+	cached bool
+}
+
+// APICall makes an Egg Inc API call against this client's server, see the package level APICall.
+// Concurrent calls saving to the same cache file share a single request.
+func (c *Client) APICall(reqURL string, request proto.Message, okayToSave bool, cacheDuration time.Duration, savefilename string, unwrapAuthEnvelope bool) ([]byte, bool) {
+	if savefilename == "" {
+		return c.apiCall(reqURL, request, okayToSave, cacheDuration, savefilename, unwrapAuthEnvelope)
+	}
+	result, _ := coalesce(c, endpointName(reqURL), savefilename, func() apiCallResult {
+		data, cached := c.apiCall(reqURL, request, okayToSave, cacheDuration, savefilename, unwrapAuthEnvelope)
+		return apiCallResult{data: data, cached: cached}
+	})
+	return result.data, result.cached
+}
+
+func (c *Client) apiCall(reqURL string, request proto.Message, okayToSave bool, cacheDuration time.Duration, savefilename string, unwrapAuthEnvelope bool) ([]byte, bool) {
 	if cachedData, ok := loadFromCache(savefilename, cacheDuration); ok {
 		return cachedData, true
 	}
@@ -356,11 +385,7 @@ func APICall(reqURL string, request proto.Message, okayToSave bool, cacheDuratio
 		return nil, false
 	}
 
-	values := url.Values{}
-	reqDataEncoded := enc.EncodeToString(reqBin)
-	values.Set("data", reqDataEncoded)
-
-	response, err := http.PostForm(reqURL, values)
+	response, err := c.PostData(reqURL, reqBin)
 	if err != nil {
 		log.Print(err)
 		return nil, false
@@ -456,6 +481,11 @@ func getSecureHash(data []byte) (string, error) {
 
 // APIAuthenticatedCall wraps the request in an AuthenticatedMessage before sending, then decodes the response the same way as APICall.
 func APIAuthenticatedCall(reqURL string, request proto.Message) []byte {
+	return DefaultClient().APIAuthenticatedCall(reqURL, request)
+}
+
+// APIAuthenticatedCall makes an authenticated Egg Inc API call against this client's server.
+func (c *Client) APIAuthenticatedCall(reqURL string, request proto.Message) []byte {
 	enc := base64.StdEncoding
 
 	innerBin, err := proto.Marshal(request)
@@ -481,10 +511,7 @@ func APIAuthenticatedCall(reqURL string, request proto.Message) []byte {
 		return nil
 	}
 
-	values := url.Values{}
-	values.Set("data", enc.EncodeToString(reqBin))
-
-	response, err := http.PostForm(reqURL, values)
+	response, err := c.PostData(reqURL, reqBin)
 	if err != nil {
 		log.Print(err)
 		return nil
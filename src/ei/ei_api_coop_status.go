@@ -10,13 +10,13 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/contractsim"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -45,8 +45,25 @@ func init() {
 	eiDatas = make(map[string]*eiData)
 }
 
+type coopStatusResult struct {
+	body       []byte
+	statusCode int
+	err        error
+}
+
+// requestCoopStatus fetches a coop status. Concurrent requests for the same
+// coop, endpoint and user share a single API call.
 func requestCoopStatus(contractID string, coopID string, eggIncID string, reqURL string, includeClientVersion bool) ([]byte, int, error) {
-	enc := base64.StdEncoding
+	client := DefaultClient()
+	key := fmt.Sprintf("coop_status:%s:%s:%s:%s:%t", reqURL, contractID, coopID, eggIncID, includeClientVersion)
+	result, _ := coalesce(client, endpointName(reqURL), key, func() coopStatusResult {
+		body, statusCode, err := client.requestCoopStatus(contractID, coopID, eggIncID, reqURL, includeClientVersion)
+		return coopStatusResult{body: body, statusCode: statusCode, err: err}
+	})
+	return result.body, result.statusCode, result.err
+}
+
+func (c *Client) requestCoopStatus(contractID string, coopID string, eggIncID string, reqURL string, includeClientVersion bool) ([]byte, int, error) {
 	coopStatusRequest := ContractCoopStatusRequest{
 		ContractIdentifier: &contractID,
 		CoopIdentifier:     &coopID,
@@ -62,11 +79,7 @@ func requestCoopStatus(contractID string, coopID string, eggIncID string, reqURL
 		return nil, 0, err
 	}
 
-	values := url.Values{}
-	reqDataEncoded := enc.EncodeToString(reqBin)
-	values.Set("data", reqDataEncoded)
-
-	response, err := http.PostForm(reqURL, values)
+	response, err := c.PostData(reqURL, reqBin)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -84,7 +97,7 @@ func requestCoopStatus(contractID string, coopID string, eggIncID string, reqURL
 
 func getCoopStatus(contractID string, coopID string, eeidOverride string, bypassCache bool) (*ContractCoopStatusResponse, time.Time, string, error) {
 	eggIncID := config.EIUserIDBasic
-	reqURL := "https://www.auxbrain.com/ei/coop_status_bot"
+	reqURL := "/ei/coop_status_bot"
 	enc := base64.StdEncoding
 	timestamp := time.Now()
 
@@ -151,7 +164,7 @@ func getCoopStatus(contractID string, coopID string, eeidOverride string, bypass
 
 		if statusCode == http.StatusInternalServerError && strings.TrimSpace(string(body)) != "eop" && eeidOverride != "" && CoopStatusFixEnabled != nil && CoopStatusFixEnabled() {
 			eggIncID = DecryptEID(eeidOverride)
-			reqURL = "https://www.auxbrain.com/ei/coop_status"
+			reqURL = "/ei/coop_status"
 			body, statusCode, err = requestCoopStatus(contractID, coopID, eggIncID, reqURL, false)
 			if err != nil {
 				log.Print(err)
@@ -286,23 +299,12 @@ func GetCoopStatusStartTimeAndDuration(contractID string, coopID string, eeidOve
 		endTime = endTime.Add(-time.Duration(secondsSinceAllGoals) * time.Second)
 		contractDurationSeconds = endTime.Sub(startTime).Seconds()
 	} else {
-		var totalContributions float64
-		var contributionRatePerSecond float64
-		for _, c := range coopStatus.GetContributors() {
-			totalContributions += c.GetContributionAmount()
-			totalContributions += -(c.GetContributionRate() * c.GetFarmInfo().GetTimestamp()) // offline eggs
-			contributionRatePerSecond += c.GetContributionRate()
-		}
-
 		startTime = startTime.Add(time.Duration(secondsRemaining) * time.Second)
 		startTime = startTime.Add(-time.Duration(eiContract.Grade[grade].LengthInSeconds) * time.Second)
 		totalReq := eiContract.Grade[grade].TargetAmount[len(eiContract.Grade[grade].TargetAmount)-1]
 		calcSecondsRemaining := secondsRemaining
-		if contributionRatePerSecond > 0 {
-			calcSecondsRemaining = int64((totalReq - totalContributions) / contributionRatePerSecond)
-			if calcSecondsRemaining < 0 {
-				calcSecondsRemaining = 0
-			}
+		if result := contractsim.Run(CoopStatusSimulation(coopStatus, totalReq)); result.Duration > 0 || result.Completed {
+			calcSecondsRemaining = int64(result.Duration.Seconds())
 		}
 		endTime = nowTime.Add(time.Duration(calcSecondsRemaining) * time.Second)
 		contractDurationSeconds = endTime.Sub(startTime).Seconds()
@@ -311,6 +313,56 @@ func GetCoopStatusStartTimeAndDuration(contractID string, coopID string, eeidOve
 	return startTime, contractDurationSeconds, nil
 }
 
+// CoopStatusSimulation converts a coop status into the starting state of a
+// contract simulation. Farms are treated as done boosting, growing at their
+// internal hatchery rate with the coop buffs already part of their rates.
+func CoopStatusSimulation(coopStatus *ContractCoopStatusResponse, target float64) contractsim.Coop {
+	coop := contractsim.Coop{
+		Target: target,
+		Length: time.Duration(coopStatus.GetSecondsRemaining() * float64(time.Second)),
+	}
+	for _, c := range coopStatus.GetContributors() {
+		farmer := contractsim.Farmer{
+			Name:    c.GetUserName(),
+			Boosted: true,
+			// Eggs shipped since the farm last reported
+			Delivered: c.GetContributionAmount() - c.GetContributionRate()*c.GetFarmInfo().GetTimestamp(),
+		}
+		pp := c.GetProductionParams()
+		if pp.GetFarmPopulation() > 0 && pp.GetElr() > 0 {
+			farmer.Population = pp.GetFarmPopulation()
+			farmer.HabCapacity = max(pp.GetFarmCapacity(), pp.GetFarmPopulation())
+			// The coop status IHR is chickens per second for each hab
+			farmer.IHR = pp.GetIhr() * 60 * float64(coopStatusHabCount(c.GetFarmInfo()))
+			farmer.LayingRate = pp.GetElr() * 3600
+			farmer.ShippingRate = pp.GetSr() * 3600
+		} else {
+			// Without production numbers the farm keeps its current rate
+			farmer.Population = 1
+			farmer.HabCapacity = 1
+			farmer.LayingRate = c.GetContributionRate() * 3600
+		}
+		coop.Farmers = append(coop.Farmers, farmer)
+	}
+	return coop
+}
+
+// coopStatusHabCount returns how many habs a farm has built, assuming all four
+// when the coop status doesn't include the farm
+func coopStatusHabCount(fi *PlayerFarmInfo) int {
+	if len(fi.GetHabs()) == 0 {
+		return 4
+	}
+	habs := 0
+	for _, hab := range fi.GetHabs() {
+		// 19 is an empty hab slot
+		if hab != 19 {
+			habs++
+		}
+	}
+	return habs
+}
+
 // ClearCoopStatusCachedData clears the cached data for coop status
 func ClearCoopStatusCachedData() {
 	var finishHash []string
@@ -337,7 +389,7 @@ func ClearCoopStatusCachedData() {
 // This saves the data in compressed form without a timestamp in the filename
 func GetCoopStatusForCompletedContracts(contractID string, coopID string, eeidOverride string) (*ContractCoopStatusResponse, time.Time, string, error) {
 	eggIncID := config.EIUserIDBasic
-	reqURL := "https://www.auxbrain.com/ei/coop_status_bot"
+	reqURL := "/ei/coop_status_bot"
 	enc := base64.StdEncoding
 	timestamp := time.Now()
 
@@ -387,7 +439,7 @@ func GetCoopStatusForCompletedContracts(contractID string, coopID string, eeidOv
 
 		if statusCode == http.StatusInternalServerError && strings.TrimSpace(string(body)) != "eop" && eeidOverride != "" && CoopStatusFixEnabled != nil && CoopStatusFixEnabled() {
 			eggIncID = DecryptEID(eeidOverride)
-			reqURL = "https://www.auxbrain.com/ei/coop_status"
+			reqURL = "/ei/coop_status"
 			body, statusCode, err = requestCoopStatus(contractID, coopID, eggIncID, reqURL, true)
 			if err != nil {
 				log.Print(err)
@@ -2,33 +2,16 @@ package ei
 
 import (
 	"encoding/base64"
-	"errors"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 )
 
-type rewriteTransport struct {
-	target *url.URL
-	base   http.RoundTripper
-}
-
-func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if rt.base == nil {
-		return nil, errors.New("nil base transport")
-	}
-	req2 := req.Clone(req.Context())
-	req2.URL.Scheme = rt.target.Scheme
-	req2.URL.Host = rt.target.Host
-	req2.Host = rt.target.Host
-	return rt.base.RoundTrip(req2)
-}
-
 func coopStatusSuccessBody(t *testing.T) []byte {
 	t.Helper()
 	statusPayload, err := proto.Marshal(&ContractCoopStatusResponse{})
@@ -62,15 +45,9 @@ func setAuxbrainServer(t *testing.T, handler http.HandlerFunc) {
 	server := httptest.NewServer(handler)
 	t.Cleanup(server.Close)
 
-	targetURL, err := url.Parse(server.URL)
-	if err != nil {
-		t.Fatalf("parse server URL: %v", err)
-	}
-
-	originalTransport := http.DefaultTransport
-	http.DefaultTransport = rewriteTransport{target: targetURL, base: originalTransport}
+	previous := SetDefaultClient(NewClient(server.URL, server.Client().Transport))
 	t.Cleanup(func() {
-		http.DefaultTransport = originalTransport
+		SetDefaultClient(previous)
 	})
 }
 
@@ -242,3 +219,48 @@ func TestGetCoopStatusForCompletedContracts_DoesNotRetryOn500EOP(t *testing.T) {
 		t.Fatalf("unexpected request paths: %v", paths)
 	}
 }
+
+func TestCoopStatusSimulation(t *testing.T) {
+	coopStatus := &ContractCoopStatusResponse{
+		SecondsRemaining: proto.Float64(3600),
+		Contributors: []*ContractCoopStatusResponse_ContributionInfo{
+			{
+				UserName:           proto.String("full"),
+				ContributionAmount: proto.Float64(1e6),
+				ContributionRate:   proto.Float64(100),
+				ProductionParams: &FarmProductionParams{
+					FarmPopulation: proto.Float64(1000),
+					FarmCapacity:   proto.Float64(1000),
+					Elr:            proto.Float64(0.1),
+					Ihr:            proto.Float64(2),
+					Sr:             proto.Float64(200),
+				},
+				// Three habs built and an empty slot
+				FarmInfo: &PlayerFarmInfo{Timestamp: proto.Float64(-60), Habs: []uint32{18, 18, 18, 19}},
+			},
+			{
+				UserName:           proto.String("noparams"),
+				ContributionAmount: proto.Float64(5e5),
+				ContributionRate:   proto.Float64(50),
+			},
+		},
+	}
+
+	coop := CoopStatusSimulation(coopStatus, 2e6)
+	if coop.Length != time.Hour || len(coop.Farmers) != 2 {
+		t.Fatalf("coop = %+v, want an hour and two farmers", coop)
+	}
+	full := coop.Farmers[0]
+	if full.Delivered != 1e6+6000 {
+		t.Errorf("delivered = %v, want eggs shipped since the report added", full.Delivered)
+	}
+	if full.LayingRate != 360 || full.ShippingRate != 720000 {
+		t.Errorf("laying/shipping = %v/%v, want 360/720000 per hour", full.LayingRate, full.ShippingRate)
+	}
+	if full.IHR != 360 {
+		t.Errorf("IHR = %v, want 360 chickens per minute across three habs", full.IHR)
+	}
+	if rate := coop.Farmers[1].Population * coop.Farmers[1].LayingRate; rate != 50*3600 {
+		t.Errorf("fallback rate = %v, want the contribution rate", rate)
+	}
+}
@@ -16,7 +16,7 @@ import (
 // Returns:
 //   - (*ContractsInfoResponse): the decoded contracts info response, or nil on error.
 func GetContractsInfoFromAPI(contractIdentifiers []string) *ContractsInfoResponse {
-	reqURL := "https://www.auxbrain.com/ei_ctx/get_contracts_info"
+	reqURL := "/ei_ctx/get_contracts_info"
 
 	clientVersion := DefaultClientVersion
 	version := DefaultVersion
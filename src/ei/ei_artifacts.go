@@ -409,6 +409,26 @@ func GetStones(afxName ArtifactSpec_Name, afxLevel ArtifactSpec_Level, afxRarity
 	return 0, errors.Errorf("artifact (%s, %s) not found in data.json", afxName, afxLevel)
 }
 
+// GetArtifactTier returns the data for one tier of an artifact family,
+// including its crafting recipe. It returns nil until the data is loaded.
+func GetArtifactTier(afxName ArtifactSpec_Name, afxLevel ArtifactSpec_Level) *Tier {
+	if data == nil {
+		return nil
+	}
+	for _, f := range data.ArtifactFamilies {
+		if f.AfxID != afxName {
+			continue
+		}
+		for _, t := range f.Tiers {
+			if t.AfxLevel == afxLevel {
+				return t
+			}
+		}
+		break
+	}
+	return nil
+}
+
 // GetGameDimensionString returns the string representation of the GameModifier_GameDimension
 func GetGameDimensionString(d GameModifier_GameDimension) string {
 	switch d {
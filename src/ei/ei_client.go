@@ -0,0 +1,79 @@
+package ei
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the Egg Inc API server
+const DefaultBaseURL = "https://www.auxbrain.com"
+
+// Client sends requests to an Egg Inc API server
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Limiter    *RateLimiter // nil sends requests without waiting
+	Retry      RetryPolicy  // zero value makes a single attempt
+
+	flights flightGroup
+	stats   clientStats
+	sleep   func(time.Duration)
+}
+
+// NewClient returns a client for baseURL without rate limits or retries.
+// A nil transport uses http.DefaultTransport.
+func NewClient(baseURL string, transport http.RoundTripper) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Transport: transport},
+	}
+}
+
+var (
+	defaultClient      = newDefaultClient()
+	defaultClientMutex sync.RWMutex
+)
+
+// DefaultClient returns the client used by the package level API calls
+func DefaultClient() *Client {
+	defaultClientMutex.RLock()
+	defer defaultClientMutex.RUnlock()
+	return defaultClient
+}
+
+// SetDefaultClient replaces the client used by the package level API calls and
+// returns the previous client so tests can restore it.
+func SetDefaultClient(c *Client) *Client {
+	defaultClientMutex.Lock()
+	defer defaultClientMutex.Unlock()
+	previous := defaultClient
+	defaultClient = c
+	return previous
+}
+
+// URL resolves an API path such as "/ei/coop_status" against the base URL.
+// Absolute URLs are returned unchanged.
+func (c *Client) URL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return base + path
+}
+
+// PostData posts a serialized protobuf request as the base64 data field the API expects
+func (c *Client) PostData(path string, reqBin []byte) (*http.Response, error) {
+	values := url.Values{}
+	values.Set("data", base64.StdEncoding.EncodeToString(reqBin))
+	return c.PostForm(path, values)
+}
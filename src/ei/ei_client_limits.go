@@ -0,0 +1,297 @@
+package ei
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for the package client. Contract drops send every guild to the same
+// few endpoints at once, so each endpoint gets a smaller share of the global rate.
+const (
+	defaultGlobalRate   = 8.0
+	defaultEndpointRate = 4.0
+	defaultBurst        = 10
+	defaultMaxAttempts  = 4
+	defaultRetryBase    = 500 * time.Millisecond
+	defaultRetryMax     = 8 * time.Second
+	defaultHTTPTimeout  = 30 * time.Second
+)
+
+func newDefaultClient() *Client {
+	c := NewClient(DefaultBaseURL, nil)
+	c.HTTPClient.Timeout = defaultHTTPTimeout
+	c.Limiter = NewRateLimiter(defaultGlobalRate, defaultEndpointRate, defaultBurst)
+	c.Retry = RetryPolicy{MaxAttempts: defaultMaxAttempts, BaseDelay: defaultRetryBase, MaxDelay: defaultRetryMax}
+	return c
+}
+
+// tokenBucket hands out reservations; tokens go negative while callers wait.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// RateLimiter limits requests globally and per endpoint path
+type RateLimiter struct {
+	mu            sync.Mutex
+	global        *tokenBucket
+	endpointRate  float64
+	endpointBurst int
+	endpoints     map[string]*tokenBucket
+}
+
+// NewRateLimiter allows globalRate requests per second overall and endpointRate
+// per endpoint, each with the given burst. A rate of zero disables that limit.
+func NewRateLimiter(globalRate float64, endpointRate float64, burst int) *RateLimiter {
+	l := &RateLimiter{endpointRate: endpointRate, endpointBurst: burst, endpoints: make(map[string]*tokenBucket)}
+	if globalRate > 0 {
+		l.global = newTokenBucket(globalRate, burst)
+	}
+	return l
+}
+
+// Reserve takes a token for endpoint and returns how long the caller must wait to use it
+func (l *RateLimiter) Reserve(endpoint string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var wait time.Duration
+	if l.global != nil {
+		wait = l.global.reserve(now)
+	}
+	if l.endpointRate > 0 {
+		b := l.endpoints[endpoint]
+		if b == nil {
+			b = newTokenBucket(l.endpointRate, l.endpointBurst)
+			l.endpoints[endpoint] = b
+		}
+		wait = max(wait, b.reserve(now))
+	}
+	return wait
+}
+
+// RetryPolicy controls exponential backoff for transient failures
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay returns the backoff before retry number attempt (starting at 0), with up to 50% jitter
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	d := p.BaseDelay << attempt
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d + rand.N(d/2+1)
+}
+
+// retryable reports whether a response is worth another attempt. auxbrain
+// answers 500 for application errors such as an unknown coop, retrying those
+// only adds load, so only gateway errors and transport errors are retried.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// EndpointStats counts the traffic to a single endpoint
+type EndpointStats struct {
+	Requests  uint64
+	Coalesced uint64
+	Throttled uint64
+	Retries   uint64
+	Failures  uint64
+}
+
+// ClientStats is a snapshot of a client's counters
+type ClientStats struct {
+	EndpointStats
+	ThrottleWait time.Duration
+	Endpoints    map[string]EndpointStats
+}
+
+type clientStats struct {
+	mu           sync.Mutex
+	throttleWait time.Duration
+	endpoints    map[string]*EndpointStats
+}
+
+func (s *clientStats) update(endpoint string, fn func(*EndpointStats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.endpoints == nil {
+		s.endpoints = make(map[string]*EndpointStats)
+	}
+	e := s.endpoints[endpoint]
+	if e == nil {
+		e = &EndpointStats{}
+		s.endpoints[endpoint] = e
+	}
+	fn(e)
+}
+
+// Stats returns a snapshot of the request, coalescing, throttling and retry counters
+func (c *Client) Stats() ClientStats {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	out := ClientStats{ThrottleWait: c.stats.throttleWait, Endpoints: make(map[string]EndpointStats)}
+	for name, e := range c.stats.endpoints {
+		out.Endpoints[name] = *e
+		out.Requests += e.Requests
+		out.Coalesced += e.Coalesced
+		out.Throttled += e.Throttled
+		out.Retries += e.Retries
+		out.Failures += e.Failures
+	}
+	return out
+}
+
+// EndpointNames returns the endpoints in the snapshot, busiest first
+func (s ClientStats) EndpointNames() []string {
+	names := make([]string, 0, len(s.Endpoints))
+	for name := range s.Endpoints {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.Endpoints[names[i]], s.Endpoints[names[j]]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func endpointName(path string) string {
+	if u, err := url.Parse(path); err == nil && u.Path != "" {
+		path = u.Path
+	}
+	return "/" + strings.TrimLeft(path, "/")
+}
+
+func (c *Client) throttle(endpoint string) {
+	if c.Limiter == nil {
+		return
+	}
+	wait := c.Limiter.Reserve(endpoint, time.Now())
+	if wait <= 0 {
+		return
+	}
+	c.stats.update(endpoint, func(e *EndpointStats) { e.Throttled++ })
+	c.stats.mu.Lock()
+	c.stats.throttleWait += wait
+	c.stats.mu.Unlock()
+	c.sleepFor(wait)
+}
+
+func (c *Client) sleepFor(d time.Duration) {
+	if c.sleep != nil {
+		c.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// PostForm posts form values to an API path, waiting for the rate limiter and
+// retrying gateway errors and timeouts with exponential backoff.
+func (c *Client) PostForm(path string, values url.Values) (*http.Response, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	endpoint := endpointName(path)
+	attempts := max(c.Retry.MaxAttempts, 1)
+	for attempt := 0; ; attempt++ {
+		c.throttle(endpoint)
+		resp, err := httpClient.PostForm(c.URL(path), values)
+		c.stats.update(endpoint, func(e *EndpointStats) { e.Requests++ })
+		if attempt+1 >= attempts || !retryable(resp, err) {
+			if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+				c.stats.update(endpoint, func(e *EndpointStats) { e.Failures++ })
+			}
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		c.stats.update(endpoint, func(e *EndpointStats) { e.Retries++ })
+		c.sleepFor(c.Retry.Delay(attempt))
+	}
+}
+
+// flightGroup coalesces identical in-flight requests
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg  sync.WaitGroup
+	val any
+}
+
+// coalesce runs fn once for all concurrent callers sharing key. shared is true
+// for the callers that waited on another caller's request.
+func coalesce[T any](c *Client, endpoint string, key string, fn func() T) (result T, shared bool) {
+	g := &c.flights
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.stats.update(endpoint, func(e *EndpointStats) { e.Coalesced++ })
+		call.wg.Wait()
+		result, _ = call.val.(T)
+		return result, true
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+	result = fn()
+	call.val = result
+	return result, false
+}
@@ -0,0 +1,187 @@
+package ei
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func newLimitTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *[]time.Duration) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c := NewClient(server.URL, server.Client().Transport)
+	var sleeps []time.Duration
+	c.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	return c, &sleeps
+}
+
+func TestTokenBucketReserve(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	b := newTokenBucket(2, 2)
+	if b.reserve(now) != 0 || b.reserve(now) != 0 {
+		t.Fatalf("expected the burst to be free")
+	}
+	if wait := b.reserve(now); wait != 500*time.Millisecond {
+		t.Fatalf("expected to wait 500ms, got %v", wait)
+	}
+	// One second later the debt is repaid and one token is available again
+	if wait := b.reserve(now.Add(time.Second)); wait != 0 {
+		t.Fatalf("expected a refilled token, got %v", wait)
+	}
+}
+
+func TestRateLimiterEndpointLimit(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(0, 1, 1)
+	if l.Reserve("/ei/coop_status", now) != 0 || l.Reserve("/ei/get_periodicals", now) != 0 {
+		t.Fatalf("expected separate endpoints to have separate buckets")
+	}
+	if wait := l.Reserve("/ei/coop_status", now); wait != time.Second {
+		t.Fatalf("expected to wait a second, got %v", wait)
+	}
+}
+
+func TestPostFormRetriesGatewayErrors(t *testing.T) {
+	var calls atomic.Int32
+	c, sleeps := newLimitTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	c.Retry = RetryPolicy{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	resp, err := c.PostData("/ei/coop_status", []byte("req"))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected a successful retry, got %v %v", resp, err)
+	}
+	_ = resp.Body.Close()
+
+	if len(*sleeps) != 2 || (*sleeps)[0] < 100*time.Millisecond || (*sleeps)[1] < 200*time.Millisecond {
+		t.Fatalf("expected exponential backoff, got %v", *sleeps)
+	}
+	stats := c.Stats()
+	if stats.Requests != 3 || stats.Retries != 2 || stats.Failures != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestPostFormDoesNotRetryApplicationErrors(t *testing.T) {
+	var calls atomic.Int32
+	c, _ := newLimitTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("eop"))
+	})
+	c.Retry = RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond}
+
+	resp, err := c.PostData("/ei/coop_status", []byte("req"))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || calls.Load() != 1 || c.Stats().Failures != 1 {
+		t.Fatalf("expected a single failed attempt, got %d calls and %+v", calls.Load(), c.Stats())
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+		want   bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusInternalServerError},
+		{status: http.StatusNotImplemented},
+		{status: http.StatusBadGateway, want: true},
+		{status: http.StatusServiceUnavailable, want: true},
+		{status: http.StatusGatewayTimeout, want: true},
+		{err: context.DeadlineExceeded, want: true},
+		{err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, want: true},
+		{err: context.Canceled},
+	}
+	for _, tt := range tests {
+		var resp *http.Response
+		if tt.err == nil {
+			resp = &http.Response{StatusCode: tt.status}
+		}
+		if got := retryable(resp, tt.err); got != tt.want {
+			t.Errorf("retryable(%d, %v) = %v, want %v", tt.status, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestPostFormThrottles(t *testing.T) {
+	c, sleeps := newLimitTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	c.Limiter = NewRateLimiter(1, 0, 1)
+
+	for range 2 {
+		resp, err := c.PostData("/ei/get_periodicals", nil)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+	stats := c.Stats()
+	if len(*sleeps) != 1 || stats.Throttled != 1 || stats.ThrottleWait <= 0 {
+		t.Fatalf("expected the second request to be throttled, got %v and %+v", *sleeps, stats)
+	}
+	if names := stats.EndpointNames(); len(names) != 1 || names[0] != "/ei/get_periodicals" {
+		t.Fatalf("unexpected endpoints %v", names)
+	}
+}
+
+func TestAPICallCoalescesSameCacheFile(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	payload, _ := proto.Marshal(&AuthenticatedMessage{Message: []byte("archive")})
+	c, _ := newLimitTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(payload)))
+	})
+
+	savefile := filepath.Join(t.TempDir(), "archive-user.pbz")
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([][]byte, callers)
+	for n := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[n], _ = c.APICall("/ei_ctx/get_contracts_archive", &BasicRequestInfo{}, false, 0, savefile, true)
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Coalesced < callers-1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("callers were not coalesced: %+v", c.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected one request, got %d", calls.Load())
+	}
+	for n, data := range results {
+		if string(data) != "archive" {
+			t.Fatalf("caller %d got %q", n, data)
+		}
+	}
+}
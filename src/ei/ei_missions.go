@@ -10,6 +10,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/xhit/go-str2duration/v2"
 )
 
 const missionJSON = `{"ships":[
@@ -46,9 +48,10 @@ type AfxMissionParam struct {
 
 // AfxMissionDuration holds duration parameter data from the AFX config.
 type AfxMissionDuration struct {
-	DurationType string  `json:"durationType"`
-	Seconds      float64 `json:"seconds"`
-	Capacity     uint32  `json:"capacity"`
+	DurationType      string  `json:"durationType"`
+	Seconds           float64 `json:"seconds"`
+	Capacity          uint32  `json:"capacity"`
+	LevelCapacityBump uint32  `json:"levelCapacityBump"`
 }
 
 // AfxArtifactParam holds artifact parameter data from the AFX config.
@@ -99,6 +102,18 @@ func GetCraftingLevel(xp float64) int {
 	return level
 }
 
+// GetArtifactCraftingXP returns the crafting XP earned for crafting a common artifact of the given tier.
+func GetArtifactCraftingXP(afxName ArtifactSpec_Name, afxLevel ArtifactSpec_Level) float64 {
+	name := ArtifactSpec_Name_name[int32(afxName)]
+	level := ArtifactSpec_Level_name[int32(afxLevel)]
+	for _, param := range AfxConfig.ArtifactParameters {
+		if param.Spec.Name == name && param.Spec.Level == level && (param.Spec.Rarity == "" || param.Spec.Rarity == "COMMON") {
+			return float64(param.CraftingXp)
+		}
+	}
+	return 0
+}
+
 // MissionArt holds the mission art and durations loaded from JSON
 var MissionArt missionData
 
@@ -177,6 +192,39 @@ func init() {
 	loadAfxConfig()
 }
 
+// GetMissionBaseDuration returns the unmodified length of a mission. The AFX
+// config is used when it loaded, otherwise the built in ship table.
+func GetMissionBaseDuration(ship MissionInfo_Spaceship, durationType MissionInfo_DurationType) time.Duration {
+	if seconds, ok := MissionDurations[int(ship)][int(durationType)]; ok && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if int(ship) < len(MissionArt.Ships) && int(durationType) < len(MissionArt.Ships[ship].Duration) {
+		d, err := str2duration.ParseDuration(MissionArt.Ships[ship].Duration[durationType])
+		if err == nil {
+			return d
+		}
+	}
+	return 0
+}
+
+// GetMissionCapacity returns how many artifacts a mission brings back for a
+// ship at the given star level, or 0 when the AFX config isn't loaded.
+func GetMissionCapacity(ship MissionInfo_Spaceship, durationType MissionInfo_DurationType, level int) float64 {
+	shipName := MissionInfo_Spaceship_name[int32(ship)]
+	durationName := MissionInfo_DurationType_name[int32(durationType)]
+	for _, mp := range AfxConfig.MissionParameters {
+		if mp.Ship != shipName {
+			continue
+		}
+		for _, d := range mp.Durations {
+			if d.DurationType == durationName {
+				return float64(d.Capacity + uint32(max(level, 0))*d.LevelCapacityBump)
+			}
+		}
+	}
+	return 0
+}
+
 // GetEpicResearchMissionCapacity calculates the mission capacity multiplier from the epic research items.
 func GetEpicResearchMissionCapacity(epicResearch []*Backup_ResearchItem) float64 {
 	missionCapacity := 1.0
@@ -0,0 +1,90 @@
+package ei
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// userCacheDir holds the encrypted API backups and archives cached per Discord user
+const userCacheDir = "ttbb-data/eiuserdata"
+
+func userCacheFiles(discordID string) ([]string, error) {
+	if discordID == "" {
+		return nil, nil
+	}
+	return filepath.Glob(filepath.Join(userCacheDir, "*"+discordID+"*"))
+}
+
+// ExportUserCache decodes the cached API responses stored for a Discord user,
+// keyed by file name. Encrypted backups are decrypted and returned as JSON.
+func ExportUserCache(discordID string) (map[string]json.RawMessage, error) {
+	files, err := userCacheFiles(discordID)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	out := make(map[string]json.RawMessage, len(files))
+	var errs []error
+	for _, file := range files {
+		name := filepath.Base(file)
+		data, err := exportCacheFile(file, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out[name] = data
+	}
+	return out, errors.Join(errs...)
+}
+
+func exportCacheFile(path string, name string) (json.RawMessage, error) {
+	if strings.HasSuffix(name, ".json") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid(data) {
+			return json.Marshal(string(data))
+		}
+		return data, nil
+	}
+
+	payload, err := readCacheFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var msg proto.Message
+	switch {
+	case strings.HasPrefix(name, "firstcontact-"):
+		msg = &EggIncFirstContactResponse{}
+	case strings.HasPrefix(name, "archive-"):
+		msg = &ContractsArchive{}
+	default:
+		return json.Marshal(payload)
+	}
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, err
+	}
+	return protojson.Marshal(msg)
+}
+
+// DeleteUserCache removes every cached API response stored for a Discord user
+func DeleteUserCache(discordID string) error {
+	files, err := userCacheFiles(discordID)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,158 @@
+// Package eifake serves recorded Egg Inc API responses from a local HTTP server
+// so API consumers can be tested end to end without reaching auxbrain.
+package eifake
+
+import (
+	"embed"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Fixture names for the recorded responses bundled with the package
+const (
+	FixtureCoopStatus       = "coop_status"
+	FixtureFirstContact     = "first_contact"
+	FixtureContractsArchive = "contracts_archive"
+	FixturePeriodicals      = "periodicals"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// LoadFixture unmarshals a bundled protojson fixture into msg
+func LoadFixture(name string, msg proto.Message) error {
+	data, err := fixtures.ReadFile("fixtures/" + name + ".json")
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(data, msg)
+}
+
+// Request is a request received by the fake server
+type Request struct {
+	Path string
+	Data []byte // base64 decoded "data" form value
+}
+
+type response struct {
+	status int
+	body   []byte
+}
+
+// Server is a fake auxbrain API server
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]response
+	requests  []Request
+}
+
+// NewServer starts a fake server with no registered responses. Unregistered
+// paths answer 404.
+func NewServer() *Server {
+	f := &Server{responses: make(map[string]response)}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// Start starts a fake server, installs it as the ei default client and undoes
+// both when the test finishes.
+func Start(tb testing.TB) *Server {
+	tb.Helper()
+	f := NewServer()
+	previous := ei.SetDefaultClient(f.Client())
+	tb.Cleanup(func() {
+		ei.SetDefaultClient(previous)
+		f.Close()
+	})
+	return f
+}
+
+// Client returns an ei.Client that sends its requests to this server
+func (f *Server) Client() *ei.Client {
+	return ei.NewClient(f.URL, f.Server.Client().Transport)
+}
+
+func (f *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	values, _ := url.ParseQuery(string(body))
+	data, _ := base64.StdEncoding.DecodeString(values.Get("data"))
+
+	f.mu.Lock()
+	f.requests = append(f.requests, Request{Path: r.URL.Path, Data: data})
+	resp, ok := f.responses[r.URL.Path]
+	f.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(resp.status)
+	_, _ = w.Write(resp.body)
+}
+
+// HandleRaw answers path with the given status code and body
+func (f *Server) HandleRaw(path string, status int, body []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[path] = response{status: status, body: body}
+}
+
+// HandleMessage answers path with msg encoded the way unwrapped API calls expect
+func (f *Server) HandleMessage(path string, msg proto.Message) error {
+	bin, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	f.HandleRaw(path, http.StatusOK, []byte(base64.StdEncoding.EncodeToString(bin)))
+	return nil
+}
+
+// HandleAuthenticated answers path with msg wrapped in an AuthenticatedMessage
+func (f *Server) HandleAuthenticated(path string, msg proto.Message) error {
+	bin, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return f.HandleMessage(path, &ei.AuthenticatedMessage{Message: bin})
+}
+
+// CoopStatus answers both coop status endpoints with status
+func (f *Server) CoopStatus(status *ei.ContractCoopStatusResponse) error {
+	if err := f.HandleAuthenticated("/ei/coop_status_bot", status); err != nil {
+		return err
+	}
+	return f.HandleAuthenticated("/ei/coop_status", status)
+}
+
+// FirstContact answers the backup endpoint with resp
+func (f *Server) FirstContact(resp *ei.EggIncFirstContactResponse) error {
+	return f.HandleMessage("/ei/bot_first_contact", resp)
+}
+
+// ContractsArchive answers the contract archive endpoint with archive
+func (f *Server) ContractsArchive(archive *ei.ContractsArchive) error {
+	return f.HandleAuthenticated("/ei_ctx/get_contracts_archive", archive)
+}
+
+// Periodicals answers the periodicals endpoint with resp
+func (f *Server) Periodicals(resp *ei.PeriodicalsResponse) error {
+	return f.HandleAuthenticated("/ei/get_periodicals", resp)
+}
+
+// Requests returns the requests received so far
+func (f *Server) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
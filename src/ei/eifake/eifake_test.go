@@ -0,0 +1,121 @@
+package eifake
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"google.golang.org/protobuf/proto"
+)
+
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir temp dir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+func TestLoadFixtures(t *testing.T) {
+	fixtures := map[string]proto.Message{
+		FixtureCoopStatus:       &ei.ContractCoopStatusResponse{},
+		FixtureFirstContact:     &ei.EggIncFirstContactResponse{},
+		FixtureContractsArchive: &ei.ContractsArchive{},
+		FixturePeriodicals:      &ei.PeriodicalsResponse{},
+	}
+	for name, msg := range fixtures {
+		if err := LoadFixture(name, msg); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if err := LoadFixture("missing", &ei.Backup{}); err == nil {
+		t.Fatalf("expected an error for a missing fixture")
+	}
+}
+
+func TestCoopStatusThroughDefaultClient(t *testing.T) {
+	chdirTemp(t)
+	server := Start(t)
+
+	status := &ei.ContractCoopStatusResponse{}
+	if err := LoadFixture(FixtureCoopStatus, status); err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	if err := server.CoopStatus(status); err != nil {
+		t.Fatalf("register coop status: %v", err)
+	}
+
+	got, _, _, err := ei.GetCoopStatusUncached("fake-contract", "fake-coop", "")
+	if err != nil {
+		t.Fatalf("GetCoopStatusUncached: %v", err)
+	}
+	if len(got.GetContributors()) != 2 || got.GetContributors()[0].GetUserName() != "FakeFarmerOne" {
+		t.Fatalf("unexpected contributors %v", got.GetContributors())
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 || requests[0].Path != "/ei/coop_status_bot" {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+	sent := &ei.ContractCoopStatusRequest{}
+	if err := proto.Unmarshal(requests[0].Data, sent); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	if sent.GetContractIdentifier() != "fake-contract" || sent.GetCoopIdentifier() != "fake-coop" {
+		t.Fatalf("unexpected request %v", sent)
+	}
+}
+
+func TestFirstContactAndArchive(t *testing.T) {
+	chdirTemp(t)
+	server := Start(t)
+
+	firstContact := &ei.EggIncFirstContactResponse{}
+	archive := &ei.ContractsArchive{}
+	if err := LoadFixture(FixtureFirstContact, firstContact); err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	if err := LoadFixture(FixtureContractsArchive, archive); err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	if err := server.FirstContact(firstContact); err != nil {
+		t.Fatalf("register first contact: %v", err)
+	}
+	if err := server.ContractsArchive(archive); err != nil {
+		t.Fatalf("register archive: %v", err)
+	}
+
+	backup, _ := ei.GetFirstContactFromAPI(nil, "EI0000000000000001", "discord-1", false)
+	if backup.GetUserName() != "FakeFarmerOne" {
+		t.Fatalf("expected the fixture backup, got %v", backup)
+	}
+
+	contracts, _ := ei.GetContractArchiveFromAPI(nil, "EI0000000000000001", "discord-1", true, false)
+	if len(contracts) != 2 || contracts[0].GetEvaluation().GetCoopIdentifier() != "fake-coop" {
+		t.Fatalf("unexpected archive %v", contracts)
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	chdirTemp(t)
+	server := Start(t)
+	server.HandleRaw("/ei/coop_status_bot", http.StatusInternalServerError, []byte("eop"))
+
+	if _, _, _, err := ei.GetCoopStatusUncached("fake-contract", "fake-coop", ""); err == nil {
+		t.Fatalf("expected an error for a failing coop status")
+	}
+	if info := ei.GetLeaderboardInfoFromAPI("EI0000000000000001"); info != nil {
+		t.Fatalf("expected no leaderboard info from an unregistered path, got %v", info)
+	}
+	requests := server.Requests()
+	if len(requests) != 2 || requests[1].Path != "/ei_ctx/get_leaderboard_info" {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+}
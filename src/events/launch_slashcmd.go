@@ -9,6 +9,7 @@ import (
 	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/xhit/go-str2duration/v2"
@@ -356,6 +357,9 @@ func HandleLaunchHelper(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	displayDubcapInstructions := false
 	displaySunInstructions := false
 
+	// Coordinators can post the returns as shared timers for the guild
+	canShare := i.GuildID != "" && guildstate.IsGuildCoordinator(i.GuildID, userID)
+
 	if showDubCap {
 		events.WriteString(doubleCapacityStr)
 	}
@@ -383,6 +387,11 @@ func HandleLaunchHelper(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 		fmt.Fprintf(&header, "## Mission arriving on <t:%d:f> (FTL:%d)\n", arrivalTime.Unix(), ftlLevel)
 
+		var shareButtons []discordgo.MessageComponent
+		if canShare {
+			shareButtons = append(shareButtons, getShareTimerButton("Share arrival", arrivalTime, "Missions arrive"))
+		}
+
 		for shipIndex, ship := range missionShips {
 			var sName = " " + ship.Name
 			var sArt = ei.GetBotEmojiMarkdown(ship.Art)
@@ -461,6 +470,9 @@ func HandleLaunchHelper(s *discordgo.Session, i *discordgo.InteractionCreate) {
 				}
 
 				fmt.Fprintf(&builder, "> %s%s%s%s%s (%s): <t:%d:t>%s\n", dcBubble, sunBubble, sArt, shipDurationName[i], sName, bottools.FmtDuration(ftlDuration), launchTime.Unix(), chainString)
+				if canShare && shipIndex == 0 {
+					shareButtons = append(shareButtons, getShareTimerButton("Share "+shipDurationName[i]+" return", launchTime, fmt.Sprintf("%s %s returns", ship.Name, shipDurationName[i])))
+				}
 				if shipIndex != 0 && len(missionShips) > 2 && selectedShipSecondary < -1 {
 					break
 				}
@@ -475,6 +487,11 @@ func HandleLaunchHelper(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		components = append(components, &discordgo.TextDisplay{
 			Content: header.String() + "\n" + builder.String(),
 		})
+		if len(shareButtons) > 0 {
+			components = append(components, &discordgo.ActionsRow{
+				Components: shareButtons,
+			})
+		}
 		divider := true
 		spacing := discordgo.SeparatorSpacingSizeLarge
 
@@ -555,3 +572,14 @@ func HandleLaunchHelper(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 }
+
+// getShareTimerButton offers a time from the launch table as a shared
+// channel timer, handled with the other timer buttons
+func getShareTimerButton(label string, when time.Time, message string) discordgo.Button {
+	return discordgo.Button{
+		Label:    label,
+		Style:    discordgo.SecondaryButton,
+		CustomID: fmt.Sprintf("timer_btn#share#%d#%s", when.Unix(), strings.ReplaceAll(message, "#", "")),
+		Emoji:    &discordgo.ComponentEmoji{Name: "⏰"},
+	}
+}
@@ -0,0 +1,620 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/dashboard"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+)
+
+// missionPlanDays is how far ahead the planner schedules launches
+const missionPlanDays = 7
+
+// missionPlanSlots is the number of missions a player can have in flight
+const missionPlanSlots = 3
+
+// missionPlanTextLimit keeps the plan inside a single Discord message
+const missionPlanTextLimit = 2000
+
+var missionDurationShortName = [...]string{"SH", "ST", "EX"}
+
+// missionPlanOption is a ship and duration the planner may launch
+type missionPlanOption struct {
+	Ship         ei.MissionInfo_Spaceship
+	DurationType ei.MissionInfo_DurationType
+	Duration     time.Duration // after FTL research
+	Capacity     float64       // after capacity research
+}
+
+// missionPlanEvent is a known mission event applied to launches inside it
+type missionPlanEvent struct {
+	Start      time.Time
+	End        time.Time
+	Multiplier float64
+}
+
+// missionSleepWindow is the daily span a player won't launch missions
+type missionSleepWindow struct {
+	Location *time.Location
+	Start    time.Duration // since local midnight
+	Length   time.Duration // 0 when the player never sleeps
+}
+
+type missionPlanInput struct {
+	Start          time.Time
+	End            time.Time
+	SlotsFree      []time.Time // when each mission slot is next empty
+	Options        []missionPlanOption
+	Sleep          missionSleepWindow
+	FastEvents     []missionPlanEvent
+	CapacityEvents []missionPlanEvent
+}
+
+// missionLaunch is one planned launch
+type missionLaunch struct {
+	Slot     int
+	Launch   time.Time
+	Return   time.Time
+	Option   missionPlanOption
+	Capacity float64
+}
+
+// missionPlanReminder is a launch reminder waiting for the user to create it
+type missionPlanReminder struct {
+	At      int64  `json:"at"`
+	Message string `json:"message"`
+}
+
+// wake returns when the player is next awake at or after t
+func (w missionSleepWindow) wake(t time.Time) time.Time {
+	if w.Length <= 0 || w.Location == nil {
+		return t
+	}
+	local := t.In(w.Location)
+	// A window starting yesterday evening can still cover t
+	for offset := -1; offset <= 0; offset++ {
+		start := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, w.Location).Add(w.Start)
+		end := start.Add(w.Length)
+		if !t.Before(start) && t.Before(end) {
+			return end
+		}
+	}
+	return t
+}
+
+func missionEventMultiplier(events []missionPlanEvent, t time.Time) float64 {
+	multiplier := 1.0
+	for _, e := range events {
+		if !t.Before(e.Start) && t.Before(e.End) && e.Multiplier > 0 {
+			multiplier *= e.Multiplier
+		}
+	}
+	return multiplier
+}
+
+// planMissionLaunches fills every mission slot until the end of the plan.
+// Each time a slot empties, it is relaunched as soon as the player is awake
+// with the mission that brings back the most artifacts per hour the slot is
+// tied up, counting the time a return spends waiting for the player to wake.
+func planMissionLaunches(in missionPlanInput) []missionLaunch {
+	free := slices.Clone(in.SlotsFree)
+	var launches []missionLaunch
+	for len(free) > 0 {
+		slot := 0
+		for n := range free {
+			if free[n].Before(free[slot]) {
+				slot = n
+			}
+		}
+		launch := free[slot]
+		if launch.Before(in.Start) {
+			launch = in.Start
+		}
+		launch = in.Sleep.wake(launch)
+		if !launch.Before(in.End) {
+			break
+		}
+
+		best := -1
+		var bestScore float64
+		var bestReturn time.Time
+		var bestCapacity float64
+		for n, opt := range in.Options {
+			duration := time.Duration(float64(opt.Duration) * missionEventMultiplier(in.FastEvents, launch))
+			if duration <= 0 {
+				continue
+			}
+			ret := launch.Add(duration)
+			idle := in.Sleep.wake(ret).Sub(ret)
+			capacity := opt.Capacity * missionEventMultiplier(in.CapacityEvents, launch)
+			if capacity <= 0 {
+				continue
+			}
+			score := capacity / (duration + idle).Hours()
+			if best < 0 || score > bestScore {
+				best, bestScore, bestReturn, bestCapacity = n, score, ret, capacity
+			}
+		}
+		if best < 0 {
+			break
+		}
+		launches = append(launches, missionLaunch{
+			Slot:     slot + 1,
+			Launch:   launch,
+			Return:   bestReturn,
+			Option:   in.Options[best],
+			Capacity: bestCapacity,
+		})
+		free[slot] = bestReturn
+	}
+	return launches
+}
+
+var missionSleepRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\s*-\s*(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+// missionClock converts a clock time into time since midnight
+func missionClock(hourStr string, minuteStr string, meridiem string) (time.Duration, bool) {
+	hour, _ := strconv.Atoi(hourStr)
+	minute := 0
+	if minuteStr != "" {
+		minute, _ = strconv.Atoi(minuteStr)
+	}
+	if minute > 59 {
+		return 0, false
+	}
+	switch meridiem {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		hour %= 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 24 {
+			return 0, false
+		}
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+// parseMissionSleep reads a daily sleep window such as "11pm-7am" or "23-7".
+// "none" plans launches around the clock.
+func parseMissionSleep(input string) (start time.Duration, length time.Duration, err error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" || input == "none" {
+		return 0, 0, nil
+	}
+	m := missionSleepRe.FindStringSubmatch(input)
+	if m == nil {
+		return 0, 0, fmt.Errorf("could not read the sleep window %q, use a range such as 11pm-7am", input)
+	}
+	startMeridiem := m[3]
+	if startMeridiem == "" {
+		startMeridiem = m[6]
+	}
+	start, ok := missionClock(m[1], m[2], startMeridiem)
+	end, ok2 := missionClock(m[4], m[5], m[6])
+	if !ok || !ok2 {
+		return 0, 0, fmt.Errorf("invalid time in the sleep window %q", input)
+	}
+	if m[3] == "" && m[6] != "" && start > end {
+		// "10-6am" means 10pm until 6am when the start has no meridiem
+		if alt, ok := missionClock(m[1], m[2], map[string]string{"am": "pm", "pm": "am"}[m[6]]); ok {
+			start = alt
+		}
+	}
+	length = end - start
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+	if length >= 24*time.Hour {
+		return 0, 0, fmt.Errorf("the sleep window %q covers the whole day", input)
+	}
+	return start % (24 * time.Hour), length, nil
+}
+
+// missionShipLevels returns the highest star level seen for each ship the
+// player has launched, keyed by ship
+func missionShipLevels(backup *ei.Backup) map[ei.MissionInfo_Spaceship]int {
+	levels := make(map[ei.MissionInfo_Spaceship]int)
+	afx := backup.GetArtifactsDb()
+	for _, mi := range append(afx.GetMissionInfos(), afx.GetMissionArchive()...) {
+		ship := mi.GetShip()
+		if level, ok := levels[ship]; !ok || int(mi.GetLevel()) > level {
+			levels[ship] = int(mi.GetLevel())
+		}
+	}
+	return levels
+}
+
+// missionShipDuration keys the capacities seen in a player's missions
+type missionShipDuration struct {
+	Ship         ei.MissionInfo_Spaceship
+	DurationType ei.MissionInfo_DurationType
+}
+
+// missionFlownCapacities returns the most artifacts each ship and duration
+// has brought back for the player, research and stars included
+func missionFlownCapacities(backup *ei.Backup) map[missionShipDuration]float64 {
+	capacities := make(map[missionShipDuration]float64)
+	afx := backup.GetArtifactsDb()
+	for _, mi := range append(afx.GetMissionInfos(), afx.GetMissionArchive()...) {
+		key := missionShipDuration{Ship: mi.GetShip(), DurationType: mi.GetDurationType()}
+		capacities[key] = max(capacities[key], float64(mi.GetCapacity()))
+	}
+	return capacities
+}
+
+// missionLaunchHelperShip maps the /launch-helper ship choices onto ships
+func missionLaunchHelperShip(choice int) ei.MissionInfo_Spaceship {
+	return ei.MissionInfo_Spaceship(len(ei.MissionArt.Ships) - choice - 1)
+}
+
+// buildMissionPlanInput reads the player's fleet, research and in flight
+// missions from their backup
+func buildMissionPlanInput(backup *ei.Backup, userID string, now time.Time, durationTypes []ei.MissionInfo_DurationType, ultra bool) (missionPlanInput, error) {
+	in := missionPlanInput{
+		Start: now,
+		End:   now.Add(missionPlanDays * 24 * time.Hour),
+	}
+
+	levels := missionShipLevels(backup)
+	if len(levels) == 0 {
+		return in, fmt.Errorf("no missions were found in your backup")
+	}
+	best := ei.MissionInfo_CHICKEN_ONE
+	for ship := range levels {
+		if ship > best {
+			best = ship
+		}
+	}
+
+	// The ships picked in /launch-helper, when they have been unlocked
+	ships := []ei.MissionInfo_Spaceship{best}
+	if primary := missionLaunchHelperShip(farmerstate.GetMissionShipPrimary(userID)); primary != best {
+		if _, ok := levels[primary]; ok {
+			ships = []ei.MissionInfo_Spaceship{primary}
+		}
+	}
+	if choice := farmerstate.GetMissionShipSecondary(userID); choice >= 0 {
+		secondary := missionLaunchHelperShip(choice)
+		if _, ok := levels[secondary]; ok && secondary != ships[0] {
+			ships = append(ships, secondary)
+		}
+	}
+
+	ftlMult := 1.0
+	capacityMult := 1.0
+	if game := backup.GetGame(); game != nil {
+		ftlMult = ei.GetEpicResearchMissionTime(game.GetEpicResearch())
+		capacityMult = ei.GetEpicResearchMissionCapacity(game.GetEpicResearch())
+	}
+	flown := missionFlownCapacities(backup)
+	for _, ship := range ships {
+		for _, dt := range durationTypes {
+			base := ei.GetMissionBaseDuration(ship, dt)
+			if base <= 0 {
+				continue
+			}
+			capacity := ei.GetMissionCapacity(ship, dt, levels[ship]) * capacityMult
+			if capacity <= 0 {
+				// Without the AFX config use what the player's own launches brought back
+				capacity = flown[missionShipDuration{Ship: ship, DurationType: dt}]
+			}
+			if capacity <= 0 {
+				continue
+			}
+			in.Options = append(in.Options, missionPlanOption{
+				Ship:         ship,
+				DurationType: dt,
+				Duration:     time.Duration(float64(base) * ftlMult),
+				Capacity:     capacity,
+			})
+		}
+	}
+	if len(in.Options) == 0 {
+		return in, fmt.Errorf("mission capacities for your ships aren't available right now, please try again later")
+	}
+
+	for _, mi := range backup.GetArtifactsDb().GetMissionInfos() {
+		if mi.GetStatus() != ei.MissionInfo_EXPLORING {
+			continue
+		}
+		ret := time.Unix(int64(mi.GetStartTimeDerived()+mi.GetDurationSeconds()), 0)
+		in.SlotsFree = append(in.SlotsFree, ret)
+	}
+	for len(in.SlotsFree) < missionPlanSlots {
+		in.SlotsFree = append(in.SlotsFree, now)
+	}
+
+	for _, e := range ei.EggIncEvents {
+		if e.Ultra && !ultra {
+			continue
+		}
+		event := missionPlanEvent{Start: e.StartTime, End: e.EndTime, Multiplier: e.Multiplier}
+		switch e.EventType {
+		case "mission-duration":
+			in.FastEvents = append(in.FastEvents, event)
+		case "mission-capacity":
+			in.CapacityEvents = append(in.CapacityEvents, event)
+		}
+	}
+	return in, nil
+}
+
+// missionPlanDurationChoices limits the mission lengths the planner uses
+var missionPlanDurationChoices = map[string][]ei.MissionInfo_DurationType{
+	"any":      {ei.MissionInfo_SHORT, ei.MissionInfo_LONG, ei.MissionInfo_EPIC},
+	"short":    {ei.MissionInfo_SHORT},
+	"standard": {ei.MissionInfo_LONG},
+	"extended": {ei.MissionInfo_EPIC},
+}
+
+// SlashMissionPlannerCommand returns the command for the /mission-planner command
+func SlashMissionPlannerCommand(cmd string) *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        cmd,
+		Description: "Plan a week of mission launches around your sleep.",
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+			discordgo.InteractionContextBotDM,
+			discordgo.InteractionContextPrivateChannel,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+			discordgo.ApplicationIntegrationUserInstall,
+		},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "sleep",
+				Description: "When you won't launch missions, e.g. 11pm-7am, or none. [Sticky]",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "zone",
+				Description: "Your time zone, e.g. America/New_York or PT. [Sticky]",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "mission-type",
+				Description: "Mission lengths to plan with. Default is any. [Sticky]",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Any", Value: "any"},
+					{Name: "Short", Value: "short"},
+					{Name: "Standard", Value: "standard"},
+					{Name: "Extended", Value: "extended"},
+				},
+			},
+		},
+	}
+}
+
+// HandleMissionPlanner handles the /mission-planner command
+func HandleMissionPlanner(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := bottools.GetInteractionUserID(i)
+	optionMap := bottools.GetCommandOptionsMap(i)
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Processing request...",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	sendError := func(content string) {
+		_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+
+	sleepStr := farmerstate.GetMiscSettingString(userID, "mission_sleep")
+	if opt, ok := optionMap["sleep"]; ok {
+		sleepStr = opt.StringValue()
+	}
+	sleepStart, sleepLength, err := parseMissionSleep(sleepStr)
+	if err != nil {
+		sendError(err.Error())
+		return
+	}
+	zone := farmerstate.GetMiscSettingString(userID, "timer_zone")
+	if opt, ok := optionMap["zone"]; ok {
+		zone = strings.TrimSpace(opt.StringValue())
+	}
+	if zone == "" {
+		zone = "UTC"
+	}
+	loc, err := bottools.ParseTimeZone(zone)
+	if err != nil {
+		sendError(err.Error())
+		return
+	}
+	missionType := farmerstate.GetMiscSettingString(userID, "mission_plan_type")
+	if opt, ok := optionMap["mission-type"]; ok {
+		missionType = opt.StringValue()
+	}
+	durationTypes, ok := missionPlanDurationChoices[missionType]
+	if !ok {
+		missionType = "any"
+		durationTypes = missionPlanDurationChoices[missionType]
+	}
+
+	// Only remember the settings once they are known to work
+	if _, ok := optionMap["sleep"]; ok {
+		farmerstate.SetMiscSettingString(userID, "mission_sleep", sleepStr)
+	}
+	if _, ok := optionMap["zone"]; ok {
+		farmerstate.SetMiscSettingString(userID, "timer_zone", zone)
+	}
+	farmerstate.SetMiscSettingString(userID, "mission_plan_type", missionType)
+
+	eiID := farmerstate.GetMiscSettingString(userID, "encrypted_ei_id")
+	if eiID == "" {
+		sendError(fmt.Sprintf("You must register your EI ID with the bot to use this command. Use the %s command.", bottools.GetFormattedCommand("register")))
+		return
+	}
+	backup, _ := ei.GetFirstContactFromAPI(s, eiID, userID, true)
+	if backup == nil {
+		sendError("Failed to retrieve your player data from Egg Inc API. Please try again later.")
+		return
+	}
+
+	in, err := buildMissionPlanInput(backup, userID, time.Now(), durationTypes, farmerstate.GetMiscSettingFlag(userID, "ultra"))
+	if err != nil {
+		sendError(err.Error())
+		return
+	}
+	in.Sleep = missionSleepWindow{Location: loc, Start: sleepStart, Length: sleepLength}
+	launches := planMissionLaunches(in)
+	text, shown := getMissionPlanText(in, launches, sleepStr, zone)
+
+	// Reminders match the launches listed in the plan
+	var reminders []missionPlanReminder
+	for _, l := range launches[:shown] {
+		if time.Until(l.Launch) > time.Minute {
+			reminders = append(reminders, missionPlanReminder{
+				At:      l.Launch.Unix(),
+				Message: fmt.Sprintf("Launch %s %s (slot %d)", ei.MissionArt.Ships[l.Option.Ship].Name, missionDurationShortName[l.Option.DurationType], l.Slot),
+			})
+		}
+	}
+	if data, err := json.Marshal(reminders); err == nil {
+		farmerstate.SetMiscSettingString(userID, "mission_plan", string(data))
+	}
+
+	components := []discordgo.MessageComponent{
+		&discordgo.TextDisplay{Content: text},
+	}
+	if len(reminders) > 0 {
+		components = append(components, &discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    fmt.Sprintf("Create %d Launch Reminders", len(reminders)),
+					Style:    discordgo.PrimaryButton,
+					CustomID: "mission_plan#timers",
+					Emoji:    &discordgo.ComponentEmoji{Name: "⏱️"},
+				},
+			},
+		})
+	}
+
+	_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Flags:      discordgo.MessageFlagsIsComponentsV2 | discordgo.MessageFlagsEphemeral,
+		Components: components,
+	})
+	if err != nil {
+		log.Println("Error sending mission plan:", err)
+	}
+}
+
+// getMissionPlanText renders the plan and returns how many launches fit in
+// the message. Later launches are left off rather than splitting the plan.
+func getMissionPlanText(in missionPlanInput, launches []missionLaunch, sleep string, zone string) (string, int) {
+	var builder strings.Builder
+	builder.WriteString("## Mission Plan\n")
+	if in.Sleep.Length > 0 {
+		fmt.Fprintf(&builder, "-# Next %d days, no launches during %s %s\n", missionPlanDays, sleep, zone)
+	} else {
+		fmt.Fprintf(&builder, "-# Next %d days, launching around the clock\n", missionPlanDays)
+	}
+
+	var footer strings.Builder
+	if len(launches) == 0 {
+		footer.WriteString("No launches fit in the next week.\n")
+	}
+	var artifacts float64
+	for _, l := range launches {
+		artifacts += l.Capacity
+	}
+	if artifacts > 0 {
+		fmt.Fprintf(&footer, "-# %d launches bringing back about %d artifacts.\n", len(launches), int(artifacts))
+	}
+	if len(in.FastEvents) > 0 || len(in.CapacityEvents) > 0 {
+		footer.WriteString("-# Includes the current mission events.\n")
+	}
+	// Room for the footer and the note about launches left off
+	limit := missionPlanTextLimit - footer.Len() - 80
+
+	shown := 0
+	day := ""
+	for _, l := range launches {
+		var entry strings.Builder
+		local := l.Launch.UTC()
+		if in.Sleep.Location != nil {
+			local = l.Launch.In(in.Sleep.Location)
+		}
+		if d := local.Format("Mon Jan 2"); d != day {
+			day = d
+			fmt.Fprintf(&entry, "### %s\n", day)
+		}
+		ship := ei.MissionArt.Ships[l.Option.Ship]
+		fmt.Fprintf(&entry, "> %s <t:%d:t> #%d %s %s ×%d (%s) returns <t:%d:f>\n",
+			ei.GetBotEmojiMarkdown(ship.Art), l.Launch.Unix(), l.Slot, ship.Name, missionDurationShortName[l.Option.DurationType], int(l.Capacity),
+			bottools.FmtDuration(l.Return.Sub(l.Launch)), l.Return.Unix())
+		if builder.Len()+entry.Len() > limit {
+			break
+		}
+		builder.WriteString(entry.String())
+		shown++
+	}
+	if shown < len(launches) {
+		fmt.Fprintf(&builder, "-# %d later launches aren't shown, run the planner again after these.\n", len(launches)-shown)
+	}
+	builder.WriteString(footer.String())
+	return builder.String(), shown
+}
+
+// HandleMissionPlannerButtons creates the timers offered with a mission plan
+func HandleMissionPlannerButtons(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, "#")
+	if len(parts) < 2 || parts[1] != "timers" {
+		return
+	}
+	userID := bottools.GetInteractionUserID(i)
+
+	var reminders []missionPlanReminder
+	if data := farmerstate.GetMiscSettingString(userID, "mission_plan"); data != "" {
+		_ = json.Unmarshal([]byte(data), &reminders)
+	}
+	// Each plan only creates its reminders once
+	farmerstate.SetMiscSettingString(userID, "mission_plan", "")
+
+	created := 0
+	for _, r := range reminders {
+		at := time.Unix(r.At, 0)
+		if time.Until(at) <= 0 {
+			continue
+		}
+		dashboard.AddReminderTimer(s, userID, r.Message, at, i.ChannelID)
+		created++
+	}
+
+	content := fmt.Sprintf("Created %d launch reminders. Manage them from %s.", created, bottools.GetFormattedCommand("dashboard"))
+	if created == 0 {
+		content = "This plan has no reminders left to create, run the planner again."
+	}
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
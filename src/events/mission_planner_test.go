@@ -0,0 +1,155 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+)
+
+func TestParseMissionSleep(t *testing.T) {
+	tests := []struct {
+		input  string
+		start  time.Duration
+		length time.Duration
+	}{
+		{"11pm-7am", 23 * time.Hour, 8 * time.Hour},
+		{"23-7", 23 * time.Hour, 8 * time.Hour},
+		{"10-6am", 22 * time.Hour, 8 * time.Hour},
+		{"1:30am-9am", 90 * time.Minute, 7*time.Hour + 30*time.Minute},
+		{"none", 0, 0},
+	}
+	for _, tt := range tests {
+		start, length, err := parseMissionSleep(tt.input)
+		if err != nil || start != tt.start || length != tt.length {
+			t.Errorf("%s: got %v for %v, %v", tt.input, start, length, err)
+		}
+	}
+	for _, input := range []string{"bedtime", "13pm-7am", "7am-7am"} {
+		if _, _, err := parseMissionSleep(input); err == nil {
+			t.Errorf("expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestPlanMissionLaunchesAvoidsSleep(t *testing.T) {
+	loc := time.UTC
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, loc)
+	sleep := missionSleepWindow{Location: loc, Start: 23 * time.Hour, Length: 10 * time.Hour}
+	in := missionPlanInput{
+		Start:     start,
+		End:       start.Add(missionPlanDays * 24 * time.Hour),
+		SlotsFree: []time.Time{start, start, start.Add(14 * time.Hour)},
+		Sleep:     sleep,
+		Options: []missionPlanOption{
+			{Ship: ei.MissionInfo_ATREGGIES, DurationType: ei.MissionInfo_SHORT, Duration: 10 * time.Hour, Capacity: 10},
+			{Ship: ei.MissionInfo_ATREGGIES, DurationType: ei.MissionInfo_EPIC, Duration: 20 * time.Hour, Capacity: 19},
+		},
+	}
+
+	launches := planMissionLaunches(in)
+	if len(launches) == 0 {
+		t.Fatal("expected launches")
+	}
+	for n, l := range launches {
+		if sleep.wake(l.Launch) != l.Launch {
+			t.Errorf("launch %d at %v is inside the sleep window", n, l.Launch)
+		}
+		if !l.Launch.Before(in.End) {
+			t.Errorf("launch %d at %v is past the end of the plan", n, l.Launch)
+		}
+		if n > 0 && l.Launch.Before(launches[n-1].Launch) {
+			t.Errorf("launches are out of order at %d", n)
+		}
+	}
+
+	// The third slot is busy until 02:00, so it launches at wake up
+	for _, l := range launches {
+		if l.Slot == 3 {
+			if !l.Launch.Equal(time.Date(2026, 10, 20, 9, 0, 0, 0, loc)) {
+				t.Errorf("expected slot 3 to launch at 09:00, got %v", l.Launch)
+			}
+			break
+		}
+	}
+
+	// At noon a 10h mission returns at 22:00 while a 20h one returns at
+	// 08:00 and waits an hour; 10 per 10h beats 19 per 21h
+	if launches[0].Option.DurationType != ei.MissionInfo_SHORT {
+		t.Errorf("expected the first launch to be the short mission, got %v", launches[0].Option.DurationType)
+	}
+	// At 22:00 a short mission would come back at 08:00 and wait an hour for
+	// 10 per 11h, while the extended mission returns at 18:00 for 19 per 20h
+	next := launches[0]
+	for _, l := range launches[1:] {
+		if l.Slot == next.Slot {
+			next = l
+			break
+		}
+	}
+	if !next.Launch.Equal(start.Add(10*time.Hour)) || next.Option.DurationType != ei.MissionInfo_EPIC {
+		t.Errorf("expected the extended mission at 22:00, got %v at %v", next.Option.DurationType, next.Launch)
+	}
+}
+
+func TestPlanMissionLaunchesUsesEvents(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	in := missionPlanInput{
+		Start:     start,
+		End:       start.Add(24 * time.Hour),
+		SlotsFree: []time.Time{start},
+		Options: []missionPlanOption{
+			{Ship: ei.MissionInfo_ATREGGIES, DurationType: ei.MissionInfo_EPIC, Duration: 8 * time.Hour, Capacity: 10},
+		},
+		FastEvents:     []missionPlanEvent{{Start: start, End: start.Add(time.Hour), Multiplier: 0.5}},
+		CapacityEvents: []missionPlanEvent{{Start: start, End: start.Add(time.Hour), Multiplier: 2}},
+	}
+	launches := planMissionLaunches(in)
+	if len(launches) < 2 {
+		t.Fatalf("expected several launches, got %d", len(launches))
+	}
+	if got := launches[0].Return.Sub(launches[0].Launch); got != 4*time.Hour || launches[0].Capacity != 20 {
+		t.Errorf("expected the event launch to take 4h for 20 artifacts, got %v for %v", got, launches[0].Capacity)
+	}
+	if got := launches[1].Return.Sub(launches[1].Launch); got != 8*time.Hour || launches[1].Capacity != 10 {
+		t.Errorf("expected launches after the event to be normal, got %v for %v", got, launches[1].Capacity)
+	}
+}
+
+func TestMissionPlanTextFitsInAMessage(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	in := missionPlanInput{
+		Start:     start,
+		End:       start.Add(missionPlanDays * 24 * time.Hour),
+		SlotsFree: []time.Time{start, start, start},
+		Options: []missionPlanOption{
+			{Ship: ei.MissionInfo_CHICKEN_ONE, DurationType: ei.MissionInfo_SHORT, Duration: 20 * time.Minute, Capacity: 4},
+		},
+	}
+	launches := planMissionLaunches(in)
+	text, shown := getMissionPlanText(in, launches, "", "UTC")
+	if len(text) > missionPlanTextLimit {
+		t.Errorf("plan is %d characters, over the %d limit", len(text), missionPlanTextLimit)
+	}
+	if shown == 0 || shown >= len(launches) {
+		t.Errorf("expected part of the %d launches to be shown, got %d", len(launches), shown)
+	}
+}
+
+func TestPlanMissionLaunchesSkipsUnknownCapacity(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	in := missionPlanInput{
+		Start:     start,
+		End:       start.Add(24 * time.Hour),
+		SlotsFree: []time.Time{start},
+		Options: []missionPlanOption{
+			{Ship: ei.MissionInfo_ATREGGIES, DurationType: ei.MissionInfo_SHORT, Duration: time.Hour},
+			{Ship: ei.MissionInfo_ATREGGIES, DurationType: ei.MissionInfo_EPIC, Duration: 8 * time.Hour, Capacity: 10},
+		},
+	}
+	for _, l := range planMissionLaunches(in) {
+		if l.Option.DurationType != ei.MissionInfo_EPIC {
+			t.Fatalf("expected only the mission with a known capacity, got %v", l.Option.DurationType)
+		}
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"io"
 	"log"
 	"math"
-	"net/http"
-	"net/url"
 	"sort"
 	"strings"
 	"sync"
@@ -150,7 +148,7 @@ func getAAAFinalSeasonCxpGoal(seasonInfo *ei.ContractSeasonInfo) float64 {
 // Returns true if it detects a meaningful live periodicals refresh.
 func GetPeriodicalsFromAPI(s *discordgo.Session) bool {
 	userID := config.EIUserID
-	reqURL := "https://www.auxbrain.com/ei/get_periodicals"
+	reqURL := "/ei/get_periodicals"
 	enc := base64.StdEncoding
 	clientVersion := uint32(99)
 
@@ -163,11 +161,7 @@ func GetPeriodicalsFromAPI(s *discordgo.Session) bool {
 		log.Print(err)
 		return false
 	}
-	values := url.Values{}
-	reqDataEncoded := enc.EncodeToString(reqBin)
-	values.Set("data", string(reqDataEncoded))
-
-	response, err := http.PostForm(reqURL, values)
+	response, err := ei.DefaultClient().PostData(reqURL, reqBin)
 	if err != nil {
 		log.Print(err)
 		return false
@@ -449,6 +443,7 @@ func GetPeriodicalsFromAPI(s *discordgo.Session) bool {
 	if updatedPredicted > 0 {
 		log.Printf("Updated %d predicted signup contract(s) to live contract IDs", updatedPredicted)
 	}
+	boost.ScheduleLiveContractJobs(newContract)
 
 	now := time.Now()
 	activeContractCount := countExpectedActiveContracts(newContract)
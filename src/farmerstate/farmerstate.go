@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"testing"
 	"time"
 
 	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
@@ -55,8 +56,12 @@ var ddl string
 var queries *Queries
 
 func sqliteInit() {
-	//db, _ := sql.Open("sqlite", ":memory:")
-	db, _ := sql.Open("sqlite", "ttbb-data/Farmers.sqlite?_busy_timeout=5000")
+	dsn := "ttbb-data/Farmers.sqlite?_busy_timeout=5000"
+	if testing.Testing() {
+		// Tests in every package get a fresh store instead of ttbb-data
+		dsn = ":memory:"
+	}
+	db, _ := sql.Open("sqlite", dsn)
 	db.SetMaxOpenConns(1)
 
 	// Drop old leaderboard_stats table if it has guild_id column to migrate to new global schema
@@ -223,6 +228,8 @@ func DeleteFarmer(userID string) {
 		_ = queries.DeleteUserLeaderboardStats(ctx, userID)
 		_ = queries.DeleteUserLeaderboardOptIns(ctx, userID)
 		_ = queries.DeleteUserLeaderboardExclusions(ctx, userID)
+		_ = queries.DeleteUserLeaderboardAnomalies(ctx, userID)
+		_ = queries.DeleteUserLeaderboardAnomalyHistory(ctx, userID)
 		_ = queries.DeleteUserWatches(ctx, userID)
 	}
 }
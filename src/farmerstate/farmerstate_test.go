@@ -1,10 +1,14 @@
 package farmerstate
 
 import (
+	"archive/zip"
+	"bytes"
 	"database/sql"
+	"io"
 	"os"
 	"slices"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
@@ -179,3 +183,90 @@ func TestDeleteFarmerAndGetFullUserData(t *testing.T) {
 		t.Errorf("expected no watches after deletion, got %v", dataAfter.Watches)
 	}
 }
+
+func TestSharedTimerParticipants(t *testing.T) {
+	AddSharedTimer("shared-1", "guild", "chan", "msg", "SharedCreator", time.Now().Add(time.Hour), "Henliners return")
+	SetSharedTimerParticipant("shared-1", "SharedCreator", true)
+	SetSharedTimerParticipant("shared-1", "SharedJoiner", true)
+	SetSharedTimerParticipant("shared-1", "SharedJoiner", true)
+	SetSharedTimerParticipant("shared-1", "SharedLeaver", true)
+	SetSharedTimerParticipant("shared-1", "SharedLeaver", false)
+
+	if got := GetSharedTimerParticipants("shared-1"); !slices.Equal(got, []string{"SharedCreator", "SharedJoiner"}) {
+		t.Errorf("GetSharedTimerParticipants() = %v", got)
+	}
+
+	if err := EraseSharedTimerUser("SharedCreator", "deleted-test"); err != nil {
+		t.Fatalf("EraseSharedTimerUser() error = %v", err)
+	}
+	if timers, _ := GetSharedTimersForUser("SharedCreator"); len(timers) != 0 {
+		t.Errorf("expected no shared timers for an erased user, got %v", timers)
+	}
+	if timers, _ := GetSharedTimersForUser("deleted-test"); len(timers) != 1 {
+		t.Errorf("expected the timer to be kept under the tombstone, got %v", timers)
+	}
+
+	DeleteSharedTimer("shared-1")
+	if got := GetSharedTimerParticipants("shared-1"); len(got) != 0 {
+		t.Errorf("expected participants to be deleted with the timer, got %v", got)
+	}
+}
+
+func TestLeaderboardAnomalyReview(t *testing.T) {
+	held := LeaderboardAnomaly{LbType: "soul_eggs", Player: "anomaly-user", GameName: "Jumpy", SnapDate: "2026-05-15", Value: 1e30, PriorValue: 1e20, PriorSnapDate: "2026-05-08", Reason: "jump"}
+	if err := RecordLeaderboardAnomaly(held); err != nil {
+		t.Fatalf("RecordLeaderboardAnomaly() error: %v", err)
+	}
+	dependent := held
+	dependent.LbType, dependent.HeldWith = "se_per_prestige", "soul_eggs"
+	_ = RecordLeaderboardAnomaly(dependent)
+
+	pending, err := GetPendingLeaderboardAnomalies()
+	if err != nil || len(pending) != 1 || pending[0].LbType != "soul_eggs" || pending[0].Status != "pending" {
+		t.Fatalf("GetPendingLeaderboardAnomalies() = %+v, %v; want only the soul_eggs flag", pending, err)
+	}
+	if rows, _ := GetHeldLeaderboardAnomalies("anomaly-user", "2026-05-15", "soul_eggs"); len(rows) != 1 || rows[0].LbType != "se_per_prestige" {
+		t.Errorf("GetHeldLeaderboardAnomalies() = %+v, want se_per_prestige", rows)
+	}
+
+	if err := SetLeaderboardAnomalyStatus(pending[0].ID, "rejected", "admin"); err != nil {
+		t.Fatalf("SetLeaderboardAnomalyStatus() error: %v", err)
+	}
+	held.Reason = "re-collected"
+	_ = RecordLeaderboardAnomaly(held)
+	got, _ := GetLeaderboardAnomaly(pending[0].ID)
+	if got.Status != "rejected" || got.Reason != "jump" || got.ReviewedBy != "admin" {
+		t.Errorf("reviewed anomaly = %+v, want the rejection kept", got)
+	}
+	if status := GetLeaderboardAnomalyStatus("soul_eggs", "anomaly-user", "2026-05-15"); status != "rejected" {
+		t.Errorf("GetLeaderboardAnomalyStatus() = %q, want rejected", status)
+	}
+
+	DeleteFarmer("anomaly-user")
+	if data := GetFullUserData("anomaly-user"); len(data.LeaderboardAnomalies) != 0 {
+		t.Errorf("expected no anomalies after deletion, got %+v", data.LeaderboardAnomalies)
+	}
+}
+
+func TestZipPrivacyExport(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"farmer_state":{}}`), 1000)
+	zipped, err := zipPrivacyExport("boostbot-data-1.json", data)
+	if err != nil {
+		t.Fatalf("zipPrivacyExport: %v", err)
+	}
+	if len(zipped) >= len(data) {
+		t.Errorf("zip is %d bytes, not smaller than %d", len(zipped), len(data))
+	}
+	zr, err := zip.NewReader(bytes.NewReader(zipped), int64(len(zipped)))
+	if err != nil || len(zr.File) != 1 || zr.File[0].Name != "boostbot-data-1.json" {
+		t.Fatalf("unexpected zip %v %v", zr, err)
+	}
+	f, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if got, _ := io.ReadAll(f); !bytes.Equal(got, data) {
+		t.Errorf("unzipped export differs from the original")
+	}
+}
@@ -2,6 +2,7 @@ package farmerstate
 
 import (
 	"database/sql"
+	"time"
 )
 
 // leaderboard_state.go — Wrapper functions for the leaderboard_stats and leaderboard_optin tables.
@@ -245,3 +246,135 @@ func PruneOlderLeaderboardStatsForPlayer(lbType, player, keepSnapDate string) er
 		SnapDate: keepSnapDate,
 	})
 }
+
+// DeleteLeaderboardStatForPlayerAndSnapDate removes one snapshot for a player and type.
+func DeleteLeaderboardStatForPlayerAndSnapDate(lbType, player, snapDate string) error {
+	if queries == nil {
+		return nil
+	}
+	return queries.DeleteLeaderboardStatForPlayerAndSnapDate(ctx, DeleteLeaderboardStatForPlayerAndSnapDateParams{
+		LbType:   lbType,
+		Player:   player,
+		SnapDate: snapDate,
+	})
+}
+
+// ─── Anomaly Review ──────────────────────────────────────────────────────────
+
+// RecordLeaderboardAnomaly holds a snapshot out of the leaderboards for review.
+// A snapshot that was already approved or rejected keeps its decision.
+func RecordLeaderboardAnomaly(a LeaderboardAnomaly) error {
+	if queries == nil {
+		return nil
+	}
+	return queries.UpsertLeaderboardAnomaly(ctx, UpsertLeaderboardAnomalyParams{
+		LbType:        a.LbType,
+		Player:        a.Player,
+		GameName:      a.GameName,
+		SnapDate:      a.SnapDate,
+		Value:         a.Value,
+		Details:       a.Details,
+		PriorValue:    a.PriorValue,
+		PriorSnapDate: a.PriorSnapDate,
+		Reason:        a.Reason,
+		HeldWith:      a.HeldWith,
+	})
+}
+
+// GetLeaderboardAnomalyStatus returns the review status of a held snapshot, or "" if it was never held.
+func GetLeaderboardAnomalyStatus(lbType, player, snapDate string) string {
+	if queries == nil {
+		return ""
+	}
+	status, err := queries.GetLeaderboardAnomalyStatus(ctx, GetLeaderboardAnomalyStatusParams{
+		LbType:   lbType,
+		Player:   player,
+		SnapDate: snapDate,
+	})
+	if err != nil {
+		return ""
+	}
+	return status
+}
+
+// GetLeaderboardAnomaly returns one held snapshot by ID.
+func GetLeaderboardAnomaly(id int64) (LeaderboardAnomaly, error) {
+	if queries == nil {
+		return LeaderboardAnomaly{}, sql.ErrNoRows
+	}
+	return queries.GetLeaderboardAnomaly(ctx, id)
+}
+
+// GetPendingLeaderboardAnomalies returns the flagged snapshots awaiting review, newest first.
+func GetPendingLeaderboardAnomalies() ([]LeaderboardAnomaly, error) {
+	if queries == nil {
+		return nil, nil
+	}
+	return queries.GetPendingLeaderboardAnomalies(ctx)
+}
+
+// GetHeldLeaderboardAnomalies returns the pending snapshots held with a flagged lb_type.
+func GetHeldLeaderboardAnomalies(player, snapDate, heldWith string) ([]LeaderboardAnomaly, error) {
+	if queries == nil {
+		return nil, nil
+	}
+	return queries.GetHeldLeaderboardAnomalies(ctx, GetHeldLeaderboardAnomaliesParams{
+		Player:   player,
+		SnapDate: snapDate,
+		HeldWith: heldWith,
+	})
+}
+
+// SetLeaderboardAnomalyStatus records the review decision for a held snapshot.
+func SetLeaderboardAnomalyStatus(id int64, status, reviewedBy string) error {
+	if queries == nil {
+		return nil
+	}
+	return queries.SetLeaderboardAnomalyStatus(ctx, SetLeaderboardAnomalyStatusParams{
+		Status:     status,
+		ReviewedBy: reviewedBy,
+		ReviewedAt: time.Now().Unix(),
+		ID:         id,
+	})
+}
+
+// RecordLeaderboardAnomalyHistory adds an accepted value to the recent values a
+// screened leaderboard is compared with, keeping the newest keep values.
+func RecordLeaderboardAnomalyHistory(lbType, player, snapDate string, value float64, keep int) error {
+	if queries == nil {
+		return nil
+	}
+	if err := queries.UpsertLeaderboardAnomalyHistory(ctx, UpsertLeaderboardAnomalyHistoryParams{
+		LbType:   lbType,
+		Player:   player,
+		SnapDate: snapDate,
+		Value:    value,
+	}); err != nil {
+		return err
+	}
+	return queries.PruneLeaderboardAnomalyHistory(ctx, PruneLeaderboardAnomalyHistoryParams{
+		LbType: lbType,
+		Player: player,
+		Limit:  int64(keep),
+	})
+}
+
+// GetLeaderboardAnomalyHistory returns the recent values of every screened
+// leaderboard for a player, ordered by lb_type, then snap_date DESC.
+func GetLeaderboardAnomalyHistory(player string) ([]LeaderboardAnomalyHistory, error) {
+	if queries == nil {
+		return nil, nil
+	}
+	return queries.GetLeaderboardAnomalyHistoryForPlayer(ctx, player)
+}
+
+// DeleteLeaderboardAnomaliesForPlayer removes every held snapshot and screened value for a player.
+func DeleteLeaderboardAnomaliesForPlayer(player string) error {
+	if queries == nil {
+		return nil
+	}
+	if err := queries.DeleteUserLeaderboardAnomalies(ctx, player); err != nil {
+		return err
+	}
+	return queries.DeleteUserLeaderboardAnomalyHistory(ctx, player)
+}
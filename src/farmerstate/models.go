@@ -27,6 +27,30 @@ type FarmerState struct {
 	Value sql.NullString
 }
 
+type LeaderboardAnomaly struct {
+	ID            int64
+	LbType        string
+	Player        string
+	GameName      string
+	SnapDate      string
+	Value         float64
+	Details       string
+	PriorValue    float64
+	PriorSnapDate string
+	Reason        string
+	HeldWith      string
+	Status        string
+	ReviewedBy    string
+	ReviewedAt    int64
+}
+
+type LeaderboardAnomalyHistory struct {
+	LbType   string
+	Player   string
+	SnapDate string
+	Value    float64
+}
+
 type LeaderboardExclusion struct {
 	GuildID string
 	UserID  string
@@ -48,6 +72,21 @@ type LeaderboardStat struct {
 	Details  sql.NullString
 }
 
+type SharedTimer struct {
+	ID        string
+	GuildID   string
+	ChannelID string
+	MsgID     string
+	CreatedBy string
+	Reminder  time.Time
+	Message   string
+}
+
+type SharedTimerParticipant struct {
+	TimerID string
+	UserID  string
+}
+
 type SuspectMission struct {
 	UserID           string
 	MissionID        string
@@ -76,6 +115,7 @@ type Timer struct {
 	OriginalChannelID string
 	OriginalMsgID     string
 	Active            bool
+	Recurrence        string
 }
 
 type Watch struct {
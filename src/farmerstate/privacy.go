@@ -1,18 +1,68 @@
 package farmerstate
 
 import (
+	"archive/zip"
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
 )
 
+// PrivacyStore exports and erases the data another package keeps about a user.
+// Packages that store user data outside farmerstate register one so /privacy
+// covers every store.
+type PrivacyStore struct {
+	// Export returns the user's data in a JSON friendly form, nil when there is none
+	Export func(userID string) (any, error)
+	// Erase removes the user, or replaces their ID with tombstone where a record
+	// has to be kept, such as a token log shared with other farmers
+	Erase func(userID string, tombstone string) error
+}
+
+var (
+	privacyStores      = make(map[string]PrivacyStore)
+	privacyStoresMutex sync.RWMutex
+)
+
+// RegisterPrivacyStore adds a named store to /privacy export and delete
+func RegisterPrivacyStore(name string, store PrivacyStore) {
+	privacyStoresMutex.Lock()
+	defer privacyStoresMutex.Unlock()
+	if _, exists := privacyStores[name]; exists {
+		panic(fmt.Sprintf("privacy store %q registered twice", name))
+	}
+	privacyStores[name] = store
+}
+
+func getPrivacyStoreNames() []string {
+	privacyStoresMutex.RLock()
+	defer privacyStoresMutex.RUnlock()
+	names := make([]string, 0, len(privacyStores))
+	for name := range privacyStores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getPrivacyStore(name string) PrivacyStore {
+	privacyStoresMutex.RLock()
+	defer privacyStoresMutex.RUnlock()
+	return privacyStores[name]
+}
+
 // GetSlashPrivacyCommand creates a new slash command for setting Egg, Inc name
 func GetSlashPrivacyCommand(cmd string) *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
@@ -29,38 +79,56 @@ func GetSlashPrivacyCommand(cmd string) *discordgo.ApplicationCommand {
 		},
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "enable-data-privacy",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "info",
+				Description: "Show what Boost Bot stores about you.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "settings",
 				Description: "Change your data privacy setting.",
-				Required:    false,
-				Choices: []*discordgo.ApplicationCommandOptionChoice{
+				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Name:  "Do not persist bot settings.",
-						Value: 1,
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "enable-data-privacy",
+						Description: "Change your data privacy setting.",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Do not persist bot settings.",
+								Value: 1,
+							},
+							{
+								Name:  "Allow the bot to store some information.",
+								Value: 0,
+							},
+						},
 					},
 					{
-						Name:  "Allow the bot to store some information.",
-						Value: 0,
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "confirm-request",
+						Description: "Confirm the privacy setting change, this removes your stored data.",
+						Required:    false,
 					},
 				},
 			},
 			{
-				Type:        discordgo.ApplicationCommandOptionBoolean,
-				Name:        "get-settings-data",
-				Description: "Retrieve a JSON file with your stored settings.",
-				Required:    false,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "export",
+				Description: "Download a JSON archive of everything Boost Bot stores about you.",
 			},
 			{
-				Type:        discordgo.ApplicationCommandOptionBoolean,
-				Name:        "remove-data",
-				Description: "Remove my data from the boost bot database.",
-				Required:    false,
-			},
-			{
-				Type:        discordgo.ApplicationCommandOptionBoolean,
-				Name:        "confirm-request",
-				Description: "Confirm privacy setting change or data removal.",
-				Required:    false,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Erase your data everywhere, shared contract history is anonymized.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "confirm-request",
+						Description: "Confirm the data removal.",
+						Required:    true,
+					},
+				},
 			},
 		},
 	}
@@ -68,13 +136,12 @@ func GetSlashPrivacyCommand(cmd string) *discordgo.ApplicationCommand {
 
 // HandlePrivacyCommand will handle the /privacy command
 func HandlePrivacyCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	var userID string
-	if i.GuildID != "" {
-		userID = i.Member.User.ID
-	} else {
-		userID = i.User.ID
-	}
+	userID := bottools.GetInteractionUserID(i)
 
+	subcommand := ""
+	if data := i.ApplicationCommandData(); len(data.Options) > 0 {
+		subcommand = data.Options[0].Name
+	}
 	optionMap := bottools.GetCommandOptionsMap(i)
 
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -90,97 +157,66 @@ func HandlePrivacyCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 	builder.WriteString("Boost Bot stores some information about your usage to provide you with a better experience and to improve the bot.\n")
 	builder.WriteString("Your Discord User ID is used as a key to this saved information.\n")
 	builder.WriteString("**This information is never sold.** It will only be shared with other Boost Bot developers or testers within the Bot's development Discord server.\n")
-	builder.WriteString("You can view this information at any time using the **get-settings-data** option.\n")
+	builder.WriteString("You can download this information at any time with **/privacy export** and erase it with **/privacy delete**.\n")
 	builder.WriteString("\n")
 	var filename string
-	var reader *bytes.Reader
+	var exportData []byte
 
-	userPrivacy := getDataPrivacy(userID)
-	removeData := false
 	confirmOption := false
-
-	// User must confirm data removal and/or privacy setting change
-	if opt, ok := optionMap["confirm-request"]; ok {
+	if opt, ok := optionMap[subcommand+"-confirm-request"]; ok {
 		confirmOption = opt.BoolValue()
 	}
 
-	if opt, ok := optionMap["enable-data-privacy"]; ok {
-		userPrivacy = opt.IntValue() == 1
+	switch subcommand {
+	case "settings":
+		userPrivacy := optionMap["settings-enable-data-privacy"].IntValue() == 1
 		if userPrivacy && confirmOption {
-			builder.WriteString("Boost Bot wil no longer store any persistent data about you.")
-			builder.WriteString("If you wish to store data again, you will need to re-enable it.")
-			builder.WriteString("If you interact with the bot for contracts and token tracking it will be stored temporarily and removed within a week of the last interaction of a contract or tracker.")
-			removeData = true
+			builder.WriteString("Boost Bot wil no longer store any persistent data about you. ")
+			builder.WriteString("If you wish to store data again, you will need to re-enable it. ")
+			builder.WriteString("If you interact with the bot for contracts and token tracking it will be stored temporarily and removed within a week of the last interaction of a contract or tracker.\n")
+			builder.WriteString("Your settings data have been removed from the Boost Bot database. ")
+			builder.WriteString("A default set of settings is now used along with your preference not to store data. ")
+			builder.WriteString("Use **/privacy delete** to also remove your contract history.")
+			removeUserSettings(userID)
+			setDataPrivacy(userID, true)
 		} else if userPrivacy && !confirmOption {
 			builder.WriteString("You have not confirmed the privacy setting change, use the **confirm-request** option.")
 		} else {
 			setDataPrivacy(userID, userPrivacy)
 			builder.WriteString("Boost Bot will store save a small amount of data about you. You can download this data at any time.")
 		}
-	}
-
-	if opt, ok := optionMap["remove-data"]; ok {
-		removeData = opt.BoolValue()
-		if removeData && !confirmOption {
-			builder.WriteString("You have not confirmed your data removal, use the **confirm-request** option.")
-			removeData = false
+	case "delete":
+		if !confirmOption {
+			builder.WriteString("You have not confirmed your data removal, set **confirm-request** to true.")
+			break
 		}
-	}
-
-	if removeData {
-		builder.WriteString("Your settings data have been removed from the Boost Bot database.")
-		builder.WriteString("A default set of settings is now used along with your preference not to store data.")
+		if err := EraseUserData(userID); err != nil {
+			log.Printf("Error erasing data for %s: %v", userID, err)
+			builder.WriteString("Some of your data could not be removed, please try again later.")
+			break
+		}
+		builder.WriteString("Your data has been removed from every Boost Bot store. ")
+		builder.WriteString("Contract history shared with other farmers now shows a deleted user in your place.")
+	case "export":
+		userData := GetFullUserData(userID)
 
-		// Want to remove all files from within ttbb-data/eiuserdata/ which contain this userID in the filename
-		userFilesPattern := "ttbb-data/eiuserdata/*" + userID + "*"
-		matches, err := filepath.Glob(userFilesPattern)
+		filename = "boostbot-data-" + userID + ".json"
+		jsonData, err := json.MarshalIndent(userData, "", "  ")
 		if err != nil {
-			log.Printf("Error finding user data files for deletion: %v", err)
+			log.Println(err.Error())
+			builder.WriteString("Error formatting JSON data. " + err.Error())
 		} else {
-			for _, file := range matches {
-				if err := os.Remove(file); err != nil {
-					log.Printf("Error deleting file %s: %v", file, err)
-				}
-			}
+			exportData = jsonData
 		}
-
-		DeleteFarmer(userID)
-
-		setDataPrivacy(userID, userPrivacy)
-	}
-
-	if opt, ok := optionMap["get-settings-data"]; ok {
-		// Return the users settings data in a JSON file to the user
-		getData := opt.BoolValue()
-		if getData {
-			userData := GetFullUserData(userID)
-
-			filename = "boostbot-data-" + userID + ".json"
-			buf := &bytes.Buffer{}
-			jsonData, err := json.Marshal(userData)
-			if err != nil {
-				log.Println(err.Error())
-				builder.WriteString("Error formatting JSON data. " + err.Error())
-			} else {
-				err = json.Indent(buf, jsonData, "", "  ")
-				if err != nil {
-					builder.WriteString("Error formatting JSON data. " + err.Error())
-				} else {
-					// Create io.Reader from JSON string
-					reader = bytes.NewReader(buf.Bytes())
-				}
-			}
+	default:
+		if getDataPrivacy(userID) {
+			builder.WriteString("Data privacy is **enabled** for your account.")
 		} else {
-			builder.WriteString("\nYou have not requested your settings data, use the **get-settings-data** option with a value of true.")
+			builder.WriteString("Data privacy is **disabled** for your account.")
 		}
 	}
-	if reader != nil {
-		builder.WriteString("Your settings data has been saved to a JSON file. You can view and download it.")
-		_, _ = s.FollowupMessageCreate(i.Interaction, true,
-			&discordgo.WebhookParams{
-				Content: builder.String(),
-				Files:   []*discordgo.File{{Name: filename, Reader: reader}},
-			})
+	if exportData != nil {
+		sendPrivacyExport(s, i, &builder, filename, exportData)
 	} else {
 		_, _ = s.FollowupMessageCreate(i.Interaction, true,
 			&discordgo.WebhookParams{
@@ -189,6 +225,60 @@ func HandlePrivacyCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 	}
 }
 
+// privacyExportUploadLimit is the largest file Discord accepts from the bot
+const privacyExportUploadLimit = 10 << 20
+
+// sendPrivacyExport uploads an export, zipping it when it is too large or the
+// plain upload fails, and tells the user when neither upload works
+func sendPrivacyExport(s *discordgo.Session, i *discordgo.InteractionCreate, builder *strings.Builder, filename string, data []byte) {
+	content := builder.String() + "Your data has been saved to a JSON file. You can view and download it."
+	var err error
+	if len(data) <= privacyExportUploadLimit {
+		_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: content,
+			Files:   []*discordgo.File{{Name: filename, ContentType: "application/json", Reader: bytes.NewReader(data)}},
+		})
+		if err == nil {
+			return
+		}
+		log.Printf("Error uploading privacy export %s: %v", filename, err)
+	}
+
+	zipped, zipErr := zipPrivacyExport(filename, data)
+	if zipErr == nil {
+		_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: builder.String() + "Your data was too large for Discord, it has been saved to a zipped JSON file.",
+			Files:   []*discordgo.File{{Name: strings.TrimSuffix(filename, ".json") + ".zip", ContentType: "application/zip", Reader: bytes.NewReader(zipped)}},
+		})
+		if err == nil {
+			return
+		}
+		log.Printf("Error uploading zipped privacy export %s: %v", filename, err)
+	} else {
+		err = zipErr
+	}
+	_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: builder.String() + "Your data could not be uploaded to Discord, please try again later. " + err.Error(),
+	})
+}
+
+// zipPrivacyExport compresses an export into a zip holding a single file
+func zipPrivacyExport(filename string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func getDataPrivacy(userID string) bool {
 	farmer := getFarmer(userID)
 	return farmer.DataPrivacy
@@ -211,9 +301,15 @@ type UserPrivacyData struct {
 	LeaderboardStats      []LeaderboardStat                    `json:"leaderboard_stats,omitempty"`
 	LeaderboardOptins     []GetLeaderboardOptInsForUserRow     `json:"leaderboard_optins,omitempty"`
 	LeaderboardExclusions []GetLeaderboardExclusionsForUserRow `json:"leaderboard_exclusions,omitempty"`
+	LeaderboardAnomalies  []LeaderboardAnomaly                 `json:"leaderboard_anomalies,omitempty"`
+	LeaderboardHistory    []LeaderboardAnomalyHistory          `json:"leaderboard_anomaly_history,omitempty"`
 	Watches               []Watch                              `json:"watches,omitempty"`
+	Stores                map[string]any                       `json:"stores,omitempty"`
+	StoreErrors           map[string]string                    `json:"store_errors,omitempty"`
 }
 
+// GetFullUserData gathers everything stored about a user, including the
+// registered privacy stores of other packages.
 func GetFullUserData(userID string) *UserPrivacyData {
 	data := &UserPrivacyData{}
 	data.FarmerState = getFarmer(userID)
@@ -240,9 +336,79 @@ func GetFullUserData(userID string) *UserPrivacyData {
 		if exclusions, err := queries.GetLeaderboardExclusionsForUser(ctx, userID); err == nil {
 			data.LeaderboardExclusions = exclusions
 		}
+		if anomalies, err := queries.GetLeaderboardAnomaliesForPlayer(ctx, userID); err == nil {
+			data.LeaderboardAnomalies = anomalies
+		}
+		if history, err := queries.GetLeaderboardAnomalyHistoryForPlayer(ctx, userID); err == nil {
+			data.LeaderboardHistory = history
+		}
 		if watches, err := queries.GetWatchesForUser(ctx, userID); err == nil {
 			data.Watches = watches
 		}
 	}
+
+	for _, name := range getPrivacyStoreNames() {
+		store := getPrivacyStore(name)
+		if store.Export == nil {
+			continue
+		}
+		exported, err := store.Export(userID)
+		if err != nil {
+			if data.StoreErrors == nil {
+				data.StoreErrors = make(map[string]string)
+			}
+			data.StoreErrors[name] = err.Error()
+			continue
+		}
+		if exported == nil {
+			continue
+		}
+		if data.Stores == nil {
+			data.Stores = make(map[string]any)
+		}
+		data.Stores[name] = exported
+	}
 	return data
 }
+
+// newPrivacyTombstone returns the ID that replaces an erased user in records
+// shared with other farmers. It is random so it can't be traced back.
+func newPrivacyTombstone() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "deleted-" + hex.EncodeToString(b)
+}
+
+// removeUserSettings deletes the farmer record and the cached Egg Inc files of
+// a user who turned data privacy on. Contract history is left to /privacy delete.
+func removeUserSettings(userID string) {
+	// Want to remove all files from within ttbb-data/eiuserdata/ which contain this userID in the filename
+	matches, err := filepath.Glob("ttbb-data/eiuserdata/*" + userID + "*")
+	if err != nil {
+		log.Printf("Error finding user data files for deletion: %v", err)
+	}
+	for _, file := range matches {
+		if err := os.Remove(file); err != nil {
+			log.Printf("Error deleting file %s: %v", file, err)
+		}
+	}
+	DeleteFarmer(userID)
+}
+
+// EraseUserData removes a user from every registered store and then deletes
+// their farmer record. Stores are all attempted even when one fails.
+func EraseUserData(userID string) error {
+	tombstone := newPrivacyTombstone()
+	var errs []error
+	for _, name := range getPrivacyStoreNames() {
+		store := getPrivacyStore(name)
+		if store.Erase == nil {
+			continue
+		}
+		if err := store.Erase(userID, tombstone); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	DeleteFarmer(userID)
+	return errors.Join(errs...)
+}
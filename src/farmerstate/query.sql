@@ -96,11 +96,11 @@ DELETE FROM custom_banners WHERE user_id = ? AND guild_id = ?;
 SELECT image_data, updated_at FROM custom_banners WHERE user_id = ? AND guild_id = ?;
 
 -- name: GetTimers :many
-SELECT id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active FROM timers;
+SELECT id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active, recurrence FROM timers;
 
 -- name: InsertTimer :exec
-INSERT INTO timers (id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO timers (id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active, recurrence)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: UpdateTimerState :exec
 UPDATE timers SET active = ? WHERE id = ?;
@@ -108,6 +108,9 @@ UPDATE timers SET active = ? WHERE id = ?;
 -- name: UpdateTimerMsg :exec
 UPDATE timers SET channel_id = ?, msg_id = ? WHERE id = ?;
 
+-- name: UpdateTimerReminder :exec
+UPDATE timers SET reminder = ?, active = ? WHERE id = ?;
+
 -- name: DeleteTimer :exec
 DELETE FROM timers WHERE id = ?;
 
@@ -220,6 +223,11 @@ ORDER BY s.lb_type ASC, s.snap_date DESC;
 DELETE FROM leaderboard_stats
 WHERE player = ? AND lb_type = ?;
 
+-- name: DeleteLeaderboardStatForPlayerAndSnapDate :exec
+-- Deletes one snapshot when thinning the history of a RetainRecentOnly leaderboard.
+DELETE FROM leaderboard_stats
+WHERE lb_type = ? AND player = ? AND snap_date = ?;
+
 -- name: DeleteAllLeaderboardStatsForPlayerInGuild :exec
 -- No-op since leaderboard_stats is now global.
 SELECT 1;
@@ -242,6 +250,77 @@ WHERE guild_id = ? AND user_id = ? AND lb_type = ?;
 DELETE FROM leaderboard_exclusion
 WHERE guild_id = ? AND user_id = ?;
 
+-- name: UpsertLeaderboardAnomaly :exec
+-- Records a snapshot held for review. Reviewed anomalies keep their decision.
+INSERT INTO leaderboard_anomaly (lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(lb_type, player, snap_date) DO UPDATE SET
+    game_name       = excluded.game_name,
+    value           = excluded.value,
+    details         = excluded.details,
+    prior_value     = excluded.prior_value,
+    prior_snap_date = excluded.prior_snap_date,
+    reason          = excluded.reason,
+    held_with       = excluded.held_with
+WHERE leaderboard_anomaly.status = 'pending';
+
+-- name: GetLeaderboardAnomalyStatus :one
+-- Returns the review status of a held snapshot.
+SELECT status FROM leaderboard_anomaly
+WHERE lb_type = ? AND player = ? AND snap_date = ?;
+
+-- name: GetLeaderboardAnomaly :one
+SELECT id, lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with, status, reviewed_by, reviewed_at
+FROM leaderboard_anomaly
+WHERE id = ?;
+
+-- name: GetPendingLeaderboardAnomalies :many
+-- Returns the flagged snapshots awaiting review, newest first. Snapshots held with another are left out.
+SELECT id, lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with, status, reviewed_by, reviewed_at
+FROM leaderboard_anomaly
+WHERE status = 'pending' AND held_with = ''
+ORDER BY snap_date DESC, id ASC;
+
+-- name: GetHeldLeaderboardAnomalies :many
+-- Returns the pending snapshots held with a flagged lb_type for the same player and snap_date.
+SELECT id, lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with, status, reviewed_by, reviewed_at
+FROM leaderboard_anomaly
+WHERE player = ? AND snap_date = ? AND held_with = ? AND status = 'pending';
+
+-- name: SetLeaderboardAnomalyStatus :exec
+UPDATE leaderboard_anomaly
+SET status = ?, reviewed_by = ?, reviewed_at = ?
+WHERE id = ?;
+
+-- name: GetLeaderboardAnomaliesForPlayer :many
+SELECT id, lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with, status, reviewed_by, reviewed_at
+FROM leaderboard_anomaly
+WHERE player = ?
+ORDER BY snap_date DESC, lb_type ASC;
+
+-- name: UpsertLeaderboardAnomalyHistory :exec
+-- Records an accepted value of a screened leaderboard.
+INSERT INTO leaderboard_anomaly_history (lb_type, player, snap_date, value)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(lb_type, player, snap_date) DO UPDATE SET
+    value = excluded.value;
+
+-- name: GetLeaderboardAnomalyHistoryForPlayer :many
+SELECT lb_type, player, snap_date, value
+FROM leaderboard_anomaly_history
+WHERE player = ?
+ORDER BY lb_type ASC, snap_date DESC;
+
+-- name: PruneLeaderboardAnomalyHistory :exec
+-- Keeps only the most recent values of a screened leaderboard for a player.
+DELETE FROM leaderboard_anomaly_history
+WHERE lb_type = ?1 AND player = ?2 AND snap_date NOT IN (
+    SELECT snap_date FROM leaderboard_anomaly_history
+    WHERE lb_type = ?1 AND player = ?2
+    ORDER BY snap_date DESC
+    LIMIT ?3
+);
+
 -- name: PruneOlderLeaderboardStatsForPlayer :exec
 -- Deletes older leaderboard stats for a player if RetainRecentOnly is true.
 DELETE FROM leaderboard_stats
@@ -267,7 +346,7 @@ DELETE FROM watches WHERE user_id = ?;
 SELECT user_id, guild_id, image_data, updated_at FROM custom_banners WHERE user_id = ?;
 
 -- name: GetTimersForUser :many
-SELECT id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active FROM timers WHERE user_id = ?;
+SELECT id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active, recurrence FROM timers WHERE user_id = ?;
 
 -- name: DeleteUserGuildMemberships :exec
 DELETE FROM farmer_guild_membership
@@ -297,3 +376,42 @@ WHERE user_id = ?;
 DELETE FROM leaderboard_exclusion
 WHERE user_id = ?;
 
+-- name: DeleteUserLeaderboardAnomalies :exec
+DELETE FROM leaderboard_anomaly
+WHERE player = ?;
+
+-- name: DeleteUserLeaderboardAnomalyHistory :exec
+DELETE FROM leaderboard_anomaly_history
+WHERE player = ?;
+
+-- name: GetSharedTimers :many
+SELECT id, guild_id, channel_id, msg_id, created_by, reminder, message FROM shared_timers;
+
+-- name: GetSharedTimersForUser :many
+SELECT id, guild_id, channel_id, msg_id, created_by, reminder, message FROM shared_timers
+WHERE created_by = ? OR id IN (SELECT timer_id FROM shared_timer_participants WHERE user_id = ?);
+
+-- name: InsertSharedTimer :exec
+INSERT INTO shared_timers (id, guild_id, channel_id, msg_id, created_by, reminder, message)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: UpdateSharedTimerCreatedBy :exec
+UPDATE shared_timers SET created_by = ? WHERE created_by = ?;
+
+-- name: DeleteSharedTimer :exec
+DELETE FROM shared_timers WHERE id = ?;
+
+-- name: GetSharedTimerParticipants :many
+SELECT user_id FROM shared_timer_participants WHERE timer_id = ? ORDER BY rowid;
+
+-- name: InsertSharedTimerParticipant :exec
+INSERT OR IGNORE INTO shared_timer_participants (timer_id, user_id) VALUES (?, ?);
+
+-- name: DeleteSharedTimerParticipant :exec
+DELETE FROM shared_timer_participants WHERE timer_id = ? AND user_id = ?;
+
+-- name: DeleteSharedTimerParticipants :exec
+DELETE FROM shared_timer_participants WHERE timer_id = ?;
+
+-- name: DeleteUserSharedTimerParticipants :exec
+DELETE FROM shared_timer_participants WHERE user_id = ?;
@@ -158,6 +158,23 @@ func (q *Queries) DeleteLeaderboardOptIn(ctx context.Context, arg DeleteLeaderbo
 	return err
 }
 
+const deleteLeaderboardStatForPlayerAndSnapDate = `-- name: DeleteLeaderboardStatForPlayerAndSnapDate :exec
+DELETE FROM leaderboard_stats
+WHERE lb_type = ? AND player = ? AND snap_date = ?
+`
+
+type DeleteLeaderboardStatForPlayerAndSnapDateParams struct {
+	LbType   string
+	Player   string
+	SnapDate string
+}
+
+// Deletes one snapshot when thinning the history of a RetainRecentOnly leaderboard.
+func (q *Queries) DeleteLeaderboardStatForPlayerAndSnapDate(ctx context.Context, arg DeleteLeaderboardStatForPlayerAndSnapDateParams) error {
+	_, err := q.db.ExecContext(ctx, deleteLeaderboardStatForPlayerAndSnapDate, arg.LbType, arg.Player, arg.SnapDate)
+	return err
+}
+
 const deleteLeaderboardStatsForPlayer = `-- name: DeleteLeaderboardStatsForPlayer :exec
 DELETE FROM leaderboard_stats
 WHERE player = ? AND lb_type = ?
@@ -183,6 +200,38 @@ func (q *Queries) DeleteLegacyFarmerstate(ctx context.Context, id string) error
 	return err
 }
 
+const deleteSharedTimer = `-- name: DeleteSharedTimer :exec
+DELETE FROM shared_timers WHERE id = ?
+`
+
+func (q *Queries) DeleteSharedTimer(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteSharedTimer, id)
+	return err
+}
+
+const deleteSharedTimerParticipant = `-- name: DeleteSharedTimerParticipant :exec
+DELETE FROM shared_timer_participants WHERE timer_id = ? AND user_id = ?
+`
+
+type DeleteSharedTimerParticipantParams struct {
+	TimerID string
+	UserID  string
+}
+
+func (q *Queries) DeleteSharedTimerParticipant(ctx context.Context, arg DeleteSharedTimerParticipantParams) error {
+	_, err := q.db.ExecContext(ctx, deleteSharedTimerParticipant, arg.TimerID, arg.UserID)
+	return err
+}
+
+const deleteSharedTimerParticipants = `-- name: DeleteSharedTimerParticipants :exec
+DELETE FROM shared_timer_participants WHERE timer_id = ?
+`
+
+func (q *Queries) DeleteSharedTimerParticipants(ctx context.Context, timerID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSharedTimerParticipants, timerID)
+	return err
+}
+
 const deleteTimer = `-- name: DeleteTimer :exec
 DELETE FROM timers WHERE id = ?
 `
@@ -212,6 +261,26 @@ func (q *Queries) DeleteUserGuildMemberships(ctx context.Context, userID string)
 	return err
 }
 
+const deleteUserLeaderboardAnomalies = `-- name: DeleteUserLeaderboardAnomalies :exec
+DELETE FROM leaderboard_anomaly
+WHERE player = ?
+`
+
+func (q *Queries) DeleteUserLeaderboardAnomalies(ctx context.Context, player string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserLeaderboardAnomalies, player)
+	return err
+}
+
+const deleteUserLeaderboardAnomalyHistory = `-- name: DeleteUserLeaderboardAnomalyHistory :exec
+DELETE FROM leaderboard_anomaly_history
+WHERE player = ?
+`
+
+func (q *Queries) DeleteUserLeaderboardAnomalyHistory(ctx context.Context, player string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserLeaderboardAnomalyHistory, player)
+	return err
+}
+
 const deleteUserLeaderboardExclusions = `-- name: DeleteUserLeaderboardExclusions :exec
 DELETE FROM leaderboard_exclusion
 WHERE user_id = ?
@@ -242,6 +311,15 @@ func (q *Queries) DeleteUserLeaderboardStats(ctx context.Context, player string)
 	return err
 }
 
+const deleteUserSharedTimerParticipants = `-- name: DeleteUserSharedTimerParticipants :exec
+DELETE FROM shared_timer_participants WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserSharedTimerParticipants(ctx context.Context, userID string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserSharedTimerParticipants, userID)
+	return err
+}
+
 const deleteUserSuspectMissions = `-- name: DeleteUserSuspectMissions :exec
 DELETE FROM suspect_missions
 WHERE user_id = ?
@@ -493,6 +571,57 @@ func (q *Queries) GetGuildMembers(ctx context.Context, guildID string) ([]string
 	return items, nil
 }
 
+const getHeldLeaderboardAnomalies = `-- name: GetHeldLeaderboardAnomalies :many
+SELECT id, lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with, status, reviewed_by, reviewed_at
+FROM leaderboard_anomaly
+WHERE player = ? AND snap_date = ? AND held_with = ? AND status = 'pending'
+`
+
+type GetHeldLeaderboardAnomaliesParams struct {
+	Player   string
+	SnapDate string
+	HeldWith string
+}
+
+// Returns the pending snapshots held with a flagged lb_type for the same player and snap_date.
+func (q *Queries) GetHeldLeaderboardAnomalies(ctx context.Context, arg GetHeldLeaderboardAnomaliesParams) ([]LeaderboardAnomaly, error) {
+	rows, err := q.db.QueryContext(ctx, getHeldLeaderboardAnomalies, arg.Player, arg.SnapDate, arg.HeldWith)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardAnomaly
+	for rows.Next() {
+		var i LeaderboardAnomaly
+		if err := rows.Scan(
+			&i.ID,
+			&i.LbType,
+			&i.Player,
+			&i.GameName,
+			&i.SnapDate,
+			&i.Value,
+			&i.Details,
+			&i.PriorValue,
+			&i.PriorSnapDate,
+			&i.Reason,
+			&i.HeldWith,
+			&i.Status,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getIdsByMiscString = `-- name: GetIdsByMiscString :many
 SELECT id
 FROM farmer_state
@@ -542,6 +671,133 @@ func (q *Queries) GetLatestLeaderboardSnapDate(ctx context.Context, lbType strin
 	return snap_date, err
 }
 
+const getLeaderboardAnomaliesForPlayer = `-- name: GetLeaderboardAnomaliesForPlayer :many
+SELECT id, lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with, status, reviewed_by, reviewed_at
+FROM leaderboard_anomaly
+WHERE player = ?
+ORDER BY snap_date DESC, lb_type ASC
+`
+
+func (q *Queries) GetLeaderboardAnomaliesForPlayer(ctx context.Context, player string) ([]LeaderboardAnomaly, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardAnomaliesForPlayer, player)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardAnomaly
+	for rows.Next() {
+		var i LeaderboardAnomaly
+		if err := rows.Scan(
+			&i.ID,
+			&i.LbType,
+			&i.Player,
+			&i.GameName,
+			&i.SnapDate,
+			&i.Value,
+			&i.Details,
+			&i.PriorValue,
+			&i.PriorSnapDate,
+			&i.Reason,
+			&i.HeldWith,
+			&i.Status,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardAnomaly = `-- name: GetLeaderboardAnomaly :one
+SELECT id, lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with, status, reviewed_by, reviewed_at
+FROM leaderboard_anomaly
+WHERE id = ?
+`
+
+func (q *Queries) GetLeaderboardAnomaly(ctx context.Context, id int64) (LeaderboardAnomaly, error) {
+	row := q.db.QueryRowContext(ctx, getLeaderboardAnomaly, id)
+	var i LeaderboardAnomaly
+	err := row.Scan(
+		&i.ID,
+		&i.LbType,
+		&i.Player,
+		&i.GameName,
+		&i.SnapDate,
+		&i.Value,
+		&i.Details,
+		&i.PriorValue,
+		&i.PriorSnapDate,
+		&i.Reason,
+		&i.HeldWith,
+		&i.Status,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
+
+const getLeaderboardAnomalyHistoryForPlayer = `-- name: GetLeaderboardAnomalyHistoryForPlayer :many
+SELECT lb_type, player, snap_date, value
+FROM leaderboard_anomaly_history
+WHERE player = ?
+ORDER BY lb_type ASC, snap_date DESC
+`
+
+func (q *Queries) GetLeaderboardAnomalyHistoryForPlayer(ctx context.Context, player string) ([]LeaderboardAnomalyHistory, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardAnomalyHistoryForPlayer, player)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardAnomalyHistory
+	for rows.Next() {
+		var i LeaderboardAnomalyHistory
+		if err := rows.Scan(
+			&i.LbType,
+			&i.Player,
+			&i.SnapDate,
+			&i.Value,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardAnomalyStatus = `-- name: GetLeaderboardAnomalyStatus :one
+SELECT status FROM leaderboard_anomaly
+WHERE lb_type = ? AND player = ? AND snap_date = ?
+`
+
+type GetLeaderboardAnomalyStatusParams struct {
+	LbType   string
+	Player   string
+	SnapDate string
+}
+
+// Returns the review status of a held snapshot.
+func (q *Queries) GetLeaderboardAnomalyStatus(ctx context.Context, arg GetLeaderboardAnomalyStatusParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getLeaderboardAnomalyStatus, arg.LbType, arg.Player, arg.SnapDate)
+	var status string
+	err := row.Scan(&status)
+	return status, err
+}
+
 const getLeaderboardExclusionsForUser = `-- name: GetLeaderboardExclusionsForUser :many
 SELECT guild_id, lb_type FROM leaderboard_exclusion
 WHERE user_id = ?
@@ -835,6 +1091,155 @@ func (q *Queries) GetLegacyFarmerstate(ctx context.Context, id string) (FarmerSt
 	return i, err
 }
 
+const getPendingLeaderboardAnomalies = `-- name: GetPendingLeaderboardAnomalies :many
+SELECT id, lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with, status, reviewed_by, reviewed_at
+FROM leaderboard_anomaly
+WHERE status = 'pending' AND held_with = ''
+ORDER BY snap_date DESC, id ASC
+`
+
+// Returns the flagged snapshots awaiting review, newest first. Snapshots held with another are left out.
+func (q *Queries) GetPendingLeaderboardAnomalies(ctx context.Context) ([]LeaderboardAnomaly, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingLeaderboardAnomalies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardAnomaly
+	for rows.Next() {
+		var i LeaderboardAnomaly
+		if err := rows.Scan(
+			&i.ID,
+			&i.LbType,
+			&i.Player,
+			&i.GameName,
+			&i.SnapDate,
+			&i.Value,
+			&i.Details,
+			&i.PriorValue,
+			&i.PriorSnapDate,
+			&i.Reason,
+			&i.HeldWith,
+			&i.Status,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSharedTimerParticipants = `-- name: GetSharedTimerParticipants :many
+SELECT user_id FROM shared_timer_participants WHERE timer_id = ? ORDER BY rowid
+`
+
+func (q *Queries) GetSharedTimerParticipants(ctx context.Context, timerID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getSharedTimerParticipants, timerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var user_id string
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSharedTimers = `-- name: GetSharedTimers :many
+SELECT id, guild_id, channel_id, msg_id, created_by, reminder, message FROM shared_timers
+`
+
+func (q *Queries) GetSharedTimers(ctx context.Context) ([]SharedTimer, error) {
+	rows, err := q.db.QueryContext(ctx, getSharedTimers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SharedTimer
+	for rows.Next() {
+		var i SharedTimer
+		if err := rows.Scan(
+			&i.ID,
+			&i.GuildID,
+			&i.ChannelID,
+			&i.MsgID,
+			&i.CreatedBy,
+			&i.Reminder,
+			&i.Message,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSharedTimersForUser = `-- name: GetSharedTimersForUser :many
+SELECT id, guild_id, channel_id, msg_id, created_by, reminder, message FROM shared_timers
+WHERE created_by = ? OR id IN (SELECT timer_id FROM shared_timer_participants WHERE user_id = ?)
+`
+
+type GetSharedTimersForUserParams struct {
+	CreatedBy string
+	UserID    string
+}
+
+func (q *Queries) GetSharedTimersForUser(ctx context.Context, arg GetSharedTimersForUserParams) ([]SharedTimer, error) {
+	rows, err := q.db.QueryContext(ctx, getSharedTimersForUser, arg.CreatedBy, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SharedTimer
+	for rows.Next() {
+		var i SharedTimer
+		if err := rows.Scan(
+			&i.ID,
+			&i.GuildID,
+			&i.ChannelID,
+			&i.MsgID,
+			&i.CreatedBy,
+			&i.Reminder,
+			&i.Message,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getStatsForPlayer = `-- name: GetStatsForPlayer :many
 SELECT lb_type, player, game_name, snap_date, value, details
 FROM leaderboard_stats
@@ -962,7 +1367,7 @@ func (q *Queries) GetSuspectMissions(ctx context.Context, userID string) ([]Susp
 }
 
 const getTimers = `-- name: GetTimers :many
-SELECT id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active FROM timers
+SELECT id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active, recurrence FROM timers
 `
 
 func (q *Queries) GetTimers(ctx context.Context) ([]Timer, error) {
@@ -985,6 +1390,7 @@ func (q *Queries) GetTimers(ctx context.Context) ([]Timer, error) {
 			&i.OriginalChannelID,
 			&i.OriginalMsgID,
 			&i.Active,
+			&i.Recurrence,
 		); err != nil {
 			return nil, err
 		}
@@ -1000,7 +1406,7 @@ func (q *Queries) GetTimers(ctx context.Context) ([]Timer, error) {
 }
 
 const getTimersForUser = `-- name: GetTimersForUser :many
-SELECT id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active FROM timers WHERE user_id = ?
+SELECT id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active, recurrence FROM timers WHERE user_id = ?
 `
 
 func (q *Queries) GetTimersForUser(ctx context.Context, userID string) ([]Timer, error) {
@@ -1023,6 +1429,7 @@ func (q *Queries) GetTimersForUser(ctx context.Context, userID string) ([]Timer,
 			&i.OriginalChannelID,
 			&i.OriginalMsgID,
 			&i.Active,
+			&i.Recurrence,
 		); err != nil {
 			return nil, err
 		}
@@ -1133,6 +1540,48 @@ func (q *Queries) InsertLegacyFarmerstate(ctx context.Context, arg InsertLegacyF
 	return i, err
 }
 
+const insertSharedTimer = `-- name: InsertSharedTimer :exec
+INSERT INTO shared_timers (id, guild_id, channel_id, msg_id, created_by, reminder, message)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type InsertSharedTimerParams struct {
+	ID        string
+	GuildID   string
+	ChannelID string
+	MsgID     string
+	CreatedBy string
+	Reminder  time.Time
+	Message   string
+}
+
+func (q *Queries) InsertSharedTimer(ctx context.Context, arg InsertSharedTimerParams) error {
+	_, err := q.db.ExecContext(ctx, insertSharedTimer,
+		arg.ID,
+		arg.GuildID,
+		arg.ChannelID,
+		arg.MsgID,
+		arg.CreatedBy,
+		arg.Reminder,
+		arg.Message,
+	)
+	return err
+}
+
+const insertSharedTimerParticipant = `-- name: InsertSharedTimerParticipant :exec
+INSERT OR IGNORE INTO shared_timer_participants (timer_id, user_id) VALUES (?, ?)
+`
+
+type InsertSharedTimerParticipantParams struct {
+	TimerID string
+	UserID  string
+}
+
+func (q *Queries) InsertSharedTimerParticipant(ctx context.Context, arg InsertSharedTimerParticipantParams) error {
+	_, err := q.db.ExecContext(ctx, insertSharedTimerParticipant, arg.TimerID, arg.UserID)
+	return err
+}
+
 const insertSuspectMission = `-- name: InsertSuspectMission :exec
 INSERT OR IGNORE INTO suspect_missions (
     user_id, mission_id, ship, status, duration_type, mission_type,
@@ -1181,8 +1630,8 @@ func (q *Queries) InsertSuspectMission(ctx context.Context, arg InsertSuspectMis
 }
 
 const insertTimer = `-- name: InsertTimer :exec
-INSERT INTO timers (id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO timers (id, user_id, channel_id, msg_id, reminder, message, duration, original_channel_id, original_msg_id, active, recurrence)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type InsertTimerParams struct {
@@ -1196,6 +1645,7 @@ type InsertTimerParams struct {
 	OriginalChannelID string
 	OriginalMsgID     string
 	Active            bool
+	Recurrence        string
 }
 
 func (q *Queries) InsertTimer(ctx context.Context, arg InsertTimerParams) error {
@@ -1210,6 +1660,7 @@ func (q *Queries) InsertTimer(ctx context.Context, arg InsertTimerParams) error
 		arg.OriginalChannelID,
 		arg.OriginalMsgID,
 		arg.Active,
+		arg.Recurrence,
 	)
 	return err
 }
@@ -1230,6 +1681,28 @@ func (q *Queries) InsertWatch(ctx context.Context, arg InsertWatchParams) error
 	return err
 }
 
+const pruneLeaderboardAnomalyHistory = `-- name: PruneLeaderboardAnomalyHistory :exec
+DELETE FROM leaderboard_anomaly_history
+WHERE lb_type = ?1 AND player = ?2 AND snap_date NOT IN (
+    SELECT snap_date FROM leaderboard_anomaly_history
+    WHERE lb_type = ?1 AND player = ?2
+    ORDER BY snap_date DESC
+    LIMIT ?3
+)
+`
+
+type PruneLeaderboardAnomalyHistoryParams struct {
+	LbType string
+	Player string
+	Limit  int64
+}
+
+// Keeps only the most recent values of a screened leaderboard for a player.
+func (q *Queries) PruneLeaderboardAnomalyHistory(ctx context.Context, arg PruneLeaderboardAnomalyHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, pruneLeaderboardAnomalyHistory, arg.LbType, arg.Player, arg.Limit)
+	return err
+}
+
 const pruneOlderLeaderboardStatsForPlayer = `-- name: PruneOlderLeaderboardStatsForPlayer :exec
 DELETE FROM leaderboard_stats
 WHERE lb_type = ? AND player = ? AND snap_date != ?
@@ -1262,6 +1735,29 @@ func (q *Queries) RemoveGuildMembership(ctx context.Context, arg RemoveGuildMemb
 	return err
 }
 
+const setLeaderboardAnomalyStatus = `-- name: SetLeaderboardAnomalyStatus :exec
+UPDATE leaderboard_anomaly
+SET status = ?, reviewed_by = ?, reviewed_at = ?
+WHERE id = ?
+`
+
+type SetLeaderboardAnomalyStatusParams struct {
+	Status     string
+	ReviewedBy string
+	ReviewedAt int64
+	ID         int64
+}
+
+func (q *Queries) SetLeaderboardAnomalyStatus(ctx context.Context, arg SetLeaderboardAnomalyStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setLeaderboardAnomalyStatus,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewedAt,
+		arg.ID,
+	)
+	return err
+}
+
 const updateLegacyFarmerstate = `-- name: UpdateLegacyFarmerstate :execrows
 UPDATE farmer_state
 SET value = ?
@@ -1281,6 +1777,20 @@ func (q *Queries) UpdateLegacyFarmerstate(ctx context.Context, arg UpdateLegacyF
 	return result.RowsAffected()
 }
 
+const updateSharedTimerCreatedBy = `-- name: UpdateSharedTimerCreatedBy :exec
+UPDATE shared_timers SET created_by = ? WHERE created_by = ?
+`
+
+type UpdateSharedTimerCreatedByParams struct {
+	CreatedBy   string
+	CreatedBy_2 string
+}
+
+func (q *Queries) UpdateSharedTimerCreatedBy(ctx context.Context, arg UpdateSharedTimerCreatedByParams) error {
+	_, err := q.db.ExecContext(ctx, updateSharedTimerCreatedBy, arg.CreatedBy, arg.CreatedBy_2)
+	return err
+}
+
 const updateTimerMsg = `-- name: UpdateTimerMsg :exec
 UPDATE timers SET channel_id = ?, msg_id = ? WHERE id = ?
 `
@@ -1296,6 +1806,21 @@ func (q *Queries) UpdateTimerMsg(ctx context.Context, arg UpdateTimerMsgParams)
 	return err
 }
 
+const updateTimerReminder = `-- name: UpdateTimerReminder :exec
+UPDATE timers SET reminder = ?, active = ? WHERE id = ?
+`
+
+type UpdateTimerReminderParams struct {
+	Reminder time.Time
+	Active   bool
+	ID       string
+}
+
+func (q *Queries) UpdateTimerReminder(ctx context.Context, arg UpdateTimerReminderParams) error {
+	_, err := q.db.ExecContext(ctx, updateTimerReminder, arg.Reminder, arg.Active, arg.ID)
+	return err
+}
+
 const updateTimerState = `-- name: UpdateTimerState :exec
 UPDATE timers SET active = ? WHERE id = ?
 `
@@ -1329,6 +1854,75 @@ func (q *Queries) UpsertCustomBanner(ctx context.Context, arg UpsertCustomBanner
 	return err
 }
 
+const upsertLeaderboardAnomaly = `-- name: UpsertLeaderboardAnomaly :exec
+INSERT INTO leaderboard_anomaly (lb_type, player, game_name, snap_date, value, details, prior_value, prior_snap_date, reason, held_with)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(lb_type, player, snap_date) DO UPDATE SET
+    game_name       = excluded.game_name,
+    value           = excluded.value,
+    details         = excluded.details,
+    prior_value     = excluded.prior_value,
+    prior_snap_date = excluded.prior_snap_date,
+    reason          = excluded.reason,
+    held_with       = excluded.held_with
+WHERE leaderboard_anomaly.status = 'pending'
+`
+
+type UpsertLeaderboardAnomalyParams struct {
+	LbType        string
+	Player        string
+	GameName      string
+	SnapDate      string
+	Value         float64
+	Details       string
+	PriorValue    float64
+	PriorSnapDate string
+	Reason        string
+	HeldWith      string
+}
+
+// Records a snapshot held for review. Reviewed anomalies keep their decision.
+func (q *Queries) UpsertLeaderboardAnomaly(ctx context.Context, arg UpsertLeaderboardAnomalyParams) error {
+	_, err := q.db.ExecContext(ctx, upsertLeaderboardAnomaly,
+		arg.LbType,
+		arg.Player,
+		arg.GameName,
+		arg.SnapDate,
+		arg.Value,
+		arg.Details,
+		arg.PriorValue,
+		arg.PriorSnapDate,
+		arg.Reason,
+		arg.HeldWith,
+	)
+	return err
+}
+
+const upsertLeaderboardAnomalyHistory = `-- name: UpsertLeaderboardAnomalyHistory :exec
+INSERT INTO leaderboard_anomaly_history (lb_type, player, snap_date, value)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(lb_type, player, snap_date) DO UPDATE SET
+    value = excluded.value
+`
+
+type UpsertLeaderboardAnomalyHistoryParams struct {
+	LbType   string
+	Player   string
+	SnapDate string
+	Value    float64
+}
+
+// Records an accepted value of a screened leaderboard.
+func (q *Queries) UpsertLeaderboardAnomalyHistory(ctx context.Context, arg UpsertLeaderboardAnomalyHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, upsertLeaderboardAnomalyHistory,
+		arg.LbType,
+		arg.Player,
+		arg.SnapDate,
+		arg.Value,
+	)
+	return err
+}
+
 const upsertLeaderboardExclusion = `-- name: UpsertLeaderboardExclusion :exec
 INSERT INTO leaderboard_exclusion (guild_id, user_id, lb_type)
 VALUES (?, ?, ?)
@@ -84,6 +84,32 @@ CREATE TABLE IF NOT EXISTS leaderboard_exclusion (
     PRIMARY KEY (guild_id, user_id, lb_type)
 );
 
+CREATE TABLE IF NOT EXISTS leaderboard_anomaly (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    lb_type         TEXT NOT NULL,
+    player          TEXT NOT NULL,  -- Discord user ID
+    game_name       TEXT NOT NULL,
+    snap_date       TEXT NOT NULL,
+    value           REAL NOT NULL,
+    details         TEXT NOT NULL DEFAULT '',
+    prior_value     REAL NOT NULL,
+    prior_snap_date TEXT NOT NULL,
+    reason          TEXT NOT NULL,            -- why the snapshot was held out of the leaderboards
+    held_with       TEXT NOT NULL DEFAULT '', -- flagged lb_type this derived snapshot is held with
+    status          TEXT NOT NULL DEFAULT 'pending',  -- pending, approved or rejected
+    reviewed_by     TEXT NOT NULL DEFAULT '',
+    reviewed_at     INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (lb_type, player, snap_date)
+);
+
+CREATE TABLE IF NOT EXISTS leaderboard_anomaly_history (
+    lb_type   TEXT NOT NULL,
+    player    TEXT NOT NULL,  -- Discord user ID
+    snap_date TEXT NOT NULL,
+    value     REAL NOT NULL,  -- recent accepted values screened snapshots are compared with
+    PRIMARY KEY (lb_type, player, snap_date)
+);
+
 CREATE TABLE IF NOT EXISTS watches (
     user_id TEXT NOT NULL,
     watch_type TEXT NOT NULL, -- 'contract' or 'colleggtible'
@@ -92,3 +118,22 @@ CREATE TABLE IF NOT EXISTS watches (
     PRIMARY KEY (user_id, watch_type, target_id)
 );
 
+CREATE TABLE IF NOT EXISTS shared_timers (
+    id TEXT PRIMARY KEY NOT NULL,
+    guild_id TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    msg_id TEXT NOT NULL, -- channel message participants opt in from
+    created_by TEXT NOT NULL,
+    reminder TIMESTAMP NOT NULL,
+    message TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS shared_timer_participants (
+    timer_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    PRIMARY KEY (timer_id, user_id)
+);
+
+-- Columns added after their table was created. On existing databases the
+-- duplicate column error is ignored by the statement-by-statement setup.
+ALTER TABLE timers ADD COLUMN recurrence TEXT NOT NULL DEFAULT ''; -- JSON encoded repeat rule, empty for one-shot timers
@@ -0,0 +1,88 @@
+package farmerstate
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// GetAllSharedTimers returns all guild shared timers from the database.
+func GetAllSharedTimers() []SharedTimer {
+	FlushPendingSaves()
+	timers, err := queries.GetSharedTimers(ctx)
+	if err != nil {
+		log.Println("GetAllSharedTimers error:", err)
+		return nil
+	}
+	return timers
+}
+
+// AddSharedTimer inserts a new guild shared timer into the database.
+func AddSharedTimer(id, guildID, channelID, msgID, createdBy string, reminder time.Time, message string) {
+	FlushPendingSaves()
+	err := queries.InsertSharedTimer(ctx, InsertSharedTimerParams{
+		ID:        id,
+		GuildID:   guildID,
+		ChannelID: channelID,
+		MsgID:     msgID,
+		CreatedBy: createdBy,
+		Reminder:  reminder,
+		Message:   message,
+	})
+	if err != nil {
+		log.Println("AddSharedTimer error:", err)
+	}
+}
+
+// DeleteSharedTimer removes a shared timer and its participants.
+func DeleteSharedTimer(id string) {
+	FlushPendingSaves()
+	if err := errors.Join(
+		queries.DeleteSharedTimerParticipants(ctx, id),
+		queries.DeleteSharedTimer(ctx, id),
+	); err != nil {
+		log.Println("DeleteSharedTimer error:", err)
+	}
+}
+
+// GetSharedTimerParticipants returns the users who opted in to a shared
+// timer, in the order they joined.
+func GetSharedTimerParticipants(id string) []string {
+	FlushPendingSaves()
+	users, err := queries.GetSharedTimerParticipants(ctx, id)
+	if err != nil {
+		log.Println("GetSharedTimerParticipants error:", err)
+		return nil
+	}
+	return users
+}
+
+// SetSharedTimerParticipant opts a user in or out of a shared timer.
+func SetSharedTimerParticipant(id, userID string, participating bool) {
+	FlushPendingSaves()
+	var err error
+	if participating {
+		err = queries.InsertSharedTimerParticipant(ctx, InsertSharedTimerParticipantParams{TimerID: id, UserID: userID})
+	} else {
+		err = queries.DeleteSharedTimerParticipant(ctx, DeleteSharedTimerParticipantParams{TimerID: id, UserID: userID})
+	}
+	if err != nil {
+		log.Println("SetSharedTimerParticipant error:", err)
+	}
+}
+
+// GetSharedTimersForUser returns the shared timers a user created or joined.
+func GetSharedTimersForUser(userID string) ([]SharedTimer, error) {
+	FlushPendingSaves()
+	return queries.GetSharedTimersForUser(ctx, GetSharedTimersForUserParams{CreatedBy: userID, UserID: userID})
+}
+
+// EraseSharedTimerUser removes a user from every shared timer. The timers
+// belong to the guild, so ones they created are kept under the tombstone.
+func EraseSharedTimerUser(userID, tombstone string) error {
+	FlushPendingSaves()
+	return errors.Join(
+		queries.DeleteUserSharedTimerParticipants(ctx, userID),
+		queries.UpdateSharedTimerCreatedBy(ctx, UpdateSharedTimerCreatedByParams{CreatedBy: tombstone, CreatedBy_2: userID}),
+	)
+}
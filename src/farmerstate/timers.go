@@ -16,8 +16,9 @@ func GetAllTimers() []Timer {
 	return timers
 }
 
-// AddTimer inserts a new timer into the database.
-func AddTimer(id, userID, channelID, msgID string, reminder time.Time, message string, duration int64, origChannelID, origMsgID string, active bool) {
+// AddTimer inserts a new timer into the database. recurrence is the encoded
+// repeat rule of a recurring timer and empty for a one-shot timer.
+func AddTimer(id, userID, channelID, msgID string, reminder time.Time, message string, duration int64, origChannelID, origMsgID string, active bool, recurrence string) {
 	FlushPendingSaves()
 	err := queries.InsertTimer(ctx, InsertTimerParams{
 		ID:                id,
@@ -30,6 +31,7 @@ func AddTimer(id, userID, channelID, msgID string, reminder time.Time, message s
 		OriginalChannelID: origChannelID,
 		OriginalMsgID:     origMsgID,
 		Active:            active,
+		Recurrence:        recurrence,
 	})
 	if err != nil {
 		log.Println("AddTimer error:", err)
@@ -48,6 +50,19 @@ func UpdateTimerState(id string, active bool) {
 	}
 }
 
+// UpdateTimerReminder moves a timer to its next reminder time.
+func UpdateTimerReminder(id string, reminder time.Time, active bool) {
+	FlushPendingSaves()
+	err := queries.UpdateTimerReminder(ctx, UpdateTimerReminderParams{
+		ID:       id,
+		Reminder: reminder,
+		Active:   active,
+	})
+	if err != nil {
+		log.Println("UpdateTimerReminder error:", err)
+	}
+}
+
 // UpdateTimerMsg updates the channel and message ID of a timer in the database.
 func UpdateTimerMsg(id, channelID, msgID string) {
 	FlushPendingSaves()
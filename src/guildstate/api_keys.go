@@ -0,0 +1,144 @@
+package guildstate
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// apiKeyHashSetting is the guild setting holding the SHA-256 of the guild's API key.
+// Only the hash is persisted so the key never appears in /admin-get-guild-settings.
+const apiKeyHashSetting = "api_key_hash"
+
+// apiKeyPrefix makes keys recognizable when pasted into config files
+const apiKeyPrefix = "ttbb_"
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateGuildAPIKey generates a new API key for the guild, replacing any previous key.
+// The plain key is returned once and cannot be recovered later.
+func CreateGuildAPIKey(guildID string) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(buf)
+	SetGuildSettingString(guildID, apiKeyHashSetting, hashAPIKey(key))
+	return key, nil
+}
+
+// RevokeGuildAPIKey removes the API key for the guild.
+func RevokeGuildAPIKey(guildID string) {
+	SetGuildSettingString(guildID, apiKeyHashSetting, "")
+}
+
+// HasGuildAPIKey returns true if an API key is configured for the guild.
+func HasGuildAPIKey(guildID string) bool {
+	return GetGuildSettingString(guildID, apiKeyHashSetting) != ""
+}
+
+// GetGuildIDForAPIKey returns the guild that owns the API key.
+func GetGuildIDForAPIKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return "", false
+	}
+	hash := []byte(hashAPIKey(key))
+
+	guilds, err := GetAllGuildState()
+	if err != nil {
+		log.Printf("GetGuildIDForAPIKey: %v", err)
+		return "", false
+	}
+	for _, guild := range guilds {
+		stored := guild.MiscSettingsString[apiKeyHashSetting]
+		if stored == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(stored), hash) == 1 {
+			return guild.GuildID, true
+		}
+	}
+	return "", false
+}
+
+// SlashAPIKeyCommand builds the /admin-api-key slash command definition.
+func SlashAPIKeyCommand(cmd string) *discordgo.ApplicationCommand {
+	var adminPermission = int64(0)
+	return &discordgo.ApplicationCommand{
+		Name:                     cmd,
+		Description:              "Manage the read-only HTTP API key for this server",
+		DefaultMemberPermissions: &adminPermission,
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+		},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "create",
+				Description: "Create a new API key, replacing the existing one",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "revoke",
+				Description: "Revoke the API key",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "status",
+				Description: "Show whether an API key is configured",
+			},
+		},
+	}
+}
+
+// HandleAPIKeyCommand dispatches the /admin-api-key subcommands.
+func HandleAPIKeyCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !respondDeferredEphemeral(s, i) {
+		return
+	}
+
+	if !isAdminCaller(s, i) {
+		followupEphemeral(s, i, "You are not authorized to manage API keys.")
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		followupEphemeral(s, i, "Please specify a subcommand.")
+		return
+	}
+
+	switch data.Options[0].Name {
+	case "create":
+		key, err := CreateGuildAPIKey(i.GuildID)
+		if err != nil {
+			log.Println("CreateGuildAPIKey:", err)
+			followupEphemeral(s, i, "Failed to create API key.")
+			return
+		}
+		followupEphemeral(s, i, fmt.Sprintf("New API key for this server:\n```\n%s\n```\nSend it in the `Authorization: Bearer` header. It won't be shown again; any previous key no longer works.", key))
+	case "revoke":
+		RevokeGuildAPIKey(i.GuildID)
+		followupEphemeral(s, i, "API key revoked.")
+	case "status":
+		if HasGuildAPIKey(i.GuildID) {
+			followupEphemeral(s, i, "An API key is configured for this server.")
+		} else {
+			followupEphemeral(s, i, "No API key is configured for this server.")
+		}
+	default:
+		followupEphemeral(s, i, "Unknown subcommand.")
+	}
+}
@@ -0,0 +1,67 @@
+package guildstate
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxContractTemplateNameLen keeps template names usable as autocomplete choices
+const maxContractTemplateNameLen = 32
+
+var contractTemplateNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9 _-]*$`)
+
+// NormalizeContractTemplateName lowercases and validates a template name.
+func NormalizeContractTemplateName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", errors.New("template name is required")
+	}
+	if len(name) > maxContractTemplateNameLen {
+		return "", errors.New("template name is too long")
+	}
+	if !contractTemplateNameRe.MatchString(name) {
+		return "", errors.New("template names may only use letters, digits, spaces, '-' and '_'")
+	}
+	return name, nil
+}
+
+// SaveContractTemplate stores the JSON encoded template under the name, replacing any existing one.
+func SaveContractTemplate(guildID, name, value, userID string) error {
+	name, err := NormalizeContractTemplateName(name)
+	if err != nil {
+		return err
+	}
+	return queries.UpsertContractTemplate(ctx, UpsertContractTemplateParams{
+		GuildID:   guildID,
+		Name:      name,
+		Value:     value,
+		UpdatedBy: userID,
+		UpdatedAt: time.Now().Unix(),
+	})
+}
+
+// GetContractTemplate returns the JSON encoded template for the name.
+func GetContractTemplate(guildID, name string) (ContractTemplate, error) {
+	name, err := NormalizeContractTemplateName(name)
+	if err != nil {
+		return ContractTemplate{}, err
+	}
+	return queries.GetContractTemplate(ctx, GetContractTemplateParams{GuildID: guildID, Name: name})
+}
+
+// GetContractTemplates returns all templates for a guild ordered by name.
+func GetContractTemplates(guildID string) ([]ContractTemplate, error) {
+	return queries.GetContractTemplates(ctx, guildID)
+}
+
+// DeleteContractTemplate removes a template, returning false if it didn't exist.
+func DeleteContractTemplate(guildID, name string) (bool, error) {
+	name, err := NormalizeContractTemplateName(name)
+	if err != nil {
+		return false, err
+	}
+	n, err := queries.DeleteContractTemplate(ctx, DeleteContractTemplateParams{GuildID: guildID, Name: name})
+	return n > 0, err
+}
@@ -2,6 +2,7 @@ package guildstate
 
 import (
 	"database/sql"
+	"errors"
 	"os"
 	"testing"
 )
@@ -103,3 +104,158 @@ func TestCoordinatorDuplicateInsert(t *testing.T) {
 		t.Error("AddGuildCoordinator() expected error on duplicate, got nil")
 	}
 }
+
+func TestGuildAPIKeyLookup(t *testing.T) {
+	key, err := CreateGuildAPIKey("guild-api")
+	if err != nil {
+		t.Fatalf("CreateGuildAPIKey() error: %v", err)
+	}
+
+	if stored := GetGuildSettingString("guild-api", apiKeyHashSetting); stored == key || stored == "" {
+		t.Errorf("stored setting = %q, want a hash of the key", stored)
+	}
+
+	if guildID, ok := GetGuildIDForAPIKey(key); !ok || guildID != "guild-api" {
+		t.Errorf("GetGuildIDForAPIKey() = %q, %t; want guild-api, true", guildID, ok)
+	}
+
+	replacement, _ := CreateGuildAPIKey("guild-api")
+	if _, ok := GetGuildIDForAPIKey(key); ok {
+		t.Error("old key still valid after a new key was created")
+	}
+
+	RevokeGuildAPIKey("guild-api")
+	if _, ok := GetGuildIDForAPIKey(replacement); ok {
+		t.Error("key still valid after revoke")
+	}
+}
+
+func TestContractTemplateCRUD(t *testing.T) {
+	if err := SaveContractTemplate("guild-tpl", "  Weekly ACO ", `{"play_style":2}`, "user-a"); err != nil {
+		t.Fatalf("SaveContractTemplate() error: %v", err)
+	}
+	if err := SaveContractTemplate("guild-tpl", "weekly aco", `{"play_style":3}`, "user-b"); err != nil {
+		t.Fatalf("SaveContractTemplate() overwrite error: %v", err)
+	}
+
+	tpl, err := GetContractTemplate("guild-tpl", "WEEKLY ACO")
+	if err != nil {
+		t.Fatalf("GetContractTemplate() error: %v", err)
+	}
+	if tpl.Value != `{"play_style":3}` || tpl.UpdatedBy != "user-b" {
+		t.Errorf("GetContractTemplate() = %+v, want the overwritten template", tpl)
+	}
+
+	if err := SaveContractTemplate("guild-tpl", "bad/name", "{}", "user-a"); err == nil {
+		t.Error("SaveContractTemplate() expected error for an invalid name")
+	}
+
+	list, _ := GetContractTemplates("guild-tpl")
+	if len(list) != 1 || list[0].Name != "weekly aco" {
+		t.Errorf("GetContractTemplates() = %+v, want one template", list)
+	}
+
+	if found, err := DeleteContractTemplate("guild-tpl", "weekly aco"); err != nil || !found {
+		t.Errorf("DeleteContractTemplate() = %t, %v; want true, nil", found, err)
+	}
+	if found, _ := DeleteContractTemplate("guild-tpl", "weekly aco"); found {
+		t.Error("DeleteContractTemplate() reported a missing template as deleted")
+	}
+}
+
+func TestLeaderboardSeasonArchive(t *testing.T) {
+	for _, snap := range []string{"2026-03-06", "2026-03-13"} {
+		if err := RecordLeaderboardSeasonSnap("spring_2026", snap); err != nil {
+			t.Fatalf("RecordLeaderboardSeasonSnap() error: %v", err)
+		}
+	}
+	_ = RecordLeaderboardSeasonSnap("summer_2026", "2026-06-05")
+
+	seasons, err := GetLeaderboardSeasons()
+	if err != nil || len(seasons) != 2 {
+		t.Fatalf("GetLeaderboardSeasons() = %+v, %v; want two seasons", seasons, err)
+	}
+	if got := seasons[1]; got.SeasonID != "spring_2026" || got.FirstSnapDate != "2026-03-06" || got.LastSnapDate != "2026-03-13" {
+		t.Errorf("spring season = %+v, want 2026-03-06 to 2026-03-13", got)
+	}
+
+	for n, player := range []string{"p1", "p2"} {
+		row := LeaderboardSeasonArchive{SeasonID: "spring_2026", GuildID: "guild-s", LbType: "contract_exp", Rank: int64(n + 1), Player: player, GameName: player, Value: float64(100 - n), SnapDate: "2026-03-13"}
+		if err := UpsertLeaderboardSeasonArchiveRow(row); err != nil {
+			t.Fatalf("UpsertLeaderboardSeasonArchiveRow() error: %v", err)
+		}
+	}
+	if err := SetLeaderboardSeasonArchived("spring_2026"); err != nil {
+		t.Fatalf("SetLeaderboardSeasonArchived() error: %v", err)
+	}
+
+	archived, _ := GetArchivedLeaderboardSeasonsForGuild("guild-s")
+	if len(archived) != 1 || archived[0].SeasonID != "spring_2026" || archived[0].ArchivedAt == 0 {
+		t.Errorf("GetArchivedLeaderboardSeasonsForGuild() = %+v, want spring_2026 archived", archived)
+	}
+	if other, _ := GetArchivedLeaderboardSeasonsForGuild("guild-other"); len(other) != 0 {
+		t.Errorf("GetArchivedLeaderboardSeasonsForGuild() for another guild = %+v, want none", other)
+	}
+
+	rows, _ := GetLeaderboardSeasonArchive("spring_2026", "guild-s", "contract_exp")
+	if len(rows) != 2 || rows[0].Player != "p1" || rows[1].Rank != 2 {
+		t.Errorf("GetLeaderboardSeasonArchive() = %+v, want p1 then p2", rows)
+	}
+	if types, _ := GetLeaderboardSeasonArchiveTypes("spring_2026", "guild-s"); len(types) != 1 || types[0] != "contract_exp" {
+		t.Errorf("GetLeaderboardSeasonArchiveTypes() = %v, want [contract_exp]", types)
+	}
+}
+
+func TestLeaderboardFederationLifecycle(t *testing.T) {
+	fed, err := CreateLeaderboardFederation("Alliance", "guild-fa", "Alpha", "user-a")
+	if err != nil {
+		t.Fatalf("CreateLeaderboardFederation() error: %v", err)
+	}
+	if _, err := CreateLeaderboardFederation("Another", "guild-fa", "Alpha", "user-a"); err == nil {
+		t.Error("CreateLeaderboardFederation() for a federated guild should fail")
+	}
+	if _, err := JoinLeaderboardFederation("lbf_bogus", "guild-fb", "Bravo", "user-b"); err == nil {
+		t.Error("JoinLeaderboardFederation() with an unknown code should fail")
+	}
+	for _, g := range []string{"guild-fb", "guild-fc"} {
+		if _, err := JoinLeaderboardFederation(fed.FederationID, g, g, "user-b"); err != nil {
+			t.Fatalf("JoinLeaderboardFederation(%s) error: %v", g, err)
+		}
+	}
+
+	got, members, err := GetLeaderboardFederationForGuild("guild-fc")
+	if err != nil || got.Name != "Alliance" || len(members) != 3 || members[0].GuildID != "guild-fa" {
+		t.Fatalf("GetLeaderboardFederationForGuild() = %+v, %+v, %v; want Alliance with guild-fa first", got, members, err)
+	}
+
+	if err := RemoveLeaderboardFederationMember("guild-fb", "guild-fc"); err == nil {
+		t.Error("RemoveLeaderboardFederationMember() by a non-owner should fail")
+	}
+	if err := RemoveLeaderboardFederationMember("guild-fa", "guild-fc"); err != nil {
+		t.Fatalf("RemoveLeaderboardFederationMember() error: %v", err)
+	}
+	if removed, err := LeaveLeaderboardFederation("guild-fb"); err != nil || len(removed) != 1 {
+		t.Errorf("LeaveLeaderboardFederation(member) = %v, %v; want [guild-fb]", removed, err)
+	}
+
+	_, _ = JoinLeaderboardFederation(fed.FederationID, "guild-fb", "Bravo", "user-b")
+	removed, err := LeaveLeaderboardFederation("guild-fa")
+	if err != nil || len(removed) != 2 {
+		t.Errorf("LeaveLeaderboardFederation(owner) = %v, %v; want both guilds", removed, err)
+	}
+	if _, _, err := GetLeaderboardFederationForGuild("guild-fb"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetLeaderboardFederationForGuild() after disband error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestDisplaySettingValueMasksSecrets(t *testing.T) {
+	if got := displaySettingValue("webhook_secret", "s3cr3t-abcd"); got != "••••abcd" {
+		t.Errorf("displaySettingValue(webhook_secret) = %q, want only the last 4 characters", got)
+	}
+	if got := displaySettingValue("webhook_secret", "abc"); got != "•••" {
+		t.Errorf("displaySettingValue(webhook_secret) = %q, want a short secret fully masked", got)
+	}
+	if got := displaySettingValue("webhook_url", "https://example.com/hook"); got != "https://example.com/hook" {
+		t.Errorf("displaySettingValue(webhook_url) = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,148 @@
+package guildstate
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// leaderboard_federation.go — A federation lets allied guilds post one combined
+// leaderboard. The federation ID doubles as the join code the owning guild
+// shares with other admins, and a guild can belong to only one federation.
+
+// federationCodePrefix makes join codes recognizable when pasted into /admin-lb
+const federationCodePrefix = "lbf_"
+
+// GetLeaderboardFederationForGuild returns the guild's federation and its members
+// in the order they joined. The error is sql.ErrNoRows when the guild isn't federated.
+func GetLeaderboardFederationForGuild(guildID string) (LeaderboardFederation, []LeaderboardFederationMember, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	ctx := context.Background()
+	member, err := queries.GetLeaderboardFederationMember(ctx, guildID)
+	if err != nil {
+		return LeaderboardFederation{}, nil, err
+	}
+	fed, err := queries.GetLeaderboardFederation(ctx, member.FederationID)
+	if err != nil {
+		return LeaderboardFederation{}, nil, err
+	}
+	members, err := queries.GetLeaderboardFederationMembers(ctx, fed.FederationID)
+	if err != nil {
+		return LeaderboardFederation{}, nil, err
+	}
+	return fed, members, nil
+}
+
+// CreateLeaderboardFederation creates a federation owned by the guild and adds
+// the guild as its first member.
+func CreateLeaderboardFederation(name, guildID, label, createdBy string) (LeaderboardFederation, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	ctx := context.Background()
+	if _, err := queries.GetLeaderboardFederationMember(ctx, guildID); err == nil {
+		return LeaderboardFederation{}, errors.New("this server already belongs to a leaderboard federation")
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return LeaderboardFederation{}, err
+	}
+
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return LeaderboardFederation{}, err
+	}
+	fed := LeaderboardFederation{
+		FederationID: federationCodePrefix + hex.EncodeToString(buf),
+		Name:         name,
+		OwnerGuildID: guildID,
+		CreatedBy:    createdBy,
+		CreatedAt:    time.Now().Unix(),
+	}
+	if err := queries.InsertLeaderboardFederation(ctx, InsertLeaderboardFederationParams(fed)); err != nil {
+		return LeaderboardFederation{}, err
+	}
+	if err := queries.InsertLeaderboardFederationMember(ctx, InsertLeaderboardFederationMemberParams{
+		GuildID:      guildID,
+		FederationID: fed.FederationID,
+		Label:        label,
+		AddedBy:      createdBy,
+		AddedAt:      fed.CreatedAt,
+	}); err != nil {
+		_ = queries.DeleteLeaderboardFederation(ctx, fed.FederationID)
+		return LeaderboardFederation{}, err
+	}
+	return fed, nil
+}
+
+// JoinLeaderboardFederation adds the guild to the federation named by the join code.
+func JoinLeaderboardFederation(code, guildID, label, addedBy string) (LeaderboardFederation, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	ctx := context.Background()
+	if _, err := queries.GetLeaderboardFederationMember(ctx, guildID); err == nil {
+		return LeaderboardFederation{}, errors.New("this server already belongs to a leaderboard federation")
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return LeaderboardFederation{}, err
+	}
+	fed, err := queries.GetLeaderboardFederation(ctx, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return LeaderboardFederation{}, errors.New("no leaderboard federation matches that join code")
+	} else if err != nil {
+		return LeaderboardFederation{}, err
+	}
+	err = queries.InsertLeaderboardFederationMember(ctx, InsertLeaderboardFederationMemberParams{
+		GuildID:      guildID,
+		FederationID: fed.FederationID,
+		Label:        label,
+		AddedBy:      addedBy,
+		AddedAt:      time.Now().Unix(),
+	})
+	return fed, err
+}
+
+// LeaveLeaderboardFederation removes the guild from its federation. When the
+// owning guild leaves the federation is disbanded. The returned guild IDs are
+// every guild that is no longer federated as a result.
+func LeaveLeaderboardFederation(guildID string) ([]string, error) {
+	fed, members, err := GetLeaderboardFederationForGuild(guildID)
+	if err != nil {
+		return nil, err
+	}
+	if fed.OwnerGuildID != guildID {
+		return []string{guildID}, queries.DeleteLeaderboardFederationMember(context.Background(), guildID)
+	}
+
+	removed := make([]string, 0, len(members))
+	for _, m := range members {
+		removed = append(removed, m.GuildID)
+	}
+	return removed, errors.Join(
+		queries.DeleteLeaderboardFederationMembers(context.Background(), fed.FederationID),
+		queries.DeleteLeaderboardFederation(context.Background(), fed.FederationID),
+	)
+}
+
+// RemoveLeaderboardFederationMember lets the owning guild remove another member guild.
+func RemoveLeaderboardFederationMember(ownerGuildID, guildID string) error {
+	fed, members, err := GetLeaderboardFederationForGuild(ownerGuildID)
+	if err != nil {
+		return err
+	}
+	if fed.OwnerGuildID != ownerGuildID {
+		return errors.New("only the server that created the federation can remove members")
+	}
+	if guildID == ownerGuildID {
+		return errors.New("the owning server can't remove itself, leave the federation to disband it")
+	}
+	for _, m := range members {
+		if m.GuildID == guildID {
+			return queries.DeleteLeaderboardFederationMember(context.Background(), guildID)
+		}
+	}
+	return errors.New("that server is not a member of this federation")
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"time"
 )
 
-// leaderboard_state.go — Wrapper functions for the leaderboard_config table.
+// leaderboard_state.go — Wrapper functions for the leaderboard_config and leaderboard_custom_metric tables.
 // These are thin adapters over the sqlc-generated Queries methods so that the
 // leaderboard package can call into guildstate without accessing internal state.
 
@@ -76,3 +77,109 @@ func DeleteLeaderboardConfig(lbType, guildID string) error {
 	}
 	return nil
 }
+
+// UpsertLeaderboardCustomMetric inserts or updates a guild's custom leaderboard metric.
+func UpsertLeaderboardCustomMetric(lbType, guildID, displayName, expression, valueFmt string, higherIsBetter bool) error {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.UpsertLeaderboardCustomMetric(context.Background(), UpsertLeaderboardCustomMetricParams{
+		LbType:         lbType,
+		GuildID:        guildID,
+		DisplayName:    displayName,
+		Expression:     expression,
+		ValueFmt:       valueFmt,
+		HigherIsBetter: higherIsBetter,
+		UpdatedAt:      time.Now().Unix(),
+	})
+}
+
+// GetLeaderboardCustomMetrics returns the custom leaderboard metrics of every guild.
+func GetLeaderboardCustomMetrics() ([]LeaderboardCustomMetric, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.GetLeaderboardCustomMetrics(context.Background())
+}
+
+// DeleteLeaderboardCustomMetric removes a custom leaderboard metric, returning false if it didn't exist.
+func DeleteLeaderboardCustomMetric(lbType, guildID string) (bool, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	n, err := queries.DeleteLeaderboardCustomMetric(context.Background(), DeleteLeaderboardCustomMetricParams{
+		LbType:  lbType,
+		GuildID: guildID,
+	})
+	return n > 0, err
+}
+
+// RecordLeaderboardSeasonSnap notes a weekly collection in a season, keeping its first snap date.
+func RecordLeaderboardSeasonSnap(seasonID, snapDate string) error {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.UpsertLeaderboardSeason(context.Background(), UpsertLeaderboardSeasonParams{
+		SeasonID:      seasonID,
+		FirstSnapDate: snapDate,
+		LastSnapDate:  snapDate,
+	})
+}
+
+// GetLeaderboardSeasons returns every recorded season, newest first.
+func GetLeaderboardSeasons() ([]LeaderboardSeason, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.GetLeaderboardSeasons(context.Background())
+}
+
+// SetLeaderboardSeasonArchived marks a season's final tables as frozen.
+func SetLeaderboardSeasonArchived(seasonID string) error {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.SetLeaderboardSeasonArchived(context.Background(), SetLeaderboardSeasonArchivedParams{
+		ArchivedAt: time.Now().Unix(),
+		SeasonID:   seasonID,
+	})
+}
+
+// UpsertLeaderboardSeasonArchiveRow saves one ranked row of a frozen season table.
+func UpsertLeaderboardSeasonArchiveRow(row LeaderboardSeasonArchive) error {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.UpsertLeaderboardSeasonArchiveRow(context.Background(), UpsertLeaderboardSeasonArchiveRowParams(row))
+}
+
+// GetLeaderboardSeasonArchive returns a guild's frozen table for a season and lb_type, ranked.
+func GetLeaderboardSeasonArchive(seasonID, guildID, lbType string) ([]LeaderboardSeasonArchive, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.GetLeaderboardSeasonArchive(context.Background(), GetLeaderboardSeasonArchiveParams{
+		SeasonID: seasonID,
+		GuildID:  guildID,
+		LbType:   lbType,
+	})
+}
+
+// GetLeaderboardSeasonArchiveTypes returns the lb_types frozen for a guild's season.
+func GetLeaderboardSeasonArchiveTypes(seasonID, guildID string) ([]string, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.GetLeaderboardSeasonArchiveTypes(context.Background(), GetLeaderboardSeasonArchiveTypesParams{
+		SeasonID: seasonID,
+		GuildID:  guildID,
+	})
+}
+
+// GetArchivedLeaderboardSeasonsForGuild returns the seasons with frozen tables for a guild, newest first.
+func GetArchivedLeaderboardSeasonsForGuild(guildID string) ([]LeaderboardSeason, error) {
+	if queries == nil {
+		sqliteInit()
+	}
+	return queries.GetArchivedLeaderboardSeasonsForGuild(context.Background(), guildID)
+}
@@ -8,6 +8,14 @@ import (
 	"database/sql"
 )
 
+type ContractTemplate struct {
+	GuildID   string
+	Name      string
+	Value     string
+	UpdatedBy string
+	UpdatedAt int64
+}
+
 type GuildCoordinator struct {
 	GuildID string
 	UserID  string
@@ -26,3 +34,48 @@ type LeaderboardConfig struct {
 	ChannelID  string
 	MessageIds sql.NullString
 }
+
+type LeaderboardCustomMetric struct {
+	LbType         string
+	GuildID        string
+	DisplayName    string
+	Expression     string
+	ValueFmt       string
+	HigherIsBetter bool
+	UpdatedAt      int64
+}
+
+type LeaderboardFederation struct {
+	FederationID string
+	Name         string
+	OwnerGuildID string
+	CreatedBy    string
+	CreatedAt    int64
+}
+
+type LeaderboardFederationMember struct {
+	GuildID      string
+	FederationID string
+	Label        string
+	AddedBy      string
+	AddedAt      int64
+}
+
+type LeaderboardSeason struct {
+	SeasonID      string
+	FirstSnapDate string
+	LastSnapDate  string
+	ArchivedAt    int64
+}
+
+type LeaderboardSeasonArchive struct {
+	SeasonID string
+	GuildID  string
+	LbType   string
+	Rank     int64
+	Player   string
+	GameName string
+	Value    float64
+	Details  string
+	SnapDate string
+}
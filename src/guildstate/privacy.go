@@ -0,0 +1,77 @@
+package guildstate
+
+import (
+	"errors"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+)
+
+// guildPrivacyData is what the guild tables record about a user
+type guildPrivacyData struct {
+	Coordinators      []GuildCoordinator            `json:"coordinators,omitempty"`
+	Templates         []ContractTemplate            `json:"templates,omitempty"`
+	SeasonArchive     []LeaderboardSeasonArchive    `json:"season_archive,omitempty"`
+	Federations       []LeaderboardFederation       `json:"federations,omitempty"`
+	FederationMembers []LeaderboardFederationMember `json:"federation_members,omitempty"`
+}
+
+func init() {
+	farmerstate.RegisterPrivacyStore("guild_coordinators", farmerstate.PrivacyStore{
+		Export: exportGuildPrivacy,
+		Erase:  eraseGuildPrivacy,
+	})
+}
+
+func exportGuildPrivacy(userID string) (any, error) {
+	if queries == nil {
+		return nil, nil
+	}
+	coordinators, err := queries.GetGuildCoordinatorsForUser(ctx, GetGuildCoordinatorsForUserParams{UserID: userID, AddedBy: userID})
+	if err != nil {
+		return nil, err
+	}
+	templates, err := queries.GetContractTemplatesUpdatedBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	seasonArchive, err := queries.GetLeaderboardSeasonArchiveForPlayer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	federations, err := queries.GetLeaderboardFederationsCreatedBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	federationMembers, err := queries.GetLeaderboardFederationMembersAddedBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(coordinators) == 0 && len(templates) == 0 && len(seasonArchive) == 0 && len(federations) == 0 && len(federationMembers) == 0 {
+		return nil, nil
+	}
+	return &guildPrivacyData{
+		Coordinators:      coordinators,
+		Templates:         templates,
+		SeasonArchive:     seasonArchive,
+		Federations:       federations,
+		FederationMembers: federationMembers,
+	}, nil
+}
+
+// eraseGuildPrivacy removes the user's coordinator roles and archived season
+// standings. Coordinators they added, templates they saved and federations
+// they set up belong to the guild, so those only lose the record of who made
+// the change.
+func eraseGuildPrivacy(userID string, tombstone string) error {
+	if queries == nil {
+		return nil
+	}
+	return errors.Join(
+		queries.DeleteUserGuildCoordinators(ctx, userID),
+		queries.UpdateGuildCoordinatorAddedBy(ctx, UpdateGuildCoordinatorAddedByParams{AddedBy: tombstone, AddedBy_2: userID}),
+		queries.UpdateContractTemplateUpdatedBy(ctx, UpdateContractTemplateUpdatedByParams{UpdatedBy: tombstone, UpdatedBy_2: userID}),
+		queries.DeleteLeaderboardSeasonArchiveForPlayer(ctx, userID),
+		queries.UpdateLeaderboardFederationCreatedBy(ctx, UpdateLeaderboardFederationCreatedByParams{CreatedBy: tombstone, CreatedBy_2: userID}),
+		queries.UpdateLeaderboardFederationMemberAddedBy(ctx, UpdateLeaderboardFederationMemberAddedByParams{AddedBy: tombstone, AddedBy_2: userID}),
+	)
+}
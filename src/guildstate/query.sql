@@ -62,6 +62,131 @@ WHERE lb_type = ? AND guild_id = ?;
 DELETE FROM leaderboard_config
 WHERE lb_type = ? AND guild_id = ?;
 
+-- --- Leaderboard Custom Metric -----------------------------------------------
+
+-- name: UpsertLeaderboardCustomMetric :exec
+INSERT INTO leaderboard_custom_metric (lb_type, guild_id, display_name, expression, value_fmt, higher_is_better, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(lb_type, guild_id) DO UPDATE SET
+    display_name     = excluded.display_name,
+    expression       = excluded.expression,
+    value_fmt        = excluded.value_fmt,
+    higher_is_better = excluded.higher_is_better,
+    updated_at       = excluded.updated_at;
+
+-- name: GetLeaderboardCustomMetrics :many
+-- Returns every guild's custom metrics - loaded once into the leaderboard registry.
+SELECT lb_type, guild_id, display_name, expression, value_fmt, higher_is_better, updated_at
+FROM leaderboard_custom_metric
+ORDER BY guild_id, lb_type;
+
+-- name: DeleteLeaderboardCustomMetric :execrows
+DELETE FROM leaderboard_custom_metric
+WHERE lb_type = ? AND guild_id = ?;
+
+-- name: UpsertLeaderboardSeason :exec
+-- Records a weekly collection in a season, keeping the first snap date.
+INSERT INTO leaderboard_season (season_id, first_snap_date, last_snap_date)
+VALUES (?, ?, ?)
+ON CONFLICT(season_id) DO UPDATE SET
+    last_snap_date = excluded.last_snap_date;
+
+-- name: GetLeaderboardSeasons :many
+SELECT season_id, first_snap_date, last_snap_date, archived_at
+FROM leaderboard_season
+ORDER BY first_snap_date DESC;
+
+-- name: SetLeaderboardSeasonArchived :exec
+UPDATE leaderboard_season SET archived_at = ? WHERE season_id = ?;
+
+-- name: UpsertLeaderboardSeasonArchiveRow :exec
+INSERT INTO leaderboard_season_archive (season_id, guild_id, lb_type, rank, player, game_name, value, details, snap_date)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(season_id, guild_id, lb_type, player) DO UPDATE SET
+    rank      = excluded.rank,
+    game_name = excluded.game_name,
+    value     = excluded.value,
+    details   = excluded.details,
+    snap_date = excluded.snap_date;
+
+-- name: GetLeaderboardSeasonArchive :many
+SELECT season_id, guild_id, lb_type, rank, player, game_name, value, details, snap_date
+FROM leaderboard_season_archive
+WHERE season_id = ? AND guild_id = ? AND lb_type = ?
+ORDER BY rank ASC;
+
+-- name: GetLeaderboardSeasonArchiveTypes :many
+SELECT DISTINCT lb_type FROM leaderboard_season_archive
+WHERE season_id = ? AND guild_id = ?
+ORDER BY lb_type ASC;
+
+-- name: GetLeaderboardSeasonArchiveForPlayer :many
+SELECT season_id, guild_id, lb_type, rank, player, game_name, value, details, snap_date
+FROM leaderboard_season_archive
+WHERE player = ?
+ORDER BY season_id, guild_id, lb_type;
+
+-- name: DeleteLeaderboardSeasonArchiveForPlayer :exec
+DELETE FROM leaderboard_season_archive WHERE player = ?;
+
+-- name: GetArchivedLeaderboardSeasonsForGuild :many
+-- Returns the seasons with frozen tables for a guild, newest first.
+SELECT season_id, first_snap_date, last_snap_date, archived_at
+FROM leaderboard_season s
+WHERE EXISTS (
+    SELECT 1 FROM leaderboard_season_archive a
+    WHERE a.season_id = s.season_id AND a.guild_id = ?
+)
+ORDER BY first_snap_date DESC;
+
+-- --- Leaderboard Federation ------------------------------------------------
+
+-- name: InsertLeaderboardFederation :exec
+INSERT INTO leaderboard_federation (federation_id, name, owner_guild_id, created_by, created_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetLeaderboardFederation :one
+SELECT federation_id, name, owner_guild_id, created_by, created_at FROM leaderboard_federation
+WHERE federation_id = ? LIMIT 1;
+
+-- name: DeleteLeaderboardFederation :exec
+DELETE FROM leaderboard_federation WHERE federation_id = ?;
+
+-- name: GetLeaderboardFederationsCreatedBy :many
+SELECT federation_id, name, owner_guild_id, created_by, created_at FROM leaderboard_federation
+WHERE created_by = ?
+ORDER BY created_at ASC;
+
+-- name: UpdateLeaderboardFederationCreatedBy :exec
+UPDATE leaderboard_federation SET created_by = ? WHERE created_by = ?;
+
+-- name: InsertLeaderboardFederationMember :exec
+INSERT INTO leaderboard_federation_member (guild_id, federation_id, label, added_by, added_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetLeaderboardFederationMember :one
+SELECT guild_id, federation_id, label, added_by, added_at FROM leaderboard_federation_member
+WHERE guild_id = ? LIMIT 1;
+
+-- name: GetLeaderboardFederationMembers :many
+SELECT guild_id, federation_id, label, added_by, added_at FROM leaderboard_federation_member
+WHERE federation_id = ?
+ORDER BY added_at ASC, guild_id ASC;
+
+-- name: DeleteLeaderboardFederationMember :exec
+DELETE FROM leaderboard_federation_member WHERE guild_id = ?;
+
+-- name: DeleteLeaderboardFederationMembers :exec
+DELETE FROM leaderboard_federation_member WHERE federation_id = ?;
+
+-- name: GetLeaderboardFederationMembersAddedBy :many
+SELECT guild_id, federation_id, label, added_by, added_at FROM leaderboard_federation_member
+WHERE added_by = ?
+ORDER BY added_at ASC;
+
+-- name: UpdateLeaderboardFederationMemberAddedBy :exec
+UPDATE leaderboard_federation_member SET added_by = ? WHERE added_by = ?;
+
 -- --- Guild Coordinator -------------------------------------------------------
 
 -- name: InsertGuildCoordinator :exec
@@ -79,3 +204,44 @@ ORDER BY added_at ASC;
 
 -- name: DeleteGuildCoordinator :exec
 DELETE FROM guild_coordinator WHERE guild_id = ? AND user_id = ?;
+
+-- name: GetGuildCoordinatorsForUser :many
+SELECT guild_id, user_id, added_by, added_at FROM guild_coordinator
+WHERE user_id = ? OR added_by = ?
+ORDER BY added_at ASC;
+
+-- name: DeleteUserGuildCoordinators :exec
+DELETE FROM guild_coordinator WHERE user_id = ?;
+
+-- name: UpdateGuildCoordinatorAddedBy :exec
+UPDATE guild_coordinator SET added_by = ? WHERE added_by = ?;
+
+-- --- Contract Template -------------------------------------------------------
+
+-- name: UpsertContractTemplate :exec
+INSERT INTO contract_template (guild_id, name, value, updated_by, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(guild_id, name) DO UPDATE SET
+    value      = excluded.value,
+    updated_by = excluded.updated_by,
+    updated_at = excluded.updated_at;
+
+-- name: GetContractTemplate :one
+SELECT guild_id, name, value, updated_by, updated_at FROM contract_template
+WHERE guild_id = ? AND name = ? LIMIT 1;
+
+-- name: GetContractTemplates :many
+SELECT guild_id, name, value, updated_by, updated_at FROM contract_template
+WHERE guild_id = ?
+ORDER BY name ASC;
+
+-- name: DeleteContractTemplate :execrows
+DELETE FROM contract_template WHERE guild_id = ? AND name = ?;
+
+-- name: GetContractTemplatesUpdatedBy :many
+SELECT guild_id, name, value, updated_by, updated_at FROM contract_template
+WHERE updated_by = ?
+ORDER BY guild_id, name;
+
+-- name: UpdateContractTemplateUpdatedBy :exec
+UPDATE contract_template SET updated_by = ? WHERE updated_by = ?;
@@ -10,6 +10,23 @@ import (
 	"database/sql"
 )
 
+const deleteContractTemplate = `-- name: DeleteContractTemplate :execrows
+DELETE FROM contract_template WHERE guild_id = ? AND name = ?
+`
+
+type DeleteContractTemplateParams struct {
+	GuildID string
+	Name    string
+}
+
+func (q *Queries) DeleteContractTemplate(ctx context.Context, arg DeleteContractTemplateParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteContractTemplate, arg.GuildID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteGuildCoordinator = `-- name: DeleteGuildCoordinator :exec
 DELETE FROM guild_coordinator WHERE guild_id = ? AND user_id = ?
 `
@@ -59,6 +76,69 @@ func (q *Queries) DeleteLeaderboardConfig(ctx context.Context, arg DeleteLeaderb
 	return err
 }
 
+const deleteLeaderboardCustomMetric = `-- name: DeleteLeaderboardCustomMetric :execrows
+DELETE FROM leaderboard_custom_metric
+WHERE lb_type = ? AND guild_id = ?
+`
+
+type DeleteLeaderboardCustomMetricParams struct {
+	LbType  string
+	GuildID string
+}
+
+func (q *Queries) DeleteLeaderboardCustomMetric(ctx context.Context, arg DeleteLeaderboardCustomMetricParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLeaderboardCustomMetric, arg.LbType, arg.GuildID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteLeaderboardFederation = `-- name: DeleteLeaderboardFederation :exec
+DELETE FROM leaderboard_federation WHERE federation_id = ?
+`
+
+func (q *Queries) DeleteLeaderboardFederation(ctx context.Context, federationID string) error {
+	_, err := q.db.ExecContext(ctx, deleteLeaderboardFederation, federationID)
+	return err
+}
+
+const deleteLeaderboardFederationMember = `-- name: DeleteLeaderboardFederationMember :exec
+DELETE FROM leaderboard_federation_member WHERE guild_id = ?
+`
+
+func (q *Queries) DeleteLeaderboardFederationMember(ctx context.Context, guildID string) error {
+	_, err := q.db.ExecContext(ctx, deleteLeaderboardFederationMember, guildID)
+	return err
+}
+
+const deleteLeaderboardFederationMembers = `-- name: DeleteLeaderboardFederationMembers :exec
+DELETE FROM leaderboard_federation_member WHERE federation_id = ?
+`
+
+func (q *Queries) DeleteLeaderboardFederationMembers(ctx context.Context, federationID string) error {
+	_, err := q.db.ExecContext(ctx, deleteLeaderboardFederationMembers, federationID)
+	return err
+}
+
+const deleteLeaderboardSeasonArchiveForPlayer = `-- name: DeleteLeaderboardSeasonArchiveForPlayer :exec
+DELETE FROM leaderboard_season_archive WHERE player = ?
+`
+
+func (q *Queries) DeleteLeaderboardSeasonArchiveForPlayer(ctx context.Context, player string) error {
+	_, err := q.db.ExecContext(ctx, deleteLeaderboardSeasonArchiveForPlayer, player)
+	return err
+}
+
+const deleteUserGuildCoordinators = `-- name: DeleteUserGuildCoordinators :exec
+DELETE FROM guild_coordinator WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserGuildCoordinators(ctx context.Context, userID string) error {
+	_, err := q.db.ExecContext(ctx, deleteUserGuildCoordinators, userID)
+	return err
+}
+
 const getAllGuildState = `-- name: GetAllGuildState :many
 SELECT id, value FROM guild_record
 `
@@ -156,6 +236,138 @@ func (q *Queries) GetAllLeaderboardConfigsForGuild(ctx context.Context, guildID
 	return items, nil
 }
 
+const getArchivedLeaderboardSeasonsForGuild = `-- name: GetArchivedLeaderboardSeasonsForGuild :many
+SELECT season_id, first_snap_date, last_snap_date, archived_at
+FROM leaderboard_season s
+WHERE EXISTS (
+    SELECT 1 FROM leaderboard_season_archive a
+    WHERE a.season_id = s.season_id AND a.guild_id = ?
+)
+ORDER BY first_snap_date DESC
+`
+
+// Returns the seasons with frozen tables for a guild, newest first.
+func (q *Queries) GetArchivedLeaderboardSeasonsForGuild(ctx context.Context, guildID string) ([]LeaderboardSeason, error) {
+	rows, err := q.db.QueryContext(ctx, getArchivedLeaderboardSeasonsForGuild, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardSeason
+	for rows.Next() {
+		var i LeaderboardSeason
+		if err := rows.Scan(
+			&i.SeasonID,
+			&i.FirstSnapDate,
+			&i.LastSnapDate,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getContractTemplate = `-- name: GetContractTemplate :one
+SELECT guild_id, name, value, updated_by, updated_at FROM contract_template
+WHERE guild_id = ? AND name = ? LIMIT 1
+`
+
+type GetContractTemplateParams struct {
+	GuildID string
+	Name    string
+}
+
+func (q *Queries) GetContractTemplate(ctx context.Context, arg GetContractTemplateParams) (ContractTemplate, error) {
+	row := q.db.QueryRowContext(ctx, getContractTemplate, arg.GuildID, arg.Name)
+	var i ContractTemplate
+	err := row.Scan(
+		&i.GuildID,
+		&i.Name,
+		&i.Value,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getContractTemplates = `-- name: GetContractTemplates :many
+SELECT guild_id, name, value, updated_by, updated_at FROM contract_template
+WHERE guild_id = ?
+ORDER BY name ASC
+`
+
+func (q *Queries) GetContractTemplates(ctx context.Context, guildID string) ([]ContractTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, getContractTemplates, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractTemplate
+	for rows.Next() {
+		var i ContractTemplate
+		if err := rows.Scan(
+			&i.GuildID,
+			&i.Name,
+			&i.Value,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getContractTemplatesUpdatedBy = `-- name: GetContractTemplatesUpdatedBy :many
+SELECT guild_id, name, value, updated_by, updated_at FROM contract_template
+WHERE updated_by = ?
+ORDER BY guild_id, name
+`
+
+func (q *Queries) GetContractTemplatesUpdatedBy(ctx context.Context, updatedBy string) ([]ContractTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, getContractTemplatesUpdatedBy, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ContractTemplate
+	for rows.Next() {
+		var i ContractTemplate
+		if err := rows.Scan(
+			&i.GuildID,
+			&i.Name,
+			&i.Value,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getGuildCoordinator = `-- name: GetGuildCoordinator :one
 SELECT guild_id, user_id, added_by, added_at FROM guild_coordinator
 WHERE guild_id = ? AND user_id = ? LIMIT 1
@@ -212,6 +424,45 @@ func (q *Queries) GetGuildCoordinators(ctx context.Context, guildID string) ([]G
 	return items, nil
 }
 
+const getGuildCoordinatorsForUser = `-- name: GetGuildCoordinatorsForUser :many
+SELECT guild_id, user_id, added_by, added_at FROM guild_coordinator
+WHERE user_id = ? OR added_by = ?
+ORDER BY added_at ASC
+`
+
+type GetGuildCoordinatorsForUserParams struct {
+	UserID  string
+	AddedBy string
+}
+
+func (q *Queries) GetGuildCoordinatorsForUser(ctx context.Context, arg GetGuildCoordinatorsForUserParams) ([]GuildCoordinator, error) {
+	rows, err := q.db.QueryContext(ctx, getGuildCoordinatorsForUser, arg.UserID, arg.AddedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GuildCoordinator
+	for rows.Next() {
+		var i GuildCoordinator
+		if err := rows.Scan(
+			&i.GuildID,
+			&i.UserID,
+			&i.AddedBy,
+			&i.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getGuildState = `-- name: GetGuildState :one
 
 SELECT id, value FROM guild_record
@@ -249,6 +500,339 @@ func (q *Queries) GetLeaderboardConfig(ctx context.Context, arg GetLeaderboardCo
 	return i, err
 }
 
+const getLeaderboardCustomMetrics = `-- name: GetLeaderboardCustomMetrics :many
+SELECT lb_type, guild_id, display_name, expression, value_fmt, higher_is_better, updated_at
+FROM leaderboard_custom_metric
+ORDER BY guild_id, lb_type
+`
+
+// Returns every guild's custom metrics - loaded once into the leaderboard registry.
+func (q *Queries) GetLeaderboardCustomMetrics(ctx context.Context) ([]LeaderboardCustomMetric, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardCustomMetrics)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardCustomMetric
+	for rows.Next() {
+		var i LeaderboardCustomMetric
+		if err := rows.Scan(
+			&i.LbType,
+			&i.GuildID,
+			&i.DisplayName,
+			&i.Expression,
+			&i.ValueFmt,
+			&i.HigherIsBetter,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardFederation = `-- name: GetLeaderboardFederation :one
+SELECT federation_id, name, owner_guild_id, created_by, created_at FROM leaderboard_federation
+WHERE federation_id = ? LIMIT 1
+`
+
+func (q *Queries) GetLeaderboardFederation(ctx context.Context, federationID string) (LeaderboardFederation, error) {
+	row := q.db.QueryRowContext(ctx, getLeaderboardFederation, federationID)
+	var i LeaderboardFederation
+	err := row.Scan(
+		&i.FederationID,
+		&i.Name,
+		&i.OwnerGuildID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLeaderboardFederationMember = `-- name: GetLeaderboardFederationMember :one
+SELECT guild_id, federation_id, label, added_by, added_at FROM leaderboard_federation_member
+WHERE guild_id = ? LIMIT 1
+`
+
+func (q *Queries) GetLeaderboardFederationMember(ctx context.Context, guildID string) (LeaderboardFederationMember, error) {
+	row := q.db.QueryRowContext(ctx, getLeaderboardFederationMember, guildID)
+	var i LeaderboardFederationMember
+	err := row.Scan(
+		&i.GuildID,
+		&i.FederationID,
+		&i.Label,
+		&i.AddedBy,
+		&i.AddedAt,
+	)
+	return i, err
+}
+
+const getLeaderboardFederationMembers = `-- name: GetLeaderboardFederationMembers :many
+SELECT guild_id, federation_id, label, added_by, added_at FROM leaderboard_federation_member
+WHERE federation_id = ?
+ORDER BY added_at ASC, guild_id ASC
+`
+
+func (q *Queries) GetLeaderboardFederationMembers(ctx context.Context, federationID string) ([]LeaderboardFederationMember, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardFederationMembers, federationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardFederationMember
+	for rows.Next() {
+		var i LeaderboardFederationMember
+		if err := rows.Scan(
+			&i.GuildID,
+			&i.FederationID,
+			&i.Label,
+			&i.AddedBy,
+			&i.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardFederationMembersAddedBy = `-- name: GetLeaderboardFederationMembersAddedBy :many
+SELECT guild_id, federation_id, label, added_by, added_at FROM leaderboard_federation_member
+WHERE added_by = ?
+ORDER BY added_at ASC
+`
+
+func (q *Queries) GetLeaderboardFederationMembersAddedBy(ctx context.Context, addedBy string) ([]LeaderboardFederationMember, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardFederationMembersAddedBy, addedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardFederationMember
+	for rows.Next() {
+		var i LeaderboardFederationMember
+		if err := rows.Scan(
+			&i.GuildID,
+			&i.FederationID,
+			&i.Label,
+			&i.AddedBy,
+			&i.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardFederationsCreatedBy = `-- name: GetLeaderboardFederationsCreatedBy :many
+SELECT federation_id, name, owner_guild_id, created_by, created_at FROM leaderboard_federation
+WHERE created_by = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetLeaderboardFederationsCreatedBy(ctx context.Context, createdBy string) ([]LeaderboardFederation, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardFederationsCreatedBy, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardFederation
+	for rows.Next() {
+		var i LeaderboardFederation
+		if err := rows.Scan(
+			&i.FederationID,
+			&i.Name,
+			&i.OwnerGuildID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardSeasonArchive = `-- name: GetLeaderboardSeasonArchive :many
+SELECT season_id, guild_id, lb_type, rank, player, game_name, value, details, snap_date
+FROM leaderboard_season_archive
+WHERE season_id = ? AND guild_id = ? AND lb_type = ?
+ORDER BY rank ASC
+`
+
+type GetLeaderboardSeasonArchiveParams struct {
+	SeasonID string
+	GuildID  string
+	LbType   string
+}
+
+func (q *Queries) GetLeaderboardSeasonArchive(ctx context.Context, arg GetLeaderboardSeasonArchiveParams) ([]LeaderboardSeasonArchive, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardSeasonArchive, arg.SeasonID, arg.GuildID, arg.LbType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardSeasonArchive
+	for rows.Next() {
+		var i LeaderboardSeasonArchive
+		if err := rows.Scan(
+			&i.SeasonID,
+			&i.GuildID,
+			&i.LbType,
+			&i.Rank,
+			&i.Player,
+			&i.GameName,
+			&i.Value,
+			&i.Details,
+			&i.SnapDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardSeasonArchiveForPlayer = `-- name: GetLeaderboardSeasonArchiveForPlayer :many
+SELECT season_id, guild_id, lb_type, rank, player, game_name, value, details, snap_date
+FROM leaderboard_season_archive
+WHERE player = ?
+ORDER BY season_id, guild_id, lb_type
+`
+
+func (q *Queries) GetLeaderboardSeasonArchiveForPlayer(ctx context.Context, player string) ([]LeaderboardSeasonArchive, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardSeasonArchiveForPlayer, player)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardSeasonArchive
+	for rows.Next() {
+		var i LeaderboardSeasonArchive
+		if err := rows.Scan(
+			&i.SeasonID,
+			&i.GuildID,
+			&i.LbType,
+			&i.Rank,
+			&i.Player,
+			&i.GameName,
+			&i.Value,
+			&i.Details,
+			&i.SnapDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardSeasonArchiveTypes = `-- name: GetLeaderboardSeasonArchiveTypes :many
+SELECT DISTINCT lb_type FROM leaderboard_season_archive
+WHERE season_id = ? AND guild_id = ?
+ORDER BY lb_type ASC
+`
+
+type GetLeaderboardSeasonArchiveTypesParams struct {
+	SeasonID string
+	GuildID  string
+}
+
+func (q *Queries) GetLeaderboardSeasonArchiveTypes(ctx context.Context, arg GetLeaderboardSeasonArchiveTypesParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardSeasonArchiveTypes, arg.SeasonID, arg.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var lb_type string
+		if err := rows.Scan(&lb_type); err != nil {
+			return nil, err
+		}
+		items = append(items, lb_type)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboardSeasons = `-- name: GetLeaderboardSeasons :many
+SELECT season_id, first_snap_date, last_snap_date, archived_at
+FROM leaderboard_season
+ORDER BY first_snap_date DESC
+`
+
+func (q *Queries) GetLeaderboardSeasons(ctx context.Context) ([]LeaderboardSeason, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboardSeasons)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LeaderboardSeason
+	for rows.Next() {
+		var i LeaderboardSeason
+		if err := rows.Scan(
+			&i.SeasonID,
+			&i.FirstSnapDate,
+			&i.LastSnapDate,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertGuildCoordinator = `-- name: InsertGuildCoordinator :exec
 
 INSERT INTO guild_coordinator (guild_id, user_id, added_by, added_at)
@@ -291,6 +875,84 @@ func (q *Queries) InsertGuildState(ctx context.Context, arg InsertGuildStatePara
 	return i, err
 }
 
+const insertLeaderboardFederation = `-- name: InsertLeaderboardFederation :exec
+INSERT INTO leaderboard_federation (federation_id, name, owner_guild_id, created_by, created_at)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type InsertLeaderboardFederationParams struct {
+	FederationID string
+	Name         string
+	OwnerGuildID string
+	CreatedBy    string
+	CreatedAt    int64
+}
+
+func (q *Queries) InsertLeaderboardFederation(ctx context.Context, arg InsertLeaderboardFederationParams) error {
+	_, err := q.db.ExecContext(ctx, insertLeaderboardFederation, arg.FederationID, arg.Name, arg.OwnerGuildID, arg.CreatedBy, arg.CreatedAt)
+	return err
+}
+
+const insertLeaderboardFederationMember = `-- name: InsertLeaderboardFederationMember :exec
+INSERT INTO leaderboard_federation_member (guild_id, federation_id, label, added_by, added_at)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type InsertLeaderboardFederationMemberParams struct {
+	GuildID      string
+	FederationID string
+	Label        string
+	AddedBy      string
+	AddedAt      int64
+}
+
+func (q *Queries) InsertLeaderboardFederationMember(ctx context.Context, arg InsertLeaderboardFederationMemberParams) error {
+	_, err := q.db.ExecContext(ctx, insertLeaderboardFederationMember, arg.GuildID, arg.FederationID, arg.Label, arg.AddedBy, arg.AddedAt)
+	return err
+}
+
+const setLeaderboardSeasonArchived = `-- name: SetLeaderboardSeasonArchived :exec
+UPDATE leaderboard_season SET archived_at = ? WHERE season_id = ?
+`
+
+type SetLeaderboardSeasonArchivedParams struct {
+	ArchivedAt int64
+	SeasonID   string
+}
+
+func (q *Queries) SetLeaderboardSeasonArchived(ctx context.Context, arg SetLeaderboardSeasonArchivedParams) error {
+	_, err := q.db.ExecContext(ctx, setLeaderboardSeasonArchived, arg.ArchivedAt, arg.SeasonID)
+	return err
+}
+
+const updateContractTemplateUpdatedBy = `-- name: UpdateContractTemplateUpdatedBy :exec
+UPDATE contract_template SET updated_by = ? WHERE updated_by = ?
+`
+
+type UpdateContractTemplateUpdatedByParams struct {
+	UpdatedBy   string
+	UpdatedBy_2 string
+}
+
+func (q *Queries) UpdateContractTemplateUpdatedBy(ctx context.Context, arg UpdateContractTemplateUpdatedByParams) error {
+	_, err := q.db.ExecContext(ctx, updateContractTemplateUpdatedBy, arg.UpdatedBy, arg.UpdatedBy_2)
+	return err
+}
+
+const updateGuildCoordinatorAddedBy = `-- name: UpdateGuildCoordinatorAddedBy :exec
+UPDATE guild_coordinator SET added_by = ? WHERE added_by = ?
+`
+
+type UpdateGuildCoordinatorAddedByParams struct {
+	AddedBy   string
+	AddedBy_2 string
+}
+
+func (q *Queries) UpdateGuildCoordinatorAddedBy(ctx context.Context, arg UpdateGuildCoordinatorAddedByParams) error {
+	_, err := q.db.ExecContext(ctx, updateGuildCoordinatorAddedBy, arg.AddedBy, arg.AddedBy_2)
+	return err
+}
+
 const updateGuildState = `-- name: UpdateGuildState :execrows
 UPDATE guild_record
 SET value = ?
@@ -328,6 +990,62 @@ func (q *Queries) UpdateLeaderboardConfigMessageIDs(ctx context.Context, arg Upd
 	return err
 }
 
+const updateLeaderboardFederationCreatedBy = `-- name: UpdateLeaderboardFederationCreatedBy :exec
+UPDATE leaderboard_federation SET created_by = ? WHERE created_by = ?
+`
+
+type UpdateLeaderboardFederationCreatedByParams struct {
+	CreatedBy   string
+	CreatedBy_2 string
+}
+
+func (q *Queries) UpdateLeaderboardFederationCreatedBy(ctx context.Context, arg UpdateLeaderboardFederationCreatedByParams) error {
+	_, err := q.db.ExecContext(ctx, updateLeaderboardFederationCreatedBy, arg.CreatedBy, arg.CreatedBy_2)
+	return err
+}
+
+const updateLeaderboardFederationMemberAddedBy = `-- name: UpdateLeaderboardFederationMemberAddedBy :exec
+UPDATE leaderboard_federation_member SET added_by = ? WHERE added_by = ?
+`
+
+type UpdateLeaderboardFederationMemberAddedByParams struct {
+	AddedBy   string
+	AddedBy_2 string
+}
+
+func (q *Queries) UpdateLeaderboardFederationMemberAddedBy(ctx context.Context, arg UpdateLeaderboardFederationMemberAddedByParams) error {
+	_, err := q.db.ExecContext(ctx, updateLeaderboardFederationMemberAddedBy, arg.AddedBy, arg.AddedBy_2)
+	return err
+}
+
+const upsertContractTemplate = `-- name: UpsertContractTemplate :exec
+INSERT INTO contract_template (guild_id, name, value, updated_by, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(guild_id, name) DO UPDATE SET
+    value      = excluded.value,
+    updated_by = excluded.updated_by,
+    updated_at = excluded.updated_at
+`
+
+type UpsertContractTemplateParams struct {
+	GuildID   string
+	Name      string
+	Value     string
+	UpdatedBy string
+	UpdatedAt int64
+}
+
+func (q *Queries) UpsertContractTemplate(ctx context.Context, arg UpsertContractTemplateParams) error {
+	_, err := q.db.ExecContext(ctx, upsertContractTemplate,
+		arg.GuildID,
+		arg.Name,
+		arg.Value,
+		arg.UpdatedBy,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
 const upsertLeaderboardConfig = `-- name: UpsertLeaderboardConfig :exec
 
 INSERT INTO leaderboard_config (lb_type, guild_id, channel_id, message_ids)
@@ -355,3 +1073,94 @@ func (q *Queries) UpsertLeaderboardConfig(ctx context.Context, arg UpsertLeaderb
 	)
 	return err
 }
+
+const upsertLeaderboardCustomMetric = `-- name: UpsertLeaderboardCustomMetric :exec
+INSERT INTO leaderboard_custom_metric (lb_type, guild_id, display_name, expression, value_fmt, higher_is_better, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(lb_type, guild_id) DO UPDATE SET
+    display_name     = excluded.display_name,
+    expression       = excluded.expression,
+    value_fmt        = excluded.value_fmt,
+    higher_is_better = excluded.higher_is_better,
+    updated_at       = excluded.updated_at
+`
+
+type UpsertLeaderboardCustomMetricParams struct {
+	LbType         string
+	GuildID        string
+	DisplayName    string
+	Expression     string
+	ValueFmt       string
+	HigherIsBetter bool
+	UpdatedAt      int64
+}
+
+func (q *Queries) UpsertLeaderboardCustomMetric(ctx context.Context, arg UpsertLeaderboardCustomMetricParams) error {
+	_, err := q.db.ExecContext(ctx, upsertLeaderboardCustomMetric,
+		arg.LbType,
+		arg.GuildID,
+		arg.DisplayName,
+		arg.Expression,
+		arg.ValueFmt,
+		arg.HigherIsBetter,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const upsertLeaderboardSeason = `-- name: UpsertLeaderboardSeason :exec
+INSERT INTO leaderboard_season (season_id, first_snap_date, last_snap_date)
+VALUES (?, ?, ?)
+ON CONFLICT(season_id) DO UPDATE SET
+    last_snap_date = excluded.last_snap_date
+`
+
+type UpsertLeaderboardSeasonParams struct {
+	SeasonID      string
+	FirstSnapDate string
+	LastSnapDate  string
+}
+
+// Records a weekly collection in a season, keeping the first snap date.
+func (q *Queries) UpsertLeaderboardSeason(ctx context.Context, arg UpsertLeaderboardSeasonParams) error {
+	_, err := q.db.ExecContext(ctx, upsertLeaderboardSeason, arg.SeasonID, arg.FirstSnapDate, arg.LastSnapDate)
+	return err
+}
+
+const upsertLeaderboardSeasonArchiveRow = `-- name: UpsertLeaderboardSeasonArchiveRow :exec
+INSERT INTO leaderboard_season_archive (season_id, guild_id, lb_type, rank, player, game_name, value, details, snap_date)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(season_id, guild_id, lb_type, player) DO UPDATE SET
+    rank      = excluded.rank,
+    game_name = excluded.game_name,
+    value     = excluded.value,
+    details   = excluded.details,
+    snap_date = excluded.snap_date
+`
+
+type UpsertLeaderboardSeasonArchiveRowParams struct {
+	SeasonID string
+	GuildID  string
+	LbType   string
+	Rank     int64
+	Player   string
+	GameName string
+	Value    float64
+	Details  string
+	SnapDate string
+}
+
+func (q *Queries) UpsertLeaderboardSeasonArchiveRow(ctx context.Context, arg UpsertLeaderboardSeasonArchiveRowParams) error {
+	_, err := q.db.ExecContext(ctx, upsertLeaderboardSeasonArchiveRow,
+		arg.SeasonID,
+		arg.GuildID,
+		arg.LbType,
+		arg.Rank,
+		arg.Player,
+		arg.GameName,
+		arg.Value,
+		arg.Details,
+		arg.SnapDate,
+	)
+	return err
+}
@@ -11,6 +11,53 @@ CREATE TABLE IF NOT EXISTS leaderboard_config (
     PRIMARY KEY (lb_type, guild_id)
 );
 
+CREATE TABLE IF NOT EXISTS leaderboard_custom_metric (
+    lb_type          TEXT NOT NULL,
+    guild_id         TEXT NOT NULL,
+    display_name     TEXT NOT NULL,
+    expression       TEXT NOT NULL,  -- evaluated over the built-in leaderboard values
+    value_fmt        TEXT NOT NULL,
+    higher_is_better BOOLEAN NOT NULL DEFAULT 1,
+    updated_at       INTEGER NOT NULL,
+    PRIMARY KEY (lb_type, guild_id)
+);
+
+CREATE TABLE IF NOT EXISTS leaderboard_season (
+    season_id       TEXT PRIMARY KEY,  -- e.g. "summer_2026"
+    first_snap_date TEXT NOT NULL,     -- first weekly collection in the season
+    last_snap_date  TEXT NOT NULL,     -- latest weekly collection in the season
+    archived_at     INTEGER NOT NULL DEFAULT 0  -- set once the final tables are frozen
+);
+
+CREATE TABLE IF NOT EXISTS leaderboard_season_archive (
+    season_id   TEXT NOT NULL,
+    guild_id    TEXT NOT NULL,
+    lb_type     TEXT NOT NULL,  -- leaderboard key, or award_* for season awards
+    rank        INTEGER NOT NULL,
+    player      TEXT NOT NULL,
+    game_name   TEXT NOT NULL,
+    value       REAL NOT NULL,
+    details     TEXT NOT NULL DEFAULT '',
+    snap_date   TEXT NOT NULL,
+    PRIMARY KEY (season_id, guild_id, lb_type, player)
+);
+
+CREATE TABLE IF NOT EXISTS leaderboard_federation (
+    federation_id  TEXT PRIMARY KEY,  -- join code shared with allied guild admins
+    name           TEXT NOT NULL,
+    owner_guild_id TEXT NOT NULL,
+    created_by     TEXT NOT NULL,
+    created_at     INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS leaderboard_federation_member (
+    guild_id      TEXT PRIMARY KEY,  -- a guild belongs to at most one federation
+    federation_id TEXT NOT NULL,
+    label         TEXT NOT NULL,     -- shown in the Guild column of federated boards
+    added_by      TEXT NOT NULL,
+    added_at      INTEGER NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS guild_coordinator (
     guild_id    TEXT NOT NULL,
     user_id     TEXT NOT NULL,
@@ -18,3 +65,12 @@ CREATE TABLE IF NOT EXISTS guild_coordinator (
     added_at    INTEGER NOT NULL,
     PRIMARY KEY (guild_id, user_id)
 );
+
+CREATE TABLE IF NOT EXISTS contract_template (
+    guild_id    TEXT NOT NULL,
+    name        TEXT NOT NULL,
+    value       TEXT NOT NULL, -- JSON encoded template settings
+    updated_by  TEXT NOT NULL,
+    updated_at  INTEGER NOT NULL,
+    PRIMARY KEY (guild_id, name)
+);
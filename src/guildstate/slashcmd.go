@@ -27,6 +27,27 @@ var snowflakeRe = regexp.MustCompile(`\b\d{17,20}\b`)
 var knownSettingKeys = []string{
 	"admin_logs_channel",
 	"amqp_url",
+	"event_sink",
+	"webhook_url",
+	"webhook_secret",
+}
+
+// secretSettingKeys are the settings shown masked, as they authenticate the guild's webhooks.
+var secretSettingKeys = map[string]bool{
+	"webhook_secret": true,
+}
+
+// displaySettingValue returns a setting value as it is shown back to admins.
+// Secrets keep only their last 4 characters.
+func displaySettingValue(key, value string) string {
+	const shown = 4
+	if !secretSettingKeys[key] {
+		return value
+	}
+	if len(value) <= shown {
+		return strings.Repeat("•", len(value))
+	}
+	return "••••" + value[len(value)-shown:]
 }
 
 // SlashSetGuildSettingCommand creates an admin slash command to set/clear a guild string setting.
@@ -414,7 +435,9 @@ func SetGuildSettingForGuild(s *discordgo.Session, i *discordgo.InteractionCreat
 
 	var builder strings.Builder
 	items := splitCSV(value)
-	if len(items) > 1 {
+	if secretSettingKeys[setting] {
+		fmt.Fprintf(&builder, "Set setting '%s' for guild '%s' to '%s'.", setting, guildName, displaySettingValue(setting, value))
+	} else if len(items) > 1 {
 		fmt.Fprintf(&builder, "Set setting '%s' for guild '%s' (%d items):", setting, guildName, len(items))
 		for _, item := range items {
 			details := getSnowflakeDetails(s, guildID, item)
@@ -430,6 +453,9 @@ func SetGuildSettingForGuild(s *discordgo.Session, i *discordgo.InteractionCreat
 			fmt.Fprintf(&builder, "\n- resolved: %s", detail)
 		}
 	}
+	if setting == "webhook_url" && GetGuildSettingString(guildID, "webhook_secret") == "" {
+		builder.WriteString("\nWarning: 'webhook_secret' is not set, so webhook deliveries will be unsigned and receivers can't verify them.")
+	}
 
 	followupEphemeralOrFile(s, i, builder.String(), fmt.Sprintf("guild-settings-%s.txt", guildID))
 }
@@ -490,7 +516,10 @@ func GetGuildSettingsForGuild(s *discordgo.Session, i *discordgo.InteractionCrea
 		for _, key := range keys {
 			value := guild.MiscSettingsString[key]
 			items := splitCSV(value)
-			fmt.Fprintf(&builder, "- %s = %s\n", key, value)
+			fmt.Fprintf(&builder, "- %s = %s\n", key, displaySettingValue(key, value))
+			if secretSettingKeys[key] {
+				continue
+			}
 			if len(items) > 1 {
 				fmt.Fprintf(&builder, "  - parsed items (%d):\n", len(items))
 				for _, item := range items {
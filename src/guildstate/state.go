@@ -8,6 +8,7 @@ import (
 	"log"
 	"sort"
 	"sync"
+	"testing"
 	"time"
 
 	_ "modernc.org/sqlite" // SQLite driver registration.
@@ -36,7 +37,16 @@ func sqliteInit() {
 		return
 	}
 
-	db, _ := sql.Open("sqlite", "ttbb-data/Guildstate.sqlite?_busy_timeout=5000")
+	dsn := "ttbb-data/Guildstate.sqlite?_busy_timeout=5000"
+	if testing.Testing() {
+		// Tests in every package get a fresh store instead of ttbb-data
+		dsn = ":memory:"
+	}
+	db, _ := sql.Open("sqlite", dsn)
+	if testing.Testing() {
+		// Every connection to :memory: is a separate database
+		db.SetMaxOpenConns(1)
+	}
 	_, _ = db.ExecContext(ctx, ddl)
 	queries = New(db)
 }
@@ -7,6 +7,7 @@ import (
 	"log"
 	"sort"
 
+	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
 	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
@@ -301,6 +302,9 @@ func LBDefByKey(key string) (LBDef, bool) {
 			return def, true
 		}
 	}
+	if m, ok := CustomMetricByKey(key); ok {
+		return m.Def, true
+	}
 	return LBDef{}, false
 }
 
@@ -319,6 +323,9 @@ func GroupByKey(key string) (LBGroup, bool) {
 // resolves to. For group keys this is the group's Members slice; for individual
 // type keys it's a single-element slice containing that key.
 func ExpandConfigKey(key string) []string {
+	if isFederatedKey(key) {
+		return ExpandConfigKey(federatedBaseKey(key))
+	}
 	key = resolveAlias(key)
 	if key == OptInAll {
 		keys := make([]string, 0, len(AllLeaderboards))
@@ -336,6 +343,11 @@ func ExpandConfigKey(key string) []string {
 // IsValidConfigKey returns true if key is either an individual LBDef key or a
 // group key — i.e. valid for use in /admin-lb admin set-channel.
 func IsValidConfigKey(key string) bool {
+	if isFederatedKey(key) {
+		// Custom metrics belong to one guild, so they can't be federated
+		base := federatedBaseKey(key)
+		return !isCustomKey(base) && IsValidConfigKey(base)
+	}
 	key = resolveAlias(key)
 	if _, ok := LBDefByKey(key); ok {
 		return true
@@ -347,6 +359,9 @@ func IsValidConfigKey(key string) bool {
 // DisplayNameForConfigKey returns a human-readable name for any config key
 // (individual or group).
 func DisplayNameForConfigKey(key string) string {
+	if isFederatedKey(key) {
+		return DisplayNameForConfigKey(federatedBaseKey(key)) + " (Federation)"
+	}
 	key = resolveAlias(key)
 	if def, ok := LBDefByKey(key); ok {
 		return def.DisplayName
@@ -384,6 +399,9 @@ func PlayerIsOptedIn(guildID, userID, lbType string) bool {
 		if o.UserID != userID {
 			continue
 		}
+		if o.LbType == OptInAll && customKeyInGuild(targetKey, guildID) {
+			return true
+		}
 		for _, optType := range ExpandConfigKey(o.LbType) {
 			if resolveAlias(optType) == targetKey {
 				return true
@@ -427,6 +445,11 @@ func GetPlayerOptInTypes(guildID, userID string) []string {
 					seen[def.Key] = struct{}{}
 					keys = append(keys, def.Key)
 				}
+				for _, key := range guildCustomKeys(guildID) {
+					if _, isExcl := excludedSet[key]; !isExcl {
+						keys = append(keys, key)
+					}
+				}
 				return keys
 			}
 			for _, optType := range ExpandConfigKey(o.LbType) {
@@ -472,6 +495,7 @@ func RemovePlayerOptInTypes(guildID, userID string, types []string) {
 		// If they have no opt-ins left in any guild, delete all stats globally.
 		if len(GetUserOptInGuilds(userID)) == 0 {
 			_ = farmerstate.DeleteAllLeaderboardStatsForPlayer(userID)
+			_ = farmerstate.DeleteLeaderboardAnomaliesForPlayer(userID)
 		}
 		return
 	}
@@ -644,6 +668,7 @@ type LBEntry struct {
 	SnapDate string // ISO date "YYYY-MM-DD"
 	Value    float64
 	Details  string // human-readable extra info
+	Guild    string // member guild label, only set on federated boards
 }
 
 // SaveLBEntry persists one leaderboard stat row.
@@ -651,10 +676,16 @@ func SaveLBEntry(e LBEntry) {
 	if err := farmerstate.UpsertLeaderboardStat(e.LBType, e.Player, e.GameName, e.SnapDate, e.Value, sql.NullString{String: e.Details, Valid: e.Details != ""}); err != nil {
 		log.Printf("leaderboard: save stat %s/%s: %v", e.LBType, e.Player, err)
 	}
+	recordAnomalyHistory(e)
 
-	// Prune older entries if this leaderboard should only retain the most recent records.
+	// Prune older entries if this leaderboard should only retain the most recent records,
+	// or thin them out when history is kept.
 	if def, ok := LBDefByKey(e.LBType); ok && def.RetainRecentOnly {
-		_ = farmerstate.PruneOlderLeaderboardStatsForPlayer(e.LBType, e.Player, e.SnapDate)
+		if config.LeaderboardKeepHistory {
+			thinLBHistory(e.LBType, e.Player)
+		} else {
+			_ = farmerstate.PruneOlderLeaderboardStatsForPlayer(e.LBType, e.Player, e.SnapDate)
+		}
 	}
 }
 
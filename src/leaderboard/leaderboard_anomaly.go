@@ -0,0 +1,479 @@
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// ─── Anomaly Detection ───────────────────────────────────────────────────────
+
+// Review states of a held snapshot
+const (
+	anomalyPending  = "pending"
+	anomalyApproved = "approved"
+	anomalyRejected = "rejected"
+)
+
+const (
+	// anomalyMinHistory is how many past weekly changes the z-score check needs
+	anomalyMinHistory = 4
+	// anomalyHistoryWindow is how many accepted values are kept per screened
+	// leaderboard, as most of them otherwise keep only the latest snapshot
+	anomalyHistoryWindow = 2 * anomalyMinHistory
+	// maxAnomaliesListed caps the /admin-lb anomalies reply
+	maxAnomaliesListed = 20
+)
+
+// anomalyRule describes the changes between snapshots that are implausible for a metric.
+type anomalyRule struct {
+	neverDecreases bool    // any drop is flagged
+	logScale       bool    // compare changes in orders of magnitude, for values that grow exponentially
+	maxZScore      float64 // weekly change this many standard deviations above the player's usual change
+	maxWeeklyRatio float64 // without enough history, growth beyond this multiple of the prior value per week
+	minPrior       float64 // prior value below which the ratio check is skipped, new accounts grow fast
+}
+
+// anomalyRules are the leaderboards screened during collection.
+var anomalyRules = map[string]anomalyRule{
+	LBSoulEggs:     {neverDecreases: true, logScale: true, maxZScore: 6, maxWeeklyRatio: 100},
+	LBContractExp:  {maxZScore: 6, maxWeeklyRatio: 2, minPrior: 100000},
+	LBProphecyEggs: {neverDecreases: true},
+	LBTETotal:      {neverDecreases: true},
+	LBPrestiges:    {neverDecreases: true},
+	LBDrones:       {neverDecreases: true},
+	LBEliteDrones:  {neverDecreases: true},
+}
+
+// anomalyDependents are the derived leaderboards held along with a flagged one.
+var anomalyDependents = map[string][]string{
+	LBContractExp: {LBCXPWeeklyDelta},
+	LBSoulEggs:    {LBSEPerPrestige},
+	LBPrestiges:   {LBSEPerPrestige},
+	LBTETotal:     {LBTEPerShift},
+}
+
+// snapWeeks returns the number of weeks between two snap dates.
+func snapWeeks(from, to string) float64 {
+	a, errA := time.Parse("2006-01-02", from)
+	b, errB := time.Parse("2006-01-02", to)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return b.Sub(a).Hours() / (24 * 7)
+}
+
+// weeklyChange returns the per-week change between two snapshots.
+func weeklyChange(rule anomalyRule, from, to LBEntry) (float64, bool) {
+	weeks := snapWeeks(from.SnapDate, to.SnapDate)
+	if weeks <= 0 {
+		return 0, false
+	}
+	if rule.logScale {
+		if from.Value <= 0 || to.Value <= 0 {
+			return 0, false
+		}
+		return (math.Log10(to.Value) - math.Log10(from.Value)) / weeks, true
+	}
+	return (to.Value - from.Value) / weeks, true
+}
+
+// checkLBAnomaly compares a new snapshot with the player's earlier ones, oldest
+// first, and returns why it looks implausible or "" when it doesn't.
+func checkLBAnomaly(def LBDef, rule anomalyRule, current LBEntry, history []LBEntry) string {
+	if len(history) == 0 {
+		return ""
+	}
+	name := def.DisplayName
+	if def.HeaderName != "" {
+		name = def.HeaderName
+	}
+	prior := history[len(history)-1]
+	change := fmt.Sprintf("from %s to %s", FormatLBValue(def.ValueFmt, prior.Value), FormatLBValue(def.ValueFmt, current.Value))
+
+	if rule.neverDecreases && current.Value < prior.Value {
+		return fmt.Sprintf("%s dropped %s", name, change)
+	}
+	rate, ok := weeklyChange(rule, prior, current)
+	if !ok || rate <= 0 {
+		return ""
+	}
+
+	var rates []float64
+	for n := 1; n < len(history); n++ {
+		if r, ok := weeklyChange(rule, history[n-1], history[n]); ok {
+			rates = append(rates, r)
+		}
+	}
+	if rule.maxZScore > 0 && len(rates) >= anomalyMinHistory {
+		var mean, variance float64
+		for _, r := range rates {
+			mean += r
+		}
+		mean /= float64(len(rates))
+		for _, r := range rates {
+			variance += (r - mean) * (r - mean)
+		}
+		// A very steady player would otherwise have ordinary weeks flagged
+		std := max(math.Sqrt(variance/float64(len(rates))), 0.5*math.Abs(mean))
+		if std > 0 {
+			if z := (rate - mean) / std; z > rule.maxZScore {
+				return fmt.Sprintf("%s grew %s, %.1fσ above this player's usual weekly change", name, change, z)
+			}
+			return ""
+		}
+	}
+
+	if rule.maxWeeklyRatio > 0 && prior.Value > 0 && prior.Value >= rule.minPrior {
+		weeks := max(snapWeeks(prior.SnapDate, current.SnapDate), 1)
+		allowed := 1 + (rule.maxWeeklyRatio-1)*weeks
+		if rule.logScale {
+			allowed = math.Pow(rule.maxWeeklyRatio, weeks)
+		}
+		if ratio := current.Value / prior.Value; ratio > allowed {
+			return fmt.Sprintf("%s grew %s, %.1f× in %.0f week(s)", name, change, ratio, math.Ceil(weeks))
+		}
+	}
+	return ""
+}
+
+// heldWithFlagged returns the flagged leaderboard a derived one is computed from, or "".
+func heldWithFlagged(key string, reasons map[string]string) string {
+	flagged := make([]string, 0, len(reasons))
+	for k := range reasons {
+		flagged = append(flagged, k)
+	}
+	sort.Strings(flagged)
+	for _, f := range flagged {
+		if slices.Contains(anomalyDependents[f], key) {
+			return f
+		}
+		if m, ok := CustomMetricByKey(key); ok && slices.Contains(m.expr.Refs, f) {
+			return f
+		}
+	}
+	return ""
+}
+
+// recordAnomalyHistory adds an accepted snapshot of a screened leaderboard to
+// the recent values later snapshots are compared with.
+func recordAnomalyHistory(e LBEntry) {
+	if _, ok := anomalyRules[e.LBType]; !ok {
+		return
+	}
+	if err := farmerstate.RecordLeaderboardAnomalyHistory(e.LBType, e.Player, e.SnapDate, e.Value, anomalyHistoryWindow); err != nil {
+		log.Printf("leaderboard: anomaly history %s/%s: %v", e.LBType, e.Player, err)
+	}
+}
+
+// anomalyHistory returns a player's accepted snapshots before snapDate for
+// each screened leaderboard, oldest first.
+func anomalyHistory(userID, snapDate string) (map[string][]LBEntry, error) {
+	rows, err := farmerstate.GetStatsForPlayer(userID)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := farmerstate.GetLeaderboardAnomalyHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]map[string]float64)
+	add := func(lbType, date string, value float64) {
+		if _, ok := anomalyRules[lbType]; !ok || date >= snapDate {
+			return
+		}
+		if values[lbType] == nil {
+			values[lbType] = make(map[string]float64)
+		}
+		values[lbType][date] = value
+	}
+	for _, r := range rows {
+		add(r.LbType, r.SnapDate, r.Value)
+	}
+	for _, r := range recent {
+		add(r.LbType, r.SnapDate, r.Value)
+	}
+
+	history := make(map[string][]LBEntry, len(values))
+	for lbType, byDate := range values {
+		h := make([]LBEntry, 0, len(byDate))
+		for date, value := range byDate {
+			h = append(h, LBEntry{LBType: lbType, Player: userID, SnapDate: date, Value: value})
+		}
+		sort.Slice(h, func(a, b int) bool { return h[a].SnapDate < h[b].SnapDate })
+		history[lbType] = h
+	}
+	return history, nil
+}
+
+// screenLBEntries holds implausible snapshots, and the ones derived from them,
+// out of the leaderboards for review. It returns the entries to save.
+func screenLBEntries(userID string, entries []LBEntry, snapDate string) []LBEntry {
+	if !slices.ContainsFunc(entries, func(e LBEntry) bool { _, ok := anomalyRules[e.LBType]; return ok }) {
+		return entries
+	}
+	history, err := anomalyHistory(userID, snapDate)
+	if err != nil {
+		log.Printf("leaderboard: anomaly history for %s: %v", userID, err)
+		return entries
+	}
+
+	reasons := make(map[string]string)
+	for _, e := range entries {
+		rule, ok := anomalyRules[e.LBType]
+		if !ok {
+			continue
+		}
+		def, _ := LBDefByKey(e.LBType)
+		if reason := checkLBAnomaly(def, rule, e, history[e.LBType]); reason != "" {
+			reasons[e.LBType] = reason
+		}
+	}
+	if len(reasons) == 0 {
+		return entries
+	}
+
+	out := make([]LBEntry, 0, len(entries))
+	for _, e := range entries {
+		heldWith := ""
+		reason, flagged := reasons[e.LBType]
+		if !flagged {
+			if heldWith = heldWithFlagged(e.LBType, reasons); heldWith == "" {
+				out = append(out, e)
+				continue
+			}
+			reason = "held with " + DisplayNameForConfigKey(heldWith)
+		}
+
+		switch farmerstate.GetLeaderboardAnomalyStatus(e.LBType, e.Player, e.SnapDate) {
+		case anomalyApproved:
+			out = append(out, e)
+			continue
+		case anomalyRejected:
+			continue
+		}
+
+		a := farmerstate.LeaderboardAnomaly{
+			LbType:   e.LBType,
+			Player:   e.Player,
+			GameName: e.GameName,
+			SnapDate: e.SnapDate,
+			Value:    e.Value,
+			Details:  e.Details,
+			Reason:   reason,
+			HeldWith: heldWith,
+		}
+		if h := history[e.LBType]; len(h) > 0 {
+			a.PriorValue = h[len(h)-1].Value
+			a.PriorSnapDate = h[len(h)-1].SnapDate
+		}
+		if err := farmerstate.RecordLeaderboardAnomaly(a); err != nil {
+			log.Printf("leaderboard: record anomaly %s/%s: %v", e.LBType, e.Player, err)
+		}
+		log.Printf("leaderboard: holding %s for %s on %s for review: %s", e.LBType, e.Player, e.SnapDate, reason)
+	}
+	return out
+}
+
+// ReviewLBAnomaly approves or rejects a held snapshot along with the snapshots
+// held with it. Approved snapshots are saved to the leaderboards, and later
+// snapshots held for the same leaderboard are screened again against them.
+func ReviewLBAnomaly(id int64, approve bool, reviewer string) (farmerstate.LeaderboardAnomaly, error) {
+	a, err := farmerstate.GetLeaderboardAnomaly(id)
+	if err != nil {
+		return a, err
+	}
+	if a.Status != anomalyPending {
+		return a, fmt.Errorf("it was already %s", a.Status)
+	}
+	held, err := farmerstate.GetHeldLeaderboardAnomalies(a.Player, a.SnapDate, a.LbType)
+	if err != nil {
+		return a, err
+	}
+
+	status := anomalyRejected
+	if approve {
+		status = anomalyApproved
+	}
+	var errs []error
+	for _, h := range append([]farmerstate.LeaderboardAnomaly{a}, held...) {
+		if approve {
+			saveApprovedLBEntry(LBEntry{
+				LBType:   h.LbType,
+				Player:   h.Player,
+				GameName: h.GameName,
+				SnapDate: h.SnapDate,
+				Value:    h.Value,
+				Details:  h.Details,
+			})
+		}
+		errs = append(errs, farmerstate.SetLeaderboardAnomalyStatus(h.ID, status, reviewer))
+	}
+	if approve {
+		errs = append(errs, rescreenLBAnomalies(a, reviewer))
+	}
+	return a, errors.Join(errs...)
+}
+
+// saveApprovedLBEntry saves an approved snapshot. One approved after a newer
+// snapshot was collected only joins the anomaly history, as saving it would
+// prune the newer snapshot from a leaderboard that keeps just the latest.
+func saveApprovedLBEntry(e LBEntry) {
+	def, ok := LBDefByKey(e.LBType)
+	if ok && def.RetainRecentOnly && !config.LeaderboardKeepHistory {
+		if latest := GetPriorStatForPlayer(e.LBType, e.Player); latest != nil && latest.SnapDate > e.SnapDate {
+			recordAnomalyHistory(e)
+			return
+		}
+	}
+	SaveLBEntry(e)
+}
+
+// rescreenLBAnomalies checks the pending snapshots collected after an approved
+// one again, now that it is their prior. Those that no longer look implausible
+// are approved, the rest are held with the updated prior.
+func rescreenLBAnomalies(approved farmerstate.LeaderboardAnomaly, reviewer string) error {
+	pending, err := farmerstate.GetPendingLeaderboardAnomalies()
+	if err != nil {
+		return err
+	}
+	// Oldest first, so each approval becomes the prior of the next
+	slices.Reverse(pending)
+	var errs []error
+	for _, p := range pending {
+		if p.LbType != approved.LbType || p.Player != approved.Player || p.SnapDate <= approved.SnapDate {
+			continue
+		}
+		history, err := anomalyHistory(p.Player, p.SnapDate)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		h := history[p.LbType]
+		def, _ := LBDefByKey(p.LbType)
+		e := LBEntry{LBType: p.LbType, Player: p.Player, GameName: p.GameName, SnapDate: p.SnapDate, Value: p.Value, Details: p.Details}
+		reason := checkLBAnomaly(def, anomalyRules[p.LbType], e, h)
+		if reason == "" {
+			log.Printf("leaderboard: approving %s for %s on %s after the earlier snapshot was approved", p.LbType, p.Player, p.SnapDate)
+			_, err := ReviewLBAnomaly(p.ID, true, reviewer)
+			errs = append(errs, err)
+			// Approving it re-screens anything later
+			break
+		}
+		p.Reason = reason
+		if len(h) > 0 {
+			p.PriorValue = h[len(h)-1].Value
+			p.PriorSnapDate = h[len(h)-1].SnapDate
+		}
+		errs = append(errs, farmerstate.RecordLeaderboardAnomaly(p))
+	}
+	return errors.Join(errs...)
+}
+
+// isHomeGuild reports whether the interaction came from the bot's home guild.
+// Snapshots are shared by every guild, so only its admins review anomalies.
+func isHomeGuild(guildID string) bool {
+	home := guildstate.GetGuildSettingString("DEFAULT", "home_guild")
+	return home != "" && guildID == home
+}
+
+// anomalySummary is the one-line description of a held snapshot.
+func anomalySummary(a farmerstate.LeaderboardAnomaly) string {
+	name := a.GameName
+	if name == "" {
+		name = a.Player
+	}
+	return fmt.Sprintf("#%d %s · %s · %s", a.ID, name, DisplayNameForConfigKey(a.LbType), a.SnapDate)
+}
+
+func handleAdminAnomalies(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isHomeGuild(i.GuildID) {
+		respondEphemeral(s, i, "Leaderboard anomalies are reviewed from the bot's home server.")
+		return
+	}
+	pending, err := farmerstate.GetPendingLeaderboardAnomalies()
+	if err != nil {
+		log.Printf("leaderboard: admin anomalies error: %v", err)
+		respondEphemeral(s, i, "Failed to load held snapshots.")
+		return
+	}
+	if len(pending) == 0 {
+		respondEphemeral(s, i, "No leaderboard snapshots are waiting for review.")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("**Leaderboard snapshots held for review:**\n")
+	for n, a := range pending {
+		if n == maxAnomaliesListed {
+			fmt.Fprintf(&b, "-# …and %d more\n", len(pending)-n)
+			break
+		}
+		fmt.Fprintf(&b, "• `%s` <@%s>: %s\n", anomalySummary(a), a.Player, a.Reason)
+	}
+	b.WriteString("-# Approve or reject with `/admin-lb anomaly-review`.")
+	respondEphemeral(s, i, b.String())
+}
+
+func handleAdminAnomalyReview(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+	if !isHomeGuild(i.GuildID) {
+		respondEphemeral(s, i, "Leaderboard anomalies are reviewed from the bot's home server.")
+		return
+	}
+	optMap := optionMap(opts)
+	id, err := strconv.ParseInt(strings.TrimPrefix(optMap["anomaly"].StringValue(), "#"), 10, 64)
+	if err != nil {
+		respondEphemeral(s, i, "Pick a held snapshot from the list.")
+		return
+	}
+	approve := optMap["decision"].StringValue() == "approve"
+
+	a, err := ReviewLBAnomaly(id, approve, bottools.GetInteractionUserID(i))
+	if err != nil {
+		respondEphemeral(s, i, fmt.Sprintf("Couldn't review anomaly #%d: %v", id, err))
+		return
+	}
+	if approve {
+		respondEphemeral(s, i, fmt.Sprintf("✅ Approved `%s`. It will appear from the next leaderboard post.", anomalySummary(a)))
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("🗑️ Rejected `%s`. The snapshot was discarded.", anomalySummary(a)))
+}
+
+// anomalyChoices lists the held snapshots matching partial.
+func anomalyChoices(guildID, partial string) []*discordgo.ApplicationCommandOptionChoice {
+	const maxChoices = 25
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if !isHomeGuild(guildID) {
+		return choices
+	}
+	pending, err := farmerstate.GetPendingLeaderboardAnomalies()
+	if err != nil {
+		return choices
+	}
+	for _, a := range pending {
+		if len(choices) >= maxChoices {
+			break
+		}
+		summary := anomalySummary(a)
+		if partial == "" || strings.Contains(strings.ToLower(summary), partial) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncateString(summary, 100),
+				Value: strconv.FormatInt(a.ID, 10),
+			})
+		}
+	}
+	return choices
+}
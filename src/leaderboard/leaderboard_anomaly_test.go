@@ -0,0 +1,128 @@
+package leaderboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+)
+
+// weeklySnaps builds consecutive weekly snapshots starting 2026-01-02.
+func weeklySnaps(values ...float64) []LBEntry {
+	dates := []string{"2026-01-02", "2026-01-09", "2026-01-16", "2026-01-23", "2026-01-30", "2026-02-06", "2026-02-13"}
+	out := make([]LBEntry, len(values))
+	for n, v := range values {
+		out[n] = LBEntry{Player: "p1", SnapDate: dates[n], Value: v}
+	}
+	return out
+}
+
+func TestCheckLBAnomaly(t *testing.T) {
+	pe, _ := LBDefByKey(LBProphecyEggs)
+	cs, _ := LBDefByKey(LBContractExp)
+	se, _ := LBDefByKey(LBSoulEggs)
+
+	tests := []struct {
+		name   string
+		def    LBDef
+		values []float64 // history followed by the new snapshot
+		want   string    // substring of the reason, "" when not flagged
+	}{
+		{"first snapshot", pe, []float64{100}, ""},
+		{"PE steady", pe, []float64{100, 101}, ""},
+		{"PE decrease", pe, []float64{100, 90}, "dropped"},
+		{"CS usual growth", cs, []float64{1e6, 1.1e6, 1.2e6, 1.3e6, 1.4e6, 1.5e6}, ""},
+		{"CS outlier growth", cs, []float64{1e6, 1.1e6, 1.2e6, 1.3e6, 1.4e6, 9e6}, "σ"},
+		{"CS short history ratio", cs, []float64{1e6, 3e6}, "×"},
+		{"CS new player", cs, []float64{1000, 50000}, ""},
+		{"SE drop", se, []float64{1e20, 1e19}, "dropped"},
+		{"SE strong week", se, []float64{1e20, 5e21}, ""},
+		{"SE implausible week", se, []float64{1e20, 1e23}, "×"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snaps := weeklySnaps(tt.values...)
+			n := len(snaps) - 1
+			got := checkLBAnomaly(tt.def, anomalyRules[tt.def.Key], snaps[n], snaps[:n])
+			if tt.want == "" && got != "" {
+				t.Errorf("checkLBAnomaly() = %q, want not flagged", got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("checkLBAnomaly() = %q, want a reason containing %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeldWithFlagged(t *testing.T) {
+	reasons := map[string]string{LBContractExp: "CS grew"}
+	if got := heldWithFlagged(LBCXPWeeklyDelta, reasons); got != LBContractExp {
+		t.Errorf("heldWithFlagged(%s) = %q, want %q", LBCXPWeeklyDelta, got, LBContractExp)
+	}
+	if got := heldWithFlagged(LBSEPerPrestige, reasons); got != "" {
+		t.Errorf("heldWithFlagged(%s) = %q, want it saved normally", LBSEPerPrestige, got)
+	}
+}
+
+func TestScreenLBEntriesKeepsHistoryWindow(t *testing.T) {
+	if config.LeaderboardKeepHistory {
+		t.Skip("history is kept in leaderboard_stats")
+	}
+	const player = "window-user"
+	snaps := weeklySnaps(1e20, 1.1e20, 1.2e20, 1.3e20, 1.4e20, 1.5e20, 7.5e21)
+	for _, e := range snaps[:len(snaps)-1] {
+		e.LBType, e.Player = LBSoulEggs, player
+		SaveLBEntry(e)
+	}
+	if rows, _ := farmerstate.GetStatsForPlayer(player); len(rows) != 1 {
+		t.Fatalf("leaderboard_stats kept %d soul egg snapshots, want only the latest", len(rows))
+	}
+
+	// 50× in a week passes the ratio check, but not this player's usual growth
+	jump := snaps[len(snaps)-1]
+	jump.LBType, jump.Player = LBSoulEggs, player
+	if out := screenLBEntries(player, []LBEntry{jump}, jump.SnapDate); len(out) != 0 {
+		t.Fatalf("screenLBEntries() = %+v, want the jump held", out)
+	}
+	pending, _ := farmerstate.GetPendingLeaderboardAnomalies()
+	if len(pending) != 1 || !strings.Contains(pending[0].Reason, "σ") {
+		t.Errorf("pending anomalies = %+v, want one held by the z-score check", pending)
+	}
+}
+
+func TestReviewLBAnomalyLateApproval(t *testing.T) {
+	const player = "late-user"
+	snaps := weeklySnaps(1e20, 1.1e20, 1.2e20, 1.3e20, 1.4e20, 7e21, 7.7e21)
+	for n := range snaps {
+		snaps[n].LBType, snaps[n].Player = LBSoulEggs, player
+	}
+	for _, e := range snaps[:5] {
+		SaveLBEntry(e)
+	}
+	// The jump isn't reviewed before the next collection compares with the pre-jump prior
+	for _, e := range snaps[5:] {
+		if out := screenLBEntries(player, []LBEntry{e}, e.SnapDate); len(out) != 0 {
+			t.Fatalf("screenLBEntries(%s) = %+v, want it held", e.SnapDate, out)
+		}
+	}
+
+	jumpID := int64(0)
+	pending, _ := farmerstate.GetPendingLeaderboardAnomalies()
+	for _, a := range pending {
+		if a.Player == player && a.SnapDate == snaps[5].SnapDate {
+			jumpID = a.ID
+		}
+	}
+	if _, err := ReviewLBAnomaly(jumpID, true, "admin"); err != nil {
+		t.Fatalf("ReviewLBAnomaly() error: %v", err)
+	}
+
+	next := snaps[6]
+	if status := farmerstate.GetLeaderboardAnomalyStatus(next.LBType, player, next.SnapDate); status != anomalyApproved {
+		t.Errorf("snapshot after the approved jump is %q, want it approved against the new prior", status)
+	}
+	if latest := GetPriorStatForPlayer(LBSoulEggs, player); latest == nil || latest.SnapDate != next.SnapDate {
+		t.Errorf("latest soul eggs = %+v, want the %s snapshot", latest, next.SnapDate)
+	}
+}
@@ -2,6 +2,7 @@ package leaderboard
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
 )
@@ -50,6 +51,8 @@ var shipStdIndex = map[string]int{
 //
 // snapDate is the ISO date string "YYYY-MM-DD" for this collection run.
 // priorCXPTotal is the total CXP from the previous collection, used for delta calculation.
+// priorCustom holds the previous snapshot of each opted-in custom metric, used
+// for prev(), delta() and weeks().
 func RunCalculators(
 	userID string,
 	backup *ei.Backup,
@@ -57,6 +60,7 @@ func RunCalculators(
 	optedIn []string,
 	snapDate string,
 	priorCXPTotal float64,
+	priorCustom map[string]*LBEntry,
 ) []LBEntry {
 	if backup == nil && archive == nil {
 		return nil
@@ -70,6 +74,47 @@ func RunCalculators(
 		}
 	}
 
+	// ── Custom metrics ────────────────────────────────────────────────────────
+	// Calculate every built-in value the expressions read, then keep only the
+	// opted-in built-ins alongside the custom results.
+	var custom []CustomMetric
+	var builtin []string
+	for _, k := range optedIn {
+		if m, ok := CustomMetricByKey(k); ok {
+			custom = append(custom, m)
+		} else if !isCustomKey(k) {
+			builtin = append(builtin, k)
+		}
+	}
+	if len(custom) > 0 {
+		needed := slices.Clone(builtin)
+		for _, m := range custom {
+			for _, ref := range m.expr.Refs {
+				if !slices.Contains(needed, ref) {
+					needed = append(needed, ref)
+				}
+			}
+		}
+		values := make(map[string]float64)
+		var entries []LBEntry
+		for _, e := range RunCalculators(userID, backup, archive, needed, snapDate, priorCXPTotal, nil) {
+			values[e.LBType] = e.Value
+			if slices.Contains(builtin, e.LBType) {
+				entries = append(entries, e)
+			}
+		}
+		for _, m := range custom {
+			e, err := evaluateCustomMetric(m, values, priorCustom[m.Def.Key], snapDate)
+			if err != nil {
+				continue
+			}
+			e.Player = userID
+			e.GameName = gameName
+			entries = append(entries, e)
+		}
+		return entries
+	}
+
 	// Build a set for fast opt-in lookup.
 	optSet := make(map[string]struct{}, len(optedIn))
 	for _, k := range optedIn {
@@ -0,0 +1,408 @@
+package leaderboard
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// ─── Custom Leaderboard Metrics ──────────────────────────────────────────────
+
+// customKeyPrefix starts every custom metric key: custom_<guildID>_<slug>
+const customKeyPrefix = "custom_"
+
+// maxCustomMetricsPerGuild caps how many custom metrics a guild can register
+const maxCustomMetricsPerGuild = 10
+
+// customValueFmts are the value formats a custom metric can use.
+var customValueFmts = []string{"int", "float", "pct", "ei", "eb", "cxp", "duration"}
+
+// CustomMetric is a guild defined leaderboard computed from an expression.
+type CustomMetric struct {
+	Def        LBDef
+	GuildID    string
+	Expression string
+	expr       *LBExpr
+}
+
+var (
+	customMutex   sync.Mutex
+	customMetrics map[string]CustomMetric // keyed by LBDef.Key, nil until loaded
+)
+
+// loadCustomMetrics returns the registry, loading it on first use.
+// Callers must hold customMutex.
+func loadCustomMetrics() map[string]CustomMetric {
+	if customMetrics != nil {
+		return customMetrics
+	}
+	customMetrics = make(map[string]CustomMetric)
+	rows, err := guildstate.GetLeaderboardCustomMetrics()
+	if err != nil {
+		log.Printf("leaderboard: load custom metrics: %v", err)
+		return customMetrics
+	}
+	for _, r := range rows {
+		m, err := newCustomMetric(r.LbType, r.GuildID, r.DisplayName, r.Expression, r.ValueFmt, r.HigherIsBetter)
+		if err != nil {
+			// Leaderboards can be renamed, leaving a stored expression behind
+			log.Printf("leaderboard: custom metric %s: %v", r.LbType, err)
+			continue
+		}
+		customMetrics[m.Def.Key] = m
+	}
+	return customMetrics
+}
+
+func newCustomMetric(key, guildID, displayName, expression, valueFmt string, higherIsBetter bool) (CustomMetric, error) {
+	expr, err := ParseLBExpr(expression)
+	if err != nil {
+		return CustomMetric{}, err
+	}
+
+	// Fetch whatever the referenced leaderboards need
+	var needsFirstContact, needsArchive bool
+	for _, ref := range expr.Refs {
+		def, _ := LBDefByKey(ref)
+		needsFirstContact = needsFirstContact || def.Source != SourceContractArchive
+		needsArchive = needsArchive || def.Source != SourceFirstContact
+	}
+	source := SourceFirstContact
+	switch {
+	case needsFirstContact && needsArchive:
+		source = SourceBoth
+	case needsArchive:
+		source = SourceContractArchive
+	}
+
+	return CustomMetric{
+		Def: LBDef{
+			Key:            key,
+			DisplayName:    displayName,
+			Description:    "Custom: `" + expression + "`",
+			ValueFmt:       valueFmt,
+			HigherIsBetter: higherIsBetter,
+			Source:         source,
+		},
+		GuildID:    guildID,
+		Expression: expression,
+		expr:       expr,
+	}, nil
+}
+
+// isBuiltinLBKey reports whether key is a collected built-in leaderboard.
+// Egg Day leaderboards are backfilled by hand, so they can't be used.
+func isBuiltinLBKey(key string) bool {
+	if key == LBEggDaySEGain || key == LBEggDaySEPct {
+		return false
+	}
+	return slices.ContainsFunc(AllLeaderboards, func(def LBDef) bool { return def.Key == key })
+}
+
+// isCustomKey reports whether key names a custom metric.
+func isCustomKey(key string) bool {
+	return strings.HasPrefix(key, customKeyPrefix)
+}
+
+// customKeyInGuild reports whether a custom metric key belongs to a guild.
+func customKeyInGuild(key, guildID string) bool {
+	return strings.HasPrefix(key, customKeyPrefix+guildID+"_")
+}
+
+// customMetricKey builds the key for a custom metric from its display name.
+func customMetricKey(guildID, displayName string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(displayName) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			slug.WriteRune(r)
+		case slug.Len() > 0 && !strings.HasSuffix(slug.String(), "_"):
+			slug.WriteByte('_')
+		}
+	}
+	s := strings.TrimSuffix(slug.String(), "_")
+	if len(s) > 32 {
+		s = strings.TrimSuffix(s[:32], "_")
+	}
+	return customKeyPrefix + guildID + "_" + s
+}
+
+// CustomMetricByKey looks up a custom metric by its key.
+func CustomMetricByKey(key string) (CustomMetric, bool) {
+	if !isCustomKey(key) {
+		return CustomMetric{}, false
+	}
+	customMutex.Lock()
+	defer customMutex.Unlock()
+	m, ok := loadCustomMetrics()[key]
+	return m, ok
+}
+
+// GetGuildCustomMetrics returns a guild's custom metrics ordered by name.
+func GetGuildCustomMetrics(guildID string) []CustomMetric {
+	if guildID == "" {
+		return nil
+	}
+	customMutex.Lock()
+	defer customMutex.Unlock()
+	var out []CustomMetric
+	for _, m := range loadCustomMetrics() {
+		if m.GuildID == guildID {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Def.DisplayName < out[b].Def.DisplayName })
+	return out
+}
+
+// guildCustomKeys returns the keys of a guild's custom metrics, which "all" opt-ins include.
+func guildCustomKeys(guildID string) []string {
+	var keys []string
+	for _, m := range GetGuildCustomMetrics(guildID) {
+		keys = append(keys, m.Def.Key)
+	}
+	return keys
+}
+
+// AddCustomMetric validates and saves a custom metric for a guild, replacing
+// one with exactly the same name.
+func AddCustomMetric(guildID, displayName, expression, valueFmt string, higherIsBetter bool) (CustomMetric, error) {
+	displayName = strings.TrimSpace(displayName)
+	expression = strings.TrimSpace(expression)
+	if !slices.Contains(customValueFmts, valueFmt) {
+		return CustomMetric{}, fmt.Errorf("unknown format %q", valueFmt)
+	}
+	key := customMetricKey(guildID, displayName)
+	if key == customKeyPrefix+guildID+"_" {
+		return CustomMetric{}, fmt.Errorf("the name needs at least one letter or digit")
+	}
+	m, err := newCustomMetric(key, guildID, displayName, expression, valueFmt, higherIsBetter)
+	if err != nil {
+		return CustomMetric{}, err
+	}
+
+	customMutex.Lock()
+	defer customMutex.Unlock()
+	registry := loadCustomMetrics()
+	existing, exists := registry[key]
+	if exists && existing.Def.DisplayName != displayName {
+		// "SE/PE" and "SE PE" share a key, only the exact name replaces a metric
+		return CustomMetric{}, fmt.Errorf("the name is too close to the existing metric %q, use that exact name to replace it", existing.Def.DisplayName)
+	}
+	if !exists {
+		count := 0
+		for _, other := range registry {
+			if other.GuildID == guildID {
+				count++
+			}
+		}
+		if count >= maxCustomMetricsPerGuild {
+			return CustomMetric{}, fmt.Errorf("this server already has %d custom metrics", maxCustomMetricsPerGuild)
+		}
+	}
+	if err := guildstate.UpsertLeaderboardCustomMetric(key, guildID, displayName, expression, valueFmt, higherIsBetter); err != nil {
+		return CustomMetric{}, err
+	}
+	registry[key] = m
+	return m, nil
+}
+
+// RemoveCustomMetric deletes a guild's custom metric and the channel it posts to.
+func RemoveCustomMetric(guildID, key string) (bool, error) {
+	if !customKeyInGuild(key, guildID) {
+		return false, nil
+	}
+	customMutex.Lock()
+	defer customMutex.Unlock()
+	removed, err := guildstate.DeleteLeaderboardCustomMetric(key, guildID)
+	if err != nil {
+		return false, err
+	}
+	delete(loadCustomMetrics(), key)
+	if err := guildstate.DeleteLeaderboardConfig(key, guildID); err != nil {
+		log.Printf("leaderboard: remove custom metric config %s: %v", key, err)
+	}
+	return removed, nil
+}
+
+// ─── Evaluation ──────────────────────────────────────────────────────────────
+
+// customDetails records the inputs of a custom metric so prev() can read them next week.
+func customDetails(refs []string, values map[string]float64) string {
+	parts := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if v, ok := values[ref]; ok {
+			parts = append(parts, ref+":"+strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseCustomDetails reads the inputs stored by customDetails.
+func parseCustomDetails(details string) map[string]float64 {
+	values := make(map[string]float64)
+	for _, field := range strings.Fields(details) {
+		key, raw, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			values[key] = v
+		}
+	}
+	return values
+}
+
+// evaluateCustomMetric computes a custom metric from the built-in values of
+// this collection and the metric's previous snapshot, which may be nil.
+func evaluateCustomMetric(m CustomMetric, values map[string]float64, prior *LBEntry, snapDate string) (LBEntry, error) {
+	env := &lbExprEnv{current: values}
+	if prior != nil {
+		env.prior = parseCustomDetails(prior.Details)
+		start, err1 := time.Parse(time.DateOnly, prior.SnapDate)
+		end, err2 := time.Parse(time.DateOnly, snapDate)
+		if err1 == nil && err2 == nil {
+			env.weeks = end.Sub(start).Hours() / (24 * 7)
+		}
+	}
+	v, err := m.expr.evaluate(env)
+	if err != nil {
+		return LBEntry{}, err
+	}
+	return LBEntry{
+		LBType:   m.Def.Key,
+		SnapDate: snapDate,
+		Value:    v,
+		Details:  customDetails(m.expr.Refs, values),
+	}, nil
+}
+
+// priorCustomStats returns the latest snapshot before snapDate of each custom
+// metric in keys, used for prev(), delta() and weeks().
+func priorCustomStats(userID string, keys []string, snapDate string) map[string]*LBEntry {
+	var custom []string
+	for _, k := range keys {
+		if isCustomKey(k) {
+			custom = append(custom, k)
+		}
+	}
+	if len(custom) == 0 {
+		return nil
+	}
+	rows, err := farmerstate.GetStatsForPlayer(userID)
+	if err != nil {
+		log.Printf("leaderboard: prior custom stats for %s: %v", userID, err)
+		return nil
+	}
+	prior := make(map[string]*LBEntry)
+	// Rows are ordered by lb_type, then snap_date DESC.
+	for _, r := range rows {
+		if !slices.Contains(custom, r.LbType) || prior[r.LbType] != nil || r.SnapDate >= snapDate {
+			continue
+		}
+		prior[r.LbType] = &LBEntry{
+			LBType:   r.LbType,
+			Player:   r.Player,
+			GameName: r.GameName,
+			SnapDate: r.SnapDate,
+			Value:    r.Value,
+			Details:  r.Details.String,
+		}
+	}
+	return prior
+}
+
+// ─── Admin Commands ──────────────────────────────────────────────────────────
+
+func handleAdminCustomAdd(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+	optMap := optionMap(opts)
+	name := optMap["name"].StringValue()
+	expression := optMap["expression"].StringValue()
+	valueFmt := "float"
+	if opt, ok := optMap["format"]; ok {
+		valueFmt = opt.StringValue()
+	}
+	higherIsBetter := true
+	if opt, ok := optMap["higher-is-better"]; ok {
+		higherIsBetter = opt.BoolValue()
+	}
+
+	m, err := AddCustomMetric(i.GuildID, name, expression, valueFmt, higherIsBetter)
+	if err != nil {
+		respondEphemeral(s, i, fmt.Sprintf("Couldn't add **%s**: %v", name, err))
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("✅ Added custom leaderboard **%s** (`%s`) = `%s`.\nUse `/admin-lb set-channel` to post it; players opted into all leaderboards are included from the next collection.",
+		m.Def.DisplayName, m.Def.Key, m.Expression))
+}
+
+func handleAdminCustomRemove(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+	optMap := optionMap(opts)
+	key := optMap["metric"].StringValue()
+	name := DisplayNameForConfigKey(key)
+
+	removed, err := RemoveCustomMetric(i.GuildID, key)
+	if err != nil {
+		log.Printf("leaderboard: admin custom-remove error: %v", err)
+		respondEphemeral(s, i, "Failed to remove the custom leaderboard.")
+		return
+	}
+	if !removed {
+		respondEphemeral(s, i, fmt.Sprintf("No custom leaderboard %q in this server.", key))
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("✅ Removed custom leaderboard **%s**.\n-# The Discord messages were not deleted.", name))
+}
+
+func handleAdminCustomList(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	metrics := GetGuildCustomMetrics(i.GuildID)
+	if len(metrics) == 0 {
+		respondEphemeral(s, i, "No custom leaderboards in this server.\nUse `/admin-lb custom-add` to define one.")
+		return
+	}
+	var b strings.Builder
+	b.WriteString("**Custom leaderboards for this guild:**\n")
+	for _, m := range metrics {
+		order := "higher is better"
+		if !m.Def.HigherIsBetter {
+			order = "lower is better"
+		}
+		fmt.Fprintf(&b, "• **%s** = `%s` (%s, %s)\n", m.Def.DisplayName, m.Expression, m.Def.ValueFmt, order)
+	}
+	respondEphemeral(s, i, b.String())
+}
+
+// customMetricChoices lists a guild's custom metrics matching partial.
+func customMetricChoices(guildID, partial string) []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, m := range GetGuildCustomMetrics(guildID) {
+		if partial == "" || strings.Contains(strings.ToLower(m.Def.DisplayName), partial) || strings.Contains(m.Def.Key, partial) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  m.Def.DisplayName + " (Custom)",
+				Value: m.Def.Key,
+			})
+		}
+	}
+	return choices
+}
+
+// withCustomChoices adds a guild's matching custom metrics to autocomplete choices.
+func withCustomChoices(choices []*discordgo.ApplicationCommandOptionChoice, guildID, partial string) []*discordgo.ApplicationCommandOptionChoice {
+	const maxChoices = 25
+	for _, c := range customMetricChoices(guildID, partial) {
+		if len(choices) >= maxChoices {
+			break
+		}
+		choices = append(choices, c)
+	}
+	return choices
+}
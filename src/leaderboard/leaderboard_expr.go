@@ -0,0 +1,366 @@
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ─── Custom Metric Expressions ───────────────────────────────────────────────
+//
+// A custom metric is an arithmetic expression over the built-in leaderboard
+// values of a player:
+//
+//	elite_drones / drones
+//	te_total / max(prestiges, 1)
+//	delta(soul_eggs) / weeks()
+//
+// Identifiers are built-in leaderboard keys. prev(key) is the value recorded
+// with the metric's previous snapshot, delta(key) is key - prev(key) and
+// weeks() is the time since that snapshot. min, max and abs are available.
+
+// errLBExprNoValue marks a player the expression can't be evaluated for,
+// such as a missing value, a first snapshot or a division by zero.
+var errLBExprNoValue = errors.New("no value")
+
+// lbExprEnv holds the values an expression is evaluated against.
+type lbExprEnv struct {
+	current map[string]float64 // built-in values from this collection
+	prior   map[string]float64 // values recorded with the previous snapshot
+	weeks   float64            // weeks since the previous snapshot, 0 when there's none
+}
+
+type lbExprNode interface {
+	eval(env *lbExprEnv) (float64, error)
+}
+
+type lbExprNumber float64
+
+type lbExprValue string
+
+type lbExprNeg struct{ x lbExprNode }
+
+type lbExprBinary struct {
+	op          byte
+	left, right lbExprNode
+}
+
+type lbExprCall struct {
+	name string
+	args []lbExprNode
+}
+
+func (n lbExprNumber) eval(*lbExprEnv) (float64, error) { return float64(n), nil }
+
+func (n lbExprValue) eval(env *lbExprEnv) (float64, error) {
+	v, ok := env.current[string(n)]
+	if !ok {
+		return 0, errLBExprNoValue
+	}
+	return v, nil
+}
+
+func (n lbExprNeg) eval(env *lbExprEnv) (float64, error) {
+	v, err := n.x.eval(env)
+	return -v, err
+}
+
+func (n lbExprBinary) eval(env *lbExprEnv) (float64, error) {
+	l, err := n.left.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	default:
+		if r == 0 {
+			return 0, errLBExprNoValue
+		}
+		return l / r, nil
+	}
+}
+
+func (n lbExprCall) eval(env *lbExprEnv) (float64, error) {
+	switch n.name {
+	case "prev", "delta":
+		key := string(n.args[0].(lbExprValue))
+		prev, ok := env.prior[key]
+		if !ok {
+			return 0, errLBExprNoValue
+		}
+		if n.name == "prev" {
+			return prev, nil
+		}
+		cur, err := n.args[0].eval(env)
+		return cur - prev, err
+	case "weeks":
+		if env.weeks <= 0 {
+			return 0, errLBExprNoValue
+		}
+		return env.weeks, nil
+	}
+
+	values := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(env)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = v
+	}
+	switch n.name {
+	case "min":
+		return slices.Min(values), nil
+	case "max":
+		return slices.Max(values), nil
+	default: // abs
+		return math.Abs(values[0]), nil
+	}
+}
+
+// LBExpr is a parsed custom metric expression.
+type LBExpr struct {
+	root lbExprNode
+	// Refs are the built-in keys the expression reads, in first-use order
+	Refs []string
+}
+
+func (e *LBExpr) evaluate(env *lbExprEnv) (float64, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errLBExprNoValue
+	}
+	return v, nil
+}
+
+// lbExprFuncArgs is the argument count of each function, -1 for one or more
+var lbExprFuncArgs = map[string]int{
+	"prev":  1,
+	"delta": 1,
+	"weeks": 0,
+	"min":   -1,
+	"max":   -1,
+	"abs":   1,
+}
+
+// ParseLBExpr parses a custom metric expression, checking every identifier
+// is a built-in leaderboard key.
+func ParseLBExpr(src string) (*LBExpr, error) {
+	p := &lbExprParser{src: src}
+	p.next()
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != "" {
+		return nil, p.errorf("unexpected %q", p.tok)
+	}
+	return &LBExpr{root: root, Refs: p.refs}, nil
+}
+
+type lbExprParser struct {
+	src  string
+	pos  int    // offset of the next token
+	tok  string // current token, "" at the end
+	at   int    // offset of the current token
+	refs []string
+}
+
+func (p *lbExprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at %d: %s", p.at+1, fmt.Sprintf(format, args...))
+}
+
+// next advances to the next token: a number, an identifier or an operator.
+func (p *lbExprParser) next() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+	p.at = p.pos
+	if p.pos >= len(p.src) {
+		p.tok = ""
+		return
+	}
+	c := p.src[p.pos]
+	end := p.pos + 1
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for end < len(p.src) {
+			d := p.src[end]
+			if d >= '0' && d <= '9' || d == '.' {
+				end++
+			} else if (d == 'e' || d == 'E') && end+1 < len(p.src) {
+				end++
+				if p.src[end] == '+' || p.src[end] == '-' {
+					end++
+				}
+			} else {
+				break
+			}
+		}
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_':
+		for end < len(p.src) {
+			d := p.src[end]
+			if d >= 'a' && d <= 'z' || d >= 'A' && d <= 'Z' || d >= '0' && d <= '9' || d == '_' {
+				end++
+			} else {
+				break
+			}
+		}
+	}
+	p.tok = p.src[p.pos:end]
+	p.pos = end
+}
+
+func (p *lbExprParser) parseSum() (lbExprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "+" || p.tok == "-" {
+		op := p.tok[0]
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = lbExprBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *lbExprParser) parseProduct() (lbExprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == "*" || p.tok == "/" {
+		op := p.tok[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = lbExprBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *lbExprParser) parseUnary() (lbExprNode, error) {
+	if p.tok == "-" {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return lbExprNeg{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *lbExprParser) parsePrimary() (lbExprNode, error) {
+	tok := p.tok
+	switch {
+	case tok == "":
+		return nil, p.errorf("expression ends early")
+	case tok == "(":
+		p.next()
+		x, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != ")" {
+			return nil, p.errorf("missing )")
+		}
+		p.next()
+		return x, nil
+	case tok[0] >= '0' && tok[0] <= '9' || tok[0] == '.':
+		v, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", tok)
+		}
+		p.next()
+		return lbExprNumber(v), nil
+	case tok[0] >= 'a' && tok[0] <= 'z' || tok[0] >= 'A' && tok[0] <= 'Z' || tok[0] == '_':
+		name := strings.ToLower(tok)
+		p.next()
+		if p.tok == "(" {
+			return p.parseCall(name)
+		}
+		return p.value(name)
+	}
+	return nil, p.errorf("unexpected %q", tok)
+}
+
+// value resolves an identifier to a built-in leaderboard key.
+func (p *lbExprParser) value(name string) (lbExprNode, error) {
+	key := resolveAlias(name)
+	if !isBuiltinLBKey(key) {
+		return nil, fmt.Errorf("unknown leaderboard %q", name)
+	}
+	if !slices.Contains(p.refs, key) {
+		p.refs = append(p.refs, key)
+	}
+	return lbExprValue(key), nil
+}
+
+func (p *lbExprParser) parseCall(name string) (lbExprNode, error) {
+	want, ok := lbExprFuncArgs[name]
+	if !ok {
+		return nil, p.errorf("unknown function %s()", name)
+	}
+	p.next() // (
+	var args []lbExprNode
+	for p.tok != ")" {
+		if len(args) > 0 {
+			if p.tok != "," {
+				return nil, p.errorf("expected , or ) in %s()", name)
+			}
+			p.next()
+		}
+		var arg lbExprNode
+		var err error
+		if name == "prev" || name == "delta" {
+			// These read the previous snapshot, so take a leaderboard key
+			arg, err = p.value(strings.ToLower(p.tok))
+			p.next()
+		} else {
+			arg, err = p.parseSum()
+		}
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next() // )
+	if (want < 0 && len(args) == 0) || (want >= 0 && len(args) != want) {
+		return nil, fmt.Errorf("%s() takes %s", name, lbExprArgCount(want))
+	}
+	return lbExprCall{name: name, args: args}, nil
+}
+
+func lbExprArgCount(n int) string {
+	switch n {
+	case -1:
+		return "one or more values"
+	case 0:
+		return "no values"
+	case 1:
+		return "one value"
+	}
+	return fmt.Sprintf("%d values", n)
+}
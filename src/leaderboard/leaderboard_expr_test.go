@@ -0,0 +1,191 @@
+package leaderboard
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseLBExprEvaluates(t *testing.T) {
+	env := &lbExprEnv{
+		current: map[string]float64{LBSoulEggs: 300, LBTETotal: 90, LBPrestiges: 30, LBDrones: 200, LBEliteDrones: 50},
+		prior:   map[string]float64{LBSoulEggs: 100},
+		weeks:   2,
+	}
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"delta(soul_eggs)/weeks()", 100},
+		{"te_total / prestiges", 3},
+		{"elite_drones/drones", 0.25},
+		{"prev(soul_eggs) + 1e2", 200},
+		{"-(drones - elite_drones) * 2", -300},
+		{"1 + 2 * 3", 7},
+		{"min(drones, elite_drones, 75) + max(1, 2)", 52},
+		{"abs(elite_drones - drones)", 150},
+		{"2.5E1 - 5", 20},
+	}
+	for _, tt := range tests {
+		expr, err := ParseLBExpr(tt.src)
+		if err != nil {
+			t.Errorf("ParseLBExpr(%q): %v", tt.src, err)
+			continue
+		}
+		got, err := expr.evaluate(env)
+		if err != nil {
+			t.Errorf("%q: %v", tt.src, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%q = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseLBExprRefs(t *testing.T) {
+	expr, err := ParseLBExpr("delta(soul_eggs) / max(prestiges, 1) + soul_eggs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{LBSoulEggs, LBPrestiges}; !slices.Equal(expr.Refs, want) {
+		t.Errorf("refs = %v, want %v", expr.Refs, want)
+	}
+}
+
+func TestParseLBExprErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", "ends early"},
+		{"drones +", "ends early"},
+		{"(drones", "missing )"},
+		{"drones drones", `at 8: unexpected "drones"`},
+		{"bogus / drones", `unknown leaderboard "bogus"`},
+		{"egg_day_se_gain", "unknown leaderboard"},
+		{"sqrt(drones)", "unknown function sqrt()"},
+		{"prev(drones + 1)", "expected , or )"},
+		{"weeks(1)", "weeks() takes no values"},
+		{"max()", "max() takes one or more values"},
+		{"drones % 2", `unexpected "%"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseLBExpr(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseLBExpr(%q) error = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestLBExprNoValue(t *testing.T) {
+	env := &lbExprEnv{current: map[string]float64{LBDrones: 0, LBEliteDrones: 5}}
+	for _, src := range []string{
+		"elite_drones / drones", // division by zero
+		"prestiges",             // not collected
+		"delta(drones)",         // no previous snapshot
+		"drones / weeks()",      // no previous snapshot
+		"0 / (drones - drones)", // NaN
+	} {
+		expr, err := ParseLBExpr(src)
+		if err != nil {
+			t.Fatalf("ParseLBExpr(%q): %v", src, err)
+		}
+		if _, err := expr.evaluate(env); !errors.Is(err, errLBExprNoValue) {
+			t.Errorf("%q error = %v, want no value", src, err)
+		}
+	}
+}
+
+func TestCustomMetricKey(t *testing.T) {
+	tests := map[string]string{
+		"SE per Week":     "custom_123_se_per_week",
+		"  TE/Prestige! ": "custom_123_te_prestige",
+		"Elite % Drones":  "custom_123_elite_drones",
+	}
+	for name, want := range tests {
+		if got := customMetricKey("123", name); got != want {
+			t.Errorf("customMetricKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if !customKeyInGuild("custom_123_se_per_week", "123") || customKeyInGuild("custom_1234_x", "123") {
+		t.Error("customKeyInGuild matched the wrong guild")
+	}
+}
+
+func TestAddCustomMetricSlugCollision(t *testing.T) {
+	if _, err := AddCustomMetric("777", "SE/PE", "soul_eggs / prophecy_eggs", "float", true); err != nil {
+		t.Fatalf("AddCustomMetric(SE/PE) error: %v", err)
+	}
+	if _, err := AddCustomMetric("777", "SE PE", "soul_eggs", "float", true); err == nil {
+		t.Error("AddCustomMetric(SE PE) replaced SE/PE, want the name collision rejected")
+	}
+	m, err := AddCustomMetric("777", "SE/PE", "soul_eggs / prophecy_eggs * 2", "float", true)
+	if err != nil || m.Expression != "soul_eggs / prophecy_eggs * 2" {
+		t.Errorf("AddCustomMetric(SE/PE) = %+v, %v, want the exact name to replace it", m, err)
+	}
+}
+
+func TestRunCalculatorsCustomMetric(t *testing.T) {
+	m, err := newCustomMetric("custom_1_drones_per_week", "1", "Drones per Week", "delta(drones) / weeks()", "float", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ratio, err := newCustomMetric("custom_1_elite_ratio", "1", "Elite Ratio", "elite_drones / drones", "pct", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	customMutex.Lock()
+	saved := customMetrics
+	customMetrics = map[string]CustomMetric{m.Def.Key: m, ratio.Def.Key: ratio}
+	customMutex.Unlock()
+	t.Cleanup(func() {
+		customMutex.Lock()
+		customMetrics = saved
+		customMutex.Unlock()
+	})
+
+	if def, ok := LBDefByKey(m.Def.Key); !ok || def.Source != SourceFirstContact {
+		t.Fatalf("LBDefByKey(%q) = %+v, %v", m.Def.Key, def, ok)
+	}
+
+	backup := &ei.Backup{Stats: &ei.Backup_Stats{
+		DroneTakedowns:      proto.Uint64(1000),
+		DroneTakedownsElite: proto.Uint64(100),
+	}}
+	optedIn := []string{LBEliteDrones, m.Def.Key, ratio.Def.Key}
+
+	// The first snapshot has nothing to compare against
+	entries := RunCalculators("u1", backup, nil, optedIn, "2026-01-15", 0, nil)
+	var types []string
+	for _, e := range entries {
+		types = append(types, e.LBType)
+	}
+	if want := []string{LBEliteDrones, ratio.Def.Key}; !slices.Equal(types, want) {
+		t.Fatalf("first snapshot types = %v, want %v", types, want)
+	}
+	if entries[1].Value != 0.1 || entries[1].Player != "u1" {
+		t.Errorf("ratio entry = %+v, want 0.1 for u1", entries[1])
+	}
+
+	prior := map[string]*LBEntry{m.Def.Key: {SnapDate: "2026-01-01", Details: customDetails(m.expr.Refs, map[string]float64{LBDrones: 800})}}
+	entries = RunCalculators("u1", backup, nil, optedIn, "2026-01-15", 0, prior)
+	for _, e := range entries {
+		if e.LBType != m.Def.Key {
+			continue
+		}
+		if e.Value != 100 {
+			t.Errorf("drones per week = %v, want 100", e.Value)
+		}
+		if e.Details != "drones:1000" {
+			t.Errorf("details = %q, want drones:1000", e.Details)
+		}
+		return
+	}
+	t.Errorf("no %s entry in %+v", m.Def.Key, entries)
+}
@@ -0,0 +1,245 @@
+package leaderboard
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// ─── Federated Leaderboards ──────────────────────────────────────────────────
+
+// federatedKeyPrefix marks a channel config that posts the combined board of
+// the guild's federation: fed_<config key>
+const federatedKeyPrefix = "fed_"
+
+// maxFederationLabelChars caps the guild label shown in the Guild column
+const maxFederationLabelChars = 12
+
+// isFederatedKey reports whether a config key posts a federated board.
+func isFederatedKey(key string) bool {
+	return strings.HasPrefix(key, federatedKeyPrefix)
+}
+
+// federatedBaseKey returns the leaderboard or group key behind a federated config key.
+func federatedBaseKey(key string) string {
+	return strings.TrimPrefix(key, federatedKeyPrefix)
+}
+
+// getBoardRows returns the definition and ranked rows for a posted board. Plain
+// keys are scoped to the guild, federated keys combine every member guild.
+func getBoardRows(boardKey, snapDate, guildID string) (LBDef, []LBEntry, map[string]float64, bool) {
+	def, ok := LBDefByKey(federatedBaseKey(boardKey))
+	if !ok {
+		return LBDef{}, nil, nil, false
+	}
+	if !isFederatedKey(boardKey) {
+		rows, prevMap := getGuildRows(def.Key, snapDate, guildID)
+		return def, rows, prevMap, true
+	}
+
+	fed, members, err := guildstate.GetLeaderboardFederationForGuild(guildID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("leaderboard: federation lookup for guild %s: %v", guildID, err)
+		}
+		return def, nil, nil, true
+	}
+	perGuild := make([][]LBEntry, 0, len(members))
+	prevMap := make(map[string]float64)
+	for _, m := range members {
+		rows, prev := getGuildRows(def.Key, snapDate, m.GuildID)
+		for n := range rows {
+			rows[n].Guild = m.Label
+		}
+		perGuild = append(perGuild, rows)
+		for player, v := range prev {
+			prevMap[player] = v
+		}
+	}
+	def.DisplayName = fmt.Sprintf("%s — %s", def.DisplayName, fed.Name)
+	return def, mergeFederationRows(def, perGuild), prevMap, true
+}
+
+// mergeFederationRows combines the opted-in rows of each member guild into one
+// ranking. A player in several member guilds is listed once, under the guild
+// that joined the federation first.
+func mergeFederationRows(def LBDef, perGuild [][]LBEntry) []LBEntry {
+	seen := make(map[string]struct{})
+	var out []LBEntry
+	for _, rows := range perGuild {
+		for _, r := range rows {
+			if _, ok := seen[r.Player]; ok {
+				continue
+			}
+			seen[r.Player] = struct{}{}
+			out = append(out, r)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if def.Key == LBCXPWeeklyDelta {
+			iNA := out[i].Details == "na"
+			jNA := out[j].Details == "na"
+			if iNA != jNA {
+				return !iNA
+			}
+		}
+		if def.HigherIsBetter {
+			return out[i].Value > out[j].Value
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
+
+// dropFederatedConfigs removes the federated channel configs of guilds that
+// have left a federation.
+func dropFederatedConfigs(guildIDs []string) {
+	for _, guildID := range guildIDs {
+		cfgs, err := GetGuildLBConfigs(guildID)
+		if err != nil {
+			log.Printf("leaderboard: load configs for guild %s: %v", guildID, err)
+			continue
+		}
+		for _, cfg := range cfgs {
+			if isFederatedKey(cfg.LBType) {
+				_ = DeleteGuildLBConfig(guildID, cfg.LBType)
+			}
+		}
+	}
+}
+
+// federationLabel returns the Guild column label, defaulting to the server name.
+func federationLabel(s *discordgo.Session, guildID string, opt *discordgo.ApplicationCommandInteractionDataOption) string {
+	label := ""
+	if opt != nil {
+		label = strings.TrimSpace(opt.StringValue())
+	}
+	if label == "" {
+		if g, err := s.State.Guild(guildID); err == nil {
+			label = g.Name
+		} else if g, err := s.Guild(guildID); err == nil {
+			label = g.Name
+		}
+	}
+	if label == "" {
+		label = guildID
+	}
+	return truncateString(label, maxFederationLabelChars)
+}
+
+func handleAdminFederationCreate(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+	optMap := optionMap(opts)
+	name := strings.TrimSpace(optMap["name"].StringValue())
+	if name == "" {
+		respondEphemeral(s, i, "The federation needs a name.")
+		return
+	}
+	label := federationLabel(s, i.GuildID, optMap["label"])
+
+	fed, err := guildstate.CreateLeaderboardFederation(name, i.GuildID, label, bottools.GetInteractionUserID(i))
+	if err != nil {
+		respondEphemeral(s, i, fmt.Sprintf("Couldn't create the federation: %v", err))
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("✅ Created leaderboard federation **%s**.\nShare the join code `%s` with allied server admins, they can join with `/admin-lb federation-join`.\nUse `/admin-lb set-channel federated:True` to post a combined board here.",
+		fed.Name, fed.FederationID))
+}
+
+func handleAdminFederationJoin(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+	optMap := optionMap(opts)
+	code := strings.TrimSpace(optMap["code"].StringValue())
+	label := federationLabel(s, i.GuildID, optMap["label"])
+
+	fed, err := guildstate.JoinLeaderboardFederation(code, i.GuildID, label, bottools.GetInteractionUserID(i))
+	if err != nil {
+		respondEphemeral(s, i, fmt.Sprintf("Couldn't join the federation: %v", err))
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("✅ Joined leaderboard federation **%s** as **%s**.\nPlayers opted into this server's leaderboards now also appear on its combined boards.\nUse `/admin-lb set-channel federated:True` to post one here.",
+		fed.Name, label))
+}
+
+func handleAdminFederationLeave(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	fed, _, err := guildstate.GetLeaderboardFederationForGuild(i.GuildID)
+	if err != nil {
+		respondEphemeral(s, i, "This server isn't in a leaderboard federation.")
+		return
+	}
+	removed, err := guildstate.LeaveLeaderboardFederation(i.GuildID)
+	if err != nil {
+		log.Printf("leaderboard: admin federation-leave error: %v", err)
+		respondEphemeral(s, i, "Failed to leave the federation.")
+		return
+	}
+	dropFederatedConfigs(removed)
+
+	if fed.OwnerGuildID == i.GuildID {
+		respondEphemeral(s, i, fmt.Sprintf("✅ Disbanded leaderboard federation **%s** and removed its combined boards from %d server(s).\n-# The Discord messages were not deleted.", fed.Name, len(removed)))
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("✅ Left leaderboard federation **%s**.\n-# The Discord messages were not deleted.", fed.Name))
+}
+
+func handleAdminFederationRemove(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+	optMap := optionMap(opts)
+	guildID := optMap["member"].StringValue()
+
+	if err := guildstate.RemoveLeaderboardFederationMember(i.GuildID, guildID); err != nil {
+		respondEphemeral(s, i, fmt.Sprintf("Couldn't remove that server: %v", err))
+		return
+	}
+	dropFederatedConfigs([]string{guildID})
+	respondEphemeral(s, i, "✅ Removed the server from this federation.")
+}
+
+func handleAdminFederationInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	fed, members, err := guildstate.GetLeaderboardFederationForGuild(i.GuildID)
+	if err != nil {
+		respondEphemeral(s, i, "This server isn't in a leaderboard federation.\nUse `/admin-lb federation-create` to start one or `/admin-lb federation-join` with a join code.")
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 🤝 %s\n", fed.Name)
+	if fed.OwnerGuildID == i.GuildID {
+		fmt.Fprintf(&b, "Join code: `%s`\n", fed.FederationID)
+	}
+	b.WriteString("**Member servers:**\n")
+	for _, m := range members {
+		owner := ""
+		if m.GuildID == fed.OwnerGuildID {
+			owner = " (owner)"
+		}
+		fmt.Fprintf(&b, "• **%s**%s\n", m.Label, owner)
+	}
+	respondEphemeral(s, i, b.String())
+}
+
+// federationMemberChoices lists the other guilds in the guild's federation matching partial.
+func federationMemberChoices(guildID, partial string) []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	_, members, err := guildstate.GetLeaderboardFederationForGuild(guildID)
+	if err != nil {
+		return choices
+	}
+	for _, m := range members {
+		if m.GuildID == guildID {
+			continue
+		}
+		if partial == "" || strings.Contains(strings.ToLower(m.Label), partial) || strings.Contains(m.GuildID, partial) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  m.Label,
+				Value: m.GuildID,
+			})
+		}
+	}
+	return choices
+}
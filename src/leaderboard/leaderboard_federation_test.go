@@ -0,0 +1,79 @@
+package leaderboard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeFederationRows(t *testing.T) {
+	def, ok := LBDefByKey(LBContractExp)
+	if !ok {
+		t.Fatalf("expected leaderboard definition for %s", LBContractExp)
+	}
+	alpha := []LBEntry{
+		{Player: "a1", GameName: "Ace", Value: 500, Guild: "Alpha"},
+		{Player: "both", GameName: "Twin", Value: 300, Guild: "Alpha"},
+	}
+	bravo := []LBEntry{
+		{Player: "b1", GameName: "Bee", Value: 900, Guild: "Bravo"},
+		{Player: "both", GameName: "Twin", Value: 300, Guild: "Bravo"},
+		{Player: "b2", GameName: "Bop", Value: 100, Guild: "Bravo"},
+	}
+
+	rows := mergeFederationRows(def, [][]LBEntry{alpha, bravo})
+	var got []string
+	for _, r := range rows {
+		got = append(got, r.Player+"@"+r.Guild)
+	}
+	if want := "b1@Bravo a1@Alpha both@Alpha b2@Bravo"; strings.Join(got, " ") != want {
+		t.Errorf("merged rows = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestMergeFederationRowsWeeklyCS(t *testing.T) {
+	def, ok := LBDefByKey(LBCXPWeeklyDelta)
+	if !ok {
+		t.Fatalf("expected leaderboard definition for %s", LBCXPWeeklyDelta)
+	}
+	rows := mergeFederationRows(def, [][]LBEntry{
+		{{Player: "new", Value: 0, Details: "na"}, {Player: "low", Value: 5}},
+		{{Player: "high", Value: 50}},
+	})
+	if rows[0].Player != "high" || rows[1].Player != "low" || rows[2].Player != "new" {
+		t.Errorf("weekly CS order = %+v, want high, low, then players without a lookback", rows)
+	}
+}
+
+func TestFederatedConfigKeys(t *testing.T) {
+	key := federatedKeyPrefix + LBContractExp
+	if !IsValidConfigKey(key) {
+		t.Errorf("IsValidConfigKey(%q) = false, want true", key)
+	}
+	if IsValidConfigKey(federatedKeyPrefix + customKeyPrefix + "123_se_week") {
+		t.Error("custom metrics should not be valid federated config keys")
+	}
+	if got := ExpandConfigKey(key); len(got) != 1 || got[0] != LBContractExp {
+		t.Errorf("ExpandConfigKey(%q) = %v, want [%s]", key, got, LBContractExp)
+	}
+	if got := DisplayNameForConfigKey(key); !strings.HasSuffix(got, " (Federation)") {
+		t.Errorf("DisplayNameForConfigKey(%q) = %q, want a (Federation) suffix", key, got)
+	}
+}
+
+func TestRenderTableGuildColumn(t *testing.T) {
+	def, _ := LBDefByKey(LBContractExp)
+	rows := []LBEntry{{Player: "p1", GameName: "Ace", Value: 100}}
+	colHeader, _, _ := renderTable(def, rows, nil, 0)
+	if strings.Contains(colHeader, "Guild") {
+		t.Errorf("guild-scoped table should not have a Guild column:\n%s", colHeader)
+	}
+
+	rows[0].Guild = "Alpha"
+	colHeader, lines, _ := renderTable(def, rows, nil, 0)
+	if !strings.Contains(colHeader, "| Guild ") {
+		t.Errorf("federated table is missing the Guild column:\n%s", colHeader)
+	}
+	if len(lines) != 1 || !strings.Contains(lines[0], "| Alpha ") {
+		t.Errorf("federated row is missing its guild label: %q", lines)
+	}
+}
@@ -0,0 +1,245 @@
+package leaderboard
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/boost"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/config"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+)
+
+// ─── Leaderboard History ─────────────────────────────────────────────────────
+
+const (
+	// historyRecentSnapshots is how many weekly snapshots a thinned history keeps
+	// before dropping to one per month
+	historyRecentSnapshots = 8
+	// maxHistoryPlayers is how many players one chart compares
+	maxHistoryPlayers = 6
+)
+
+// GetPlayerHistory returns a player's stored snapshots for a lb_type in a guild, oldest first.
+func GetPlayerHistory(guildID, playerID, lbType string) []LBEntry {
+	rows, err := farmerstate.GetStatsForPlayerInGuild(playerID, guildID)
+	if err != nil {
+		log.Printf("leaderboard: GetPlayerHistory %s/%s/%s: %v", lbType, guildID, playerID, err)
+		return nil
+	}
+	var out []LBEntry
+	for _, r := range rows {
+		if r.LbType != lbType {
+			continue
+		}
+		e := LBEntry{
+			LBType:   r.LbType,
+			Player:   r.Player,
+			GameName: r.GameName,
+			SnapDate: r.SnapDate,
+			Value:    r.Value,
+		}
+		if r.Details.Valid {
+			e.Details = r.Details.String
+		}
+		out = append(out, e)
+	}
+	// Rows are ordered by snap_date DESC.
+	slices.Reverse(out)
+	return out
+}
+
+// thinnedSnapDates returns the snap dates to drop from a history so it keeps
+// the recent weeks and then the latest snapshot of each older month.
+func thinnedSnapDates(dates []string) []string {
+	sorted := slices.Clone(dates)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+
+	var drop []string
+	keptMonths := make(map[string]bool)
+	for n, d := range sorted {
+		if n < historyRecentSnapshots {
+			continue
+		}
+		month := d[:min(len(d), 7)] // "YYYY-MM"
+		if keptMonths[month] {
+			drop = append(drop, d)
+			continue
+		}
+		keptMonths[month] = true
+	}
+	return drop
+}
+
+// thinLBHistory downsamples a player's history for a lb_type in place of pruning it.
+func thinLBHistory(lbType, player string) {
+	rows, err := farmerstate.GetStatsForPlayer(player)
+	if err != nil {
+		log.Printf("leaderboard: thin history %s/%s: %v", lbType, player, err)
+		return
+	}
+	var dates []string
+	for _, r := range rows {
+		if r.LbType == lbType {
+			dates = append(dates, r.SnapDate)
+		}
+	}
+	for _, d := range thinnedSnapDates(dates) {
+		if err := farmerstate.DeleteLeaderboardStatForPlayerAndSnapDate(lbType, player, d); err != nil {
+			log.Printf("leaderboard: thin history %s/%s/%s: %v", lbType, player, d, err)
+		}
+	}
+}
+
+var historyMentionRe = regexp.MustCompile(`<@!?(\d+)>|\b(\d{15,20})\b`)
+
+// parseHistoryPlayers pulls Discord user IDs out of mentions or raw IDs.
+func parseHistoryPlayers(raw string) []string {
+	var ids []string
+	for _, m := range historyMentionRe.FindAllStringSubmatch(raw, -1) {
+		if m[1] != "" {
+			ids = append(ids, m[1])
+		} else {
+			ids = append(ids, m[2])
+		}
+	}
+	return ids
+}
+
+// historyTrend describes how a player's value moved across their history.
+func historyTrend(def LBDef, name string, history []LBEntry) string {
+	first, last := history[0], history[len(history)-1]
+	line := fmt.Sprintf("**%s**: %s", name, FormatLBValue(def.ValueFmt, last.Value))
+	if len(history) < 2 {
+		return line + " (one snapshot)"
+	}
+	delta := last.Value - first.Value
+	if delta == 0 {
+		return line + fmt.Sprintf(", unchanged since %s", first.SnapDate)
+	}
+	line += fmt.Sprintf(", %s since %s", FormatLBDelta(def.ValueFmt, delta), first.SnapDate)
+
+	start, err1 := time.Parse(time.DateOnly, first.SnapDate)
+	end, err2 := time.Parse(time.DateOnly, last.SnapDate)
+	if weeks := end.Sub(start).Hours() / (24 * 7); err1 == nil && err2 == nil && weeks >= 1 {
+		line += fmt.Sprintf(" (%s a week)", FormatLBDelta(def.ValueFmt, delta/weeks))
+	}
+	return line
+}
+
+// historySeries converts a history to a chart line.
+func historySeries(label string, history []LBEntry) boost.ChartSeries {
+	series := boost.ChartSeries{Label: label}
+	for _, e := range history {
+		t, err := time.Parse(time.DateOnly, e.SnapDate)
+		if err != nil {
+			continue
+		}
+		series.Points = append(series.Points, boost.ChartPoint{Time: t, Value: e.Value})
+	}
+	return series
+}
+
+func handleHistory(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+	if guildID == "" {
+		respondEphemeral(s, i, "This command must be used within a server.")
+		return
+	}
+	optMap := optionMap(opts)
+	def, ok := LBDefByKey(optMap["metric"].StringValue())
+	if !ok {
+		respondEphemeral(s, i, "Unknown leaderboard metric, pick one from the list.")
+		return
+	}
+
+	players := []string{bottools.GetInteractionUserID(i)}
+	if altOpt, ok := optMap["alt"]; ok && altOpt.StringValue() != "" {
+		players[0] = altOpt.StringValue()
+	}
+	if cmpOpt, ok := optMap["compare"]; ok {
+		for _, id := range parseHistoryPlayers(cmpOpt.StringValue()) {
+			if !slices.Contains(players, id) {
+				players = append(players, id)
+			}
+		}
+	}
+	players = players[:min(len(players), maxHistoryPlayers)]
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	var series []boost.ChartSeries
+	var b strings.Builder
+	var missing []string
+	single := false
+	for _, p := range players {
+		history := GetPlayerHistory(guildID, p, def.Key)
+		name := p
+		if _, err := strconv.ParseUint(p, 10, 64); err == nil {
+			name = "<@" + p + ">"
+		}
+		if len(history) == 0 {
+			missing = append(missing, name)
+			continue
+		}
+		label := history[len(history)-1].GameName
+		if label == "" {
+			label = p
+		}
+		series = append(series, historySeries(label, history))
+		b.WriteString(historyTrend(def, label, history))
+		b.WriteString("\n")
+		single = single || len(history) == 1
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(&b, "No %s history in this server for %s\n", def.DisplayName, strings.Join(missing, ", "))
+	}
+	if single && def.RetainRecentOnly && !config.LeaderboardKeepHistory {
+		b.WriteString("-# Only the latest snapshot of this leaderboard is kept.\n")
+	}
+
+	components := []discordgo.MessageComponent{
+		&discordgo.TextDisplay{Content: fmt.Sprintf("## %s History\n%s", def.DisplayName, b.String())},
+	}
+	var files []*discordgo.File
+	if len(series) > 0 {
+		imgBytes, err := boost.RenderLineChartImage(def.DisplayName, series, func(v float64) string {
+			return FormatLBValue(def.ValueFmt, v)
+		})
+		if err != nil {
+			log.Printf("leaderboard: render history chart: %v", err)
+		} else {
+			files = append(files, &discordgo.File{
+				Name:        "lb_history.png",
+				ContentType: "image/png",
+				Reader:      bytes.NewReader(imgBytes),
+			})
+			var mediaItem discordgo.MediaGalleryItem
+			mediaItem.Media.URL = "attachment://lb_history.png"
+			components = append(components, &discordgo.MediaGallery{
+				Items: []discordgo.MediaGalleryItem{mediaItem},
+			})
+		}
+	}
+
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Flags:      discordgo.MessageFlagsIsComponentsV2 | discordgo.MessageFlagsEphemeral,
+		Components: components,
+		Files:      files,
+	}); err != nil {
+		log.Printf("leaderboard: send history: %v", err)
+	}
+}
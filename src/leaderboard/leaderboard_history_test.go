@@ -0,0 +1,64 @@
+package leaderboard
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestThinnedSnapDates(t *testing.T) {
+	// Weekly snapshots from January to mid April
+	dates := []string{
+		"2026-01-02", "2026-01-09", "2026-01-16", "2026-01-23", "2026-01-30",
+		"2026-02-06", "2026-02-13", "2026-02-20", "2026-02-27",
+		"2026-03-06", "2026-03-13", "2026-03-20", "2026-03-27",
+		"2026-04-03", "2026-04-10",
+	}
+
+	drop := thinnedSnapDates(dates)
+	slices.Sort(drop)
+	// The 8 newest stay back to Feb 20, then Feb 13 and Jan 30 as the latest of their months
+	want := []string{"2026-01-02", "2026-01-09", "2026-01-16", "2026-01-23", "2026-02-06"}
+	if !slices.Equal(drop, want) {
+		t.Errorf("drop = %v, want %v", drop, want)
+	}
+
+	if drop := thinnedSnapDates(dates[:historyRecentSnapshots]); len(drop) != 0 {
+		t.Errorf("drop = %v, want nothing from a short history", drop)
+	}
+}
+
+func TestParseHistoryPlayers(t *testing.T) {
+	got := parseHistoryPlayers("<@123456789012345678> and <@!223456789012345678>, 323456789012345678 <@&999>")
+	want := []string{"123456789012345678", "223456789012345678", "323456789012345678"}
+	if !slices.Equal(got, want) {
+		t.Errorf("players = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryTrend(t *testing.T) {
+	def, _ := LBDefByKey(LBDrones)
+	history := []LBEntry{
+		{SnapDate: "2026-03-06", Value: 100},
+		{SnapDate: "2026-03-13", Value: 130},
+		{SnapDate: "2026-03-20", Value: 160},
+	}
+	got := historyTrend(def, "farmer", history)
+	if got != "**farmer**: 160, +60 since 2026-03-06 (+30 a week)" {
+		t.Errorf("trend = %q", got)
+	}
+	if got := historyTrend(def, "farmer", history[:1]); !strings.HasSuffix(got, "(one snapshot)") {
+		t.Errorf("single trend = %q", got)
+	}
+}
+
+func TestBuildMetricChoicesExcludesGroups(t *testing.T) {
+	for _, c := range buildMetricChoices("") {
+		if _, ok := LBDefByKey(c.Value.(string)); !ok {
+			t.Errorf("choice %q isn't an individual leaderboard", c.Value)
+		}
+	}
+	if choices := buildMetricChoices("elite"); len(choices) != 1 || choices[0].Value != LBEliteDrones {
+		t.Errorf("elite choices = %+v", choices)
+	}
+}
@@ -330,8 +330,11 @@ func findLookbackValueMap(lbType string, snapDate string, guildID string) (map[s
 }
 
 func postSingleMetric(s *discordgo.Session, cfg LBConfig, lbType, snapDate string, newMsgIDs *[]string, msgIDOffset *int, forceNewPosts *bool) {
-	def, _ := LBDefByKey(lbType)
-	guildRows, prevMap := getGuildRows(lbType, snapDate, cfg.GuildID)
+	boardKey := lbType
+	if isFederatedKey(cfg.LBType) {
+		boardKey = federatedKeyPrefix + lbType
+	}
+	def, guildRows, prevMap, _ := getBoardRows(boardKey, snapDate, cfg.GuildID)
 
 	if len(guildRows) == 0 {
 		log.Printf("leaderboard: no eligible guild members for %s in guild %s", lbType, cfg.GuildID)
@@ -371,13 +374,13 @@ func postSingleMetric(s *discordgo.Session, cfg LBConfig, lbType, snapDate strin
 						discordgo.Button{
 							Label:    "Previous",
 							Style:    discordgo.SecondaryButton,
-							CustomID: fmt.Sprintf("lb_p#%s#%s#%d", lbType, snapDate, page-1),
+							CustomID: fmt.Sprintf("lb_p#%s#%s#%d", boardKey, snapDate, page-1),
 							Disabled: true,
 						},
 						discordgo.Button{
 							Label:    "Next",
 							Style:    discordgo.SecondaryButton,
-							CustomID: fmt.Sprintf("lb_p#%s#%s#%d", lbType, snapDate, page+1),
+							CustomID: fmt.Sprintf("lb_p#%s#%s#%d", boardKey, snapDate, page+1),
 							Disabled: false,
 						},
 					},
@@ -452,12 +455,10 @@ func HandleLBPageButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	snapDate := parts[2]
 	page, _ := strconv.Atoi(parts[3])
 
-	def, ok := LBDefByKey(lbType)
+	def, guildRows, prevMap, ok := getBoardRows(lbType, snapDate, i.GuildID)
 	if !ok {
 		return
 	}
-
-	guildRows, prevMap := getGuildRows(lbType, snapDate, i.GuildID)
 	pageSize := 25
 	start := page * pageSize
 	if start < 0 {
@@ -611,10 +612,16 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	isCraftingXP := def.Key == LBCraftingXP
 	craftingLevelWidth := runewidth.StringWidth("Lvl")
 
+	// Federated boards add a Guild column after the name
+	const maxGuildChars = maxFederationLabelChars
+	hasGuildColumn := false
+	guildWidth := runewidth.StringWidth("Guild")
+
 	type rowInfo struct {
 		row                       LBEntry
 		rankStr                   string
 		nameStr                   string
+		guildStr                  string
 		displayValStr             string
 		dressedValStr             string
 		teStr                     string
@@ -704,6 +711,15 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 			maxNameWidth = w
 		}
 
+		guildStr := ""
+		if r.Guild != "" {
+			hasGuildColumn = true
+			guildStr = truncateString(r.Guild, maxGuildChars)
+			if w := runewidth.StringWidth(guildStr); w > guildWidth {
+				guildWidth = w
+			}
+		}
+
 		displayValStr := FormatLBValue(def.ValueFmt, r.Value)
 		if def.Key == LBCXPWeeklyDelta {
 			if r.Details == "na" {
@@ -810,6 +826,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 			row:                       r,
 			rankStr:                   rankStr,
 			nameStr:                   nameStr,
+			guildStr:                  guildStr,
 			displayValStr:             displayValStr,
 			dressedValStr:             dressedValStr,
 			teStr:                     teStr,
@@ -831,12 +848,18 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	padField := func(s string, width int, align bottools.StringAlign) string {
 		return " " + bottools.AlignString(s, width, align) + " "
 	}
+	guildField := func(s string) string {
+		if !hasGuildColumn {
+			return ""
+		}
+		return "|" + padField(s, guildWidth, bottools.StringAlignLeft)
+	}
 
 	var colHeader string
 	if isEB {
 		headerLine := strings.Join([]string{
 			padField(rankHeader, rankWidth, bottools.StringAlignLeft),
-			padField("Name", maxNameWidth, bottools.StringAlignLeft),
+			padField("Name", maxNameWidth, bottools.StringAlignLeft) + guildField("Guild"),
 			padField("Nekkid", maxValOnlyWidth, bottools.StringAlignRight),
 			padField("Dressed", maxDressedWidth, bottools.StringAlignRight),
 		}, "|")
@@ -844,7 +867,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	} else if hasCTEComparisonColumn {
 		headerLine := strings.Join([]string{
 			padField(rankHeader, rankWidth, bottools.StringAlignLeft),
-			padField("Name", maxNameWidth, bottools.StringAlignLeft),
+			padField("Name", maxNameWidth, bottools.StringAlignLeft) + guildField("Guild"),
 			padField("Pending", maxValOnlyWidth, bottools.StringAlignRight),
 			padField("CTE", actualCTEWidth, bottools.StringAlignRight),
 		}, "|")
@@ -852,7 +875,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	} else if hasTEColumn {
 		headerLine := strings.Join([]string{
 			padField(rankHeader, rankWidth, bottools.StringAlignLeft),
-			padField("Name", maxNameWidth, bottools.StringAlignLeft),
+			padField("Name", maxNameWidth, bottools.StringAlignLeft) + guildField("Guild"),
 			padField(shortDisplayName, maxValOnlyWidth, bottools.StringAlignRight),
 			padField("TE", teWidth, bottools.StringAlignRight),
 		}, "|")
@@ -860,7 +883,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	} else if def.Key == LBSoulMirrors {
 		headerLine := strings.Join([]string{
 			padField(rankHeader, rankWidth, bottools.StringAlignLeft),
-			padField("Name", maxNameWidth, bottools.StringAlignLeft),
+			padField("Name", maxNameWidth, bottools.StringAlignLeft) + guildField("Guild"),
 			padField(shortDisplayName, maxValOnlyWidth, bottools.StringAlignRight),
 			padField("C", soulMirrorCommonWidth, bottools.StringAlignRight),
 			padField("E", soulMirrorEpicWidth, bottools.StringAlignRight),
@@ -870,7 +893,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	} else if isTEPerShift {
 		headerLine := strings.Join([]string{
 			padField(rankHeader, rankWidth, bottools.StringAlignLeft),
-			padField("Name", maxNameWidth, bottools.StringAlignLeft),
+			padField("Name", maxNameWidth, bottools.StringAlignLeft) + guildField("Guild"),
 			padField(shortDisplayName, maxValOnlyWidth, bottools.StringAlignRight),
 			padField("TE", tePerShiftTEWidth, bottools.StringAlignRight),
 			padField("Shifts", tePerShiftShiftsWidth, bottools.StringAlignRight),
@@ -879,7 +902,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	} else if isSEPerPrestige {
 		headerLine := strings.Join([]string{
 			padField(rankHeader, rankWidth, bottools.StringAlignLeft),
-			padField("Name", maxNameWidth, bottools.StringAlignLeft),
+			padField("Name", maxNameWidth, bottools.StringAlignLeft) + guildField("Guild"),
 			padField(shortDisplayName, maxValOnlyWidth, bottools.StringAlignRight),
 			padField("SE", sePerPrestigeSEWidth, bottools.StringAlignRight),
 			padField("Prestiges", sePerPrestigePrestigesWidth, bottools.StringAlignRight),
@@ -888,7 +911,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	} else if isCraftingXP {
 		headerLine := strings.Join([]string{
 			padField(rankHeader, rankWidth, bottools.StringAlignLeft),
-			padField("Name", maxNameWidth, bottools.StringAlignLeft),
+			padField("Name", maxNameWidth, bottools.StringAlignLeft) + guildField("Guild"),
 			padField(shortDisplayName, maxValOnlyWidth, bottools.StringAlignRight),
 			padField("Lvl", craftingLevelWidth, bottools.StringAlignRight),
 		}, "|")
@@ -896,7 +919,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	} else {
 		headerLine := strings.Join([]string{
 			padField(rankHeader, rankWidth, bottools.StringAlignLeft),
-			padField("Name", maxNameWidth, bottools.StringAlignLeft),
+			padField("Name", maxNameWidth, bottools.StringAlignLeft) + guildField("Guild"),
 			padField(shortDisplayName, maxValOnlyWidth, bottools.StringAlignRight),
 		}, "|")
 		colHeader = fmt.Sprintf("```\n%s\n%s\n", headerLine, strings.Repeat("-", runewidth.StringWidth(headerLine)))
@@ -905,14 +928,15 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 	rowLines := make([]string, 0, len(infos))
 	for _, info := range infos {
 		detail := ""
-		if info.row.Details != "" && info.row.Details != "na" && !strings.HasPrefix(info.row.Details, "total:") && !strings.Contains(info.row.Details, "dressed:") && !strings.HasPrefix(info.row.Details, "te:") && !strings.HasSuffix(info.row.Details, " TE") && !strings.HasPrefix(info.row.Details, "actual:") && !strings.HasPrefix(info.row.Details, "se:") {
+		// Custom metric details are the stored expression inputs, not for display
+		if info.row.Details != "" && info.row.Details != "na" && !isCustomKey(def.Key) && !strings.HasPrefix(info.row.Details, "total:") && !strings.Contains(info.row.Details, "dressed:") && !strings.HasPrefix(info.row.Details, "te:") && !strings.HasSuffix(info.row.Details, " TE") && !strings.HasPrefix(info.row.Details, "actual:") && !strings.HasPrefix(info.row.Details, "se:") {
 			detail = fmt.Sprintf(" (%s)", info.row.Details)
 		}
 
 		if isEB {
 			rowLines = append(rowLines, fmt.Sprintf("%s%s\n", strings.Join([]string{
 				padField(info.rankStr, rankWidth, bottools.StringAlignLeft),
-				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft),
+				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft) + guildField(info.guildStr),
 				padField(info.displayValStr, maxValOnlyWidth, bottools.StringAlignRight),
 				padField(info.dressedValStr, maxDressedWidth, bottools.StringAlignRight),
 			}, "|"), detail))
@@ -922,7 +946,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 		if hasCTEComparisonColumn {
 			rowLines = append(rowLines, fmt.Sprintf("%s%s\n", strings.Join([]string{
 				padField(info.rankStr, rankWidth, bottools.StringAlignLeft),
-				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft),
+				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft) + guildField(info.guildStr),
 				padField(info.displayValStr, maxValOnlyWidth, bottools.StringAlignRight),
 				padField(info.actualCTEStr, actualCTEWidth, bottools.StringAlignRight),
 			}, "|"), detail))
@@ -932,7 +956,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 		if hasTEColumn {
 			rowLines = append(rowLines, fmt.Sprintf("%s%s\n", strings.Join([]string{
 				padField(info.rankStr, rankWidth, bottools.StringAlignLeft),
-				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft),
+				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft) + guildField(info.guildStr),
 				padField(info.displayValStr, maxValOnlyWidth, bottools.StringAlignRight),
 				padField(info.teStr, teWidth, bottools.StringAlignRight),
 			}, "|"), detail))
@@ -942,7 +966,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 		if def.Key == LBSoulMirrors {
 			rowLines = append(rowLines, fmt.Sprintf("%s\n", strings.Join([]string{
 				padField(info.rankStr, rankWidth, bottools.StringAlignLeft),
-				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft),
+				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft) + guildField(info.guildStr),
 				padField(info.displayValStr, maxValOnlyWidth, bottools.StringAlignRight),
 				padField(info.soulMirrorCommonStr, soulMirrorCommonWidth, bottools.StringAlignRight),
 				padField(info.soulMirrorEpicStr, soulMirrorEpicWidth, bottools.StringAlignRight),
@@ -954,7 +978,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 		if isTEPerShift {
 			rowLines = append(rowLines, fmt.Sprintf("%s%s\n", strings.Join([]string{
 				padField(info.rankStr, rankWidth, bottools.StringAlignLeft),
-				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft),
+				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft) + guildField(info.guildStr),
 				padField(info.displayValStr, maxValOnlyWidth, bottools.StringAlignRight),
 				padField(info.tePerShiftTEStr, tePerShiftTEWidth, bottools.StringAlignRight),
 				padField(info.tePerShiftShiftsStr, tePerShiftShiftsWidth, bottools.StringAlignRight),
@@ -965,7 +989,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 		if isSEPerPrestige {
 			rowLines = append(rowLines, fmt.Sprintf("%s%s\n", strings.Join([]string{
 				padField(info.rankStr, rankWidth, bottools.StringAlignLeft),
-				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft),
+				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft) + guildField(info.guildStr),
 				padField(info.displayValStr, maxValOnlyWidth, bottools.StringAlignRight),
 				padField(info.sePerPrestigeSEStr, sePerPrestigeSEWidth, bottools.StringAlignRight),
 				padField(info.sePerPrestigePrestigesStr, sePerPrestigePrestigesWidth, bottools.StringAlignRight),
@@ -976,7 +1000,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 		if isCraftingXP {
 			rowLines = append(rowLines, fmt.Sprintf("%s\n", strings.Join([]string{
 				padField(info.rankStr, rankWidth, bottools.StringAlignLeft),
-				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft),
+				padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft) + guildField(info.guildStr),
 				padField(info.displayValStr, maxValOnlyWidth, bottools.StringAlignRight),
 				padField(info.craftingLevelStr, craftingLevelWidth, bottools.StringAlignRight),
 			}, "|")))
@@ -985,7 +1009,7 @@ func renderTable(def LBDef, rows []LBEntry, prevMap map[string]float64, rankOffs
 
 		rowLines = append(rowLines, fmt.Sprintf("%s%s\n", strings.Join([]string{
 			padField(info.rankStr, rankWidth, bottools.StringAlignLeft),
-			padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft),
+			padField(info.nameStr, maxNameWidth, bottools.StringAlignLeft) + guildField(info.guildStr),
 			padField(info.displayValStr, maxValOnlyWidth, bottools.StringAlignRight),
 		}, "|"), detail))
 	}
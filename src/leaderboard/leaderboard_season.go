@@ -0,0 +1,377 @@
+package leaderboard
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/boost"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/guildstate"
+)
+
+// ─── Season Archives ─────────────────────────────────────────────────────────
+//
+// The weekly collection records which contract season each snapshot falls in.
+// The first collection of a new season freezes the guild tables of the season
+// that just ended and posts its awards, before the new week overwrites them.
+
+// Season award kinds, stored in the archive alongside the frozen tables.
+const (
+	awardCSGain       = "award_cs_gain"
+	awardMostImproved = "award_most_improved"
+	awardTopMover     = "award_top_mover"
+)
+
+// maxSeasonTopMovers is how many rank climbers the awards name
+const maxSeasonTopMovers = 3
+
+// seasonAward is one award of a finished season.
+type seasonAward struct {
+	Kind     string
+	Player   string
+	GameName string
+	Value    float64 // CS gained, percent gained or ranks climbed
+	Details  string  // the rank change of a top mover, "#9 → #2"
+}
+
+// computeSeasonAwards compares a guild's Contract Score rows, ranked best
+// first, at the start and the end of a season. Players without a starting
+// score joined during the season and aren't eligible.
+func computeSeasonAwards(start, final []LBEntry) []seasonAward {
+	startRank := make(map[string]int, len(start))
+	startValue := make(map[string]float64, len(start))
+	for n, e := range start {
+		startRank[e.Player] = n + 1
+		startValue[e.Player] = e.Value
+	}
+
+	var awards []seasonAward
+	var gain, improved *seasonAward
+	var movers []seasonAward
+	for n, e := range final {
+		before, ok := startValue[e.Player]
+		if !ok {
+			continue
+		}
+		if delta := e.Value - before; delta > 0 && (gain == nil || delta > gain.Value) {
+			gain = &seasonAward{Kind: awardCSGain, Player: e.Player, GameName: e.GameName, Value: delta}
+		}
+		if before > 0 {
+			if pct := (e.Value - before) / before * 100; pct > 0 && (improved == nil || pct > improved.Value) {
+				improved = &seasonAward{Kind: awardMostImproved, Player: e.Player, GameName: e.GameName, Value: pct}
+			}
+		}
+		if climb := startRank[e.Player] - (n + 1); climb > 0 {
+			movers = append(movers, seasonAward{
+				Kind:     awardTopMover,
+				Player:   e.Player,
+				GameName: e.GameName,
+				Value:    float64(climb),
+				Details:  fmt.Sprintf("#%d → #%d", startRank[e.Player], n+1),
+			})
+		}
+	}
+	if gain != nil {
+		awards = append(awards, *gain)
+	}
+	if improved != nil {
+		awards = append(awards, *improved)
+	}
+	// Ties keep the final ranking order
+	sort.SliceStable(movers, func(a, b int) bool { return movers[a].Value > movers[b].Value })
+	awards = append(awards, movers[:min(len(movers), maxSeasonTopMovers)]...)
+	return awards
+}
+
+// seasonAwardsMessage formats a season's awards for posting.
+func seasonAwardsMessage(seasonLabel string, awards []seasonAward) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 🏅 %s Season Awards\n", seasonLabel)
+	if len(awards) == 0 {
+		b.WriteString("No Contract Score changes were recorded this season.\n")
+	}
+	moverRank := 0
+	for _, a := range awards {
+		switch a.Kind {
+		case awardCSGain:
+			fmt.Fprintf(&b, "**Biggest CS Gain:** %s %s\n", a.GameName, FormatLBDelta("cxp", a.Value))
+		case awardMostImproved:
+			fmt.Fprintf(&b, "**Most Improved:** %s +%.1f%% CS\n", a.GameName, a.Value)
+		case awardTopMover:
+			if moverRank == 0 {
+				b.WriteString("**Top Movers:**\n")
+			}
+			moverRank++
+			fmt.Fprintf(&b, "%d. %s %s (+%.0f)\n", moverRank, a.GameName, a.Details, a.Value)
+		}
+	}
+	b.WriteString("-# Browse the final tables with `/lb seasons`.")
+	return b.String()
+}
+
+// checkSeasonBoundary records the season of this collection, archiving the
+// previous season the first time a new one is seen.
+func checkSeasonBoundary(s *discordgo.Session, snapDate string) {
+	seasonID, ok := boost.CurrentLeaderboardSeasonID()
+	if !ok {
+		return
+	}
+	seasons, err := guildstate.GetLeaderboardSeasons()
+	if err != nil {
+		log.Printf("leaderboard: load seasons: %v", err)
+		return
+	}
+	if len(seasons) > 0 {
+		last := seasons[0]
+		if last.ArchivedAt == 0 && boost.LeaderboardSeasonIsAfter(seasonID, last.SeasonID) {
+			archiveSeason(s, last)
+		}
+		if boost.LeaderboardSeasonIsAfter(last.SeasonID, seasonID) {
+			// The periodicals briefly name an older season; don't reopen it.
+			return
+		}
+	}
+	if err := guildstate.RecordLeaderboardSeasonSnap(seasonID, snapDate); err != nil {
+		log.Printf("leaderboard: record season %s: %v", seasonID, err)
+	}
+}
+
+// archiveSeason freezes every configured guild's tables for a finished season and posts its awards.
+func archiveSeason(s *discordgo.Session, season guildstate.LeaderboardSeason) {
+	cfgs, err := GetAllLBConfigs()
+	if err != nil {
+		log.Printf("leaderboard: archive season %s: %v", season.SeasonID, err)
+		return
+	}
+	guildCfgs := make(map[string][]LBConfig)
+	for _, cfg := range cfgs {
+		guildCfgs[cfg.GuildID] = append(guildCfgs[cfg.GuildID], cfg)
+	}
+
+	label := boost.LeaderboardSeasonLabel(season.SeasonID)
+	for guildID, gc := range guildCfgs {
+		awards := archiveSeasonForGuild(season, guildID, gc)
+		if s == nil {
+			continue
+		}
+		if _, err := s.ChannelMessageSend(seasonAwardsChannel(gc), seasonAwardsMessage(label, awards)); err != nil {
+			log.Printf("leaderboard: post %s awards in guild %s: %v", season.SeasonID, guildID, err)
+		}
+		time.Sleep(rateLimitDelay)
+	}
+
+	if err := guildstate.SetLeaderboardSeasonArchived(season.SeasonID); err != nil {
+		log.Printf("leaderboard: mark season %s archived: %v", season.SeasonID, err)
+	}
+	log.Printf("leaderboard: archived season %s for %d guilds", season.SeasonID, len(guildCfgs))
+}
+
+// archiveSeasonForGuild saves a guild's final tables and awards for a season.
+func archiveSeasonForGuild(season guildstate.LeaderboardSeason, guildID string, cfgs []LBConfig) []seasonAward {
+	var lbTypes []string
+	for _, cfg := range cfgs {
+		for _, k := range ExpandConfigKey(cfg.LBType) {
+			if k != LBEggDaySEGain && k != LBEggDaySEPct && !slices.Contains(lbTypes, k) {
+				lbTypes = append(lbTypes, k)
+			}
+		}
+	}
+
+	save := func(row guildstate.LeaderboardSeasonArchive) {
+		row.SeasonID = season.SeasonID
+		row.GuildID = guildID
+		if err := guildstate.UpsertLeaderboardSeasonArchiveRow(row); err != nil {
+			log.Printf("leaderboard: archive %s/%s/%s: %v", season.SeasonID, guildID, row.LbType, err)
+		}
+	}
+
+	for _, lbType := range lbTypes {
+		snapDate := GetLatestSnapDate(lbType)
+		if lbType == LBCXPWeeklyDelta {
+			snapDate = GetLatestSnapDate(LBContractExp)
+		}
+		if snapDate == "" {
+			continue
+		}
+		rows, _ := getGuildRows(lbType, snapDate, guildID)
+		for n, r := range rows {
+			save(guildstate.LeaderboardSeasonArchive{
+				LbType:   lbType,
+				Rank:     int64(n + 1),
+				Player:   r.Player,
+				GameName: r.GameName,
+				Value:    r.Value,
+				Details:  r.Details,
+				SnapDate: r.SnapDate,
+			})
+		}
+	}
+
+	// Contract Score is kept every week, so compare the week before the season with its last week.
+	startDate := GetPreviousSnapDate(LBContractExp, season.FirstSnapDate)
+	if startDate == "" {
+		startDate = season.FirstSnapDate
+	}
+	start, _ := getGuildRows(LBContractExp, startDate, guildID)
+	final, _ := getGuildRows(LBContractExp, season.LastSnapDate, guildID)
+	awards := computeSeasonAwards(start, final)
+	rank := make(map[string]int64)
+	for _, a := range awards {
+		rank[a.Kind]++
+		save(guildstate.LeaderboardSeasonArchive{
+			LbType:   a.Kind,
+			Rank:     rank[a.Kind],
+			Player:   a.Player,
+			GameName: a.GameName,
+			Value:    a.Value,
+			Details:  a.Details,
+			SnapDate: season.LastSnapDate,
+		})
+	}
+	return awards
+}
+
+// seasonAwardsChannel picks the channel showing Contract Score, or the guild's first leaderboard channel.
+func seasonAwardsChannel(cfgs []LBConfig) string {
+	for _, cfg := range cfgs {
+		if slices.Contains(ExpandConfigKey(cfg.LBType), LBContractExp) {
+			return cfg.ChannelID
+		}
+	}
+	return cfgs[0].ChannelID
+}
+
+// getSeasonAwards reads a guild's stored awards for a season.
+func getSeasonAwards(seasonID, guildID string) []seasonAward {
+	var awards []seasonAward
+	for _, kind := range []string{awardCSGain, awardMostImproved, awardTopMover} {
+		rows, err := guildstate.GetLeaderboardSeasonArchive(seasonID, guildID, kind)
+		if err != nil {
+			log.Printf("leaderboard: season awards %s/%s: %v", seasonID, guildID, err)
+			continue
+		}
+		for _, r := range rows {
+			awards = append(awards, seasonAward{Kind: kind, Player: r.Player, GameName: r.GameName, Value: r.Value, Details: r.Details})
+		}
+	}
+	return awards
+}
+
+func isSeasonAwardKey(lbType string) bool {
+	return strings.HasPrefix(lbType, "award_")
+}
+
+// ─── /lb seasons ─────────────────────────────────────────────────────────────
+
+func handleSeasons(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
+	guildID := i.GuildID
+	if guildID == "" {
+		respondEphemeral(s, i, "This command must be used within a server.")
+		return
+	}
+	seasons, err := guildstate.GetArchivedLeaderboardSeasonsForGuild(guildID)
+	if err != nil || len(seasons) == 0 {
+		respondEphemeral(s, i, "No seasons have been archived for this server yet. Final tables are saved when a contract season ends.")
+		return
+	}
+
+	optMap := optionMap(opts)
+	season := seasons[0]
+	if opt, ok := optMap["season"]; ok {
+		idx := slices.IndexFunc(seasons, func(ls guildstate.LeaderboardSeason) bool { return ls.SeasonID == opt.StringValue() })
+		if idx < 0 {
+			respondEphemeral(s, i, fmt.Sprintf("No archive for season %q, pick one from the list.", opt.StringValue()))
+			return
+		}
+		season = seasons[idx]
+	}
+	label := boost.LeaderboardSeasonLabel(season.SeasonID)
+
+	opt, ok := optMap["board"]
+	if !ok {
+		var b strings.Builder
+		b.WriteString(seasonAwardsMessage(label, getSeasonAwards(season.SeasonID, guildID)))
+		fmt.Fprintf(&b, "\n### Final Tables (%s)\n", season.LastSnapDate)
+		types, _ := guildstate.GetLeaderboardSeasonArchiveTypes(season.SeasonID, guildID)
+		var names []string
+		for _, t := range types {
+			if !isSeasonAwardKey(t) {
+				names = append(names, seasonBoardDef(t).DisplayName)
+			}
+		}
+		b.WriteString(strings.Join(names, ", "))
+		b.WriteString("\n-# Pick a `board` to see its final standings.")
+		respondEphemeral(s, i, b.String())
+		return
+	}
+
+	lbType := opt.StringValue()
+	rows, err := guildstate.GetLeaderboardSeasonArchive(season.SeasonID, guildID, lbType)
+	if err != nil || len(rows) == 0 || isSeasonAwardKey(lbType) {
+		respondEphemeral(s, i, fmt.Sprintf("No %s table was archived for %s.", DisplayNameForConfigKey(lbType), label))
+		return
+	}
+	entries := make([]LBEntry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, LBEntry{LBType: r.LbType, Player: r.Player, GameName: r.GameName, SnapDate: r.SnapDate, Value: r.Value, Details: r.Details})
+	}
+	def := seasonBoardDef(lbType)
+	def.DisplayName = fmt.Sprintf("%s: %s Final", label, def.DisplayName)
+	blocks := buildMessageBlocks(def, entries, entries[0].SnapDate, nil, 0)
+	respondEphemeral(s, i, blocks[0])
+}
+
+// seasonBoardDef returns the definition to render an archived table with,
+// even when its leaderboard has since been removed.
+func seasonBoardDef(lbType string) LBDef {
+	if def, ok := LBDefByKey(lbType); ok {
+		return def
+	}
+	return LBDef{Key: lbType, DisplayName: lbType, ValueFmt: "float", HigherIsBetter: true}
+}
+
+// seasonChoices lists a guild's archived seasons matching partial.
+func seasonChoices(guildID, partial string) []*discordgo.ApplicationCommandOptionChoice {
+	seasons, _ := guildstate.GetArchivedLeaderboardSeasonsForGuild(guildID)
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, ls := range seasons {
+		label := boost.LeaderboardSeasonLabel(ls.SeasonID)
+		if partial == "" || strings.Contains(strings.ToLower(label), partial) || strings.Contains(ls.SeasonID, partial) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: label, Value: ls.SeasonID})
+		}
+		if len(choices) == 25 {
+			break
+		}
+	}
+	return choices
+}
+
+// seasonBoardChoices lists the archived tables of a season (the latest when seasonID is empty) matching partial.
+func seasonBoardChoices(guildID, seasonID, partial string) []*discordgo.ApplicationCommandOptionChoice {
+	if seasonID == "" {
+		seasons, _ := guildstate.GetArchivedLeaderboardSeasonsForGuild(guildID)
+		if len(seasons) == 0 {
+			return nil
+		}
+		seasonID = seasons[0].SeasonID
+	}
+	types, _ := guildstate.GetLeaderboardSeasonArchiveTypes(seasonID, guildID)
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, t := range types {
+		if isSeasonAwardKey(t) {
+			continue
+		}
+		name := seasonBoardDef(t).DisplayName
+		if partial == "" || strings.Contains(strings.ToLower(name), partial) || strings.Contains(t, partial) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: t})
+		}
+		if len(choices) == 25 {
+			break
+		}
+	}
+	return choices
+}
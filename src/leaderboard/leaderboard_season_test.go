@@ -0,0 +1,96 @@
+package leaderboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/boost"
+)
+
+func TestComputeSeasonAwards(t *testing.T) {
+	start := []LBEntry{
+		{Player: "a", GameName: "Alpha", Value: 1000},
+		{Player: "b", GameName: "Bravo", Value: 800},
+		{Player: "c", GameName: "Charlie", Value: 100},
+		{Player: "d", GameName: "Delta", Value: 50},
+	}
+	final := []LBEntry{
+		{Player: "b", GameName: "Bravo", Value: 1500},
+		{Player: "new", GameName: "Newbie", Value: 1200},
+		{Player: "a", GameName: "Alpha", Value: 1100},
+		{Player: "c", GameName: "Charlie", Value: 400},
+		{Player: "d", GameName: "Delta", Value: 50},
+	}
+	awards := computeSeasonAwards(start, final)
+
+	want := []seasonAward{
+		{Kind: awardCSGain, Player: "b", GameName: "Bravo", Value: 700},
+		{Kind: awardMostImproved, Player: "c", GameName: "Charlie", Value: 300},
+		{Kind: awardTopMover, Player: "b", GameName: "Bravo", Value: 1, Details: "#2 → #1"},
+	}
+	if len(awards) != len(want) {
+		t.Fatalf("awards = %+v, want %+v", awards, want)
+	}
+	for n := range want {
+		if awards[n] != want[n] {
+			t.Errorf("award %d = %+v, want %+v", n, awards[n], want[n])
+		}
+	}
+}
+
+func TestComputeSeasonAwardsTopMoversOrder(t *testing.T) {
+	var start, final []LBEntry
+	for _, p := range []string{"a", "b", "c", "d", "e"} {
+		start = append(start, LBEntry{Player: p, GameName: p, Value: 10})
+	}
+	for _, p := range []string{"e", "d", "c", "b", "a"} {
+		final = append(final, LBEntry{Player: p, GameName: p, Value: 10})
+	}
+	var movers []string
+	for _, a := range computeSeasonAwards(start, final) {
+		if a.Kind == awardTopMover {
+			movers = append(movers, a.Player+":"+a.Details)
+		}
+	}
+	if got, want := strings.Join(movers, " "), "e:#5 → #1 d:#4 → #2"; got != want {
+		t.Errorf("top movers = %q, want %q", got, want)
+	}
+}
+
+func TestSeasonAwardsMessage(t *testing.T) {
+	msg := seasonAwardsMessage("Spring 2026", []seasonAward{
+		{Kind: awardMostImproved, GameName: "Charlie", Value: 300},
+		{Kind: awardTopMover, GameName: "Echo", Value: 4, Details: "#5 → #1"},
+		{Kind: awardTopMover, GameName: "Delta", Value: 2, Details: "#4 → #2"},
+	})
+	for _, want := range []string{
+		"## 🏅 Spring 2026 Season Awards",
+		"**Most Improved:** Charlie +300.0% CS",
+		"**Top Movers:**\n1. Echo #5 → #1 (+4)\n2. Delta #4 → #2 (+2)",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+	if empty := seasonAwardsMessage("Fall 2025", nil); !strings.Contains(empty, "No Contract Score changes") {
+		t.Errorf("empty message = %q", empty)
+	}
+}
+
+func TestLeaderboardSeasonIsAfter(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"summer_2026", "spring_2026", true},
+		{"winter_2027", "fall_2026", true},
+		{"fall_2025", "winter_2026", false},
+		{"spring_2026", "spring_2026", false},
+		{"bogus", "spring_2026", false},
+	}
+	for _, tt := range tests {
+		if got := boost.LeaderboardSeasonIsAfter(tt.a, tt.b); got != tt.want {
+			t.Errorf("LeaderboardSeasonIsAfter(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -45,6 +45,12 @@ func GetSlashAdminLBCommand(cmd string) *discordgo.ApplicationCommand {
 						Required:     true,
 						Autocomplete: true,
 					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "federated",
+						Description: "Post the combined board of this server's federation (default false)",
+						Required:    false,
+					},
 				},
 			},
 			{
@@ -76,6 +82,127 @@ func GetSlashAdminLBCommand(cmd string) *discordgo.ApplicationCommand {
 						Required:     true,
 						Autocomplete: true,
 					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "federated",
+						Description: "Remove the federation's combined board instead (default false)",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "custom-add",
+				Description: "Define a custom leaderboard from an expression over other leaderboards.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Display name, e.g. SE per Week", Required: true, MaxLength: 48},
+					{Type: discordgo.ApplicationCommandOptionString, Name: "expression", Description: "e.g. delta(soul_eggs)/weeks(), te_total/prestiges, elite_drones/drones", Required: true, MaxLength: 200},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "format",
+						Description: "How values are shown (default float)",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Number", Value: "int"},
+							{Name: "Decimal", Value: "float"},
+							{Name: "Percent", Value: "pct"},
+							{Name: "Egg Inc units (SE)", Value: "ei"},
+							{Name: "Earnings Bonus", Value: "eb"},
+							{Name: "Contract Score", Value: "cxp"},
+							{Name: "Duration (seconds)", Value: "duration"},
+						},
+					},
+					{Type: discordgo.ApplicationCommandOptionBoolean, Name: "higher-is-better", Description: "Rank higher values first (default true)", Required: false},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "custom-remove",
+				Description: "Remove a custom leaderboard from this guild.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "metric",
+						Description:  "Custom leaderboard to remove",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "custom-list",
+				Description: "List the custom leaderboards defined for this guild.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "federation-create",
+				Description: "Start a leaderboard federation that allied servers can join.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionString, Name: "name", Description: "Federation name shown on combined boards", Required: true, MaxLength: 48},
+					{Type: discordgo.ApplicationCommandOptionString, Name: "label", Description: "Short name for this server in the Guild column (default server name)", Required: false, MaxLength: maxFederationLabelChars},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "federation-join",
+				Description: "Join an allied server's leaderboard federation.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Type: discordgo.ApplicationCommandOptionString, Name: "code", Description: "Join code from the federation's owning server", Required: true},
+					{Type: discordgo.ApplicationCommandOptionString, Name: "label", Description: "Short name for this server in the Guild column (default server name)", Required: false, MaxLength: maxFederationLabelChars},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "federation-leave",
+				Description: "Leave this server's federation. The owning server disbands it.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "federation-remove",
+				Description: "Remove a member server from the federation (owning server only).",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "member",
+						Description:  "Member server to remove",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "federation-info",
+				Description: "Show this server's federation and its members.",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "anomalies",
+				Description: "List leaderboard snapshots held for review (home guild only).",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "anomaly-review",
+				Description: "Approve or reject a held leaderboard snapshot (home guild only).",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "anomaly",
+						Description:  "Held snapshot to review",
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "decision",
+						Description: "Approve to post the snapshot, reject to discard it",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Approve", Value: "approve"},
+							{Name: "Reject", Value: "reject"},
+						},
+					},
 				},
 			},
 			{
@@ -193,6 +320,54 @@ func GetSlashLBPlayerCommand(cmd string) *discordgo.ApplicationCommand {
 				Name:        "rankings",
 				Description: "Show your latest leaderboard rankings.",
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "history",
+				Description: "Chart a leaderboard metric over time.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "metric",
+						Description:  "Leaderboard metric to chart.",
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "compare",
+						Description: "Mention up to 5 server members to compare against.",
+						Required:    false,
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "alt",
+						Description:  "The name of the alternate account (optional).",
+						Required:     false,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "seasons",
+				Description: "Browse the awards and final tables of past contract seasons.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "season",
+						Description:  "Past season (defaults to the latest).",
+						Required:     false,
+						Autocomplete: true,
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "board",
+						Description:  "Leaderboard to show the final standings of.",
+						Required:     false,
+						Autocomplete: true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -223,6 +398,26 @@ func HandleAdminLB(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		handleRun(s, i, opts[0].Options)
 	case "backfill-eggday":
 		handleAdminBackfillEggDay(s, i, opts[0].Options)
+	case "custom-add":
+		handleAdminCustomAdd(s, i, opts[0].Options)
+	case "custom-remove":
+		handleAdminCustomRemove(s, i, opts[0].Options)
+	case "custom-list":
+		handleAdminCustomList(s, i)
+	case "federation-create":
+		handleAdminFederationCreate(s, i, opts[0].Options)
+	case "federation-join":
+		handleAdminFederationJoin(s, i, opts[0].Options)
+	case "federation-leave":
+		handleAdminFederationLeave(s, i)
+	case "federation-remove":
+		handleAdminFederationRemove(s, i, opts[0].Options)
+	case "federation-info":
+		handleAdminFederationInfo(s, i)
+	case "anomalies":
+		handleAdminAnomalies(s, i)
+	case "anomaly-review":
+		handleAdminAnomalyReview(s, i, opts[0].Options)
 	default:
 		respondEphemeral(s, i, "Unknown admin subcommand.")
 	}
@@ -372,6 +567,10 @@ func HandleLBPlayer(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		handlePlayerList(s, i)
 	case "rankings":
 		handleRankings(s, i)
+	case "history":
+		handleHistory(s, i, opts[0].Options)
+	case "seasons":
+		handleSeasons(s, i, opts[0].Options)
 	default:
 		respondEphemeral(s, i, "Unknown player subcommand.")
 	}
@@ -383,7 +582,19 @@ func handleAdminSetChannel(s *discordgo.Session, i *discordgo.InteractionCreate,
 	// Use the channel where the command was invoked.
 	channelID := i.ChannelID
 
-	if !IsValidConfigKey(lbType) {
+	if opt, ok := optMap["federated"]; ok && opt.BoolValue() {
+		if isCustomKey(lbType) {
+			respondEphemeral(s, i, "Custom leaderboards belong to one server and can't be federated.")
+			return
+		}
+		if _, _, err := guildstate.GetLeaderboardFederationForGuild(i.GuildID); err != nil {
+			respondEphemeral(s, i, "This server isn't in a leaderboard federation.\nUse `/admin-lb federation-create` or `/admin-lb federation-join` first.")
+			return
+		}
+		lbType = federatedKeyPrefix + lbType
+	}
+
+	if !IsValidConfigKey(lbType) || (isCustomKey(lbType) && !customKeyInGuild(lbType, i.GuildID)) {
 		respondEphemeral(s, i, fmt.Sprintf("Unknown leaderboard type or group: %q", lbType))
 		return
 	}
@@ -432,6 +643,9 @@ func handleAdminList(s *discordgo.Session, i *discordgo.InteractionCreate) {
 func handleAdminRemove(s *discordgo.Session, i *discordgo.InteractionCreate, opts []*discordgo.ApplicationCommandInteractionDataOption) {
 	optMap := optionMap(opts)
 	lbType := optMap["type"].StringValue()
+	if opt, ok := optMap["federated"]; ok && opt.BoolValue() {
+		lbType = federatedKeyPrefix + lbType
+	}
 
 	if !IsValidConfigKey(lbType) {
 		respondEphemeral(s, i, fmt.Sprintf("Unknown leaderboard type or group: %q", lbType))
@@ -461,6 +675,10 @@ func handlePlayerOptIn(s *discordgo.Session, i *discordgo.InteractionCreate, opt
 	} else {
 		types = ExpandConfigKey(raw)
 	}
+	if isCustomKey(raw) && !customKeyInGuild(raw, i.GuildID) {
+		respondEphemeral(s, i, fmt.Sprintf("Unknown leaderboard type or group: %q", raw))
+		return
+	}
 
 	AddPlayerOptInTypes(i.GuildID, userID, types)
 
@@ -774,10 +992,12 @@ func HandleAdminLBAutoComplete(s *discordgo.Session, i *discordgo.InteractionCre
 	data := i.ApplicationCommandData()
 
 	var partial string
+	var focusedName string
 	var found bool
 	for _, opt := range data.Options {
 		for _, leaf := range opt.Options {
 			if leaf.Focused {
+				focusedName = leaf.Name
 				partial = strings.ToLower(strings.TrimSpace(leaf.StringValue()))
 				found = true
 			}
@@ -788,7 +1008,17 @@ func HandleAdminLBAutoComplete(s *discordgo.Session, i *discordgo.InteractionCre
 		return
 	}
 
-	choices := buildAutocompleteChoices(partial, false)
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	switch focusedName {
+	case "metric":
+		choices = customMetricChoices(i.GuildID, partial)
+	case "member":
+		choices = federationMemberChoices(i.GuildID, partial)
+	case "anomaly":
+		choices = anomalyChoices(i.GuildID, partial)
+	default:
+		choices = withCustomChoices(buildAutocompleteChoices(partial, false), i.GuildID, partial)
+	}
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
 		Data: &discordgo.InteractionResponseData{Choices: choices},
@@ -835,7 +1065,38 @@ func HandleLBPlayerAutoComplete(s *discordgo.Session, i *discordgo.InteractionCr
 		return
 	}
 
-	choices := buildAutocompleteChoices(partial, true)
+	if focusedName == "season" || focusedName == "board" {
+		var choices []*discordgo.ApplicationCommandOptionChoice
+		if focusedName == "season" {
+			choices = seasonChoices(i.GuildID, partial)
+		} else {
+			seasonID := ""
+			for _, opt := range data.Options {
+				for _, leaf := range opt.Options {
+					if leaf.Name == "season" {
+						seasonID = leaf.StringValue()
+					}
+				}
+			}
+			choices = seasonBoardChoices(i.GuildID, seasonID, partial)
+		}
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{Choices: choices},
+		})
+		return
+	}
+
+	if focusedName == "metric" {
+		choices := withCustomChoices(buildMetricChoices(partial), i.GuildID, partial)
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{Choices: choices},
+		})
+		return
+	}
+
+	choices := withCustomChoices(buildAutocompleteChoices(partial, true), i.GuildID, partial)
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
 		Data: &discordgo.InteractionResponseData{Choices: choices},
@@ -899,6 +1160,25 @@ func buildAutocompleteChoices(partial string, isPlayerCmd bool) []*discordgo.App
 	return choices
 }
 
+// buildMetricChoices lists individual leaderboards, without groups, matching partial.
+func buildMetricChoices(partial string) []*discordgo.ApplicationCommandOptionChoice {
+	const maxChoices = 25
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, maxChoices)
+	for _, def := range AllLeaderboards {
+		if len(choices) >= maxChoices {
+			break
+		}
+		choiceName := leaderboardChoiceName(def)
+		if partial == "" || strings.Contains(strings.ToLower(choiceName), partial) || strings.Contains(def.Key, partial) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  choiceName,
+				Value: def.Key,
+			})
+		}
+	}
+	return choices
+}
+
 func handleRankings(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Acknowledge immediately to avoid timeout.
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
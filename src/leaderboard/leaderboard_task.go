@@ -156,6 +156,7 @@ func RunLeaderboardCollection(s *discordgo.Session, dryRun bool, guildID string,
 					}
 					keys = append(keys, def.Key)
 				}
+				keys = append(keys, guildCustomKeys(o.GuildID)...)
 			} else {
 				for _, k := range ExpandConfigKey(o.LbType) {
 					if k == LBEggDaySEGain || k == LBEggDaySEPct {
@@ -206,6 +207,10 @@ func RunLeaderboardCollection(s *discordgo.Session, dryRun bool, guildID string,
 	}
 
 	snapDate := SnapDateNow()
+	if guildID == "" && !dryRun {
+		// A new season freezes the last one before this week's data replaces it.
+		checkSeasonBoundary(s, snapDate)
+	}
 	n := workerCount()
 	status := fmt.Sprintf("📡 Collecting %d unique players (from %d opted-in Discord accounts) with %d workers...", len(playerGroups), len(userIDs), n)
 	log.Printf("leaderboard: %s (snap_date %s, dry=%v)", status, snapDate, dryRun)
@@ -315,6 +320,9 @@ func collectPlayerGroup(s *discordgo.Session, g *playerGroup, snapDate string) {
 					}
 					userKeysSet[def.Key] = struct{}{}
 				}
+				for _, key := range guildCustomKeys(o.GuildID) {
+					userKeysSet[key] = struct{}{}
+				}
 			} else {
 				for _, k := range ExpandConfigKey(o.LbType) {
 					if k == LBEggDaySEGain || k == LBEggDaySEPct {
@@ -336,8 +344,14 @@ func collectPlayerGroup(s *discordgo.Session, g *playerGroup, snapDate string) {
 			_, _ = fmt.Sscanf(prior.Details, "total:%f", &priorCXPTotal)
 		}
 
+		// Custom metrics compare against their previous snapshot.
+		priorCustom := priorCustomStats(userID, userKeys, snapDate)
+
 		// Run calculators specifically for this user's opted-in keys.
-		allEntries := RunCalculators(userID, backup, archive, userKeys, snapDate, priorCXPTotal)
+		allEntries := RunCalculators(userID, backup, archive, userKeys, snapDate, priorCXPTotal, priorCustom)
+
+		// Implausible jumps wait for admin review instead of being posted.
+		allEntries = screenLBEntries(userID, allEntries, snapDate)
 
 		// Save global entries.
 		for _, e := range allEntries {
@@ -383,6 +397,7 @@ func CollectSinglePlayer(s *discordgo.Session, userID string, snapDate string) e
 				}
 				keys = append(keys, def.Key)
 			}
+			keys = append(keys, guildCustomKeys(o.GuildID)...)
 		} else {
 			for _, k := range ExpandConfigKey(o.LbType) {
 				if k == LBEggDaySEGain || k == LBEggDaySEPct {
@@ -101,6 +101,32 @@ func SlashHuntCommand(cmd string) *discordgo.ApplicationCommand {
 		},
 	}
 
+	commandThree := []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "items",
+			Description: "Shopping list, e.g. \"2x T4 Gusset, T3 Gold Meteorite x10\"",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "objective",
+			Description: "What to minimize (Sticky)",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Fewest missions", Value: HuntMinimizeMissions},
+				{Name: "Fewest days", Value: HuntMinimizeDays},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "minimum-drops",
+			Description: "Select the minimum number of drops (Sticky)",
+			MinValue:    &integerZeroMinValue,
+			Required:    false,
+		},
+	}
+
 	return &discordgo.ApplicationCommand{
 		Name:        cmd,
 		Description: "Find artifact drop probabilities",
@@ -126,6 +152,12 @@ func SlashHuntCommand(cmd string) *discordgo.ApplicationCommand {
 				Description: "Hunt Menno drop data across multiple ships",
 				Options:     commandTwo,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "plan",
+				Description: "Plan the missions to complete a shopping list",
+				Options:     commandThree,
+			},
 		},
 	}
 }
@@ -145,6 +177,12 @@ func HandleHuntAutoComplete(s *discordgo.Session, i *discordgo.InteractionCreate
 			searchString = opt.StringValue()
 		}
 	}
+	// /craft shares the artifact search
+	if opt, ok := optionMap["artifact"]; ok {
+		if opt.Focused {
+			searchString = opt.StringValue()
+		}
+	}
 	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
 
 	if searchString == "" {
@@ -319,6 +357,13 @@ func HandleHuntCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 	}
 
+	if _, ok := optionMap["plan"]; ok {
+		response = handleHuntPlan(s, i, optionMap, userID)
+		if response == "" {
+			return
+		}
+	}
+
 	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
 		Flags: flags,
 		Components: []discordgo.MessageComponent{
@@ -332,3 +377,67 @@ func HandleHuntCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 
 }
+
+// handleHuntPlan handles /hunt plan, returning the response for the deferred
+// reply or an empty string when it has already responded
+func handleHuntPlan(s *discordgo.Session, i *discordgo.InteractionCreate, optionMap map[string]*discordgo.ApplicationCommandInteractionDataOption, userID string) string {
+	respondEphemeral := func(content string) {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags: discordgo.MessageFlagsIsComponentsV2 | discordgo.MessageFlagsEphemeral,
+				Components: []discordgo.MessageComponent{
+					discordgo.TextDisplay{
+						Content: content,
+					},
+				},
+			},
+		})
+	}
+
+	// This command requires the user to be registered
+	eiID := farmerstate.GetMiscSettingString(userID, "encrypted_ei_id")
+	if eiID == "" {
+		respondEphemeral(fmt.Sprintf("You must register your EI ID with the bot to use this command. Use the %s command.", bottools.GetFormattedCommand("register")))
+		return ""
+	}
+
+	needs, err := parseHuntNeeds(optionMap["plan-items"].StringValue())
+	if err != nil {
+		respondEphemeral(fmt.Sprintf("Unable to read the shopping list: %s", err))
+		return ""
+	}
+
+	objective := HuntMinimizeMissions
+	if opt, ok := optionMap["plan-objective"]; ok {
+		objective = opt.StringValue()
+		farmerstate.SetMiscSettingString(userID, "huntPlanObjective", objective)
+	} else if saved := farmerstate.GetMiscSettingString(userID, "huntPlanObjective"); saved != "" {
+		objective = saved
+	}
+
+	minimumDrops := DefaultMinimumDrops
+	if opt, ok := optionMap["plan-minimum-drops"]; ok {
+		minimumDrops = int(opt.IntValue())
+		farmerstate.SetMiscSettingString(userID, "huntMinimumDrops", fmt.Sprintf("%d", minimumDrops))
+	} else if saved := farmerstate.GetMiscSettingString(userID, "huntMinimumDrops"); saved != "" {
+		if parsed, err := strconv.Atoi(saved); err == nil && parsed >= 0 {
+			minimumDrops = parsed
+		}
+	}
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Processing request...",
+			Flags:   discordgo.MessageFlagsIsComponentsV2,
+		},
+	})
+
+	backup, _ := ei.GetFirstContactFromAPI(s, eiID, userID, true)
+	if backup == nil {
+		return "Unable to retrieve your backup from Egg, Inc."
+	}
+	plan := PlanHunt(backup, needs, objective, minimumDrops)
+	return PrintHuntPlan(plan, objective)
+}
@@ -0,0 +1,451 @@
+package menno
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/bottools"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+	"github.com/mkmccarty/TokenTimeBoostBot/src/farmerstate"
+)
+
+// craftTier and craftXP read the recipe data, replaceable in tests
+var craftTier = func(item huntItem) *ei.Tier {
+	return ei.GetArtifactTier(item.Artifact, ei.ArtifactSpec_Level(item.Tier-1))
+}
+var craftXP = func(item huntItem) float64 {
+	return ei.GetArtifactCraftingXP(item.Artifact, ei.ArtifactSpec_Level(item.Tier-1))
+}
+
+// CraftStep is a number of crafts of one item
+type CraftStep struct {
+	Item  HuntNeed // Quantity is the number of crafts
+	Ready int      // crafts possible with the current inventory
+	XP    float64
+}
+
+// CraftPlan is everything needed to craft a target from the inventory
+type CraftPlan struct {
+	Target    HuntNeed
+	Owned     int         // copies of the target already owned
+	Steps     []CraftStep // lowest tier first
+	Used      []HuntNeed  // items taken from the inventory
+	Missing   []HuntNeed  // items that must be found on missions
+	XP        float64     // XP from crafting the target
+	Spare     []CraftStep // crafts using the ingredients left over
+	SpareXP   float64
+	CurrentXP float64
+}
+
+type craftPlanner struct {
+	inventory map[huntItem]int
+	used      map[huntItem]int
+	missing   map[huntItem]int
+	steps     map[huntItem]*CraftStep
+	xp        float64
+}
+
+func craftIngredient(ingredient ei.Ingredient) huntItem {
+	return huntItem{Artifact: ingredient.AfxID, Tier: int(ingredient.AfxLevel) + 1}
+}
+
+// take finds one item in the inventory or crafts it, reporting whether the
+// whole tree below it came from the inventory
+func (p *craftPlanner) take(item huntItem) bool {
+	if p.inventory[item] > 0 {
+		p.inventory[item]--
+		p.used[item]++
+		return true
+	}
+	return p.craft(item)
+}
+
+// craft makes one item from its ingredients
+func (p *craftPlanner) craft(item huntItem) bool {
+	tier := craftTier(item)
+	if tier == nil || !tier.Craftable || tier.Recipe == nil || len(tier.Recipe.Ingredients) == 0 {
+		p.missing[item]++
+		return false
+	}
+
+	ready := true
+	for _, ingredient := range tier.Recipe.Ingredients {
+		for range ingredient.Count {
+			if !p.take(craftIngredient(ingredient)) {
+				ready = false
+			}
+		}
+	}
+
+	step, ok := p.steps[item]
+	if !ok {
+		step = &CraftStep{Item: HuntNeed{Artifact: item.Artifact, Tier: item.Tier}}
+		p.steps[item] = step
+	}
+	step.Item.Quantity++
+	if ready {
+		step.Ready++
+	}
+	xp := craftXP(item)
+	step.XP += xp
+	p.xp += xp
+	return ready
+}
+
+func craftNeeds(counts map[huntItem]int) []HuntNeed {
+	needs := make([]HuntNeed, 0, len(counts))
+	for item, qty := range counts {
+		if qty > 0 {
+			needs = append(needs, HuntNeed{Artifact: item.Artifact, Tier: item.Tier, Quantity: qty})
+		}
+	}
+	sortHuntNeeds(needs)
+	return needs
+}
+
+func sortHuntNeeds(needs []HuntNeed) {
+	sort.Slice(needs, func(i, j int) bool {
+		if needs[i].Tier != needs[j].Tier {
+			return needs[i].Tier < needs[j].Tier
+		}
+		return needs[i].Name() < needs[j].Name()
+	})
+}
+
+func sortCraftSteps(steps []CraftStep) {
+	sort.Slice(steps, func(i, j int) bool {
+		if steps[i].Item.Tier != steps[j].Item.Tier {
+			return steps[i].Item.Tier < steps[j].Item.Tier
+		}
+		return steps[i].Item.Name() < steps[j].Item.Name()
+	})
+}
+
+// craftSpareIngredients crafts the leftover ingredients as far up their
+// families as they go, lowest tier first so each tier feeds the next
+func craftSpareIngredients(inventory map[huntItem]int) ([]CraftStep, float64) {
+	candidates := make(map[huntItem]bool)
+	for item, qty := range inventory {
+		if qty <= 0 {
+			continue
+		}
+		tier := craftTier(item)
+		if tier == nil || tier.AfxType != ei.ArtifactSpec_INGREDIENT {
+			continue
+		}
+		for next := (huntItem{Artifact: item.Artifact, Tier: item.Tier + 1}); craftTier(next) != nil; next.Tier++ {
+			candidates[next] = true
+		}
+	}
+	order := make([]huntItem, 0, len(candidates))
+	for item := range candidates {
+		order = append(order, item)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].Tier != order[j].Tier {
+			return order[i].Tier < order[j].Tier
+		}
+		return order[i].Artifact < order[j].Artifact
+	})
+
+	var steps []CraftStep
+	var totalXP float64
+	for _, item := range order {
+		tier := craftTier(item)
+		if tier == nil || !tier.Craftable || tier.Recipe == nil || len(tier.Recipe.Ingredients) == 0 {
+			continue
+		}
+		crafts := math.MaxInt
+		for _, ingredient := range tier.Recipe.Ingredients {
+			if ingredient.Count > 0 {
+				crafts = min(crafts, inventory[craftIngredient(ingredient)]/int(ingredient.Count))
+			}
+		}
+		if crafts <= 0 || crafts == math.MaxInt {
+			continue
+		}
+		for _, ingredient := range tier.Recipe.Ingredients {
+			inventory[craftIngredient(ingredient)] -= crafts * int(ingredient.Count)
+		}
+		inventory[item] += crafts
+		xp := float64(crafts) * craftXP(item)
+		steps = append(steps, CraftStep{Item: HuntNeed{Artifact: item.Artifact, Tier: item.Tier, Quantity: crafts}, Ready: crafts, XP: xp})
+		totalXP += xp
+	}
+	return steps, totalXP
+}
+
+// craftInventory counts the common items in a backup. Rare items are left
+// out so they're never suggested as ingredients.
+func craftInventory(backup *ei.Backup) (common map[huntItem]int, all map[huntItem]int) {
+	common = make(map[huntItem]int)
+	all = make(map[huntItem]int)
+	for _, item := range backup.GetArtifactsDb().GetInventoryItems() {
+		spec := item.GetArtifact().GetSpec()
+		if spec == nil {
+			continue
+		}
+		key := huntItem{Artifact: spec.GetName(), Tier: int(spec.GetLevel()) + 1}
+		all[key] += int(item.GetQuantity())
+		if spec.GetRarity() == ei.ArtifactSpec_COMMON {
+			common[key] += int(item.GetQuantity())
+		}
+	}
+	return common, all
+}
+
+// buildCraftPlan works out the crafts for a target from an inventory
+func buildCraftPlan(target HuntNeed, inventory map[huntItem]int) *CraftPlan {
+	p := &craftPlanner{
+		inventory: make(map[huntItem]int, len(inventory)),
+		used:      make(map[huntItem]int),
+		missing:   make(map[huntItem]int),
+		steps:     make(map[huntItem]*CraftStep),
+	}
+	for item, qty := range inventory {
+		p.inventory[item] = qty
+	}
+
+	targetItem := huntItem{Artifact: target.Artifact, Tier: target.Tier}
+	plan := &CraftPlan{Target: target}
+	for range target.Quantity {
+		p.craft(targetItem)
+	}
+	// A target that can't be crafted only lands in missing
+	delete(p.missing, targetItem)
+
+	for _, step := range p.steps {
+		plan.Steps = append(plan.Steps, *step)
+	}
+	sortCraftSteps(plan.Steps)
+	plan.Used = craftNeeds(p.used)
+	plan.Missing = craftNeeds(p.missing)
+	plan.XP = p.xp
+	plan.Spare, plan.SpareXP = craftSpareIngredients(p.inventory)
+	return plan
+}
+
+// PlanCraft works out how to craft a target from the player's inventory
+func PlanCraft(backup *ei.Backup, target HuntNeed) *CraftPlan {
+	common, all := craftInventory(backup)
+	plan := buildCraftPlan(target, common)
+	plan.Owned = all[huntItem{Artifact: target.Artifact, Tier: target.Tier}]
+	plan.CurrentXP = backup.GetArtifacts().GetCraftingXp()
+	return plan
+}
+
+// PrintCraftPlan formats a crafting plan for Discord
+func PrintCraftPlan(plan *CraftPlan) string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "## Crafting %d× %s\n", plan.Target.Quantity, plan.Target.Name())
+	if plan.Owned > 0 {
+		fmt.Fprintf(&output, "You already own %d.\n", plan.Owned)
+	}
+
+	if len(plan.Steps) == 0 {
+		output.WriteString("This item can't be crafted, it has to be found on missions.\n")
+	} else {
+		output.WriteString("### Crafts\n")
+		for _, step := range plan.Steps {
+			status := "⏳"
+			if step.Ready == step.Item.Quantity {
+				status = "✅"
+			}
+			fmt.Fprintf(&output, "%s **%d×** %s", status, step.Item.Quantity, step.Item.Name())
+			if step.Ready > 0 && step.Ready < step.Item.Quantity {
+				fmt.Fprintf(&output, " (%d ready now)", step.Ready)
+			}
+			fmt.Fprintf(&output, " %s XP\n", ei.FormatEIValue(step.XP, map[string]any{"decimals": 0, "trim": true}))
+		}
+	}
+
+	if len(plan.Used) > 0 {
+		var used []string
+		for _, need := range plan.Used {
+			used = append(used, fmt.Sprintf("%d× %s", need.Quantity, need.Name()))
+		}
+		fmt.Fprintf(&output, "**From inventory:** %s\n", strings.Join(used, ", "))
+	}
+	if len(plan.Missing) > 0 {
+		var missing []string
+		for _, need := range plan.Missing {
+			missing = append(missing, fmt.Sprintf("%d× %s", need.Quantity, need.Name()))
+		}
+		fmt.Fprintf(&output, "**Missing:** %s\n", strings.Join(missing, ", "))
+	} else if len(plan.Steps) > 0 {
+		output.WriteString("Everything needed is in your inventory.\n")
+	}
+	if plan.XP > 0 {
+		fmt.Fprintf(&output, "Crafting XP: **%s**\n", ei.FormatEIValue(plan.XP, map[string]any{"decimals": 0, "trim": true}))
+	}
+
+	if len(plan.Spare) > 0 {
+		output.WriteString("### Spare Ingredients\n")
+		for _, step := range plan.Spare {
+			fmt.Fprintf(&output, "> **%d×** %s %s XP\n", step.Item.Quantity, step.Item.Name(), ei.FormatEIValue(step.XP, map[string]any{"decimals": 0, "trim": true}))
+		}
+		fmt.Fprintf(&output, "Crafting the leftovers gives **%s** XP", ei.FormatEIValue(plan.SpareXP, map[string]any{"decimals": 0, "trim": true}))
+		if plan.CurrentXP > 0 {
+			fmt.Fprintf(&output, ", crafting level %d ➜ %d", ei.GetCraftingLevel(plan.CurrentXP), ei.GetCraftingLevel(plan.CurrentXP+plan.XP+plan.SpareXP))
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
+
+// huntNeedsCommand formats needs as a /hunt plan shopping list
+func huntNeedsCommand(needs []HuntNeed) string {
+	var items []string
+	for _, need := range needs {
+		items = append(items, fmt.Sprintf("%dx %s", need.Quantity, need.Name()))
+	}
+	return strings.Join(items, ", ")
+}
+
+// SlashCraftCommand returns the command for the /craft command
+func SlashCraftCommand(cmd string) *discordgo.ApplicationCommand {
+	integerOneMinValue := float64(1)
+	return &discordgo.ApplicationCommand{
+		Name:        cmd,
+		Description: "Plan crafting an artifact from your inventory",
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+			discordgo.InteractionContextBotDM,
+			discordgo.InteractionContextPrivateChannel,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+			discordgo.ApplicationIntegrationUserInstall,
+		},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "artifact",
+				Description:  "What artifact or ingredient to craft, searchable",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "tier",
+				Description: "Tier to craft, default the highest",
+				MinValue:    &integerOneMinValue,
+				MaxValue:    4,
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "quantity",
+				Description: "How many to craft, default 1",
+				MinValue:    &integerOneMinValue,
+				MaxValue:    100,
+				Required:    false,
+			},
+		},
+	}
+}
+
+// HandleCraftCommand handles the /craft command
+func HandleCraftCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	optionMap := bottools.GetCommandOptionsMap(i)
+	userID := bottools.GetInteractionUserID(i)
+	flags := discordgo.MessageFlagsIsComponentsV2
+
+	respondEphemeral := func(content string) {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Flags: flags | discordgo.MessageFlagsEphemeral,
+				Components: []discordgo.MessageComponent{
+					discordgo.TextDisplay{
+						Content: content,
+					},
+				},
+			},
+		})
+	}
+
+	// This command requires the user to be registered
+	eiID := farmerstate.GetMiscSettingString(userID, "encrypted_ei_id")
+	if eiID == "" {
+		respondEphemeral(fmt.Sprintf("You must register your EI ID with the bot to use this command. Use the %s command.", bottools.GetFormattedCommand("register")))
+		return
+	}
+
+	target := HuntNeed{Quantity: 1}
+	if opt, ok := optionMap["artifact"]; ok {
+		if id, err := strconv.Atoi(opt.StringValue()); err == nil {
+			target.Artifact = ei.ArtifactSpec_Name(id)
+		} else if artifact, err := findHuntArtifact(opt.StringValue()); err == nil {
+			target.Artifact = artifact
+		} else {
+			respondEphemeral(err.Error())
+			return
+		}
+	}
+	if opt, ok := optionMap["tier"]; ok {
+		target.Tier = int(opt.IntValue())
+	} else {
+		for tier := len(ei.ArtifactLevels); tier > 0; tier-- {
+			if craftTier(huntItem{Artifact: target.Artifact, Tier: tier}) != nil {
+				target.Tier = tier
+				break
+			}
+		}
+	}
+	if opt, ok := optionMap["quantity"]; ok {
+		target.Quantity = int(opt.IntValue())
+	}
+	if target.Tier == 0 || craftTier(huntItem{Artifact: target.Artifact, Tier: target.Tier}) == nil {
+		respondEphemeral("The artifact data isn't loaded or that tier doesn't exist.")
+		return
+	}
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Processing request...",
+			Flags:   flags,
+		},
+	})
+
+	var components []discordgo.MessageComponent
+	backup, _ := ei.GetFirstContactFromAPI(s, eiID, userID, true)
+	if backup == nil {
+		components = append(components, discordgo.TextDisplay{Content: "Unable to retrieve your backup from Egg, Inc."})
+	} else {
+		plan := PlanCraft(backup, target)
+		components = append(components, discordgo.TextDisplay{Content: PrintCraftPlan(plan)})
+
+		// Suggest the missions that find the missing items
+		if len(plan.Missing) > 0 {
+			objective := farmerstate.GetMiscSettingString(userID, "huntPlanObjective")
+			if objective == "" {
+				objective = HuntMinimizeMissions
+			}
+			minimumDrops := DefaultMinimumDrops
+			if saved, err := strconv.Atoi(farmerstate.GetMiscSettingString(userID, "huntMinimumDrops")); err == nil && saved >= 0 {
+				minimumDrops = saved
+			}
+			candidates := getHuntCandidates(backup, plan.Missing, int64(minimumDrops))
+			huntPlan := buildHuntPlan(candidates, plan.Missing, nil, objective)
+			components = append(components,
+				bottools.NewSmallSeparatorComponent(true),
+				discordgo.TextDisplay{Content: PrintHuntPlan(huntPlan, objective) +
+					fmt.Sprintf("-# Adjust the list with %s `items:%s`\n", bottools.GetFormattedCommand("hunt plan"), huntNeedsCommand(plan.Missing))},
+			)
+		}
+	}
+
+	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Flags:      flags,
+		Components: components,
+	})
+	if err != nil {
+		fmt.Printf("HandleCraftCommand error: %v\n", err)
+	}
+}
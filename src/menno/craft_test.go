@@ -0,0 +1,92 @@
+package menno
+
+import (
+	"testing"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+)
+
+// useCraftRecipes replaces the recipe data with a small tree for a test
+func useCraftRecipes(t *testing.T) {
+	gusset := ei.ArtifactSpec_ORNATE_GUSSET
+	meteorite := ei.ArtifactSpec_GOLD_METEORITE
+	ingredient := func(name ei.ArtifactSpec_Name, tier int, count uint32) ei.Ingredient {
+		return ei.Ingredient{CoreTier: ei.CoreTier{ItemIdentifiers: ei.ItemIdentifiers{AfxID: name, AfxLevel: ei.ArtifactSpec_Level(tier - 1)}}, Count: count}
+	}
+	tiers := map[huntItem]*ei.Tier{
+		{Artifact: meteorite, Tier: 1}: {CoreTier: ei.CoreTier{AfxType: ei.ArtifactSpec_INGREDIENT}},
+		{Artifact: meteorite, Tier: 2}: {CoreTier: ei.CoreTier{AfxType: ei.ArtifactSpec_INGREDIENT}, Craftable: true,
+			Recipe: &ei.Recipe{Ingredients: []ei.Ingredient{ingredient(meteorite, 1, 3)}}},
+		{Artifact: meteorite, Tier: 3}: {CoreTier: ei.CoreTier{AfxType: ei.ArtifactSpec_INGREDIENT}, Craftable: true,
+			Recipe: &ei.Recipe{Ingredients: []ei.Ingredient{ingredient(meteorite, 2, 3)}}},
+		{Artifact: gusset, Tier: 1}: {CoreTier: ei.CoreTier{AfxType: ei.ArtifactSpec_ARTIFACT}},
+		{Artifact: gusset, Tier: 2}: {CoreTier: ei.CoreTier{AfxType: ei.ArtifactSpec_ARTIFACT}, Craftable: true,
+			Recipe: &ei.Recipe{Ingredients: []ei.Ingredient{ingredient(gusset, 1, 2), ingredient(meteorite, 2, 1)}}},
+	}
+	xp := map[huntItem]float64{
+		{Artifact: meteorite, Tier: 2}: 10,
+		{Artifact: meteorite, Tier: 3}: 50,
+		{Artifact: gusset, Tier: 2}:    100,
+	}
+
+	savedTier, savedXP := craftTier, craftXP
+	craftTier = func(item huntItem) *ei.Tier { return tiers[item] }
+	craftXP = func(item huntItem) float64 { return xp[item] }
+	t.Cleanup(func() { craftTier, craftXP = savedTier, savedXP })
+}
+
+func TestBuildCraftPlan(t *testing.T) {
+	useCraftRecipes(t)
+	gusset := huntItem{Artifact: ei.ArtifactSpec_ORNATE_GUSSET, Tier: 1}
+	meteorite := huntItem{Artifact: ei.ArtifactSpec_GOLD_METEORITE, Tier: 1}
+
+	target := HuntNeed{Artifact: ei.ArtifactSpec_ORNATE_GUSSET, Tier: 2, Quantity: 2}
+	plan := buildCraftPlan(target, map[huntItem]int{gusset: 3, meteorite: 10})
+
+	// The first gusset is ready, the second is a T1 gusset short
+	want := []CraftStep{
+		{Item: HuntNeed{Artifact: ei.ArtifactSpec_GOLD_METEORITE, Tier: 2, Quantity: 2}, Ready: 2, XP: 20},
+		{Item: HuntNeed{Artifact: ei.ArtifactSpec_ORNATE_GUSSET, Tier: 2, Quantity: 2}, Ready: 1, XP: 200},
+	}
+	if len(plan.Steps) != len(want) {
+		t.Fatalf("steps = %+v, want %+v", plan.Steps, want)
+	}
+	for n := range want {
+		if plan.Steps[n] != want[n] {
+			t.Errorf("step %d = %+v, want %+v", n, plan.Steps[n], want[n])
+		}
+	}
+	if len(plan.Missing) != 1 || plan.Missing[0] != (HuntNeed{Artifact: ei.ArtifactSpec_ORNATE_GUSSET, Tier: 1, Quantity: 1}) {
+		t.Errorf("missing = %+v, want one T1 gusset", plan.Missing)
+	}
+	if plan.XP != 220 {
+		t.Errorf("xp = %v, want 220", plan.XP)
+	}
+
+	// The four meteorites left make one more T2
+	if len(plan.Spare) != 1 || plan.Spare[0].Item.Quantity != 1 || plan.Spare[0].Item.Tier != 2 || plan.SpareXP != 10 {
+		t.Errorf("spare = %+v (%v XP), want one T2 meteorite for 10 XP", plan.Spare, plan.SpareXP)
+	}
+}
+
+func TestCraftSpareIngredients(t *testing.T) {
+	useCraftRecipes(t)
+	meteorite := huntItem{Artifact: ei.ArtifactSpec_GOLD_METEORITE, Tier: 1}
+
+	// 10 T1 make 3 T2, which make 1 T3
+	steps, xp := craftSpareIngredients(map[huntItem]int{meteorite: 10})
+	if len(steps) != 2 || steps[0].Item.Quantity != 3 || steps[1].Item.Quantity != 1 {
+		t.Fatalf("steps = %+v, want 3 T2 then 1 T3", steps)
+	}
+	if xp != 80 {
+		t.Errorf("xp = %v, want 80", xp)
+	}
+}
+
+func TestBuildCraftPlanUncraftable(t *testing.T) {
+	useCraftRecipes(t)
+	plan := buildCraftPlan(HuntNeed{Artifact: ei.ArtifactSpec_ORNATE_GUSSET, Tier: 1, Quantity: 1}, nil)
+	if len(plan.Steps) != 0 || len(plan.Missing) != 0 {
+		t.Errorf("plan = %+v, want nothing to craft", plan)
+	}
+}
@@ -0,0 +1,476 @@
+package menno
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+)
+
+// Hunt plan objectives
+const (
+	HuntMinimizeMissions = "missions" // fewest missions launched
+	HuntMinimizeDays     = "days"     // fewest days with every slot busy
+)
+
+// huntPlanSlots is the number of missions a player can have in flight
+const huntPlanSlots = 3
+
+// huntConfidenceZ is the z-score of the reported 95% drop ranges
+const huntConfidenceZ = 1.96
+
+// HuntNeed is an artifact, stone or ingredient the player wants more of
+type HuntNeed struct {
+	Artifact ei.ArtifactSpec_Name
+	Tier     int // 1 to 4 as shown in game
+	Quantity int
+}
+
+// Name is the need as shown to the player, such as "T4 Gusset"
+func (n HuntNeed) Name() string {
+	return fmt.Sprintf("T%d %s", n.Tier, ei.ArtifactTypeName[int32(n.Artifact)])
+}
+
+type huntItem struct {
+	Artifact ei.ArtifactSpec_Name
+	Tier     int
+}
+
+// huntRate is the expected haul of one item from one mission
+type huntRate struct {
+	PerMission float64 // expected items per mission
+	Rate       float64 // share of all drops
+	Samples    int64   // drops recorded for this mission
+}
+
+// huntCandidate is one mission the planner can send
+type huntCandidate struct {
+	Ship         ei.MissionInfo_Spaceship
+	DurationType ei.MissionInfo_DurationType
+	Level        int
+	Target       ei.ArtifactSpec_Name
+	Duration     time.Duration
+	Capacity     float64
+	Rates        map[huntItem]huntRate
+}
+
+// HuntPlanStep is a batch of identical missions in the plan
+type HuntPlanStep struct {
+	Ship         ei.MissionInfo_Spaceship
+	DurationType ei.MissionInfo_DurationType
+	Level        int
+	Target       ei.ArtifactSpec_Name
+	Missions     int
+	Duration     time.Duration
+	Capacity     float64
+}
+
+// HuntPlanItem reports how a plan covers one need
+type HuntPlanItem struct {
+	Need      HuntNeed
+	Owned     int
+	Remaining int
+	Expected  float64 // expected drops from the plan
+	Low       float64 // 95% range from the drop data sample sizes
+	High      float64
+	Samples   int64 // smallest sample behind the expected drops
+}
+
+// HuntPlan is the recommended set of missions for a shopping list
+type HuntPlan struct {
+	Steps       []HuntPlanStep
+	Items       []HuntPlanItem
+	Missions    int
+	Days        float64
+	Unreachable []HuntNeed // needs no known mission drops
+}
+
+var huntTierRe = regexp.MustCompile(`(?i)\bt([1-4])\b`)
+var huntLeadingQtyRe = regexp.MustCompile(`(?i)^(\d+)\s*x?\s+`)
+var huntTrailingQtyRe = regexp.MustCompile(`(?i)\s+x?\s*(\d+)$|\s*x(\d+)$`)
+
+// parseHuntNeeds reads a shopping list such as "2x T4 Gusset, T3 Gold
+// Meteorite x10, tachyon stone t2". Items without a tier are tier 1.
+func parseHuntNeeds(input string) ([]HuntNeed, error) {
+	var needs []HuntNeed
+	for _, entry := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		need := HuntNeed{Tier: 1, Quantity: 1}
+		if m := huntTierRe.FindStringSubmatch(entry); m != nil {
+			need.Tier, _ = strconv.Atoi(m[1])
+			entry = strings.TrimSpace(huntTierRe.ReplaceAllString(entry, " "))
+		}
+		if m := huntLeadingQtyRe.FindStringSubmatch(entry); m != nil {
+			need.Quantity, _ = strconv.Atoi(m[1])
+			entry = strings.TrimSpace(entry[len(m[0]):])
+		} else if m := huntTrailingQtyRe.FindStringSubmatch(entry); m != nil {
+			qty := m[1]
+			if qty == "" {
+				qty = m[2]
+			}
+			need.Quantity, _ = strconv.Atoi(qty)
+			entry = strings.TrimSpace(entry[:len(entry)-len(m[0])])
+		}
+		if need.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity in %q", entry)
+		}
+		artifact, err := findHuntArtifact(entry)
+		if err != nil {
+			return nil, err
+		}
+		need.Artifact = artifact
+		needs = append(needs, need)
+	}
+	if len(needs) == 0 {
+		return nil, fmt.Errorf("the shopping list is empty")
+	}
+	return needs, nil
+}
+
+func normalizeHuntName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer("'", "", "’", "", "-", " ").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// findHuntArtifact matches a name, or an unambiguous part of one
+func findHuntArtifact(name string) (ei.ArtifactSpec_Name, error) {
+	search := normalizeHuntName(name)
+	var matches []int32
+	for id, artifactName := range ei.ArtifactTypeName {
+		if id == 10000 {
+			continue
+		}
+		normalized := normalizeHuntName(artifactName)
+		if normalized == search {
+			return ei.ArtifactSpec_Name(id), nil
+		}
+		if strings.Contains(normalized, search) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("unknown artifact %q", name)
+	case 1:
+		return ei.ArtifactSpec_Name(matches[0]), nil
+	}
+	names := make([]string, 0, len(matches))
+	for _, id := range matches {
+		names = append(names, ei.ArtifactTypeName[id])
+	}
+	sort.Strings(names)
+	return 0, fmt.Errorf("%q could be %s", name, strings.Join(names, ", "))
+}
+
+// huntInventory counts the spare items in a backup by artifact and tier
+func huntInventory(backup *ei.Backup) map[huntItem]int {
+	owned := make(map[huntItem]int)
+	for _, item := range backup.GetArtifactsDb().GetInventoryItems() {
+		spec := item.GetArtifact().GetSpec()
+		if spec == nil {
+			continue
+		}
+		key := huntItem{Artifact: spec.GetName(), Tier: int(spec.GetLevel()) + 1}
+		owned[key] += int(item.GetQuantity())
+	}
+	return owned
+}
+
+// planHuntMissions greedily covers the remaining items. It repeatedly picks
+// the mission bringing back the most still needed items per mission (or per
+// hour for the days objective) and sends it until one of them is covered.
+func planHuntMissions(candidates []huntCandidate, remaining map[huntItem]float64, objective string) map[int]float64 {
+	rem := make(map[huntItem]float64, len(remaining))
+	for item, qty := range remaining {
+		rem[item] = qty
+	}
+	allocation := make(map[int]float64)
+
+	// Every round covers at least one item
+	for round := 0; round <= len(rem); round++ {
+		best := -1
+		var bestScore float64
+		for n, c := range candidates {
+			useful := 0.0
+			for item, r := range c.Rates {
+				if rem[item] > 0 {
+					useful += r.PerMission
+				}
+			}
+			if useful <= 0 {
+				continue
+			}
+			score := useful
+			if objective == HuntMinimizeDays && c.Duration > 0 {
+				score = useful / c.Duration.Hours()
+			}
+			if best < 0 || score > bestScore {
+				best, bestScore = n, score
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		missions := math.Inf(1)
+		for item, r := range candidates[best].Rates {
+			if rem[item] > 0 && r.PerMission > 0 {
+				missions = math.Min(missions, rem[item]/r.PerMission)
+			}
+		}
+		for item, r := range candidates[best].Rates {
+			rem[item] = math.Max(0, rem[item]-r.PerMission*missions)
+		}
+		allocation[best] += missions
+	}
+	return allocation
+}
+
+// buildHuntPlan turns the mission allocation into whole missions and reports
+// the expected drops of every need
+func buildHuntPlan(candidates []huntCandidate, needs []HuntNeed, owned map[huntItem]int, objective string) *HuntPlan {
+	plan := &HuntPlan{}
+	remaining := make(map[huntItem]float64)
+	for _, need := range needs {
+		key := huntItem{Artifact: need.Artifact, Tier: need.Tier}
+		have := owned[key]
+		left := max(need.Quantity-have, 0)
+		plan.Items = append(plan.Items, HuntPlanItem{Need: need, Owned: have, Remaining: left})
+		if left > 0 {
+			remaining[key] += float64(left)
+		}
+	}
+
+	allocation := planHuntMissions(candidates, remaining, objective)
+	indexes := make([]int, 0, len(allocation))
+	for n := range allocation {
+		indexes = append(indexes, n)
+	}
+	sort.Ints(indexes)
+
+	var slotHours float64
+	for _, n := range indexes {
+		c := candidates[n]
+		missions := int(math.Ceil(allocation[n] - 1e-9))
+		if missions <= 0 {
+			continue
+		}
+		plan.Steps = append(plan.Steps, HuntPlanStep{
+			Ship:         c.Ship,
+			DurationType: c.DurationType,
+			Level:        c.Level,
+			Target:       c.Target,
+			Missions:     missions,
+			Duration:     c.Duration,
+			Capacity:     c.Capacity,
+		})
+		plan.Missions += missions
+		slotHours += float64(missions) * c.Duration.Hours()
+
+		for idx := range plan.Items {
+			key := huntItem{Artifact: plan.Items[idx].Need.Artifact, Tier: plan.Items[idx].Need.Tier}
+			r, ok := c.Rates[key]
+			if !ok || r.PerMission <= 0 {
+				continue
+			}
+			item := &plan.Items[idx]
+			item.Expected += float64(missions) * r.PerMission
+			se := 0.0
+			if r.Samples > 0 {
+				se = math.Sqrt(r.Rate * (1 - r.Rate) / float64(r.Samples))
+			}
+			perArtifact := r.PerMission / r.Rate
+			item.Low += float64(missions) * perArtifact * math.Max(0, r.Rate-huntConfidenceZ*se)
+			item.High += float64(missions) * perArtifact * (r.Rate + huntConfidenceZ*se)
+			if item.Samples == 0 || r.Samples < item.Samples {
+				item.Samples = r.Samples
+			}
+		}
+	}
+	plan.Days = slotHours / huntPlanSlots / 24
+
+	for _, item := range plan.Items {
+		if item.Remaining > 0 && item.Expected == 0 {
+			plan.Unreachable = append(plan.Unreachable, item.Need)
+		}
+	}
+	return plan
+}
+
+// huntShipCapacities returns the largest capacity seen in the player's
+// missions for each ship, duration and star level
+func huntShipCapacities(backup *ei.Backup) map[[3]int]float64 {
+	capacities := make(map[[3]int]float64)
+	afx := backup.GetArtifactsDb()
+	for _, mi := range append(afx.GetMissionInfos(), afx.GetMissionArchive()...) {
+		key := [3]int{int(mi.GetShip()), int(mi.GetDurationType()), int(mi.GetLevel())}
+		capacities[key] = math.Max(capacities[key], float64(mi.GetCapacity()))
+	}
+	return capacities
+}
+
+// getHuntCandidates reads the drop data for every ship the player has
+// unlocked, at their star level, targeting each needed artifact or nothing
+func getHuntCandidates(backup *ei.Backup, needs []HuntNeed, minimumDrops int64) []huntCandidate {
+	afx := backup.GetArtifactsDb()
+	shipLevels := make(map[ei.MissionInfo_Spaceship]int)
+	for _, mi := range append(afx.GetMissionInfos(), afx.GetMissionArchive()...) {
+		if level, ok := shipLevels[mi.GetShip()]; !ok || int(mi.GetLevel()) > level {
+			shipLevels[mi.GetShip()] = int(mi.GetLevel())
+		}
+	}
+	capacities := huntShipCapacities(backup)
+
+	ftlMult := 1.0
+	capacityMult := 1.0
+	if game := backup.GetGame(); game != nil {
+		ftlMult = ei.GetEpicResearchMissionTime(game.GetEpicResearch())
+		capacityMult = ei.GetEpicResearchMissionCapacity(game.GetEpicResearch())
+	}
+
+	targets := []ei.ArtifactSpec_Name{ei.ArtifactSpec_Name(10000)}
+	for _, need := range needs {
+		if !containsHuntTarget(targets, need.Artifact) {
+			targets = append(targets, need.Artifact)
+		}
+	}
+
+	var ships []ei.MissionInfo_Spaceship
+	for ship := range shipLevels {
+		ships = append(ships, ship)
+	}
+	sort.Slice(ships, func(i, j int) bool { return ships[i] < ships[j] })
+
+	var candidates []huntCandidate
+	for _, ship := range ships {
+		level := shipLevels[ship]
+		for _, dt := range []ei.MissionInfo_DurationType{ei.MissionInfo_SHORT, ei.MissionInfo_LONG, ei.MissionInfo_EPIC} {
+			capacity := capacities[[3]int{int(ship), int(dt), level}]
+			if capacity == 0 {
+				capacity = ei.GetMissionCapacity(ship, dt, level) * capacityMult
+			}
+			if capacity == 0 {
+				continue
+			}
+
+			// Drops of each needed artifact, grouped by the mission target
+			byTarget := make(map[ei.ArtifactSpec_Name]map[huntItem]huntRate)
+			for _, need := range needs {
+				key := huntItem{Artifact: need.Artifact, Tier: need.Tier}
+				drops := make(map[ei.ArtifactSpec_Name]int64)
+				samples := make(map[ei.ArtifactSpec_Name]int64)
+				for _, row := range GetShipDropData(ship, dt, level, need.Artifact) {
+					if int(row.ArtifactTier.Int64)+1 != need.Tier {
+						continue
+					}
+					target := ei.ArtifactSpec_Name(row.TargetArtifactID.Int64)
+					drops[target] += row.TotalDrops.Int64
+					samples[target] = int64(asFloat64(row.AllDropsValue))
+				}
+				for target, count := range drops {
+					if samples[target] < minimumDrops || samples[target] == 0 || count == 0 {
+						continue
+					}
+					if byTarget[target] == nil {
+						byTarget[target] = make(map[huntItem]huntRate)
+					}
+					rate := float64(count) / float64(samples[target])
+					byTarget[target][key] = huntRate{PerMission: rate * capacity, Rate: rate, Samples: samples[target]}
+				}
+			}
+
+			for _, target := range targets {
+				// The first ships can't be sent after a target
+				if ship <= ei.MissionInfo_CHICKEN_HEAVY && target != ei.ArtifactSpec_Name(10000) {
+					continue
+				}
+				if len(byTarget[target]) == 0 {
+					continue
+				}
+				candidates = append(candidates, huntCandidate{
+					Ship:         ship,
+					DurationType: dt,
+					Level:        level,
+					Target:       target,
+					Duration:     time.Duration(float64(ei.GetMissionBaseDuration(ship, dt)) * ftlMult),
+					Capacity:     capacity,
+					Rates:        byTarget[target],
+				})
+			}
+		}
+	}
+	return candidates
+}
+
+func containsHuntTarget(targets []ei.ArtifactSpec_Name, target ei.ArtifactSpec_Name) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanHunt recommends the missions that complete a shopping list with the
+// fewest missions or days, after taking the player's inventory into account
+func PlanHunt(backup *ei.Backup, needs []HuntNeed, objective string, minimumDrops int) *HuntPlan {
+	candidates := getHuntCandidates(backup, needs, int64(minimumDrops))
+	return buildHuntPlan(candidates, needs, huntInventory(backup), objective)
+}
+
+// PrintHuntPlan formats a hunt plan for Discord
+func PrintHuntPlan(plan *HuntPlan, objective string) string {
+	var output strings.Builder
+	output.WriteString("## Hunt Plan\n")
+
+	output.WriteString("### Shopping List\n")
+	for _, item := range plan.Items {
+		switch {
+		case item.Remaining == 0:
+			fmt.Fprintf(&output, "✅ **%s** %d/%d owned\n", item.Need.Name(), item.Owned, item.Need.Quantity)
+		case item.Expected == 0:
+			fmt.Fprintf(&output, "❌ **%s** need %d more, no drop data\n", item.Need.Name(), item.Remaining)
+		default:
+			fmt.Fprintf(&output, "🔎 **%s** need %d more, expect %.1f (%.1f–%.1f)\n", item.Need.Name(), item.Remaining, item.Expected, item.Low, item.High)
+		}
+	}
+
+	if len(plan.Steps) > 0 {
+		output.WriteString("### Missions\n")
+		for _, step := range plan.Steps {
+			durationName := ei.DurationTypeName[int32(step.DurationType)]
+			if len(durationName) >= 2 {
+				durationName = strings.ToUpper(durationName[:2])
+			}
+			target := ei.ArtifactTypeName[int32(step.Target)]
+			fmt.Fprintf(&output, "> **%d×** %s %s %s (%d⭐️) ➜ %s\n", step.Missions, durationName,
+				ei.GetBotEmojiMarkdown(ei.MissionArt.Ships[step.Ship].Art), ei.ShipTypeName[int32(step.Ship)], step.Level, target)
+		}
+		if objective == HuntMinimizeDays {
+			fmt.Fprintf(&output, "**%d missions**, about **%.1f days** with all %d slots busy\n", plan.Missions, plan.Days, huntPlanSlots)
+		} else {
+			fmt.Fprintf(&output, "**%d missions**, %.1f days with all %d slots busy\n", plan.Missions, plan.Days, huntPlanSlots)
+		}
+	}
+
+	var lowSample []string
+	for _, item := range plan.Items {
+		if item.Expected > 0 && item.High-item.Low > item.Expected {
+			lowSample = append(lowSample, item.Need.Name())
+		}
+	}
+	if len(lowSample) > 0 {
+		fmt.Fprintf(&output, "-# Few recorded drops for %s, expect a wide spread.\n", strings.Join(lowSample, ", "))
+	}
+	output.WriteString("-# Ranges are 95% intervals from the sample size of Menno's drop data.\n")
+	return output.String()
+}
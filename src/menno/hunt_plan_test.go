@@ -0,0 +1,112 @@
+package menno
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mkmccarty/TokenTimeBoostBot/src/ei"
+)
+
+func TestParseHuntNeeds(t *testing.T) {
+	needs, err := parseHuntNeeds("2x T4 Gusset, T3 Gold Meteorite x10; tachyon stone t2 5, demeters necklace")
+	if err != nil {
+		t.Fatalf("parseHuntNeeds: %v", err)
+	}
+	want := []HuntNeed{
+		{Artifact: ei.ArtifactSpec_ORNATE_GUSSET, Tier: 4, Quantity: 2},
+		{Artifact: ei.ArtifactSpec_GOLD_METEORITE, Tier: 3, Quantity: 10},
+		{Artifact: ei.ArtifactSpec_TACHYON_STONE, Tier: 2, Quantity: 5},
+		{Artifact: ei.ArtifactSpec_DEMETERS_NECKLACE, Tier: 1, Quantity: 1},
+	}
+	if len(needs) != len(want) {
+		t.Fatalf("got %d needs, want %d: %+v", len(needs), len(want), needs)
+	}
+	for n := range want {
+		if needs[n] != want[n] {
+			t.Errorf("need %d = %+v, want %+v", n, needs[n], want[n])
+		}
+	}
+
+	if _, err := parseHuntNeeds("T2 stone"); err == nil {
+		t.Error("expected an ambiguous name to fail")
+	}
+	if _, err := parseHuntNeeds("T2 unobtainium"); err == nil {
+		t.Error("expected an unknown name to fail")
+	}
+	if _, err := parseHuntNeeds(" , "); err == nil {
+		t.Error("expected an empty list to fail")
+	}
+}
+
+func TestBuildHuntPlan(t *testing.T) {
+	gusset := huntItem{Artifact: ei.ArtifactSpec_ORNATE_GUSSET, Tier: 4}
+	meteorite := huntItem{Artifact: ei.ArtifactSpec_GOLD_METEORITE, Tier: 3}
+
+	candidates := []huntCandidate{
+		{
+			Ship:     ei.MissionInfo_HENERPRISE,
+			Target:   ei.ArtifactSpec_ORNATE_GUSSET,
+			Duration: 4 * 24 * time.Hour,
+			Capacity: 100,
+			Rates:    map[huntItem]huntRate{gusset: {PerMission: 0.5, Rate: 0.005, Samples: 100000}},
+		},
+		{
+			Ship:     ei.MissionInfo_HENERPRISE,
+			Target:   ei.ArtifactSpec_Name(10000),
+			Duration: 4 * 24 * time.Hour,
+			Capacity: 100,
+			Rates: map[huntItem]huntRate{
+				gusset:    {PerMission: 0.25, Rate: 0.0025, Samples: 100000},
+				meteorite: {PerMission: 4, Rate: 0.04, Samples: 100000},
+			},
+		},
+	}
+	needs := []HuntNeed{
+		{Artifact: ei.ArtifactSpec_ORNATE_GUSSET, Tier: 4, Quantity: 3},
+		{Artifact: ei.ArtifactSpec_GOLD_METEORITE, Tier: 3, Quantity: 10},
+		{Artifact: ei.ArtifactSpec_TACHYON_STONE, Tier: 2, Quantity: 1},
+	}
+	owned := map[huntItem]int{gusset: 1}
+
+	plan := buildHuntPlan(candidates, needs, owned, HuntMinimizeMissions)
+
+	// The untargeted mission covers the meteorites first, then the gusset
+	// target finishes off the remaining gussets
+	if plan.Missions != 6 {
+		t.Errorf("missions = %d, want 6: %+v", plan.Missions, plan.Steps)
+	}
+	if plan.Items[0].Owned != 1 || plan.Items[0].Remaining != 2 {
+		t.Errorf("gusset owned/remaining = %d/%d, want 1/2", plan.Items[0].Owned, plan.Items[0].Remaining)
+	}
+	for _, item := range plan.Items[:2] {
+		if item.Expected < float64(item.Remaining) {
+			t.Errorf("%s expected %.2f, below the %d needed", item.Need.Name(), item.Expected, item.Remaining)
+		}
+		if item.Low > item.Expected || item.High < item.Expected {
+			t.Errorf("%s range %.2f-%.2f doesn't contain %.2f", item.Need.Name(), item.Low, item.High, item.Expected)
+		}
+	}
+	if len(plan.Unreachable) != 1 || plan.Unreachable[0].Artifact != ei.ArtifactSpec_TACHYON_STONE {
+		t.Errorf("unreachable = %+v, want the tachyon stone", plan.Unreachable)
+	}
+	if math.Abs(plan.Days-6*4.0/3) > 1e-9 {
+		t.Errorf("days = %.2f, want %.2f", plan.Days, 6*4.0/3)
+	}
+}
+
+func TestPlanHuntMissionsDays(t *testing.T) {
+	item := huntItem{Artifact: ei.ArtifactSpec_GOLD_METEORITE, Tier: 1}
+	candidates := []huntCandidate{
+		{Duration: 48 * time.Hour, Rates: map[huntItem]huntRate{item: {PerMission: 10}}},
+		{Duration: 4 * time.Hour, Rates: map[huntItem]huntRate{item: {PerMission: 2}}},
+	}
+	remaining := map[huntItem]float64{item: 20}
+
+	if got := planHuntMissions(candidates, remaining, HuntMinimizeMissions); got[0] != 2 {
+		t.Errorf("missions objective = %v, want 2 of the long mission", got)
+	}
+	if got := planHuntMissions(candidates, remaining, HuntMinimizeDays); got[1] != 10 {
+		t.Errorf("days objective = %v, want 10 of the short mission", got)
+	}
+}